@@ -7,5 +7,5 @@ import (
 )
 
 func main() {
-	os.Exit(duh.RunCmd(os.Stdout, os.Args[1:]))
+	os.Exit(duh.RunCmd(os.Stdout, os.Stderr, os.Args[1:]))
 }
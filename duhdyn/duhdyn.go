@@ -0,0 +1,269 @@
+// Package duhdyn calls DUH-RPC services with protobuf messages built at
+// runtime from a descriptor set, such as 'buf build -o api.binpb' writes from
+// the proto of 'duh proto', for gateways and test harnesses which have no
+// generated Go types. The rpc of a path is found the way 'duh proto' names it
+// with --proto-service, which the proto needs for its services:
+// /v1/users.create is rpc Create of UsersService.
+package duhdyn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	dproto "github.com/duh-rpc/duh-cli/internal/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	contentTypeProtoBuf = "application/protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// ErrUnknownRPC is returned for a path without an rpc in the descriptor set
+var ErrUnknownRPC = errors.New("no rpc in the descriptor set")
+
+// Error is the error reply of a DUH-RPC service
+type Error struct {
+	// Status is the HTTP status code of the reply
+	Status  int               `json:"-"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+// Config controls the server called and the requests sent to it
+type Config struct {
+	// BaseURL is prepended to the path of every rpc, e.g. http://localhost:8080
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+	// Header is added to every request, e.g. Authorization
+	Header http.Header
+}
+
+// Client calls the rpcs of the services in a descriptor set
+type Client struct {
+	files *protoregistry.Files
+	conf  Config
+}
+
+// Load reads the binary FileDescriptorSet at path, with the imports of its
+// files included as 'buf build' and 'protoc --include_imports' write them
+func Load(path string) (*protoregistry.Files, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	return Parse(content)
+}
+
+// Parse returns the files of a binary FileDescriptorSet
+func Parse(content []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// New returns a Client for the rpcs of files
+func New(files *protoregistry.Files, conf Config) *Client {
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = http.DefaultClient
+	}
+	conf.BaseURL = strings.TrimSuffix(conf.BaseURL, "/")
+	return &Client{files: files, conf: conf}
+}
+
+// Method returns the rpc of the DUH-RPC path, with an ErrUnknownRPC error
+// when no service of the descriptor set has it
+func (c *Client) Method(path string) (protoreflect.MethodDescriptor, error) {
+	service, method := dproto.RPCName(path)
+	if method == "" {
+		return nil, fmt.Errorf("%w for %s: the path has no method, such as /v1/users.create", ErrUnknownRPC, path)
+	}
+
+	var found protoreflect.MethodDescriptor
+	c.files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		if svc := file.Services().ByName(protoreflect.Name(service)); svc != nil {
+			found = svc.Methods().ByName(protoreflect.Name(method))
+		}
+		return found == nil
+	})
+	if found == nil {
+		return nil, fmt.Errorf("%w for %s: no rpc %s in service %s", ErrUnknownRPC, path, method, service)
+	}
+	return found, nil
+}
+
+// NewRequest returns an empty request of the rpc at path
+func (c *Client) NewRequest(path string) (*dynamicpb.Message, error) {
+	method, err := c.Method(path)
+	if err != nil {
+		return nil, err
+	}
+	return dynamicpb.NewMessage(method.Input()), nil
+}
+
+// NewReply returns an empty reply of the rpc at path
+func (c *Client) NewReply(path string) (*dynamicpb.Message, error) {
+	method, err := c.Method(path)
+	if err != nil {
+		return nil, err
+	}
+	return dynamicpb.NewMessage(method.Output()), nil
+}
+
+// Call sends req to the rpc at path as protobuf and reads the reply into
+// resp. An error reply of the service is returned as an *Error.
+func (c *Client) Call(ctx context.Context, path string, req, resp proto.Message) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, c.conf.BaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range c.conf.Header {
+		r.Header[name] = values
+	}
+	r.Header.Set("Content-Type", contentTypeProtoBuf)
+	r.Header.Set("Accept", contentTypeProtoBuf)
+
+	hr, err := c.conf.HTTPClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = hr.Body.Close() }()
+
+	reply, err := io.ReadAll(hr.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read reply: %w", err)
+	}
+	contentType, _, _ := mime.ParseMediaType(hr.Header.Get("Content-Type"))
+	if hr.StatusCode != http.StatusOK {
+		return replyError(hr.StatusCode, contentType, reply)
+	}
+
+	switch contentType {
+	case contentTypeProtoBuf:
+		err = proto.Unmarshal(reply, resp)
+	case contentTypeJSON:
+		err = protojson.Unmarshal(reply, resp)
+	default:
+		return fmt.Errorf("reply has unsupported Content-Type '%s'", hr.Header.Get("Content-Type"))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode reply: %w", err)
+	}
+	return nil
+}
+
+// CallJSON calls the rpc at path with the request in JSON and returns the
+// reply in JSON, both in the protojson encoding of the messages
+func (c *Client) CallJSON(ctx context.Context, path string, request []byte) ([]byte, error) {
+	req, err := c.NewRequest(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(request, req); err != nil {
+		return nil, fmt.Errorf("invalid request for %s: %w", req.Descriptor().FullName(), err)
+	}
+	resp, err := c.NewReply(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Call(ctx, path, req, resp); err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Multiline: true}.Marshal(resp)
+}
+
+// replyError decodes the duh.v1.Reply of an error reply, which is
+// code = 1, message = 3 and details = 4, without its generated type
+func replyError(status int, contentType string, body []byte) error {
+	e := &Error{Status: status}
+	switch contentType {
+	case contentTypeJSON:
+		_ = json.Unmarshal(body, e)
+	case contentTypeProtoBuf:
+		rangeBytes(body, func(num protowire.Number, value []byte) {
+			switch num {
+			case 1:
+				e.Code = string(value)
+			case 3:
+				e.Message = string(value)
+			case 4:
+				// A map entry is key = 1 and value = 2
+				var key, val string
+				rangeBytes(value, func(num protowire.Number, value []byte) {
+					if num == 1 {
+						key = string(value)
+					} else if num == 2 {
+						val = string(value)
+					}
+				})
+				if e.Details == nil {
+					e.Details = make(map[string]string)
+				}
+				e.Details[key] = val
+			}
+		})
+	default:
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+// rangeBytes calls fn with the number and value of every length delimited
+// field of the message in b, skipping the other fields
+func rangeBytes(b []byte, fn func(num protowire.Number, value []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return
+		}
+		b = b[n:]
+		if typ != protowire.BytesType {
+			if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+				return
+			}
+			b = b[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			return
+		}
+		fn(num, value)
+		b = b[n:]
+	}
+}
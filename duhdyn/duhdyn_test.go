@@ -0,0 +1,258 @@
+package duhdyn_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/duhdyn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestCall(t *testing.T) {
+	// The descriptor set of the proto 'duh proto --proto-service' writes for
+	// /v1/users.create and /v1/users.get
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number),
+			Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), JsonName: proto.String(name)}
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	rpc := func(name, input, output string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{Name: proto.String(name),
+			InputType: proto.String(".users.v1." + input), OutputType: proto.String(".users.v1." + output)}
+	}
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("users/v1/users.proto"),
+		Package: proto.String("users.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("CreateRequest", field("name", 1)),
+			message("GetRequest", field("id", 1)),
+			message("User", field("id", 1), field("name", 2)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("UsersService"),
+			Method: []*descriptorpb.MethodDescriptorProto{rpc("Create", "CreateRequest", "User"), rpc("Get", "GetRequest", "User")},
+		}},
+	}}})
+	require.NoError(t, err)
+	files, err := duhdyn.Parse(set)
+	require.NoError(t, err)
+
+	// The server replies with the user it creates
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/users.create", r.URL.Path)
+		assert.Equal(t, "application/protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		desc, err := files.FindDescriptorByName("users.v1.CreateRequest")
+		require.NoError(t, err)
+		req := dynamicpb.NewMessage(desc.(protoreflect.MessageDescriptor))
+		require.NoError(t, proto.Unmarshal(body, req))
+		name := req.Get(req.Descriptor().Fields().ByName("name")).String()
+
+		var reply []byte
+		reply = protowire.AppendTag(reply, 1, protowire.BytesType)
+		reply = protowire.AppendString(reply, "1")
+		reply = protowire.AppendTag(reply, 2, protowire.BytesType)
+		reply = protowire.AppendString(reply, name)
+		w.Header().Set("Content-Type", "application/protobuf")
+		_, _ = w.Write(reply)
+	}))
+	defer server.Close()
+
+	client := duhdyn.New(files, duhdyn.Config{BaseURL: server.URL + "/"})
+
+	method, err := client.Method("/v1/users.create")
+	require.NoError(t, err)
+	assert.Equal(t, protoreflect.FullName("users.v1.UsersService.Create"), method.FullName())
+
+	req, err := client.NewRequest("/v1/users.create")
+	require.NoError(t, err)
+	req.Set(req.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("Ann"))
+	resp, err := client.NewReply("/v1/users.create")
+	require.NoError(t, err)
+
+	require.NoError(t, client.Call(context.Background(), "/v1/users.create", req, resp))
+	assert.Equal(t, "1", resp.Get(resp.Descriptor().Fields().ByName("id")).String())
+	assert.Equal(t, "Ann", resp.Get(resp.Descriptor().Fields().ByName("name")).String())
+
+	reply, err := client.CallJSON(context.Background(), "/v1/users.create", []byte(`{"name": "Bob"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "1", "name": "Bob"}`, string(reply))
+}
+
+func TestCallErrors(t *testing.T) {
+	// The descriptor set of the proto 'duh proto --proto-service' writes for
+	// /v1/users.create and /v1/users.get
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number),
+			Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), JsonName: proto.String(name)}
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	rpc := func(name, input, output string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{Name: proto.String(name),
+			InputType: proto.String(".users.v1." + input), OutputType: proto.String(".users.v1." + output)}
+	}
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("users/v1/users.proto"),
+		Package: proto.String("users.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("CreateRequest", field("name", 1)),
+			message("GetRequest", field("id", 1)),
+			message("User", field("id", 1), field("name", 2)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("UsersService"),
+			Method: []*descriptorpb.MethodDescriptorProto{rpc("Create", "CreateRequest", "User"), rpc("Get", "GetRequest", "User")},
+		}},
+	}}})
+	require.NoError(t, err)
+	files, err := duhdyn.Parse(set)
+	require.NoError(t, err)
+
+	// The server replies with a not found error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/protobuf", r.Header.Get("Content-Type"))
+		var reply []byte
+		reply = protowire.AppendTag(reply, 1, protowire.BytesType)
+		reply = protowire.AppendString(reply, "404")
+		reply = protowire.AppendTag(reply, 3, protowire.BytesType)
+		reply = protowire.AppendString(reply, "user not found")
+		w.Header().Set("Content-Type", "application/protobuf")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write(reply)
+	}))
+	defer server.Close()
+
+	client := duhdyn.New(files, duhdyn.Config{BaseURL: server.URL})
+
+	_, err = client.CallJSON(context.Background(), "/v1/users.get", []byte(`{"id": "2"}`))
+	var reply *duhdyn.Error
+	require.True(t, errors.As(err, &reply))
+	assert.Equal(t, http.StatusNotFound, reply.Status)
+	assert.Equal(t, "404", reply.Code)
+	assert.EqualError(t, err, "404 user not found")
+
+	_, err = client.CallJSON(context.Background(), "/v1/users.delete", nil)
+	assert.ErrorIs(t, err, duhdyn.ErrUnknownRPC)
+	assert.ErrorContains(t, err, "no rpc Delete in service UsersService")
+
+	_, err = client.CallJSON(context.Background(), "/v1/users.create", []byte(`{"email": "ann@example.com"}`))
+	assert.ErrorContains(t, err, "invalid request for users.v1.CreateRequest")
+
+	_, err = duhdyn.Parse([]byte("not a descriptor set"))
+	assert.ErrorContains(t, err, "invalid descriptor set")
+}
+
+func TestCallCmd(t *testing.T) {
+	// The descriptor set of the proto 'duh proto --proto-service' writes for
+	// /v1/users.create and /v1/users.get
+	field := func(name string, number int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number),
+			Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), JsonName: proto.String(name)}
+	}
+	message := func(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+		return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	rpc := func(name, input, output string) *descriptorpb.MethodDescriptorProto {
+		return &descriptorpb.MethodDescriptorProto{Name: proto.String(name),
+			InputType: proto.String(".users.v1." + input), OutputType: proto.String(".users.v1." + output)}
+	}
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("users/v1/users.proto"),
+		Package: proto.String("users.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("CreateRequest", field("name", 1)),
+			message("GetRequest", field("id", 1)),
+			message("User", field("id", 1), field("name", 2)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   proto.String("UsersService"),
+			Method: []*descriptorpb.MethodDescriptorProto{rpc("Create", "CreateRequest", "User"), rpc("Get", "GetRequest", "User")},
+		}},
+	}}})
+	require.NoError(t, err)
+	files, err := duhdyn.Parse(set)
+	require.NoError(t, err)
+
+	// The server replies to /v1/users.create with the user it creates and to
+	// everything else with a not found error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/protobuf", r.Header.Get("Content-Type"))
+		if r.URL.Path != "/v1/users.create" {
+			var reply []byte
+			reply = protowire.AppendTag(reply, 1, protowire.BytesType)
+			reply = protowire.AppendString(reply, "404")
+			reply = protowire.AppendTag(reply, 3, protowire.BytesType)
+			reply = protowire.AppendString(reply, "user not found")
+			w.Header().Set("Content-Type", "application/protobuf")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write(reply)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		desc, err := files.FindDescriptorByName("users.v1.CreateRequest")
+		require.NoError(t, err)
+		req := dynamicpb.NewMessage(desc.(protoreflect.MessageDescriptor))
+		require.NoError(t, proto.Unmarshal(body, req))
+		name := req.Get(req.Descriptor().Fields().ByName("name")).String()
+
+		var reply []byte
+		reply = protowire.AppendTag(reply, 1, protowire.BytesType)
+		reply = protowire.AppendString(reply, "1")
+		reply = protowire.AppendTag(reply, 2, protowire.BytesType)
+		reply = protowire.AppendString(reply, name)
+		w.Header().Set("Content-Type", "application/protobuf")
+		_, _ = w.Write(reply)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "api.binpb")
+	require.NoError(t, os.WriteFile(path, set, 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"call", "/v1/users.create", "--descriptors", path,
+		"--base-url", server.URL, "--data", `{"name": "Ann"}`})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.JSONEq(t, `{"id": "1", "name": "Ann"}`, stdout.String())
+
+	// The reply is the result, so --quiet still prints it
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"call", "-q", "/v1/users.create", "--descriptors", path,
+		"--base-url", server.URL, "--data", `{"name": "Bob"}`})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.JSONEq(t, `{"id": "1", "name": "Bob"}`, stdout.String())
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"call", "/v1/users.get", "--descriptors", path,
+		"--base-url", server.URL})
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, "Error: 404 user not found\n", stderr.String())
+
+	stderr.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"call", "/v1/users.get", "--base-url", server.URL})
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "Error: --descriptors is required\n", stderr.String())
+}
@@ -0,0 +1,155 @@
+// Package generate runs the DUH-RPC Go code generator in-process, for build
+// systems such as Bazel rules or mage targets which would otherwise exec the
+// duh CLI. Run writes the same files as 'duh generate', Parse returns the
+// TemplateData of a spec for generators with their own templates.
+package generate
+
+import (
+	"io"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+)
+
+// TemplateData is what the Go templates are rendered from
+type TemplateData = duh.TemplateData
+
+// Operation is a single DUH-RPC method of the spec
+type Operation = duh.Operation
+
+// ListOperation is an operation whose response is paginated
+type ListOperation = duh.ListOperation
+
+// ErrValidation is returned by Run and Parse when the spec is not DUH-RPC compliant;
+// 'duh lint' reports the violations
+var ErrValidation = lint.ErrValidation
+
+// Config controls generation, the zero value of each field is the default of
+// the matching 'duh generate' flag
+type Config struct {
+	// SpecPath is the OpenAPI spec file or an http or https URL to fetch it
+	// from, openapi.yaml when empty
+	SpecPath string
+	// OutputDir receives the generated files and must exist, the current
+	// directory when empty
+	OutputDir string
+	// PackageName is the Go package of the generated code, api when empty
+	PackageName string
+	// ProtoPath is the proto file relative to OutputDir, proto/v1/api.proto when empty
+	ProtoPath    string
+	ProtoImport  string
+	ProtoPackage string
+	// ModulePath overrides the module path otherwise read from go.mod
+	ModulePath string
+	// PathNames names methods after their paths even when operations have an operationId
+	PathNames bool
+	// Full also generates the editable scaffolding of a complete service
+	Full bool
+	// Connect also generates a Connect protocol client
+	Connect bool
+	// CLI also generates a command line client under cmd/
+	CLI bool
+	// ServeSpec embeds the spec in the server and serves it at /v1/openapi.get
+	ServeSpec bool
+	// Introspect serves the list of rpcs at /v1/rpc.list
+	Introspect bool
+	// Docker adds a daemon main, Dockerfile and docker-compose.yaml, it implies Full
+	Docker bool
+	// K8s adds Kubernetes manifests under k8s/, it implies Docker
+	K8s bool
+	// CI is github or gitlab to add a CI workflow, it implies Full
+	CI string
+	// ProtoTool is buf, the default, or protoc for the proto target of the Makefile
+	ProtoTool string
+	// ProtoOut is the directory the Go code of the proto files is generated
+	// into, relative to OutputDir
+	ProtoOut string
+	// ClientOnly generates the client without server.go
+	ClientOnly bool
+	// Checksum is the sha256:<hex> a SpecPath which is an http or https URL
+	// must match
+	Checksum string
+	// LintTargets adds lint targets to the Makefile: spec, proto or vet
+	LintTargets []string
+	// BufPlugins are name[=version] entries which pin the version of a
+	// plugin of buf.gen.yaml or add one, like protoc-gen-go=v1.34.0
+	BufPlugins []string
+	// BufModule names the module of buf.yaml in the Buf Schema Registry, like
+	// buf.build/acme/api, and adds a push target to the Makefile of Full
+	BufModule string
+	// BufDeps are the Buf Schema Registry modules buf.yaml depends on
+	BufDeps []string
+	// EnumsAsStrings keeps enum properties as strings instead of proto enums
+	EnumsAsStrings bool
+	// SplitBySubject writes a proto file per subject plus a shared common.proto
+	SplitBySubject bool
+	// ProtoService adds a gRPC service definition for the operations to the proto
+	ProtoService bool
+	// Writer receives the summary 'duh generate' prints, nil discards it
+	Writer io.Writer
+}
+
+// Run generates the code for the spec and returns the paths of the files
+// written, joined with OutputDir
+func Run(conf Config) ([]string, error) {
+	result := &output.Result{}
+	err := duh.Run(conf.runConfig(result))
+	if err != nil {
+		return nil, err
+	}
+	return result.Files, nil
+}
+
+// Parse validates the spec and returns the data the Go templates are rendered
+// from, without writing any files. Specs with versioned paths are not supported.
+func Parse(conf Config) (*TemplateData, error) {
+	return duh.Parse(conf.runConfig(nil))
+}
+
+// runConfig applies the defaults of the 'duh generate' flags
+func (conf Config) runConfig(result *output.Result) duh.RunConfig {
+	if conf.SpecPath == "" {
+		conf.SpecPath = "openapi.yaml"
+	}
+	if conf.OutputDir == "" {
+		conf.OutputDir = "."
+	}
+	if conf.Writer == nil {
+		conf.Writer = io.Discard
+	}
+	return duh.RunConfig{
+		Writer:         conf.Writer,
+		SpecPath:       conf.SpecPath,
+		PackageName:    conf.PackageName,
+		OutputDir:      conf.OutputDir,
+		ProtoPath:      conf.ProtoPath,
+		ProtoImport:    conf.ProtoImport,
+		ProtoPackage:   conf.ProtoPackage,
+		ModulePath:     conf.ModulePath,
+		PathNames:      conf.PathNames,
+		FullFlag:       conf.Full,
+		ConnectFlag:    conf.Connect,
+		CLIFlag:        conf.CLI,
+		ServeSpecFlag:  conf.ServeSpec,
+		IntrospectFlag: conf.Introspect,
+		DockerFlag:     conf.Docker,
+		K8sFlag:        conf.K8s,
+		CI:             conf.CI,
+		ProtoTool:      conf.ProtoTool,
+		ProtoOut:       conf.ProtoOut,
+		LintTargets:    conf.LintTargets,
+		ClientOnlyFlag: conf.ClientOnly,
+		Checksum:       conf.Checksum,
+		BufPlugins:     conf.BufPlugins,
+		BufModule:      conf.BufModule,
+		BufDeps:        conf.BufDeps,
+		Converter: duh.NewProtoConverter(duh.ProtoOptions{
+			EnumsAsStrings: conf.EnumsAsStrings,
+			SplitBySubject: conf.SplitBySubject,
+			// The Connect client calls the rpcs of the proto services
+			Services: conf.ProtoService || conf.Connect,
+		}),
+		Output: result,
+	}
+}
@@ -0,0 +1,102 @@
+package generate_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+	outputDir := filepath.Join(t.TempDir(), "api")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+
+	stdout.Reset()
+	files, err := generate.Run(generate.Config{
+		SpecPath:   specPath,
+		OutputDir:  outputDir,
+		ModulePath: "github.com/example/service",
+		Writer:     &stdout,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, files, filepath.Join(outputDir, "server.go"))
+	assert.Contains(t, files, filepath.Join(outputDir, "client.go"))
+	for _, file := range files {
+		assert.FileExists(t, file)
+	}
+	assert.Contains(t, stdout.String(), "✓ Generated")
+}
+
+func TestParse(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+
+	data, err := generate.Parse(generate.Config{
+		SpecPath:   specPath,
+		ModulePath: "github.com/example/service",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "api", data.Package)
+	require.Len(t, data.Operations, 4)
+	assert.Equal(t, "/users.create", data.Operations[0].Path)
+	assert.Len(t, data.ListOps, 1)
+}
+
+func TestRunErrors(t *testing.T) {
+	invalidSpec := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(invalidSpec, []byte(`openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+paths:
+  /Users.Create:
+    get:
+      responses:
+        '200':
+          description: OK
+`), 0644))
+
+	for _, test := range []struct {
+		name     string
+		specPath string
+		wantErr  error
+		errMsg   string
+	}{
+		{
+			name:     "InvalidSpec",
+			specPath: invalidSpec,
+			wantErr:  generate.ErrValidation,
+		},
+		{
+			name:     "MissingSpec",
+			specPath: filepath.Join(t.TempDir(), "missing.yaml"),
+			errMsg:   "missing.yaml",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := generate.Run(generate.Config{
+				SpecPath:   test.specPath,
+				OutputDir:  t.TempDir(),
+				ModulePath: "github.com/example/service",
+			})
+			require.Error(t, err)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+			if test.errMsg != "" {
+				assert.Contains(t, err.Error(), test.errMsg)
+			}
+		})
+	}
+}
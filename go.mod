@@ -3,11 +3,11 @@ module github.com/duh-rpc/duh-cli
 go 1.24.7
 
 require (
-	github.com/duh-rpc/openapi-proto.go v0.2.0
 	github.com/pb33f/libopenapi v0.28.1
 	github.com/spf13/cobra v1.10.1
 	github.com/stretchr/testify v1.11.1
 	go.yaml.in/yaml/v4 v4.0.0-rc.2
+	google.golang.org/protobuf v1.36.11
 	gopkg.in/yaml.v3 v3.0.1
 )
 
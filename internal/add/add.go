@@ -11,56 +11,212 @@ import (
 
 var pathFormatRegex = regexp.MustCompile(`^/[a-z][a-z0-9_-]{0,49}\.[a-z][a-z0-9_-]{0,49}$`)
 
-func Run(w io.Writer, filePath, path, name string) error {
-	if !pathFormatRegex.MatchString(path) {
-		return fmt.Errorf("invalid path format: %s (must follow /{resource}.{method})", path)
+// Config controls how endpoints are added to the spec
+type Config struct {
+	Writer   io.Writer
+	FilePath string
+	// Path is the /{resource}.{method} path, or the resource path such as
+	// /products when CRUD is set
+	Path string
+	Name string
+	// CRUD adds the create, get, list, update and delete endpoints of a resource
+	CRUD bool
+	// From is the path of an existing endpoint to copy
+	From string
+	// RequestFields and ResponseFields replace the placeholder properties, each
+	// is a name:type definition such as created_at:date-time
+	RequestFields  []string
+	ResponseFields []string
+	// RequestSchema and ResponseSchema reference existing schemas in
+	// components/schemas instead of generating {Name}Request and {Name}Response
+	RequestSchema  string
+	ResponseSchema string
+	// ErrorCodes are the error responses of the endpoint, defaults to 400, 404 and 500
+	ErrorCodes []string
+	// Tags are set on the added operations, the generator groups operations
+	// which share a tag into their own service interface
+	Tags []string
+	// Interactive prompts on Reader for everything not given on the command line
+	// and previews the YAML before writing it
+	Interactive bool
+	Reader      io.Reader
+}
+
+// spec is an OpenAPI document loaded for editing
+type spec struct {
+	root    yaml.Node
+	paths   *yaml.Node
+	schemas *yaml.Node
+	// addedError is set when the Error schema was missing and has been added
+	addedError bool
+}
+
+func Run(conf Config) error {
+	if len(conf.ErrorCodes) > 0 {
+		codes, err := parseErrorCodes(conf.ErrorCodes)
+		if err != nil {
+			return err
+		}
+		conf.ErrorCodes = codes
 	}
 
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return fmt.Errorf("file not found: %s", filePath)
+	tags, err := parseTags(conf.Tags)
+	if err != nil {
+		return err
 	}
+	conf.Tags = tags
 
-	data, err := os.ReadFile(filePath)
+	if conf.From != "" {
+		return runFrom(conf)
+	}
+	if conf.Interactive {
+		return runInteractive(conf)
+	}
+	if conf.CRUD {
+		return runCRUD(conf)
+	}
+
+	if !pathFormatRegex.MatchString(conf.Path) {
+		return fmt.Errorf("invalid path format: %s (must follow /{resource}.{method})", conf.Path)
+	}
+
+	s, err := load(conf.FilePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return err
 	}
 
-	var root yaml.Node
-	if err := yaml.Unmarshal(data, &root); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
+	if err := s.addEndpoint(conf); err != nil {
+		return err
 	}
 
-	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
-		return fmt.Errorf("invalid OpenAPI document structure")
+	if err := s.save(conf.FilePath); err != nil {
+		return err
 	}
 
-	doc := root.Content[0]
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Added endpoint %s to %s\n", conf.Path, conf.FilePath)
+	s.printAddedError(conf.Writer)
+	return nil
+}
+
+// addEndpoint adds the path and the schemas it needs to the spec
+func (s *spec) addEndpoint(conf Config) error {
+	if pathExists(s.paths, conf.Path) {
+		return fmt.Errorf("path already exists: %s", conf.Path)
+	}
 
-	pathsNode, err := findOrCreateNode(doc, "paths")
+	requestName, err := s.endpointSchema(conf.RequestSchema, conf.Name+"Request", conf.RequestFields,
+		"--request-schema", "--req-field", generateRequestSchema(conf.Name))
+	if err != nil {
+		return err
+	}
+	responseName, err := s.endpointSchema(conf.ResponseSchema, conf.Name+"Response", conf.ResponseFields,
+		"--response-schema", "--resp-field", generateResponseSchema(conf.Name))
 	if err != nil {
-		return fmt.Errorf("failed to find or create paths: %w", err)
+		return err
 	}
 
-	if pathExists(pathsNode, path) {
-		return fmt.Errorf("path already exists: %s", path)
+	addPath(s.paths, conf.Path, generatePathItem(conf.Name, conf.Name+" operation", requestName, responseName, conf.errorCodes(), conf.Tags))
+	s.ensureErrorSchema()
+	return nil
+}
+
+// ensureErrorSchema adds the standard Error schema the error responses
+// reference when the spec does not define one
+func (s *spec) ensureErrorSchema() {
+	if schemaExists(s.schemas, "Error") {
+		return
 	}
+	addSchema(s.schemas, "Error", generateErrorSchema())
+	s.addedError = true
+}
 
-	componentsNode, err := findOrCreateNode(doc, "components")
+func (s *spec) printAddedError(w io.Writer) {
+	if s.addedError {
+		_, _ = fmt.Fprintln(w, "✓ Added the standard DUH-RPC Error schema")
+	}
+}
+
+func (c Config) errorCodes() []string {
+	if len(c.ErrorCodes) == 0 {
+		return defaultErrorCodes
+	}
+	return c.ErrorCodes
+}
+
+// endpointSchema returns the name of the schema the endpoint references. An
+// existing schema is used as is, otherwise a schema is added with the fields
+// or, when there are none, the placeholder properties.
+func (s *spec) endpointSchema(existing, name string, fields []string, schemaFlag, fieldFlag string, placeholder *yaml.Node) (string, error) {
+	if existing != "" {
+		if len(fields) > 0 {
+			return "", fmt.Errorf("%s cannot be used with %s", fieldFlag, schemaFlag)
+		}
+		if !schemaExists(s.schemas, existing) {
+			return "", fmt.Errorf("schema '%s' not found in components/schemas", existing)
+		}
+		return existing, nil
+	}
+
+	schema := placeholder
+	if len(fields) > 0 {
+		properties, required, err := parseFields(s.schemas, fields)
+		if err != nil {
+			return "", err
+		}
+		schema = object(required, properties...)
+	}
+	addSchema(s.schemas, name, schema)
+	return name, nil
+}
+
+// load reads the spec, creating the paths and components/schemas sections if missing
+func load(filePath string) (*spec, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("file not found: %s", filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to find or create components: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	schemasNode, err := findOrCreateNode(componentsNode, "schemas")
+	var s spec
+	if err := yaml.Unmarshal(data, &s.root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if s.root.Kind != yaml.DocumentNode || len(s.root.Content) == 0 {
+		return nil, fmt.Errorf("invalid OpenAPI document structure")
+	}
+
+	doc := s.root.Content[0]
+
+	s.paths, err = findOrCreateNode(doc, "paths")
 	if err != nil {
-		return fmt.Errorf("failed to find or create schemas: %w", err)
+		return nil, fmt.Errorf("failed to find or create paths: %w", err)
 	}
 
-	addSchema(schemasNode, name+"Request", generateRequestSchema(name))
-	addSchema(schemasNode, name+"Response", generateResponseSchema(name))
+	componentsNode, err := findOrCreateNode(doc, "components")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find or create components: %w", err)
+	}
 
-	addPath(pathsNode, path, generatePathItem(name))
+	s.schemas, err = findOrCreateNode(componentsNode, "schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find or create schemas: %w", err)
+	}
+
+	// An empty 'paths: {}' would otherwise write what is added in flow style too
+	for _, node := range []*yaml.Node{s.paths, componentsNode, s.schemas} {
+		if len(node.Content) == 0 {
+			node.Style = 0
+		}
+	}
+	return &s, nil
+}
 
-	output, err := yaml.Marshal(&root)
+func (s *spec) save(filePath string) error {
+	output, err := yaml.Marshal(&s.root)
 	if err != nil {
 		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
@@ -68,8 +224,6 @@ func Run(w io.Writer, filePath, path, name string) error {
 	if err := os.WriteFile(filePath, output, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
-
-	_, _ = fmt.Fprintf(w, "✓ Added endpoint %s to %s\n", path, filePath)
 	return nil
 }
 
@@ -112,3 +266,16 @@ func addSchema(schemasNode *yaml.Node, name string, schema *yaml.Node) {
 	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
 	schemasNode.Content = append(schemasNode.Content, keyNode, schema)
 }
+
+func schemaExists(schemasNode *yaml.Node, name string) bool {
+	if schemasNode.Kind != yaml.MappingNode {
+		return false
+	}
+
+	for i := 0; i < len(schemasNode.Content); i += 2 {
+		if schemasNode.Content[i].Value == name {
+			return true
+		}
+	}
+	return false
+}
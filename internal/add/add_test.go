@@ -47,7 +47,7 @@ func TestAddCommandWithDefaultFile(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "/users.create", "CreateUser"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "/users.create", "CreateUser"})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "✓ Added endpoint /users.create")
@@ -67,7 +67,7 @@ func TestAddCommandWithFFlag(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "-f", customPath, "/products.list", "ListProducts"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", customPath, "/products.list", "ListProducts"})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "✓ Added endpoint /products.list")
@@ -104,7 +104,7 @@ components:
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
 
 	require.Equal(t, 2, exitCode)
 	require.Contains(t, stdout.String(), "Error:")
@@ -140,7 +140,7 @@ func TestAddCommandInvalidPath(t *testing.T) {
 		},
 	} {
 		var stdout bytes.Buffer
-		exitCode := duh.RunCmd(&stdout, []string{"add", "-f", filePath, test.path, test.name})
+		exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, test.path, test.name})
 
 		assert.Equal(t, 2, exitCode)
 		assert.Contains(t, stdout.String(), "Error:")
@@ -153,7 +153,7 @@ func TestAddCommandFileNotFound(t *testing.T) {
 	nonexistentFile := filepath.Join(tempDir, "nonexistent.yaml")
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "-f", nonexistentFile, "/users.create", "CreateUser"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", nonexistentFile, "/users.create", "CreateUser"})
 
 	require.Equal(t, 2, exitCode)
 	require.Contains(t, stdout.String(), "Error:")
@@ -162,7 +162,7 @@ func TestAddCommandFileNotFound(t *testing.T) {
 
 func TestAddCommandNoArguments(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add"})
 
 	require.Equal(t, 2, exitCode)
 	output := strings.ToLower(stdout.String())
@@ -171,7 +171,7 @@ func TestAddCommandNoArguments(t *testing.T) {
 
 func TestAddCommandHelp(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "--help"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "--help"})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "add <path> <name>")
@@ -205,11 +205,11 @@ components:
 	require.NoError(t, err)
 
 	var addStdout bytes.Buffer
-	addExitCode := duh.RunCmd(&addStdout, []string{"add", "-f", filePath, "/orders.update", "Update"})
+	addExitCode := duh.RunCmd(&addStdout, &addStdout, []string{"add", "-f", filePath, "/orders.update", "Update"})
 	require.Equal(t, 0, addExitCode)
 
 	var lintStdout bytes.Buffer
-	lintExitCode := duh.RunCmd(&lintStdout, []string{"lint", filePath})
+	lintExitCode := duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
 	require.Equal(t, 0, lintExitCode)
 	require.Contains(t, lintStdout.String(), "✓")
 	require.Contains(t, lintStdout.String(), "DUH-RPC compliant")
@@ -223,11 +223,11 @@ func TestAddCommandMultipleEndpoints(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout1 bytes.Buffer
-	exitCode1 := duh.RunCmd(&stdout1, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
+	exitCode1 := duh.RunCmd(&stdout1, &stdout1, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
 	require.Equal(t, 0, exitCode1)
 
 	var stdout2 bytes.Buffer
-	exitCode2 := duh.RunCmd(&stdout2, []string{"add", "-f", filePath, "/users.get", "GetUser"})
+	exitCode2 := duh.RunCmd(&stdout2, &stdout2, []string{"add", "-f", filePath, "/users.get", "GetUser"})
 	require.Equal(t, 0, exitCode2)
 
 	content, err := os.ReadFile(filePath)
@@ -246,7 +246,7 @@ func TestAddCommandVerifyResponseStructure(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"add", "-f", filePath, "/products.create", "CreateProduct"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/products.create", "CreateProduct"})
 	require.Equal(t, 0, exitCode)
 
 	content, err := os.ReadFile(filePath)
@@ -266,3 +266,769 @@ func TestAddCommandVerifyResponseStructure(t *testing.T) {
 	require.Contains(t, contentStr, "name:")
 	require.Contains(t, contentStr, "example:")
 }
+
+func TestAddCRUD(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "openapi.yaml")
+
+	minimalWithServers := `openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths: {}
+components:
+  schemas:
+    Error:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+`
+	err := os.WriteFile(filePath, []byte(minimalWithServers), 0644)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--crud", "/products", "Product"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	for _, method := range []string{"Create", "Get", "List", "Update", "Delete"} {
+		path := "/products." + strings.ToLower(method)
+		assert.Contains(t, stdout.String(), "✓ Added endpoint "+path)
+		assert.Contains(t, contentStr, path+":")
+		assert.Contains(t, contentStr, "$ref: '#/components/schemas/Products"+method+"Request'")
+		assert.Contains(t, contentStr, "$ref: '#/components/schemas/Products"+method+"Response'")
+	}
+	assert.Contains(t, contentStr, "    Product:")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/PaginationRequest'")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/PaginationResponse'")
+	assert.Contains(t, contentStr, "end_cursor:")
+	assert.Contains(t, contentStr, "has_more:")
+
+	var lintStdout bytes.Buffer
+	lintExitCode := duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
+	require.Equal(t, 0, lintExitCode)
+	require.Contains(t, lintStdout.String(), "DUH-RPC compliant")
+
+	// A second resource reuses the pagination schemas
+	var stdout2 bytes.Buffer
+	exitCode = duh.RunCmd(&stdout2, &stdout2, []string{"add", "-f", filePath, "--crud", "/user-groups", "UserGroup"})
+	require.Equal(t, 0, exitCode)
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "PaginationRequest:"))
+	assert.Contains(t, string(content), "UserGroupsListResponse:")
+	assert.Contains(t, string(content), "user_group:")
+
+	lintStdout.Reset()
+	lintExitCode = duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
+	require.Equal(t, 0, lintExitCode)
+}
+
+func TestAddCRUDErrors(t *testing.T) {
+	existingAPI := minimalOpenAPI + `    ProductsDeleteRequest:
+      type: object
+`
+	for _, test := range []struct {
+		name    string
+		spec    string
+		path    string
+		wantErr string
+	}{
+		{
+			name:    "method path",
+			spec:    minimalOpenAPI,
+			path:    "/products.create",
+			wantErr: "invalid resource path",
+		},
+		{
+			name:    "uppercase",
+			spec:    minimalOpenAPI,
+			path:    "/Products",
+			wantErr: "invalid resource path",
+		},
+		{
+			name:    "existing schema",
+			spec:    existingAPI,
+			path:    "/products",
+			wantErr: "schema already exists: ProductsDeleteRequest",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(test.spec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"add", "-f", filePath, "--crud", test.path, "Product"})
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, test.spec, string(content))
+		})
+	}
+}
+
+func TestAddFields(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "openapi.yaml")
+
+	spec := `openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths: {}
+components:
+  schemas:
+    Error:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+    LineItem:
+      type: object
+      properties:
+        sku:
+          type: string
+`
+	err := os.WriteFile(filePath, []byte(spec), 0644)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/orders.create", "OrdersCreate",
+		"--req-field", "customer_id:string",
+		"--req-field", "quantity:int32",
+		"--req-field", "lines:[]LineItem",
+		"--resp-field", "order_id:uuid",
+		"--resp-field", "created_at:date-time",
+	})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(content), `        OrdersCreateRequest:
+            type: object
+            properties:
+                customer_id:
+                    type: string
+                quantity:
+                    type: integer
+                    format: int32
+                lines:
+                    type: array
+                    items:
+                        $ref: '#/components/schemas/LineItem'
+        OrdersCreateResponse:
+            type: object
+            properties:
+                order_id:
+                    type: string
+                    format: uuid
+                created_at:
+                    type: string
+                    format: date-time
+`)
+	assert.NotContains(t, string(content), "success:")
+
+	var lintStdout bytes.Buffer
+	lintExitCode := duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
+	require.Equal(t, 0, lintExitCode)
+}
+
+func TestAddFieldsCRUD(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "openapi.yaml")
+
+	err := os.WriteFile(filePath, []byte(minimalOpenAPI), 0644)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--crud", "/products", "Product",
+		"--req-field", "title:string",
+		"--resp-field", "title:string",
+		"--resp-field", "price_cents:int64",
+	})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, `        Product:
+            type: object
+            required:
+                - id
+            properties:
+                id:`)
+	assert.Contains(t, contentStr, `                price_cents:
+                    type: integer
+                    format: int64`)
+	assert.Contains(t, contentStr, `        ProductsCreateRequest:
+            type: object
+            properties:
+                title:
+                    type: string
+`)
+	assert.Contains(t, contentStr, `        ProductsUpdateRequest:
+            type: object
+            required:
+                - id
+            properties:
+                id:`)
+	assert.NotContains(t, contentStr, "Example Name")
+}
+
+func TestAddFieldErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		field   string
+		wantErr string
+	}{
+		{
+			name:    "missing type",
+			field:   "name",
+			wantErr: "invalid field 'name': must be name:type",
+		},
+		{
+			name:    "camel case",
+			field:   "createdAt:date-time",
+			wantErr: "name must be snake_case",
+		},
+		{
+			name:    "unknown type",
+			field:   "count:long",
+			wantErr: "unknown type 'long'",
+		},
+		{
+			name:    "unknown schema",
+			field:   "address:Address",
+			wantErr: "schema 'Address' not found in components/schemas",
+		},
+		{
+			name:    "nested array",
+			field:   "matrix:[][]int32",
+			wantErr: "nested arrays are not allowed",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"add", "-f", filePath, "/users.create", "CreateUser", "--req-field", test.field})
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestAddExistingSchemas(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "openapi.yaml")
+
+	spec := minimalOpenAPI + `    OrdersCancelRequest:
+      type: object
+      properties:
+        order_id:
+          type: string
+    OrdersCancelResponse:
+      type: object
+      properties:
+        cancelled:
+          type: boolean
+`
+	err := os.WriteFile(filePath, []byte(spec), 0644)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/orders.cancel", "CancelOrder",
+		"--request-schema", "OrdersCancelRequest",
+		"--response-schema", "OrdersCancelResponse",
+	})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/OrdersCancelRequest'")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/OrdersCancelResponse'")
+	assert.Equal(t, 1, strings.Count(contentStr, "OrdersCancelRequest:"))
+	assert.NotContains(t, contentStr, "CancelOrderRequest")
+	assert.NotContains(t, contentStr, "CancelOrderResponse")
+	assert.Contains(t, contentStr, "operationId: cancelOrder")
+}
+
+func TestAddExistingSchemaWithGeneratedResponse(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "openapi.yaml")
+
+	err := os.WriteFile(filePath, []byte(minimalOpenAPI), 0644)
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/errors.get", "GetError", "--request-schema", "Error"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "GetErrorResponse:")
+	assert.NotContains(t, string(content), "GetErrorRequest")
+}
+
+func TestAddExistingSchemaErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "unknown request schema",
+			args:    []string{"/users.create", "CreateUser", "--request-schema", "Missing"},
+			wantErr: "schema 'Missing' not found in components/schemas",
+		},
+		{
+			name:    "unknown response schema",
+			args:    []string{"/users.create", "CreateUser", "--response-schema", "Missing"},
+			wantErr: "schema 'Missing' not found in components/schemas",
+		},
+		{
+			name:    "fields and schema",
+			args:    []string{"/users.create", "CreateUser", "--request-schema", "Error", "--req-field", "name:string"},
+			wantErr: "--req-field cannot be used with --request-schema",
+		},
+		{
+			name:    "crud",
+			args:    []string{"--crud", "/users", "User", "--response-schema", "Error"},
+			wantErr: "cannot be used with --crud",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"add", "-f", filePath}, test.args...))
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, minimalOpenAPI, string(content))
+		})
+	}
+}
+
+// withStdin runs fn with input available on os.Stdin
+func withStdin(t *testing.T, input string, fn func()) {
+	stdinPath := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(stdinPath, []byte(input), 0644))
+
+	f, err := os.Open(stdinPath)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	original := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = original }()
+
+	fn()
+}
+
+func TestAddInteractive(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	input := strings.Join([]string{
+		"/orders",        // invalid path, asked again
+		"/orders.create", // path
+		"",               // default name OrdersCreate
+		"customer_id:string",
+		"y",          // required
+		"total:long", // unknown type, asked again
+		"total:double",
+		"",
+		"", // end of request fields
+		"created_at:date-time",
+		"n",
+		"",        // end of response fields
+		"400,409", // error codes
+		"",        // write
+	}, "\n") + "\n"
+
+	var stdout bytes.Buffer
+	var exitCode int
+	withStdin(t, input, func() {
+		exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-i", "-f", filePath})
+	})
+	require.Equal(t, 0, exitCode)
+
+	output := stdout.String()
+	assert.Contains(t, output, "Invalid path format: /orders")
+	assert.Contains(t, output, "Name [OrdersCreate]")
+	assert.Contains(t, output, "unknown type 'long'")
+	assert.Contains(t, output, "The following will be added to "+filePath)
+	assert.Contains(t, output, "✓ Added endpoint /orders.create")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, `        OrdersCreateRequest:
+            type: object
+            required:
+                - customer_id
+            properties:
+                customer_id:
+                    type: string
+                total:
+                    type: number
+                    format: double
+`)
+	assert.Contains(t, contentStr, "'409':")
+	assert.NotContains(t, contentStr, "'404':")
+}
+
+func TestAddInteractiveArguments(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	// Only the request fields and error codes are asked for
+	input := "\n500\ny\n"
+
+	var stdout bytes.Buffer
+	var exitCode int
+	withStdin(t, input, func() {
+		exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-i", "-f", filePath, "/users.get", "GetUser",
+			"--resp-field", "user_id:string!"})
+	})
+	require.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout.String(), "Path (")
+	assert.NotContains(t, stdout.String(), "Response fields")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `        GetUserResponse:
+            type: object
+            required:
+                - user_id
+`)
+}
+
+func TestAddInteractiveDeclined(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	var exitCode int
+	withStdin(t, "/users.create\n\n\n\n\nn\n", func() {
+		exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-i", "-f", filePath})
+	})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "/users.create:")
+	assert.Contains(t, stdout.String(), "Nothing written")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, minimalOpenAPI, string(content))
+}
+
+func TestAddInteractiveEndOfInput(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout, stderr bytes.Buffer
+	var exitCode int
+	withStdin(t, "/users.create\n", func() {
+		exitCode = duh.RunCmd(&stdout, &stderr, []string{"add", "-i", "-f", filePath})
+	})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "Error: unexpected end of input")
+}
+
+func TestAddFrom(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"add", "-f", filePath, "--from", "/users.get", "/admins.get", "AdminsGet"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Added endpoint /admins.get to "+filePath+" as a copy of /users.get")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, `    /admins.get:
+        post:
+            operationId: adminsGet
+            requestBody:
+                required: true
+                content:
+                    application/json:
+                        schema:
+                            $ref: '#/components/schemas/AdminsGetRequest'
+`)
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/AdminsGetResponse'")
+	assert.Contains(t, contentStr, `        AdminsGetResponse:
+            type: object
+            properties:
+                user:
+                    $ref: '#/components/schemas/User'
+`)
+	// The source endpoint is untouched
+	assert.Contains(t, contentStr, "operationId: usersGet")
+	assert.Equal(t, 2, strings.Count(contentStr, "$ref: '#/components/schemas/UsersGetRequest'")+
+		strings.Count(contentStr, "$ref: '#/components/schemas/UsersGetResponse'"))
+}
+
+func TestAddFromErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "source not found",
+			args:    []string{"--from", "/users.delete", "/admins.delete", "AdminsDelete"},
+			wantErr: "path not found: /users.delete",
+		},
+		{
+			name:    "path exists",
+			args:    []string{"--from", "/users.get", "/users.list", "UsersList"},
+			wantErr: "path already exists: /users.list",
+		},
+		{
+			name:    "schema exists",
+			args:    []string{"--from", "/users.get", "/admins.get", "UsersList"},
+			wantErr: "schema already exists: UsersListRequest",
+		},
+		{
+			name:    "with fields",
+			args:    []string{"--from", "/users.get", "/admins.get", "AdminsGet", "--req-field", "id:string"},
+			wantErr: "cannot be used with the field or schema flags",
+		},
+		{
+			name:    "with crud",
+			args:    []string{"--from", "/users.get", "--crud", "/admins", "Admin"},
+			wantErr: "--from cannot be used with --crud",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"add", "-f", filePath}, test.args...))
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, editOpenAPI, string(content))
+		})
+	}
+}
+
+func TestAddErrorCodes(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser", "--errors", "429,400,401,400"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, `                '400':
+                    description: Bad request
+                    content:
+                        application/json:
+                            schema:
+                                $ref: '#/components/schemas/Error'
+                '401':
+                    description: Unauthorized
+`)
+	assert.Contains(t, contentStr, "'429':")
+	assert.Equal(t, 1, strings.Count(contentStr, "'400':"))
+	assert.NotContains(t, contentStr, "'404':")
+	assert.NotContains(t, contentStr, "'500':")
+
+	// Every endpoint of --crud gets the same error responses
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--crud", "/products", "Product", "--errors", "403"})
+	require.Equal(t, 0, exitCode)
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, 5, strings.Count(string(content), "'403':"))
+}
+
+func TestAddErrorCodesInvalid(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"add", "-f", filePath, "/users.create", "CreateUser", "--errors", "400,418"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "unsupported error code '418'")
+	assert.Empty(t, stdout.String())
+}
+
+func TestAddTags(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser",
+		"--tag", "accounts", "--tag", " billing ", "--tag", "accounts"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `            operationId: createUser
+            tags:
+                - accounts
+                - billing
+            requestBody:
+`)
+
+	// Every endpoint of --crud is tagged
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--crud", "/invoices", "Invoice", "--tag", "billing"})
+	require.Equal(t, 0, exitCode)
+
+	// --tag replaces the tags of the copied endpoint
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--from", "/users.create", "/admins.create", "AdminsCreate", "--tag", "admin"})
+	require.Equal(t, 0, exitCode)
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+	assert.Equal(t, 6, strings.Count(contentStr, "- billing\n"))
+	assert.Contains(t, contentStr, `            operationId: adminsCreate
+            tags:
+                - admin
+            requestBody:
+`)
+}
+
+func TestAddTagsInvalid(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"add", "-f", filePath, "/users.create", "CreateUser", "--tag", " "})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "tags cannot be empty")
+	assert.Empty(t, stdout.String())
+}
+
+func TestAddInjectsErrorSchema(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+	spec := `openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths: {}
+`
+	require.NoError(t, os.WriteFile(filePath, []byte(spec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/orders.update", "Update"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Added the standard DUH-RPC Error schema")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `        Error:
+            description: Standard DUH-RPC error response
+            type: object
+            required:
+                - message
+`)
+
+	var lintStdout bytes.Buffer
+	lintExitCode := duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
+	require.Equal(t, 0, lintExitCode)
+
+	// Only the first endpoint needs to add it
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "--crud", "/products", "Product"})
+	require.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout.String(), "Error schema")
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "        Error:"))
+}
+
+func TestAddKeepsExistingErrorSchema(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
+	require.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout.String(), "Error schema")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "Standard DUH-RPC error response")
+}
+
+func TestAddEmptyPathsBlockStyle(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+	spec := `openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths: {}
+components:
+  schemas: {}
+`
+	require.NoError(t, os.WriteFile(filePath, []byte(spec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "-f", filePath, "/users.create", "CreateUser"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "\npaths:\n    /users.create:\n        post:\n")
+	assert.Contains(t, string(content), "\ncomponents:\n    schemas:\n")
+	assert.NotContains(t, string(content), "{")
+}
@@ -0,0 +1,279 @@
+package add
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	paginationRequest  = "PaginationRequest"
+	paginationResponse = "PaginationResponse"
+)
+
+var resourcePathRegex = regexp.MustCompile(`^/([a-z][a-z0-9_-]{0,49}/)*[a-z][a-z0-9_-]{0,49}$`)
+
+// crudMethods are the endpoints added for a resource, in the order they appear in the spec
+var crudMethods = []string{"create", "get", "list", "update", "delete"}
+
+// runCRUD adds the create, get, list, update and delete endpoints of a resource. The
+// name is the entity schema, e.g. Product, shared by the responses and list items.
+// The request and response schemas are named {Resource}{Method}Request and
+// {Resource}{Method}Response as REQUEST_STANDARD_NAME requires.
+func runCRUD(conf Config) error {
+	if conf.RequestSchema != "" || conf.ResponseSchema != "" {
+		return fmt.Errorf("--request-schema and --response-schema cannot be used with --crud")
+	}
+
+	if !resourcePathRegex.MatchString(conf.Path) {
+		return fmt.Errorf("invalid resource path: %s (must follow /{resource}, e.g. /products)", conf.Path)
+	}
+
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	resource := conf.Path[strings.LastIndex(conf.Path, "/")+1:]
+	prefix := pascalCase(resource)
+
+	// Check everything up front so a conflict leaves the spec untouched
+	for _, method := range crudMethods {
+		path := conf.Path + "." + method
+		if pathExists(s.paths, path) {
+			return fmt.Errorf("path already exists: %s", path)
+		}
+		for _, suffix := range []string{"Request", "Response"} {
+			if name := prefix + pascalCase(method) + suffix; schemaExists(s.schemas, name) {
+				return fmt.Errorf("schema already exists: %s", name)
+			}
+		}
+	}
+
+	requestFields, requestRequired, err := parseFields(s.schemas, conf.RequestFields)
+	if err != nil {
+		return err
+	}
+	responseFields, responseRequired, err := parseFields(s.schemas, conf.ResponseFields)
+	if err != nil {
+		return err
+	}
+
+	if !schemaExists(s.schemas, conf.Name) {
+		addSchema(s.schemas, conf.Name, generateEntitySchema(responseFields, responseRequired))
+	}
+	if !schemaExists(s.schemas, paginationRequest) {
+		addSchema(s.schemas, paginationRequest, generatePaginationRequest())
+	}
+	if !schemaExists(s.schemas, paginationResponse) {
+		addSchema(s.schemas, paginationResponse, generatePaginationResponse())
+	}
+
+	entity := snakeCase(conf.Name)
+	for _, method := range crudMethods {
+		name := prefix + pascalCase(method)
+		request, response := generateCRUDSchemas(method, conf.Name, entity, requestFields, requestRequired)
+
+		addSchema(s.schemas, name+"Request", request)
+		addSchema(s.schemas, name+"Response", response)
+		addPath(s.paths, conf.Path+"."+method, generatePathItem(name, crudSummary(method, conf.Name), name+"Request", name+"Response", conf.errorCodes(), conf.Tags))
+	}
+	s.ensureErrorSchema()
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	for _, method := range crudMethods {
+		_, _ = fmt.Fprintf(conf.Writer, "✓ Added endpoint %s.%s to %s\n", conf.Path, method, conf.FilePath)
+	}
+	s.printAddedError(conf.Writer)
+	return nil
+}
+
+// generateCRUDSchemas returns the request and response schemas for a CRUD method.
+// Create, get and update return the entity under its snake_case name, list returns
+// the items and pagination expected by the generated iterators. The fields, when
+// given, replace the placeholder properties of the create and update requests.
+func generateCRUDSchemas(method, name, entity string, fields []*yaml.Node, required []string) (*yaml.Node, *yaml.Node) {
+	id := stringProperty("Unique identifier of the "+name, "123")
+	entityRef := schemaRef(name)
+
+	switch method {
+	case "create":
+		if len(fields) > 0 {
+			return object(required, fields...), object(nil, scalar(entity), entityRef)
+		}
+		return object(nil, scalar("name"), stringProperty("Name of the "+name, "Example Name")),
+			object(nil, scalar(entity), entityRef)
+	case "get":
+		return object([]string{"id"}, scalar("id"), id),
+			object(nil, scalar(entity), entityRef)
+	case "list":
+		return object(nil, scalar("pagination"), schemaRef(paginationRequest)),
+			object([]string{"items"},
+				scalar("items"), mapping(
+					scalar("type"), scalar("array"),
+					scalar("items"), entityRef,
+				),
+				scalar("pagination"), schemaRef(paginationResponse),
+			)
+	case "update":
+		if len(fields) > 0 {
+			return object(withIDRequired(required), withID(id, fields)...), object(nil, scalar(entity), entityRef)
+		}
+		return object([]string{"id"},
+				scalar("id"), id,
+				scalar("name"), stringProperty("Name of the "+name, "Example Name"),
+			),
+			object(nil, scalar(entity), entityRef)
+	default:
+		return object([]string{"id"}, scalar("id"), id),
+			object(nil, scalar("success"), mapping(
+				scalar("type"), scalar("boolean"),
+				scalar("example"), &yaml.Node{Kind: yaml.ScalarNode, Value: "true", Tag: "!!bool"},
+			))
+	}
+}
+
+func generateEntitySchema(fields []*yaml.Node, required []string) *yaml.Node {
+	if len(fields) > 0 {
+		return object(withIDRequired(required), withID(stringProperty("Unique identifier", "123"), fields)...)
+	}
+	return object([]string{"id", "name"},
+		scalar("id"), stringProperty("Unique identifier", "123"),
+		scalar("name"), stringProperty("Display name", "Example Name"),
+	)
+}
+
+func generatePaginationRequest() *yaml.Node {
+	return mapping(
+		scalar("type"), scalar("object"),
+		scalar("properties"), mapping(
+			scalar("first"), mapping(
+				scalar("type"), scalar("integer"),
+				scalar("format"), scalar("int32"),
+				scalar("minimum"), &yaml.Node{Kind: yaml.ScalarNode, Value: "1", Tag: "!!int"},
+				scalar("maximum"), &yaml.Node{Kind: yaml.ScalarNode, Value: "100", Tag: "!!int"},
+				scalar("description"), scalar("Number of items to return"),
+			),
+			scalar("after"), mapping(
+				scalar("type"), scalar("string"),
+				scalar("description"), scalar("Cursor for the next page"),
+			),
+		),
+	)
+}
+
+func generatePaginationResponse() *yaml.Node {
+	return mapping(
+		scalar("type"), scalar("object"),
+		scalar("required"), sequence(scalar("end_cursor")),
+		scalar("properties"), mapping(
+			scalar("end_cursor"), mapping(
+				scalar("type"), scalar("string"),
+				scalar("description"), scalar("Cursor for the next page"),
+			),
+			scalar("has_more"), mapping(
+				scalar("type"), scalar("boolean"),
+				scalar("description"), scalar("Whether more results are available"),
+			),
+		),
+	)
+}
+
+// withID prepends the id property unless the fields already define one
+func withID(id *yaml.Node, fields []*yaml.Node) []*yaml.Node {
+	for i := 0; i < len(fields); i += 2 {
+		if fields[i].Value == "id" {
+			return fields
+		}
+	}
+	return append([]*yaml.Node{scalar("id"), id}, fields...)
+}
+
+func withIDRequired(required []string) []string {
+	if slices.Contains(required, "id") {
+		return required
+	}
+	return append([]string{"id"}, required...)
+}
+
+func crudSummary(method, name string) string {
+	switch method {
+	case "get":
+		return "Get a " + name
+	case "list":
+		return "List " + name + " items"
+	default:
+		return strings.ToUpper(method[:1]) + method[1:] + " a " + name
+	}
+}
+
+// object returns an object schema with the given name and schema pairs as properties
+func object(required []string, properties ...*yaml.Node) *yaml.Node {
+	schema := mapping(scalar("type"), scalar("object"))
+	if len(required) > 0 {
+		list := sequence()
+		for _, name := range required {
+			list.Content = append(list.Content, scalar(name))
+		}
+		schema.Content = append(schema.Content, scalar("required"), list)
+	}
+	schema.Content = append(schema.Content, scalar("properties"), mapping(properties...))
+	return schema
+}
+
+func stringProperty(description, example string) *yaml.Node {
+	return mapping(
+		scalar("type"), scalar("string"),
+		scalar("description"), scalar(description),
+		scalar("example"), &yaml.Node{Kind: yaml.ScalarNode, Value: example, Tag: "!!str", Style: yaml.DoubleQuotedStyle},
+	)
+}
+
+func schemaRef(name string) *yaml.Node {
+	return mapping(scalar("$ref"), scalar("#/components/schemas/"+name))
+}
+
+func mapping(pairs ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: pairs}
+}
+
+func sequence(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Content: items}
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// pascalCase converts a kebab-case path segment to PascalCase, the same way the
+// linter derives schema names from paths
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "-") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// snakeCase converts a PascalCase schema name to the snake_case property name
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package add
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	fieldNameRegex  = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	schemaNameRegex = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+)
+
+// fieldTypes maps the types accepted by --req-field and --resp-field to their
+// OpenAPI type and format. Integers and numbers always carry a format as
+// INTEGER_FORMAT_REQUIRED demands.
+var fieldTypes = map[string][2]string{
+	"string":    {"string", ""},
+	"integer":   {"integer", "int64"},
+	"int32":     {"integer", "int32"},
+	"int64":     {"integer", "int64"},
+	"number":    {"number", "double"},
+	"float":     {"number", "float"},
+	"double":    {"number", "double"},
+	"boolean":   {"boolean", ""},
+	"bool":      {"boolean", ""},
+	"date":      {"string", "date"},
+	"date-time": {"string", "date-time"},
+	"email":     {"string", "email"},
+	"uuid":      {"string", "uuid"},
+	"uri":       {"string", "uri"},
+}
+
+// parseFields converts name:type definitions into property name and schema pairs
+// and returns the names of the required fields, marked with a trailing '!'. A
+// type prefixed with [] is an array and a PascalCase type references a schema in
+// components/schemas, e.g. items:[]LineItem!.
+func parseFields(schemas *yaml.Node, defs []string) ([]*yaml.Node, []string, error) {
+	var properties []*yaml.Node
+	var required []string
+	seen := make(map[string]bool)
+
+	for _, def := range defs {
+		name, typ, ok := strings.Cut(strings.TrimSuffix(def, "!"), ":")
+		if !ok || name == "" || typ == "" {
+			return nil, nil, fmt.Errorf("invalid field '%s': must be name:type, e.g. created_at:date-time", def)
+		}
+		if !fieldNameRegex.MatchString(name) {
+			return nil, nil, fmt.Errorf("invalid field '%s': name must be snake_case", def)
+		}
+		if seen[name] {
+			return nil, nil, fmt.Errorf("duplicate field '%s'", name)
+		}
+		seen[name] = true
+
+		schema, err := fieldSchema(schemas, typ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid field '%s': %w", def, err)
+		}
+		properties = append(properties, scalar(name), schema)
+		if strings.HasSuffix(def, "!") {
+			required = append(required, name)
+		}
+	}
+	return properties, required, nil
+}
+
+func fieldSchema(schemas *yaml.Node, typ string) (*yaml.Node, error) {
+	if item, ok := strings.CutPrefix(typ, "[]"); ok {
+		if strings.HasPrefix(item, "[]") {
+			return nil, fmt.Errorf("nested arrays are not allowed")
+		}
+		items, err := fieldSchema(schemas, item)
+		if err != nil {
+			return nil, err
+		}
+		return mapping(scalar("type"), scalar("array"), scalar("items"), items), nil
+	}
+
+	if schemaNameRegex.MatchString(typ) {
+		if !schemaExists(schemas, typ) {
+			return nil, fmt.Errorf("schema '%s' not found in components/schemas", typ)
+		}
+		return schemaRef(typ), nil
+	}
+
+	t, ok := fieldTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown type '%s'", typ)
+	}
+	schema := mapping(scalar("type"), scalar(t[0]))
+	if t[1] != "" {
+		schema.Content = append(schema.Content, scalar("format"), scalar(t[1]))
+	}
+	return schema, nil
+}
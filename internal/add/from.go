@@ -0,0 +1,104 @@
+package add
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runFrom adds the endpoint as a copy of an existing one. The request and
+// response schemas are copied to {Name}Request and {Name}Response, schemas
+// they reference in turn stay shared.
+func runFrom(conf Config) error {
+	if conf.CRUD || conf.Interactive {
+		return fmt.Errorf("--from cannot be used with --crud or --interactive")
+	}
+	if len(conf.RequestFields) > 0 || len(conf.ResponseFields) > 0 || conf.RequestSchema != "" || conf.ResponseSchema != "" {
+		return fmt.Errorf("--from copies the schemas of %s and cannot be used with the field or schema flags", conf.From)
+	}
+	if len(conf.ErrorCodes) > 0 {
+		return fmt.Errorf("--from copies the error responses of %s and cannot be used with --errors", conf.From)
+	}
+
+	if !pathFormatRegex.MatchString(conf.Path) {
+		return fmt.Errorf("invalid path format: %s (must follow /{resource}.{method})", conf.Path)
+	}
+
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	source := child(s.paths, conf.From)
+	if source == nil {
+		return fmt.Errorf("path not found: %s", conf.From)
+	}
+	if pathExists(s.paths, conf.Path) {
+		return fmt.Errorf("path already exists: %s", conf.Path)
+	}
+
+	item := deepCopy(source)
+	copied := make(map[string]string)
+	for _, op := range operations(item) {
+		for _, c := range []struct {
+			schema *yaml.Node
+			name   string
+		}{
+			{child(child(child(child(op, "requestBody"), "content"), "application/json"), "schema"), conf.Name + "Request"},
+			{child(child(child(child(child(op, "responses"), "200"), "content"), "application/json"), "schema"), conf.Name + "Response"},
+		} {
+			from := refName(c.schema)
+			if from == "" {
+				// Inline schemas are copied with the path item
+				continue
+			}
+			if _, ok := copied[from]; !ok {
+				if schemaExists(s.schemas, c.name) {
+					return fmt.Errorf("schema already exists: %s", c.name)
+				}
+				addSchema(s.schemas, c.name, deepCopy(s.schema(from)))
+				copied[from] = c.name
+			}
+			child(c.schema, "$ref").Value = schemaRefPrefix + copied[from]
+		}
+
+		if id := child(op, "operationId"); id != nil {
+			id.Value = camelCase(conf.Name)
+		}
+		if len(conf.Tags) > 0 {
+			setTags(op, conf.Tags)
+		}
+	}
+	addPath(s.paths, conf.Path, item)
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Added endpoint %s to %s as a copy of %s\n", conf.Path, conf.FilePath, conf.From)
+	return nil
+}
+
+// deepCopy copies the node so the copy can be changed without touching the original
+func deepCopy(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, n := range node.Content {
+		c.Content[i] = deepCopy(n)
+	}
+	return &c
+}
+
+// setTags replaces the tags of the operation, adding them when it has none
+func setTags(op *yaml.Node, tags []string) {
+	for i := 0; i+1 < len(op.Content); i += 2 {
+		if op.Content[i].Value == "tags" {
+			op.Content[i+1] = tagSequence(tags)
+			return
+		}
+	}
+	op.Content = append(op.Content, scalar("tags"), tagSequence(tags))
+}
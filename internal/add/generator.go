@@ -1,6 +1,60 @@
 package add
 
-import "gopkg.in/yaml.v3"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultErrorCodes are the error responses added unless others are chosen
+var defaultErrorCodes = []string{"400", "404", "500"}
+
+// errorDescriptions are the DUH-RPC error codes an endpoint may declare
+var errorDescriptions = map[string]string{
+	"400": "Bad request",
+	"401": "Unauthorized",
+	"403": "Forbidden",
+	"404": "Not found",
+	"409": "Conflict",
+	"429": "Too many requests",
+	"500": "Internal server error",
+}
+
+// parseTags trims the tags and drops duplicates, keeping the order they were given in
+func parseTags(tags []string) ([]string, error) {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return nil, fmt.Errorf("tags cannot be empty")
+		}
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// parseErrorCodes validates the error codes, returning them sorted without duplicates
+func parseErrorCodes(codes []string) ([]string, error) {
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("at least one error code is required")
+	}
+
+	var result []string
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if _, ok := errorDescriptions[code]; !ok {
+			return nil, fmt.Errorf("unsupported error code '%s': must be one of 400, 401, 403, 404, 409, 429, 500", code)
+		}
+		result = append(result, code)
+	}
+
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
 
 func generateRequestSchema(name string) *yaml.Node {
 	return &yaml.Node{
@@ -84,160 +138,82 @@ func generateResponseSchema(name string) *yaml.Node {
 	}
 }
 
-func generatePathItem(name string) *yaml.Node {
-	requestRef := "#/components/schemas/" + name + "Request"
-	responseRef := "#/components/schemas/" + name + "Response"
-	errorRef := "#/components/schemas/Error"
+func generatePathItem(name, summary, request, response string, codes, tags []string) *yaml.Node {
+	requestRef := "#/components/schemas/" + request
+	responseRef := "#/components/schemas/" + response
 
-	return &yaml.Node{
+	responses := &yaml.Node{
 		Kind: yaml.MappingNode,
 		Content: []*yaml.Node{
-			{Kind: yaml.ScalarNode, Value: "post"},
+			{Kind: yaml.ScalarNode, Value: "200", Style: yaml.SingleQuotedStyle},
 			{
 				Kind: yaml.MappingNode,
 				Content: []*yaml.Node{
-					{Kind: yaml.ScalarNode, Value: "summary"},
-					{Kind: yaml.ScalarNode, Value: name + " operation"},
-					{Kind: yaml.ScalarNode, Value: "operationId"},
-					{Kind: yaml.ScalarNode, Value: camelCase(name)},
-					{Kind: yaml.ScalarNode, Value: "requestBody"},
+					{Kind: yaml.ScalarNode, Value: "description"},
+					{Kind: yaml.ScalarNode, Value: "Successful response"},
+					{Kind: yaml.ScalarNode, Value: "content"},
 					{
 						Kind: yaml.MappingNode,
 						Content: []*yaml.Node{
-							{Kind: yaml.ScalarNode, Value: "required"},
-							{Kind: yaml.ScalarNode, Value: "true"},
-							{Kind: yaml.ScalarNode, Value: "content"},
+							{Kind: yaml.ScalarNode, Value: "application/json"},
 							{
 								Kind: yaml.MappingNode,
 								Content: []*yaml.Node{
-									{Kind: yaml.ScalarNode, Value: "application/json"},
+									{Kind: yaml.ScalarNode, Value: "schema"},
 									{
 										Kind: yaml.MappingNode,
 										Content: []*yaml.Node{
-											{Kind: yaml.ScalarNode, Value: "schema"},
-											{
-												Kind: yaml.MappingNode,
-												Content: []*yaml.Node{
-													{Kind: yaml.ScalarNode, Value: "$ref"},
-													{Kind: yaml.ScalarNode, Value: requestRef},
-												},
-											},
+											{Kind: yaml.ScalarNode, Value: "$ref"},
+											{Kind: yaml.ScalarNode, Value: responseRef},
 										},
 									},
 								},
 							},
 						},
 					},
-					{Kind: yaml.ScalarNode, Value: "responses"},
+				},
+			},
+		},
+	}
+
+	for _, code := range codes {
+		responses.Content = append(responses.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: code, Style: yaml.SingleQuotedStyle},
+			generateErrorResponse(errorDescriptions[code]),
+		)
+	}
+
+	item := &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "post"},
+			{
+				Kind: yaml.MappingNode,
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Value: "summary"},
+					{Kind: yaml.ScalarNode, Value: summary},
+					{Kind: yaml.ScalarNode, Value: "operationId"},
+					{Kind: yaml.ScalarNode, Value: camelCase(name)},
+					{Kind: yaml.ScalarNode, Value: "requestBody"},
 					{
 						Kind: yaml.MappingNode,
 						Content: []*yaml.Node{
-							{Kind: yaml.ScalarNode, Value: "200", Style: yaml.SingleQuotedStyle},
-							{
-								Kind: yaml.MappingNode,
-								Content: []*yaml.Node{
-									{Kind: yaml.ScalarNode, Value: "description"},
-									{Kind: yaml.ScalarNode, Value: "Successful response"},
-									{Kind: yaml.ScalarNode, Value: "content"},
-									{
-										Kind: yaml.MappingNode,
-										Content: []*yaml.Node{
-											{Kind: yaml.ScalarNode, Value: "application/json"},
-											{
-												Kind: yaml.MappingNode,
-												Content: []*yaml.Node{
-													{Kind: yaml.ScalarNode, Value: "schema"},
-													{
-														Kind: yaml.MappingNode,
-														Content: []*yaml.Node{
-															{Kind: yaml.ScalarNode, Value: "$ref"},
-															{Kind: yaml.ScalarNode, Value: responseRef},
-														},
-													},
-												},
-											},
-										},
-									},
-								},
-							},
-							{Kind: yaml.ScalarNode, Value: "400", Style: yaml.SingleQuotedStyle},
-							{
-								Kind: yaml.MappingNode,
-								Content: []*yaml.Node{
-									{Kind: yaml.ScalarNode, Value: "description"},
-									{Kind: yaml.ScalarNode, Value: "Bad request"},
-									{Kind: yaml.ScalarNode, Value: "content"},
-									{
-										Kind: yaml.MappingNode,
-										Content: []*yaml.Node{
-											{Kind: yaml.ScalarNode, Value: "application/json"},
-											{
-												Kind: yaml.MappingNode,
-												Content: []*yaml.Node{
-													{Kind: yaml.ScalarNode, Value: "schema"},
-													{
-														Kind: yaml.MappingNode,
-														Content: []*yaml.Node{
-															{Kind: yaml.ScalarNode, Value: "$ref"},
-															{Kind: yaml.ScalarNode, Value: errorRef},
-														},
-													},
-												},
-											},
-										},
-									},
-								},
-							},
-							{Kind: yaml.ScalarNode, Value: "404", Style: yaml.SingleQuotedStyle},
-							{
-								Kind: yaml.MappingNode,
-								Content: []*yaml.Node{
-									{Kind: yaml.ScalarNode, Value: "description"},
-									{Kind: yaml.ScalarNode, Value: "Not found"},
-									{Kind: yaml.ScalarNode, Value: "content"},
-									{
-										Kind: yaml.MappingNode,
-										Content: []*yaml.Node{
-											{Kind: yaml.ScalarNode, Value: "application/json"},
-											{
-												Kind: yaml.MappingNode,
-												Content: []*yaml.Node{
-													{Kind: yaml.ScalarNode, Value: "schema"},
-													{
-														Kind: yaml.MappingNode,
-														Content: []*yaml.Node{
-															{Kind: yaml.ScalarNode, Value: "$ref"},
-															{Kind: yaml.ScalarNode, Value: errorRef},
-														},
-													},
-												},
-											},
-										},
-									},
-								},
-							},
-							{Kind: yaml.ScalarNode, Value: "500", Style: yaml.SingleQuotedStyle},
+							{Kind: yaml.ScalarNode, Value: "required"},
+							{Kind: yaml.ScalarNode, Value: "true"},
+							{Kind: yaml.ScalarNode, Value: "content"},
 							{
 								Kind: yaml.MappingNode,
 								Content: []*yaml.Node{
-									{Kind: yaml.ScalarNode, Value: "description"},
-									{Kind: yaml.ScalarNode, Value: "Internal server error"},
-									{Kind: yaml.ScalarNode, Value: "content"},
+									{Kind: yaml.ScalarNode, Value: "application/json"},
 									{
 										Kind: yaml.MappingNode,
 										Content: []*yaml.Node{
-											{Kind: yaml.ScalarNode, Value: "application/json"},
+											{Kind: yaml.ScalarNode, Value: "schema"},
 											{
 												Kind: yaml.MappingNode,
 												Content: []*yaml.Node{
-													{Kind: yaml.ScalarNode, Value: "schema"},
-													{
-														Kind: yaml.MappingNode,
-														Content: []*yaml.Node{
-															{Kind: yaml.ScalarNode, Value: "$ref"},
-															{Kind: yaml.ScalarNode, Value: errorRef},
-														},
-													},
+													{Kind: yaml.ScalarNode, Value: "$ref"},
+													{Kind: yaml.ScalarNode, Value: requestRef},
 												},
 											},
 										},
@@ -246,10 +222,62 @@ func generatePathItem(name string) *yaml.Node {
 							},
 						},
 					},
+					{Kind: yaml.ScalarNode, Value: "responses"},
+					responses,
 				},
 			},
 		},
 	}
+
+	if len(tags) > 0 {
+		// The tags follow the operationId
+		post := item.Content[1]
+		post.Content = slices.Insert(post.Content, 4, scalar("tags"), tagSequence(tags))
+	}
+	return item
+}
+
+func tagSequence(tags []string) *yaml.Node {
+	var values []*yaml.Node
+	for _, tag := range tags {
+		values = append(values, scalar(tag))
+	}
+	return sequence(values...)
+}
+
+// generateErrorSchema returns the standard DUH-RPC Error schema referenced by the error responses
+func generateErrorSchema() *yaml.Node {
+	return mapping(
+		scalar("description"), scalar("Standard DUH-RPC error response"),
+		scalar("type"), scalar("object"),
+		scalar("required"), sequence(scalar("message")),
+		scalar("properties"), mapping(
+			scalar("message"), mapping(
+				scalar("description"), scalar("Human-readable error message"),
+				scalar("type"), scalar("string"),
+			),
+			scalar("code"), mapping(
+				scalar("description"), scalar("Machine-readable error code"),
+				scalar("type"), scalar("string"),
+			),
+			scalar("details"), mapping(
+				scalar("description"), scalar("Additional error context as key-value pairs"),
+				scalar("type"), scalar("object"),
+				scalar("additionalProperties"), mapping(scalar("type"), scalar("string")),
+			),
+		),
+	)
+}
+
+func generateErrorResponse(description string) *yaml.Node {
+	return mapping(
+		scalar("description"), scalar(description),
+		scalar("content"), mapping(
+			scalar("application/json"), mapping(
+				scalar("schema"), schemaRef("Error"),
+			),
+		),
+	)
 }
 
 func camelCase(name string) string {
@@ -0,0 +1,215 @@
+package add
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// prompter asks questions on the writer and reads the answers line by line
+type prompter struct {
+	scanner *bufio.Scanner
+	w       io.Writer
+}
+
+// ask prints the question and returns the trimmed answer, or the fallback
+// when the answer is blank
+func (p *prompter) ask(question, fallback string) (string, error) {
+	if fallback != "" {
+		_, _ = fmt.Fprintf(p.w, "%s [%s]: ", question, fallback)
+	} else {
+		_, _ = fmt.Fprintf(p.w, "%s: ", question)
+	}
+
+	if !p.scanner.Scan() {
+		if err := p.scanner.Err(); err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		return "", fmt.Errorf("unexpected end of input")
+	}
+
+	answer := strings.TrimSpace(p.scanner.Text())
+	if answer == "" {
+		return fallback, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes/no question
+func (p *prompter) confirm(question string, fallback bool) (bool, error) {
+	hint := "y/N"
+	if fallback {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.ask(question+" ("+hint+")", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return fallback, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		_, _ = fmt.Fprintln(p.w, "  Please answer y or n")
+	}
+}
+
+// runInteractive prompts for the endpoint, previews the YAML that will be
+// inserted and writes it once confirmed. Anything given on the command line is
+// not asked for again.
+func runInteractive(conf Config) error {
+	if conf.CRUD {
+		return fmt.Errorf("--interactive cannot be used with --crud")
+	}
+
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	p := &prompter{scanner: bufio.NewScanner(conf.Reader), w: conf.Writer}
+
+	for conf.Path == "" {
+		path, err := p.ask("Path (/{resource}.{method})", "")
+		if err != nil {
+			return err
+		}
+		switch {
+		case !pathFormatRegex.MatchString(path):
+			_, _ = fmt.Fprintf(p.w, "  Invalid path format: %s (must follow /{resource}.{method})\n", path)
+		case pathExists(s.paths, path):
+			_, _ = fmt.Fprintf(p.w, "  Path already exists: %s\n", path)
+		default:
+			conf.Path = path
+		}
+	}
+	if !pathFormatRegex.MatchString(conf.Path) {
+		return fmt.Errorf("invalid path format: %s (must follow /{resource}.{method})", conf.Path)
+	}
+
+	// {Resource}{Method} gives the names REQUEST_STANDARD_NAME expects
+	resource, method, _ := strings.Cut(strings.TrimPrefix(conf.Path, "/"), ".")
+	for conf.Name == "" {
+		name, err := p.ask("Name", pascalCase(resource)+pascalCase(method))
+		if err != nil {
+			return err
+		}
+		if !schemaNameRegex.MatchString(name) {
+			_, _ = fmt.Fprintf(p.w, "  Invalid name: %s (must be PascalCase, e.g. CreateUser)\n", name)
+			continue
+		}
+		conf.Name = name
+	}
+
+	if conf.RequestSchema == "" && len(conf.RequestFields) == 0 {
+		if conf.RequestFields, err = askFields(p, s, "Request"); err != nil {
+			return err
+		}
+	}
+	if conf.ResponseSchema == "" && len(conf.ResponseFields) == 0 {
+		if conf.ResponseFields, err = askFields(p, s, "Response"); err != nil {
+			return err
+		}
+	}
+
+	if len(conf.ErrorCodes) == 0 {
+		if conf.ErrorCodes, err = askErrorCodes(p); err != nil {
+			return err
+		}
+	}
+
+	paths, schemas := len(s.paths.Content), len(s.schemas.Content)
+	if err := s.addEndpoint(conf); err != nil {
+		return err
+	}
+
+	preview, err := yaml.Marshal(mapping(
+		scalar("paths"), mapping(s.paths.Content[paths:]...),
+		scalar("components"), mapping(
+			scalar("schemas"), mapping(s.schemas.Content[schemas:]...),
+		),
+	))
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	_, _ = fmt.Fprintf(p.w, "\nThe following will be added to %s:\n\n%s\n", conf.FilePath, preview)
+
+	ok, err := p.confirm("Write changes", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		_, _ = fmt.Fprintln(p.w, "Nothing written")
+		return nil
+	}
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Added endpoint %s to %s\n", conf.Path, conf.FilePath)
+	s.printAddedError(conf.Writer)
+	return nil
+}
+
+// askFields reads name:type fields until a blank line. Leaving the list empty
+// keeps the placeholder properties.
+func askFields(p *prompter, s *spec, kind string) ([]string, error) {
+	_, _ = fmt.Fprintf(p.w, "%s fields as name:type, e.g. created_at:date-time (blank line to finish)\n", kind)
+
+	var fields []string
+	for {
+		field, err := p.ask("  Field", "")
+		if err != nil {
+			return nil, err
+		}
+		if field == "" {
+			return fields, nil
+		}
+
+		if _, _, err := parseFields(s.schemas, append(slices.Clone(fields), field)); err != nil {
+			_, _ = fmt.Fprintf(p.w, "  %s\n", capitalize(err.Error()))
+			continue
+		}
+
+		required, err := p.confirm("  Required", false)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			field += "!"
+		}
+		fields = append(fields, field)
+	}
+}
+
+func askErrorCodes(p *prompter) ([]string, error) {
+	for {
+		answer, err := p.ask("Error codes (400, 401, 403, 404, 409, 429, 500)", strings.Join(defaultErrorCodes, ","))
+		if err != nil {
+			return nil, err
+		}
+
+		codes, err := parseErrorCodes(strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }))
+		if err != nil {
+			_, _ = fmt.Fprintf(p.w, "  %s\n", capitalize(err.Error()))
+			continue
+		}
+		return codes, nil
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
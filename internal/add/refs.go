@@ -0,0 +1,110 @@
+package add
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// referencedSchemas returns the names of the schemas the node references,
+// including discriminator mappings, in the order they are found
+func referencedSchemas(node *yaml.Node) []string {
+	var names []string
+	seen := make(map[string]bool)
+	walk(node, func(n *yaml.Node) {
+		if name, ok := schemaRefName(n.Value); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// reachableSchemas returns every schema referenced from outside
+// components/schemas, directly or through other schemas
+func (s *spec) reachableSchemas() map[string]bool {
+	var roots []string
+	doc := s.root.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "components" {
+			roots = append(roots, referencedSchemas(doc.Content[i+1])...)
+			continue
+		}
+		components := doc.Content[i+1]
+		for j := 0; j+1 < len(components.Content); j += 2 {
+			if components.Content[j].Value != "schemas" {
+				roots = append(roots, referencedSchemas(components.Content[j+1])...)
+			}
+		}
+	}
+	return s.closure(roots)
+}
+
+// closure returns the schemas and every schema they reference
+func (s *spec) closure(names []string) map[string]bool {
+	result := make(map[string]bool)
+	for len(names) > 0 {
+		name := names[0]
+		names = names[1:]
+		if result[name] {
+			continue
+		}
+		result[name] = true
+		if schema := s.schema(name); schema != nil {
+			names = append(names, referencedSchemas(schema)...)
+		}
+	}
+	return result
+}
+
+func (s *spec) schema(name string) *yaml.Node {
+	for i := 0; i+1 < len(s.schemas.Content); i += 2 {
+		if s.schemas.Content[i].Value == name {
+			return s.schemas.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// renameSchema renames the schema and updates every reference to it
+func (s *spec) renameSchema(from, to string) {
+	for i := 0; i+1 < len(s.schemas.Content); i += 2 {
+		if s.schemas.Content[i].Value == from {
+			s.schemas.Content[i].Value = to
+		}
+	}
+	walk(&s.root, func(n *yaml.Node) {
+		if name, ok := schemaRefName(n.Value); ok && name == from {
+			n.Value = schemaRefPrefix + to + strings.TrimPrefix(n.Value, schemaRefPrefix+from)
+		}
+	})
+}
+
+// schemaRefName returns the schema a reference such as #/components/schemas/User or
+// #/components/schemas/User/properties/id points to
+func schemaRefName(value string) (string, bool) {
+	rest, ok := strings.CutPrefix(value, schemaRefPrefix)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name, name != ""
+}
+
+// walk calls fn for every scalar value in the tree, mapping keys excluded
+func walk(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walk(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walk(node.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		fn(node)
+	}
+}
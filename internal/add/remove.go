@@ -0,0 +1,60 @@
+package add
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// RemoveConfig controls the removal of an endpoint
+type RemoveConfig struct {
+	Writer   io.Writer
+	FilePath string
+	Path     string
+}
+
+// Remove deletes the path from the spec along with the schemas it referenced
+// that nothing else references anymore. Schemas that were unreferenced before
+// are left alone.
+func Remove(conf RemoveConfig) error {
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i := 0; i+1 < len(s.paths.Content); i += 2 {
+		if s.paths.Content[i].Value == conf.Path {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("path not found: %s", conf.Path)
+	}
+
+	candidates := s.closure(referencedSchemas(s.paths.Content[index+1]))
+	s.paths.Content = slices.Delete(s.paths.Content, index, index+2)
+
+	reachable := s.reachableSchemas()
+	var removed []string
+	for i := 0; i+1 < len(s.schemas.Content); {
+		name := s.schemas.Content[i].Value
+		if candidates[name] && !reachable[name] {
+			s.schemas.Content = slices.Delete(s.schemas.Content, i, i+2)
+			removed = append(removed, name)
+			continue
+		}
+		i += 2
+	}
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Removed endpoint %s from %s\n", conf.Path, conf.FilePath)
+	for _, name := range removed {
+		_, _ = fmt.Fprintf(conf.Writer, "✓ Removed unreferenced schema %s\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package add_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const editOpenAPI = `openapi: 3.0.3
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /users.get:
+    post:
+      operationId: usersGet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/UsersGetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UsersGetResponse'
+        '400':
+          description: Bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+  /users.list:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/UsersListRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UsersListResponse'
+        '400':
+          description: Bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    # Keep this comment
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+    UsersGetRequest:
+      type: object
+      properties:
+        user_id:
+          type: string
+    UsersGetResponse:
+      type: object
+      properties:
+        user:
+          $ref: '#/components/schemas/User'
+        address:
+          $ref: '#/components/schemas/Address'
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+    UsersListRequest:
+      type: object
+      properties:
+        first:
+          type: integer
+          format: int32
+    UsersListResponse:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            $ref: '#/components/schemas/User'
+    Unused:
+      type: object
+`
+
+func TestRemoveEndpoint(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"remove", "-f", filePath, "/users.get"})
+	require.Equal(t, 0, exitCode)
+
+	assert.Contains(t, stdout.String(), "✓ Removed endpoint /users.get from "+filePath)
+	assert.Contains(t, stdout.String(), "✓ Removed unreferenced schema UsersGetRequest")
+	assert.Contains(t, stdout.String(), "✓ Removed unreferenced schema UsersGetResponse")
+	assert.Contains(t, stdout.String(), "✓ Removed unreferenced schema Address")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.NotContains(t, contentStr, "/users.get")
+	assert.NotContains(t, contentStr, "UsersGet")
+	assert.NotContains(t, contentStr, "Address")
+	assert.Contains(t, contentStr, "# Keep this comment")
+	// Still referenced by /users.list or by nothing before the removal
+	assert.Contains(t, contentStr, "    User:")
+	assert.Contains(t, contentStr, "    Error:")
+	assert.Contains(t, contentStr, "    Unused:")
+}
+
+func TestRemoveEndpointNotFound(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"remove", "-f", filePath, "/users.delete"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "Error: path not found: /users.delete")
+	assert.Empty(t, stdout.String())
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, editOpenAPI, string(content))
+}
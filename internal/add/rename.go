@@ -0,0 +1,158 @@
+package add
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var endpointPathRegex = regexp.MustCompile(`^/([a-z][a-z0-9_-]{0,49}/)*[a-z][a-z0-9_-]{0,49}\.[a-z][a-z0-9_-]{0,49}$`)
+
+// RenameConfig controls the renaming of an endpoint
+type RenameConfig struct {
+	Writer   io.Writer
+	FilePath string
+	Path     string
+	NewPath  string
+	// Name renames the request and response schemas to {Name}Request and
+	// {Name}Response and sets the operationId. Without it only schemas following
+	// the {Resource}{Method}Request convention are renamed to match the new path.
+	Name string
+}
+
+// Rename moves the endpoint to a new path in place, renaming the request and
+// response schemas it references and every reference to them
+func Rename(conf RenameConfig) error {
+	if !endpointPathRegex.MatchString(conf.NewPath) {
+		return fmt.Errorf("invalid path format: %s (must follow /{resource}.{method})", conf.NewPath)
+	}
+	if conf.Name != "" && !schemaNameRegex.MatchString(conf.Name) {
+		return fmt.Errorf("invalid name: %s (must be PascalCase, e.g. FetchUser)", conf.Name)
+	}
+
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	var key, item *yaml.Node
+	for i := 0; i+1 < len(s.paths.Content); i += 2 {
+		if s.paths.Content[i].Value == conf.Path {
+			key, item = s.paths.Content[i], s.paths.Content[i+1]
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("path not found: %s", conf.Path)
+	}
+	if pathExists(s.paths, conf.NewPath) {
+		return fmt.Errorf("path already exists: %s", conf.NewPath)
+	}
+
+	type rename struct{ from, to string }
+	var renames []rename
+	for _, op := range operations(item) {
+		request := refName(child(child(child(child(op, "requestBody"), "content"), "application/json"), "schema"))
+		response := refName(child(child(child(child(child(op, "responses"), "200"), "content"), "application/json"), "schema"))
+
+		for _, r := range []rename{
+			{from: request, to: newSchemaName(request, "Request", conf)},
+			{from: response, to: newSchemaName(response, "Response", conf)},
+		} {
+			if r.from == "" || r.to == "" || r.from == r.to {
+				continue
+			}
+			if schemaExists(s.schemas, r.to) {
+				return fmt.Errorf("schema already exists: %s", r.to)
+			}
+			renames = append(renames, r)
+		}
+
+		if conf.Name != "" {
+			if id := child(op, "operationId"); id != nil {
+				id.Value = camelCase(conf.Name)
+			} else {
+				op.Content = append(op.Content, scalar("operationId"), scalar(camelCase(conf.Name)))
+			}
+		}
+	}
+
+	key.Value = conf.NewPath
+	for _, r := range renames {
+		s.renameSchema(r.from, r.to)
+	}
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Renamed endpoint %s to %s in %s\n", conf.Path, conf.NewPath, conf.FilePath)
+	for _, r := range renames {
+		_, _ = fmt.Fprintf(conf.Writer, "✓ Renamed schema %s to %s\n", r.from, r.to)
+	}
+	return nil
+}
+
+// newSchemaName returns the name the schema gets after the rename, or an empty
+// string when it keeps its name
+func newSchemaName(name, suffix string, conf RenameConfig) string {
+	if name == "" {
+		return ""
+	}
+	if conf.Name != "" {
+		return conf.Name + suffix
+	}
+
+	oldResource, oldMethod := resourceMethod(conf.Path)
+	newResource, newMethod := resourceMethod(conf.NewPath)
+	switch name {
+	case oldResource + oldMethod + suffix:
+		return newResource + newMethod + suffix
+	case oldMethod + suffix:
+		return newMethod + suffix
+	}
+	return ""
+}
+
+// resourceMethod returns the PascalCase resource and method of a path such as
+// /users.get-by-id
+func resourceMethod(path string) (string, string) {
+	path = path[strings.LastIndex(path, "/")+1:]
+	resource, method, _ := strings.Cut(path, ".")
+	return pascalCase(resource), pascalCase(method)
+}
+
+// operations returns the operations of a path item
+func operations(item *yaml.Node) []*yaml.Node {
+	var ops []*yaml.Node
+	for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+		if op := child(item, method); op != nil {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// refName returns the name of the component schema a schema node references
+func refName(schema *yaml.Node) string {
+	ref := child(schema, "$ref")
+	if ref == nil {
+		return ""
+	}
+	name, _ := schemaRefName(ref.Value)
+	return name
+}
+
+func child(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package add_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameEndpoint(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"rename", "-f", filePath, "/users.get", "/users.fetch"})
+	require.Equal(t, 0, exitCode)
+
+	assert.Contains(t, stdout.String(), "✓ Renamed endpoint /users.get to /users.fetch in "+filePath)
+	assert.Contains(t, stdout.String(), "✓ Renamed schema UsersGetRequest to UsersFetchRequest")
+	assert.Contains(t, stdout.String(), "✓ Renamed schema UsersGetResponse to UsersFetchResponse")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.NotContains(t, contentStr, "UsersGet")
+	assert.Contains(t, contentStr, "# Keep this comment")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/UsersFetchRequest'")
+	assert.Contains(t, contentStr, "    UsersFetchResponse:")
+	// The path keeps its position
+	assert.Less(t, strings.Index(contentStr, "/users.fetch:"), strings.Index(contentStr, "/users.list:"))
+	assert.Contains(t, contentStr, "operationId: usersGet")
+
+	var lintStdout bytes.Buffer
+	duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", filePath})
+	assert.NotContains(t, lintStdout.String(), "STANDARD_NAME")
+}
+
+func TestRenameEndpointWithName(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(editOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"rename", "-f", filePath, "/users.list", "/users.search", "--name", "SearchUsers"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, "/users.search:")
+	assert.Contains(t, contentStr, "operationId: searchUsers")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/SearchUsersRequest'")
+	assert.Contains(t, contentStr, "$ref: '#/components/schemas/SearchUsersResponse'")
+	assert.NotContains(t, contentStr, "UsersList")
+}
+
+func TestRenameEndpointErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "path not found",
+			args:    []string{"/users.delete", "/users.remove"},
+			wantErr: "path not found: /users.delete",
+		},
+		{
+			name:    "path exists",
+			args:    []string{"/users.get", "/users.list"},
+			wantErr: "path already exists: /users.list",
+		},
+		{
+			name:    "invalid path",
+			args:    []string{"/users.get", "/users/fetch"},
+			wantErr: "invalid path format: /users/fetch",
+		},
+		{
+			name:    "invalid name",
+			args:    []string{"/users.get", "/users.fetch", "--name", "fetch_user"},
+			wantErr: "invalid name: fetch_user",
+		},
+		{
+			name:    "schema exists",
+			args:    []string{"/users.get", "/users.fetch", "--name", "User"},
+			wantErr: "schema already exists: UserRequest",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			spec := strings.Replace(editOpenAPI, "    Unused:", "    UserRequest:", 1)
+			require.NoError(t, os.WriteFile(filePath, []byte(spec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"rename", "-f", filePath}, test.args...))
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, spec, string(content))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package add
+
+import (
+	"fmt"
+	"io"
+)
+
+// SchemaConfig controls adding a standalone component schema
+type SchemaConfig struct {
+	Writer   io.Writer
+	FilePath string
+	Name     string
+	// Fields are name:type definitions as accepted by --req-field
+	Fields []string
+}
+
+// Schema adds an object schema to components/schemas without adding a path, for
+// shared sub-objects referenced by other schemas
+func Schema(conf SchemaConfig) error {
+	if !schemaNameRegex.MatchString(conf.Name) {
+		return fmt.Errorf("invalid schema name: %s (must be PascalCase, e.g. Address)", conf.Name)
+	}
+	if len(conf.Fields) == 0 {
+		return fmt.Errorf("no fields given: use --field name:type, e.g. --field street:string")
+	}
+
+	s, err := load(conf.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if schemaExists(s.schemas, conf.Name) {
+		return fmt.Errorf("schema already exists: %s", conf.Name)
+	}
+
+	properties, required, err := parseFields(s.schemas, conf.Fields)
+	if err != nil {
+		return err
+	}
+	addSchema(s.schemas, conf.Name, object(required, properties...))
+
+	if err := s.save(conf.FilePath); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Added schema %s to %s\n", conf.Name, conf.FilePath)
+	return nil
+}
@@ -0,0 +1,99 @@
+package add_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddSchema(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"add", "schema", "Address", "-f", filePath,
+		"--field", "street:string",
+		"--field", "zip:string!",
+	})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Added schema Address to "+filePath)
+
+	// The new schema can be referenced by later fields
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"add", "schema", "Customer", "-f", filePath,
+		"--field", "addresses:[]Address",
+	})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, `        Address:
+            type: object
+            required:
+                - zip
+            properties:
+                street:
+                    type: string
+                zip:
+                    type: string
+        Customer:
+            type: object
+            properties:
+                addresses:
+                    type: array
+                    items:
+                        $ref: '#/components/schemas/Address'
+`)
+}
+
+func TestAddSchemaErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "exists",
+			args:    []string{"Error", "--field", "message:string"},
+			wantErr: "schema already exists: Error",
+		},
+		{
+			name:    "invalid name",
+			args:    []string{"address", "--field", "street:string"},
+			wantErr: "invalid schema name: address",
+		},
+		{
+			name:    "no fields",
+			args:    []string{"Address"},
+			wantErr: "no fields given",
+		},
+		{
+			name:    "invalid field",
+			args:    []string{"Address", "--field", "street"},
+			wantErr: "invalid field 'street'",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(minimalOpenAPI), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"add", "schema", "-f", filePath}, test.args...))
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, minimalOpenAPI, string(content))
+		})
+	}
+}
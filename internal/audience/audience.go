@@ -0,0 +1,225 @@
+package audience
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Extension names the audience of an operation, operations without one are
+// public
+const Extension = "x-duh-audience"
+
+const componentsPrefix = "#/components/"
+
+// Audiences from the widest to the narrowest, each audience sees the
+// operations of the audiences after it: internal sees every operation,
+// partner the partner and public ones and public only the public ones
+var Audiences = []string{"internal", "partner", "public"}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Check returns an error unless audience is empty or one of Audiences
+func Check(audience string) error {
+	if audience != "" && !slices.Contains(Audiences, audience) {
+		return fmt.Errorf("unknown --audience value '%s': must be internal, partner or public", audience)
+	}
+	return nil
+}
+
+// Load reads the spec at path with only the operations audience sees, or
+// every operation when audience is empty
+func Load(path, audience string) (*v3.Document, error) {
+	if audience == "" {
+		return lint.Load(path)
+	}
+	if err := Check(audience); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", lint.ErrFileNotFound, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", lint.ErrParse, err)
+	}
+	if content, err = Filter(content, audience); err != nil {
+		return nil, err
+	}
+	return lint.Parse(content)
+}
+
+// Filter returns the spec without the operations audience does not see, the
+// paths left without operations and the components and tags only those
+// operations used. Components no operation uses are kept.
+func Filter(content []byte, audience string) ([]byte, error) {
+	if err := Check(audience); err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", lint.ErrParse, err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return content, nil
+	}
+	doc := root.Content[0]
+	paths := mapValue(doc, "paths")
+	if paths == nil {
+		return content, nil
+	}
+
+	var removed, kept []*yaml.Node
+	var filtered []*yaml.Node
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, item := paths.Content[i].Value, paths.Content[i+1]
+		var operations []*yaml.Node
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			if !slices.Contains(methods, item.Content[j].Value) {
+				continue
+			}
+			op := item.Content[j+1]
+			sees, err := sees(audience, op, path)
+			if err != nil {
+				return nil, err
+			}
+			if sees {
+				kept = append(kept, op)
+				operations = append(operations, op)
+				continue
+			}
+			removed = append(removed, op)
+			item.Content = slices.Delete(item.Content, j, j+2)
+			j -= 2
+		}
+		if len(operations) > 0 {
+			filtered = append(filtered, paths.Content[i], item)
+		}
+	}
+	if len(removed) == 0 {
+		return content, nil
+	}
+	paths.Content = filtered
+
+	// The path items, such as their parameters, use components too
+	used, unused := make(map[string]bool), make(map[string]bool)
+	for i := 1; i < len(paths.Content); i += 2 {
+		reach(doc, paths.Content[i], used)
+	}
+	for _, op := range removed {
+		reach(doc, op, unused)
+	}
+	components := mapValue(doc, "components")
+	for ref := range unused {
+		kind, name, _ := strings.Cut(ref, "/")
+		if !used[ref] {
+			deleteKey(mapValue(components, kind), name)
+		}
+	}
+
+	if tags := mapValue(doc, "tags"); tags != nil {
+		usedTags, unusedTags := operationTags(kept), operationTags(removed)
+		tags.Content = slices.DeleteFunc(tags.Content, func(tag *yaml.Node) bool {
+			name := mapValue(tag, "name")
+			return name != nil && unusedTags[name.Value] && !usedTags[name.Value]
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to write OpenAPI spec: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// sees reports whether audience sees the operation at path
+func sees(audience string, op *yaml.Node, path string) (bool, error) {
+	node := mapValue(op, Extension)
+	if node == nil {
+		return true, nil
+	}
+	i := slices.Index(Audiences, node.Value)
+	if node.Kind != yaml.ScalarNode || i < 0 {
+		return false, fmt.Errorf("%s '%s' on path %s must be internal, partner or public", Extension, node.Value, path)
+	}
+	return i >= slices.Index(Audiences, audience), nil
+}
+
+func operationTags(ops []*yaml.Node) map[string]bool {
+	tags := make(map[string]bool)
+	for _, op := range ops {
+		if list := mapValue(op, "tags"); list != nil {
+			for _, tag := range list.Content {
+				tags[tag.Value] = true
+			}
+		}
+	}
+	return tags
+}
+
+// reach records every component the node references, directly or through
+// other components, as kind/name
+func reach(doc, node *yaml.Node, reached map[string]bool) {
+	walkRefs(node, func(ref *yaml.Node) {
+		rest, ok := strings.CutPrefix(ref.Value, componentsPrefix)
+		if !ok || reached[rest] {
+			return
+		}
+		reached[rest] = true
+		kind, name, _ := strings.Cut(rest, "/")
+		if target := mapValue(mapValue(mapValue(doc, "components"), kind), name); target != nil {
+			reach(doc, target, reached)
+		}
+	})
+}
+
+// walkRefs calls fn with the value of every $ref in the tree
+func walkRefs(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkRefs(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+				fn(node.Content[i+1])
+				continue
+			}
+			walkRefs(node.Content[i+1], fn)
+		}
+	}
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func deleteKey(node *yaml.Node, key string) {
+	if node == nil {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package audience_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/internal/audience"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+
+	initSpec, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	spec := strings.Replace(string(initSpec), "  /users.list:\n    post:\n", "  /users.list:\n    post:\n      x-duh-audience: internal\n", 1)
+	spec = strings.Replace(spec, "  /users.update:\n    post:\n", "  /users.update:\n    post:\n      x-duh-audience: partner\n", 1)
+	content := []byte(spec)
+
+	public, err := audience.Filter(content, "public")
+	require.NoError(t, err)
+	assert.Contains(t, string(public), "  /users.create:\n")
+	assert.Contains(t, string(public), "  /users.get:\n")
+	assert.NotContains(t, string(public), "/users.list")
+	assert.NotContains(t, string(public), "/users.update")
+	for _, schema := range []string{"ListRequest", "ListResponse", "PaginationRequest", "PaginationResponse", "UpdateRequest", "UpdateResponse"} {
+		assert.NotContains(t, string(public), "    "+schema+":\n")
+	}
+	assert.Contains(t, string(public), "    Error:\n")
+	assert.Contains(t, string(public), "    CreateRequest:\n")
+
+	partner, err := audience.Filter(content, "partner")
+	require.NoError(t, err)
+	assert.Contains(t, string(partner), "  /users.update:\n")
+	assert.Contains(t, string(partner), "    UpdateRequest:\n")
+	assert.NotContains(t, string(partner), "/users.list")
+
+	// internal sees every operation, the spec is left as it is
+	internal, err := audience.Filter(content, "internal")
+	require.NoError(t, err)
+	assert.Equal(t, string(content), string(internal))
+}
+
+func TestFilterErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+
+	content, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+
+	_, err = audience.Filter(content, "everyone")
+	assert.EqualError(t, err, "unknown --audience value 'everyone': must be internal, partner or public")
+
+	invalid := strings.Replace(string(content), "  /users.update:\n    post:\n", "  /users.update:\n    post:\n      x-duh-audience: partners\n", 1)
+	_, err = audience.Filter([]byte(invalid), "public")
+	assert.EqualError(t, err, "x-duh-audience 'partners' on path /users.update must be internal, partner or public")
+}
+
+func TestExportAudience(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+
+	content, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	spec := strings.Replace(string(content), "  /users.list:\n    post:\n", "  /users.list:\n    post:\n      x-duh-audience: internal\n", 1)
+	spec = strings.Replace(spec, "  /users.update:\n    post:\n", "  /users.update:\n    post:\n      x-duh-audience: partner\n", 1)
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+	dir := filepath.Dir(specPath)
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"export", "postman", specPath, "--audience", "partner",
+		"-o", filepath.Join(dir, "collection.json")})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), "✓ Exported 3 request(s)")
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"export", "jsonschema", specPath, "--audience", "public",
+		"--out", filepath.Join(dir, "schemas")})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.NoFileExists(t, filepath.Join(dir, "schemas", "ListRequest.json"))
+	assert.FileExists(t, filepath.Join(dir, "schemas", "CreateRequest.json"))
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"docs", specPath, "--audience", "public",
+		"-o", filepath.Join(dir, "docs.html")})
+	require.Equal(t, 0, exitCode, stderr.String())
+	html, err := os.ReadFile(filepath.Join(dir, "docs.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "/users.create")
+	assert.NotContains(t, string(html), "/users.list")
+}
@@ -0,0 +1,75 @@
+package call
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/duhdyn"
+	"github.com/duh-rpc/duh-cli/internal/conformance"
+	"github.com/duh-rpc/duh-cli/internal/output"
+)
+
+// Config controls the rpc called and the request sent to it
+type Config struct {
+	Writer io.Writer
+	// Descriptors is the binary descriptor set the messages are built from
+	Descriptors string
+	// Path is the DUH-RPC path of the rpc, e.g. /v1/users.create
+	Path    string
+	BaseURL string
+	// Data is the request in JSON, - reads Stdin
+	Data    string
+	Stdin   io.Reader
+	Headers []string
+	Timeout time.Duration
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// Run calls the rpc at Path with the request of Data and prints the reply in
+// JSON. An error reply of the service is returned as a *duhdyn.Error.
+func Run(conf Config) error {
+	if conf.Descriptors == "" {
+		return errors.New("--descriptors is required")
+	}
+	if conf.BaseURL == "" {
+		return errors.New("--base-url is required")
+	}
+	headers, err := conformance.ParseHeaders(conf.Headers)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	files, err := duhdyn.Load(conf.Descriptors)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.Descriptors, start)
+
+	request := []byte(conf.Data)
+	if conf.Data == "-" {
+		if request, err = io.ReadAll(conf.Stdin); err != nil {
+			return fmt.Errorf("failed to read the request from stdin: %w", err)
+		}
+	}
+	if len(request) == 0 {
+		request = []byte("{}")
+	}
+
+	client := duhdyn.New(files, duhdyn.Config{
+		BaseURL:    conf.BaseURL,
+		HTTPClient: &http.Client{Timeout: conf.Timeout},
+		Header:     headers,
+	})
+	reply, err := client.CallJSON(context.Background(), conf.Path, request)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(conf.Writer, string(reply))
+	return nil
+}
@@ -0,0 +1,264 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/example"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/duh-rpc/duh-cli/internal/validate"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// ErrFailed is returned when an operation does not conform to the spec
+var ErrFailed = errors.New("server does not conform to the spec")
+
+const (
+	pass = "✓"
+	fail = "✗"
+	skip = "-"
+)
+
+// Config controls the server tested and the requests sent to it
+type Config struct {
+	Writer   io.Writer
+	SpecPath string
+	// BaseURL is prepended to the path of every operation
+	BaseURL string
+	// Headers are added to every request, e.g. 'Authorization: Bearer token'
+	Headers []string
+	Timeout time.Duration
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// check is the outcome of the request sent to an operation, a cell is - when
+// it was not checked
+type check struct {
+	path        string
+	status      string
+	contentType string
+	schema      string
+	errs        []string
+}
+
+func (c check) failed() bool {
+	return len(c.errs) > 0
+}
+
+// Run sends an example request to every operation of the spec and checks
+// the status code, content type and body of the response against it
+func Run(conf Config) error {
+	if conf.BaseURL == "" {
+		return errors.New("--base-url is required")
+	}
+	headers, err := ParseHeaders(conf.Headers)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	doc, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	client := &http.Client{Timeout: conf.Timeout}
+	baseURL := strings.TrimSuffix(conf.BaseURL, "/")
+
+	var checks []check
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		for path, item := range doc.Paths.PathItems.FromOldest() {
+			if item.Post == nil {
+				continue
+			}
+			start := time.Now()
+			checks = append(checks, run(client, baseURL, path, item.Post, headers))
+			conf.Log.Printf("Tested %s in %s", path, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	if len(checks) == 0 {
+		return fmt.Errorf("no operations found in %s", conf.SpecPath)
+	}
+
+	write(conf.Writer, baseURL, checks)
+
+	for _, c := range checks {
+		if c.failed() {
+			return ErrFailed
+		}
+	}
+	return nil
+}
+
+// run posts the example of the request schema to the operation and checks
+// the response
+func run(client *http.Client, baseURL, path string, op *v3.Operation, headers http.Header) check {
+	c := check{path: path, status: skip, contentType: skip, schema: skip}
+
+	body := []byte("{}")
+	if media := jsonMedia(op.RequestBody); media != nil && media.Schema != nil {
+		b, err := example.JSON(media.Schema)
+		if err != nil {
+			c.errs = append(c.errs, fmt.Sprintf("failed to build the request: %v", err))
+			return c
+		}
+		body = b
+	}
+
+	req, err := http.NewRequest(http.MethodPost, baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		c.errs = append(c.errs, fmt.Sprintf("failed to build the request: %v", err))
+		return c
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		c.status = fail
+		c.errs = append(c.errs, fmt.Sprintf("request failed: %v", err))
+		return c
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	code := strconv.Itoa(resp.StatusCode)
+	response := lookupResponse(op.Responses, code)
+	if response == nil {
+		c.status = fail + " " + code
+		c.errs = append(c.errs, fmt.Sprintf("status %s is not a declared response", code))
+		return c
+	}
+	c.status = pass + " " + code
+
+	// A response without content, such as a 204, has nothing more to check
+	if response.Content == nil || response.Content.Len() == 0 {
+		return c
+	}
+
+	header := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(header)
+	media := response.Content.GetOrZero(mediaType)
+	if media == nil {
+		c.contentType = fail
+		c.errs = append(c.errs, fmt.Sprintf("content type '%s' is not declared for status %s", header, code))
+		return c
+	}
+	c.contentType = pass
+
+	// Only JSON bodies are validated, protobuf and streams are not decoded
+	if mediaType != "application/json" || media.Schema == nil {
+		return c
+	}
+
+	errs, err := validateBody(resp.Body, media.Schema)
+	switch {
+	case err != nil:
+		c.schema = fail
+		c.errs = append(c.errs, err.Error())
+	case len(errs) > 0:
+		c.schema = fail
+		c.errs = append(c.errs, errs...)
+	default:
+		c.schema = pass
+	}
+	return c
+}
+
+// lookupResponse returns the response declared for the status code, falling
+// back to its range (e.g. 4XX) and then the default response
+func lookupResponse(responses *v3.Responses, code string) *v3.Response {
+	if responses == nil {
+		return nil
+	}
+	if responses.Codes != nil {
+		if r := responses.Codes.GetOrZero(code); r != nil {
+			return r
+		}
+		if r := responses.Codes.GetOrZero(code[:1] + "XX"); r != nil {
+			return r
+		}
+	}
+	return responses.Default
+}
+
+func jsonMedia(body *v3.RequestBody) *v3.MediaType {
+	if body == nil || body.Content == nil {
+		return nil
+	}
+	return body.Content.GetOrZero("application/json")
+}
+
+// validateBody decodes the body keeping numbers as json.Number, which tells
+// integers from numbers, and validates it against the schema the way protojson
+// writes it
+func validateBody(r io.Reader, proxy *base.SchemaProxy) ([]string, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse the response: %w", err)
+	}
+	return validate.ProtoJSON(proxy, value), nil
+}
+
+// ParseHeaders returns the --header values, each 'Name: value', as headers
+func ParseHeaders(values []string) (http.Header, error) {
+	headers := make(http.Header)
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header '%s': must be 'Name: value'", value)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	}
+	return headers, nil
+}
+
+// write prints the pass/fail matrix followed by the reasons of each failure
+func write(w io.Writer, baseURL string, checks []check) {
+	width := len("OPERATION")
+	for _, c := range checks {
+		width = max(width, len(c.path))
+	}
+
+	_, _ = fmt.Fprintf(w, "Conformance of %s\n\n", baseURL)
+	_, _ = fmt.Fprintf(w, "  %-*s  %-8s  %-12s  %s\n", width, "OPERATION", "STATUS", "CONTENT-TYPE", "SCHEMA")
+	var failed int
+	for _, c := range checks {
+		_, _ = fmt.Fprintf(w, "  %-*s  %-8s  %-12s  %s\n", width, c.path, c.status, c.contentType, c.schema)
+		if c.failed() {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		_, _ = fmt.Fprintf(w, "\n✓ All %d operation(s) conform to the spec\n", len(checks))
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "\n✗ %d of %d operation(s) do not conform to the spec\n", failed, len(checks))
+	for _, c := range checks {
+		if !c.failed() {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\n%s\n", c.path)
+		for _, e := range c.errs {
+			_, _ = fmt.Fprintf(w, "  - %s\n", e)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package conformance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const conformanceSpec = `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /v1/users.create:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateUserRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        '400':
+          $ref: '#/components/responses/Error'
+  /v1/users.get:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetUserRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  responses:
+    Error:
+      description: Error
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+  schemas:
+    CreateUserRequest:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Ann
+    GetUserRequest:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+    User:
+      type: object
+      required: [id, name, visits, active, roles]
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+        visits:
+          type: integer
+          format: int64
+        active:
+          type: boolean
+        roles:
+          type: array
+          items:
+            type: string
+    Error:
+      type: object
+      required: [code, message]
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+`
+
+func reply(w http.ResponseWriter, contentType string, status int, body any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestConformancePass(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	var paths []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		auth = r.Header.Get("Authorization")
+
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if r.URL.Path == "/v1/users.create" {
+			assert.Equal(t, "Ann", req["name"])
+		}
+		reply(w, "application/json; charset=utf-8", http.StatusOK, map[string]any{"id": "1", "name": "Ann"})
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"test", "conformance", specPath, "--base-url", server.URL,
+		"--header", "Authorization: Bearer token"})
+	out := stdout.String()
+
+	require.Equal(t, 0, exitCode, out)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, []string{"/v1/users.create", "/v1/users.get"}, paths)
+	assert.Equal(t, "Bearer token", auth)
+	assert.Contains(t, out, "  /v1/users.create  ✓ 200     ✓             ✓\n")
+	assert.Contains(t, out, "✓ All 2 operation(s) conform to the spec")
+}
+
+func TestConformanceFail(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/users.create":
+			reply(w, "application/json", http.StatusBadRequest, map[string]any{"code": "400"})
+		default:
+			reply(w, "text/plain", http.StatusInternalServerError, "boom")
+		}
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"test", "conformance", specPath, "--base-url", server.URL})
+	out := stdout.String()
+
+	require.Equal(t, 1, exitCode, out)
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, out, "  /v1/users.create  ✓ 400     ✓             ✗\n")
+	assert.Contains(t, out, "  /v1/users.get     ✗ 500     -             -\n")
+	assert.Contains(t, out, "✗ 2 of 2 operation(s) do not conform to the spec")
+	assert.Contains(t, out, "$.code: expected integer, got string")
+	assert.Contains(t, out, "$: missing required property 'message'")
+	assert.Contains(t, out, "status 500 is not a declared response")
+}
+
+func TestConformanceProtoJSON(t *testing.T) {
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("user.proto"),
+		Package: proto.String("users.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("id"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("name"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+				{Name: proto.String("visits"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum()},
+				{Name: proto.String("active"), Number: proto.Int32(4), Type: descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum()},
+				{Name: proto.String("roles"), Number: proto.Int32(5), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+			},
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	// visits is written as a string, active and roles hold their zero value and are omitted
+	user := dynamicpb.NewMessage(file.Messages().ByName("User"))
+	fields := user.Descriptor().Fields()
+	user.Set(fields.ByName("id"), protoreflect.ValueOfString("1"))
+	user.Set(fields.ByName("name"), protoreflect.ValueOfString("Ann"))
+	user.Set(fields.ByName("visits"), protoreflect.ValueOfInt64(9007199254740993))
+	body, err := protojson.Marshal(user)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `"9007199254740993"`)
+
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"test", "conformance", specPath, "--base-url", server.URL})
+
+	require.Equal(t, 0, exitCode, stdout.String())
+	assert.Contains(t, stdout.String(), "✓ All 2 operation(s) conform to the spec")
+	assert.Empty(t, stderr.String())
+}
+
+func TestConformanceContentType(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply(w, "text/plain", http.StatusOK, "ok")
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"test", "conformance", specPath, "--base-url", server.URL})
+
+	require.Equal(t, 1, exitCode, stdout.String())
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "content type 'text/plain' is not declared for status 200")
+}
+
+func TestConformanceErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	for _, test := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "no base url",
+			args: []string{"--base-url", ""},
+			want: "--base-url is required",
+		},
+		{
+			name: "invalid header",
+			args: []string{"--header", "Authorization"},
+			want: "invalid header 'Authorization'",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"test", "conformance", specPath,
+				"--base-url", "http://localhost:8080"}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.want)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestConformanceQuiet(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(conformanceSpec), 0644))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply(w, "text/plain", http.StatusInternalServerError, "boom")
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"test", "conformance", "-q", specPath, "--base-url", server.URL})
+
+	require.Equal(t, 1, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), "✗ 2 of 2 operation(s) do not conform to the spec")
+	assert.Contains(t, stdout.String(), "status 500 is not a declared response")
+	assert.Empty(t, stderr.String())
+}
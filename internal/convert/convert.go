@@ -0,0 +1,180 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultVersion = "v1"
+	errorSchema    = "Error"
+)
+
+var serverVersionRegex = regexp.MustCompile(`/v\d+$`)
+
+// Config controls the REST to DUH-RPC conversion
+type Config struct {
+	Writer     io.Writer
+	SpecPath   string
+	OutputPath string
+}
+
+// Run rewrites a RESTful OpenAPI spec into DUH-RPC form and writes the result to OutputPath
+func Run(conf Config) error {
+	if _, err := os.Stat(conf.SpecPath); os.IsNotExist(err) {
+		return fmt.Errorf("file not found: %s", conf.SpecPath)
+	}
+
+	data, err := os.ReadFile(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("invalid OpenAPI document structure")
+	}
+
+	c := &converter{doc: root.Content[0]}
+	if err := c.convert(); err != nil {
+		return err
+	}
+
+	if err := writeYAML(conf.OutputPath, &root); err != nil {
+		return err
+	}
+
+	for _, s := range c.skipped {
+		_, _ = fmt.Fprintf(conf.Writer, "Skipped %s\n", s)
+	}
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Converted %d operation(s) to %s\n", c.converted, conf.OutputPath)
+	return nil
+}
+
+func writeYAML(path string, root *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	_ = enc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+type converter struct {
+	doc       *yaml.Node
+	schemas   *yaml.Node
+	version   string
+	paginated bool
+	converted int
+	skipped   []string
+}
+
+func (c *converter) convert() error {
+	version := get(c.doc, "openapi")
+	if version == nil || !strings.HasPrefix(version.Value, "3.") {
+		return fmt.Errorf("only OpenAPI 3.x specifications can be converted")
+	}
+
+	components := get(c.doc, "components")
+	if components == nil {
+		components = mapping()
+		set(c.doc, "components", components)
+	}
+
+	c.schemas = get(components, "schemas")
+	if c.schemas == nil {
+		c.schemas = mapping()
+		set(components, "schemas", c.schemas)
+	}
+
+	paths := mapping()
+	if old := get(c.doc, "paths"); old != nil {
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			c.convertPath(paths, old.Content[i].Value, old.Content[i+1])
+		}
+	}
+	set(c.doc, "paths", paths)
+
+	set(c.schemas, errorSchema, newErrorSchema())
+	if c.paginated {
+		set(c.schemas, paginationRequest, newPaginationRequest())
+		set(c.schemas, paginationResponse, newPaginationResponse())
+	}
+
+	for i := 1; i < len(c.schemas.Content); i += 2 {
+		normalize(c.schemas.Content[i])
+	}
+
+	// Parameters, request bodies and responses are inlined into the new operations
+	remove(components, "parameters")
+	remove(components, "requestBodies")
+	remove(components, "responses")
+
+	c.convertServers()
+	return nil
+}
+
+// convertServers moves the API version onto the server URLs since DUH-RPC paths are unversioned
+func (c *converter) convertServers() {
+	version := c.version
+	if version == "" {
+		version = defaultVersion
+	}
+
+	servers := get(c.doc, "servers")
+	if servers == nil || servers.Kind != yaml.SequenceNode || len(servers.Content) == 0 {
+		set(c.doc, "servers", sequence(mapping(scalar("url"), scalar("/"+version))))
+		return
+	}
+
+	for _, server := range servers.Content {
+		url := get(server, "url")
+		if url == nil || serverVersionRegex.MatchString(url.Value) {
+			continue
+		}
+		url.Value = strings.TrimSuffix(url.Value, "/") + "/" + version
+	}
+}
+
+// resolve follows local $ref pointers such as '#/components/parameters/Limit'
+func (c *converter) resolve(node *yaml.Node) *yaml.Node {
+	for range 10 {
+		r := get(node, "$ref")
+		if r == nil {
+			return node
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.Value, "#/"), "/")
+		if len(parts) != 3 || parts[0] != "components" {
+			return node
+		}
+
+		target := get(get(get(c.doc, "components"), parts[1]), parts[2])
+		if target == nil {
+			return node
+		}
+		node = target
+	}
+	return node
+}
@@ -0,0 +1,471 @@
+package convert_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const restSpec = `openapi: 3.0.3
+info:
+  title: Users API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /v1/users:
+    get:
+      summary: List users
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: status
+          in: query
+          description: Filter by status
+          schema:
+            type: string
+      responses:
+        '200':
+          description: A list of users
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/User'
+    post:
+      summary: Create a user
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewUser'
+      responses:
+        '201':
+          description: Created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        '422':
+          description: Validation failed
+  /v1/users/{userId}:
+    parameters:
+      - $ref: '#/components/parameters/UserId'
+    get:
+      operationId: getUser
+      responses:
+        '200':
+          description: The user
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+        '404':
+          description: Not found
+          content:
+            application/json:
+              schema:
+                type: string
+        default:
+          description: Unexpected error
+    patch:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/NewUser'
+      responses:
+        '200':
+          description: Updated
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+    delete:
+      responses:
+        '204':
+          description: Deleted
+        '503':
+          description: Unavailable
+  /v1/users/{userId}/activate:
+    post:
+      parameters:
+        - name: userId
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '204':
+          description: Activated
+components:
+  parameters:
+    UserId:
+      name: userId
+      in: path
+      required: true
+      description: The user identifier
+      schema:
+        type: string
+  schemas:
+    NewUser:
+      type: object
+      required: [firstName]
+      properties:
+        firstName:
+          type: string
+        nickName:
+          type: string
+          nullable: true
+        age:
+          type: integer
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        firstName:
+          type: string
+        createdAt:
+          type: string
+        score:
+          type: number
+        tags:
+          type: object
+          additionalProperties: true
+`
+
+type convertedSpec struct {
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]struct {
+		Post struct {
+			Description string                    `yaml:"description"`
+			Responses   map[string]map[string]any `yaml:"responses"`
+		} `yaml:"post"`
+	} `yaml:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Required   []string       `yaml:"required"`
+			Properties map[string]any `yaml:"properties"`
+			Enum       []string       `yaml:"enum"`
+		} `yaml:"schemas"`
+		Parameters map[string]any `yaml:"parameters"`
+	} `yaml:"components"`
+}
+
+func TestConvertPassesLint(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"convert", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Converted 6 operation(s)")
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"lint", outputPath})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "0 errors")
+}
+
+func TestConvertPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	assert.Len(t, converted.Paths, 6)
+	for _, path := range []string{
+		"/users.list",
+		"/users.create",
+		"/users.get",
+		"/users.update",
+		"/users.delete",
+		"/users.activate",
+	} {
+		assert.Contains(t, converted.Paths, path)
+	}
+
+	assert.Equal(t, "List users", converted.Paths["/users.list"].Post.Description)
+	assert.Equal(t, "Converted from DELETE /v1/users/{userId}", converted.Paths["/users.delete"].Post.Description)
+}
+
+func TestConvertVersionMovesToServer(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	require.Len(t, converted.Servers, 1)
+	assert.Equal(t, "https://api.example.com/v1", converted.Servers[0].URL)
+}
+
+func TestConvertFoldsParametersIntoRequest(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	get := converted.Components.Schemas["UsersGetRequest"]
+	assert.Equal(t, []string{"user_id"}, get.Required)
+	assert.Contains(t, get.Properties, "user_id")
+
+	update := converted.Components.Schemas["UsersUpdateRequest"]
+	assert.Equal(t, []string{"user_id", "first_name"}, update.Required)
+	assert.Contains(t, update.Properties, "user_id")
+	assert.Contains(t, update.Properties, "nick_name")
+
+	list := converted.Components.Schemas["UsersListRequest"]
+	assert.Contains(t, list.Properties, "status")
+	assert.Contains(t, list.Properties, "pagination")
+	assert.NotContains(t, list.Properties, "limit")
+
+	assert.Nil(t, converted.Components.Parameters)
+}
+
+func TestConvertListResponseIsPaginated(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	list := converted.Components.Schemas["UsersListResponse"]
+	assert.Equal(t, []string{"items", "pagination"}, list.Required)
+	assert.Contains(t, converted.Components.Schemas, "PaginationRequest")
+	assert.Contains(t, converted.Components.Schemas, "PaginationResponse")
+}
+
+func TestConvertStatusCodes(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	create := converted.Paths["/users.create"].Post.Responses
+	assert.Contains(t, create, "200")
+	assert.Contains(t, create, "400")
+	assert.NotContains(t, create, "201")
+	assert.NotContains(t, create, "422")
+
+	get := converted.Paths["/users.get"].Post.Responses
+	assert.Contains(t, get, "404")
+	assert.Contains(t, get, "500")
+	assert.NotContains(t, get, "default")
+
+	remove := converted.Paths["/users.delete"].Post.Responses
+	assert.Contains(t, remove, "200")
+	assert.Contains(t, remove, "500")
+	assert.NotContains(t, remove, "204")
+
+	errorSchema := converted.Components.Schemas["Error"]
+	assert.Equal(t, []string{"message"}, errorSchema.Required)
+}
+
+func TestConvertNormalizesSchemas(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(restSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	user := converted.Components.Schemas["User"]
+	assert.Contains(t, user.Properties, "first_name")
+	assert.Contains(t, user.Properties, "created_at")
+	assert.NotContains(t, user.Properties, "firstName")
+
+	created := user.Properties["created_at"].(map[string]any)
+	assert.Equal(t, "date-time", created["format"])
+
+	newUser := converted.Components.Schemas["NewUser"]
+	assert.Equal(t, []string{"first_name"}, newUser.Required)
+
+	age := newUser.Properties["age"].(map[string]any)
+	assert.Equal(t, "int64", age["format"])
+
+	nick := newUser.Properties["nick_name"].(map[string]any)
+	assert.NotContains(t, nick, "nullable")
+}
+
+func TestConvertAddsServerWhenMissing(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(`openapi: 3.0.3
+info:
+  title: Orders API
+  version: 1.0.0
+paths:
+  /orders/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          description: The order
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: string
+`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var converted convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &converted))
+
+	require.Len(t, converted.Servers, 1)
+	assert.Equal(t, "/v1", converted.Servers[0].URL)
+	assert.Contains(t, converted.Paths, "/orders.get")
+	assert.Contains(t, converted.Components.Schemas["OrdersGetResponse"].Properties, "id")
+}
+
+func TestConvertSkipsUnsupportedMethods(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "rest.yaml")
+	outputPath := filepath.Join(tempDir, "duh.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(`openapi: 3.0.3
+info:
+  title: Health API
+  version: 1.0.0
+paths:
+  /health:
+    head:
+      responses:
+        '200':
+          description: Healthy
+`), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"convert", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Skipped HEAD /health")
+	assert.Contains(t, stdout.String(), "✓ Converted 0 operation(s)")
+}
+
+func TestConvertRejectsSwagger(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "swagger.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte("swagger: '2.0'\ninfo:\n  title: Old\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", specPath, "-o", filepath.Join(tempDir, "out.yaml")})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "only OpenAPI 3.x specifications can be converted")
+	assert.Empty(t, stdout.String())
+}
+
+func TestConvertFileNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"convert", filepath.Join(tempDir, "missing.yaml")})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
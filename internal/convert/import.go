@@ -0,0 +1,392 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errorStatusCodes are the DUH-RPC error responses added to every imported operation
+var errorStatusCodes = []string{"400", "401", "403", "404", "409", "429", "500"}
+
+// scalarTypes maps proto scalar types to OpenAPI type and format
+var scalarTypes = map[string][2]string{
+	"double":   {"number", "double"},
+	"float":    {"number", "float"},
+	"int32":    {"integer", "int32"},
+	"sint32":   {"integer", "int32"},
+	"sfixed32": {"integer", "int32"},
+	"uint32":   {"integer", "int64"},
+	"fixed32":  {"integer", "int64"},
+	"int64":    {"integer", "int64"},
+	"sint64":   {"integer", "int64"},
+	"sfixed64": {"integer", "int64"},
+	"uint64":   {"integer", "int64"},
+	"fixed64":  {"integer", "int64"},
+	"bool":     {"boolean", ""},
+	"string":   {"string", ""},
+	"bytes":    {"string", "byte"},
+}
+
+// wellKnownTypes maps google.protobuf types onto their JSON representation
+var wellKnownTypes = map[string][2]string{
+	"google.protobuf.Timestamp":   {"string", "date-time"},
+	"google.protobuf.Duration":    {"string", ""},
+	"google.protobuf.FieldMask":   {"string", ""},
+	"google.protobuf.StringValue": {"string", ""},
+	"google.protobuf.BytesValue":  {"string", "byte"},
+	"google.protobuf.BoolValue":   {"boolean", ""},
+	"google.protobuf.Int32Value":  {"integer", "int32"},
+	"google.protobuf.UInt32Value": {"integer", "int64"},
+	"google.protobuf.Int64Value":  {"integer", "int64"},
+	"google.protobuf.UInt64Value": {"integer", "int64"},
+	"google.protobuf.FloatValue":  {"number", "float"},
+	"google.protobuf.DoubleValue": {"number", "double"},
+}
+
+const emptyType = "google.protobuf.Empty"
+
+// ProtoConfig controls the import of a .proto file
+type ProtoConfig struct {
+	Writer     io.Writer
+	ProtoPath  string
+	OutputPath string
+}
+
+// FromProto generates a DUH-RPC OpenAPI spec from the services and messages in a .proto file
+func FromProto(conf ProtoConfig) error {
+	if _, err := os.Stat(conf.ProtoPath); os.IsNotExist(err) {
+		return fmt.Errorf("file not found: %s", conf.ProtoPath)
+	}
+
+	data, err := os.ReadFile(conf.ProtoPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	file, err := parseProto(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse proto: %w", err)
+	}
+
+	if len(file.services) == 0 {
+		return fmt.Errorf("no services found in %s", conf.ProtoPath)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(conf.ProtoPath), filepath.Ext(conf.ProtoPath))
+	if file.pkg != "" {
+		title = file.pkg
+	}
+
+	im := &importer{file: file, schemas: mapping(), names: make(map[string]string)}
+	doc, err := im.document(title)
+	if err != nil {
+		return err
+	}
+
+	if err := writeYAML(conf.OutputPath, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{doc}}); err != nil {
+		return err
+	}
+
+	for _, s := range im.skipped {
+		_, _ = fmt.Fprintf(conf.Writer, "Skipped %s\n", s)
+	}
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Imported %d operation(s) to %s\n", im.imported, conf.OutputPath)
+	return nil
+}
+
+type importer struct {
+	file     *protoFile
+	schemas  *yaml.Node
+	names    map[string]string
+	imported int
+	skipped  []string
+}
+
+func (im *importer) document(title string) (*yaml.Node, error) {
+	// Nested definitions are named after their parents, e.g. User.Status becomes UserStatus
+	for _, m := range im.file.messages {
+		im.names[m.full] = strings.ReplaceAll(m.full, ".", "")
+	}
+	for _, e := range im.file.enums {
+		im.names[e.full] = strings.ReplaceAll(e.full, ".", "")
+	}
+
+	for _, m := range im.file.messages {
+		schema, err := im.message(m)
+		if err != nil {
+			return nil, err
+		}
+		set(im.schemas, im.names[m.full], schema)
+	}
+	for _, e := range im.file.enums {
+		set(im.schemas, im.names[e.full], enumSchema(e))
+	}
+
+	paths := mapping()
+	for _, svc := range im.file.services {
+		for _, rpc := range svc.rpcs {
+			if err := im.operation(paths, svc, rpc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	set(im.schemas, errorSchema, newErrorSchema())
+	for i := 1; i < len(im.schemas.Content); i += 2 {
+		normalize(im.schemas.Content[i])
+	}
+
+	version := defaultVersion
+	for _, part := range strings.Split(im.file.pkg, ".") {
+		if versionSegmentRegex.MatchString(part) {
+			version = part
+		}
+	}
+
+	return mapping(
+		scalar("openapi"), scalar("3.0.3"),
+		scalar("info"), mapping(
+			scalar("title"), scalar(title),
+			scalar("version"), scalar("1.0.0"),
+		),
+		scalar("servers"), sequence(mapping(scalar("url"), scalar("/"+version))),
+		scalar("paths"), paths,
+		scalar("components"), mapping(scalar("schemas"), im.schemas),
+	), nil
+}
+
+func (im *importer) operation(paths *yaml.Node, svc *protoService, rpc *protoRPC) error {
+	if rpc.clientStream {
+		im.skipped = append(im.skipped, fmt.Sprintf("%s.%s: client streaming is not supported by DUH-RPC", svc.name, rpc.name))
+		return nil
+	}
+
+	resource := kebab(strings.TrimSuffix(svc.name, "Service"))
+	if resource == "" {
+		resource = kebab(svc.name)
+	}
+
+	method := kebab(rpc.name)
+	path := "/" + resource + "." + method
+	if !rpcPathRegex.MatchString(path) {
+		im.skipped = append(im.skipped, fmt.Sprintf("%s.%s: '%s' is not a valid DUH-RPC path", svc.name, rpc.name, path))
+		return nil
+	}
+
+	if get(paths, path) != nil {
+		im.skipped = append(im.skipped, fmt.Sprintf("%s.%s: %s already exists", svc.name, rpc.name, path))
+		return nil
+	}
+
+	request, err := im.rpcSchema(rpc.request, pascal(method)+"Request", pascal(resource)+pascal(method)+"Request")
+	if err != nil {
+		return fmt.Errorf("rpc '%s.%s': %w", svc.name, rpc.name, err)
+	}
+
+	response, err := im.rpcSchema(rpc.response, pascal(method)+"Response", pascal(resource)+pascal(method)+"Response")
+	if err != nil {
+		return fmt.Errorf("rpc '%s.%s': %w", svc.name, rpc.name, err)
+	}
+
+	description := rpc.comment
+	if description == "" {
+		description = fmt.Sprintf("Calls %s.%s", svc.name, rpc.name)
+	}
+
+	binary := mapping(scalar("schema"), mapping(
+		scalar("type"), scalar("string"),
+		scalar("format"), scalar("binary"),
+	))
+
+	success := mapping(scalar("application/json"), mapping(scalar("schema"), ref(response)))
+	if rpc.serverStream {
+		success = mapping(scalar("application/duh-stream+json"), mapping(scalar("schema"), ref(response)))
+	} else {
+		set(success, "application/protobuf", binary)
+	}
+
+	responses := mapping(scalar("200"), mapping(
+		scalar("description"), scalar("Success"),
+		scalar("content"), success,
+	))
+	for _, code := range errorStatusCodes {
+		set(responses, code, mapping(
+			scalar("description"), scalar(http.StatusText(statusInt(code))),
+			scalar("content"), jsonContent(errorSchema),
+		))
+	}
+
+	set(paths, path, mapping(scalar("post"), mapping(
+		scalar("description"), scalar(description),
+		scalar("operationId"), scalar(strings.ToLower(rpc.name[:1])+rpc.name[1:]),
+		scalar("requestBody"), mapping(
+			scalar("required"), boolean(true),
+			scalar("content"), mapping(
+				scalar("application/json"), mapping(scalar("schema"), ref(request)),
+				scalar("application/protobuf"), clone(binary),
+			),
+		),
+		scalar("responses"), responses,
+	)))
+	im.imported++
+	return nil
+}
+
+// rpcSchema returns the component name used for an rpc request or response.
+// DUH-RPC lint requires {Method}Request style names that are unique per
+// operation, so a copy of the message is made when its own name does not fit.
+func (im *importer) rpcSchema(typ, name, fallback string) (string, error) {
+	var schema *yaml.Node
+	if strings.TrimPrefix(typ, ".") == emptyType {
+		schema = object(mapping(), sequence())
+	} else {
+		full, ok := im.lookup(typ, "")
+		if !ok {
+			return "", fmt.Errorf("unknown message type '%s'", typ)
+		}
+		if im.names[full] == name {
+			return name, nil
+		}
+		schema = clone(get(im.schemas, im.names[full]))
+	}
+
+	if get(im.schemas, name) != nil {
+		name = fallback
+	}
+	set(im.schemas, name, schema)
+	return name, nil
+}
+
+func (im *importer) message(m *protoMessage) (*yaml.Node, error) {
+	properties, required := mapping(), sequence()
+	for _, f := range m.fields {
+		schema, err := im.fieldSchema(f)
+		if err != nil {
+			return nil, fmt.Errorf("message '%s': field '%s': %w", m.full, f.name, err)
+		}
+		set(properties, f.name, schema)
+		if f.required {
+			appendUnique(required, f.name)
+		}
+	}
+
+	schema := object(properties, required)
+	if m.comment != "" {
+		schema.Content = append([]*yaml.Node{scalar("description"), scalar(m.comment)}, schema.Content...)
+	}
+	return schema, nil
+}
+
+func (im *importer) fieldSchema(f *protoField) (*yaml.Node, error) {
+	schema, err := im.typeSchema(f.typ, f.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case f.key != "":
+		schema = mapping(
+			scalar("type"), scalar("object"),
+			scalar("additionalProperties"), schema,
+		)
+	case f.repeated:
+		schema = mapping(
+			scalar("type"), scalar("array"),
+			scalar("items"), schema,
+		)
+	}
+
+	// OpenAPI 3.0 ignores siblings of $ref, so only inline schemas carry the comment
+	if f.comment != "" && get(schema, "$ref") == nil {
+		schema.Content = append([]*yaml.Node{scalar("description"), scalar(f.comment)}, schema.Content...)
+	}
+	return schema, nil
+}
+
+func (im *importer) typeSchema(typ, scope string) (*yaml.Node, error) {
+	if t, ok := scalarTypes[typ]; ok {
+		return typed(t), nil
+	}
+
+	name := strings.TrimPrefix(typ, ".")
+	if t, ok := wellKnownTypes[name]; ok {
+		return typed(t), nil
+	}
+
+	switch name {
+	case "google.protobuf.Struct", "google.protobuf.Any":
+		return mapping(
+			scalar("type"), scalar("object"),
+			scalar("additionalProperties"), mapping(scalar("type"), scalar("string")),
+		), nil
+	case "google.protobuf.Value", "google.protobuf.ListValue", emptyType:
+		return nil, fmt.Errorf("type '%s' has no DUH-RPC equivalent", name)
+	}
+
+	full, ok := im.lookup(typ, scope)
+	if !ok {
+		return nil, fmt.Errorf("unknown type '%s'", typ)
+	}
+	return ref(im.names[full]), nil
+}
+
+// lookup resolves a type reference using proto scoping rules, searching
+// from the innermost enclosing message outwards
+func (im *importer) lookup(typ, scope string) (string, bool) {
+	name := strings.TrimPrefix(typ, ".")
+	if pkg := im.file.pkg; pkg != "" {
+		name = strings.TrimPrefix(name, pkg+".")
+	}
+
+	if strings.HasPrefix(typ, ".") {
+		_, ok := im.names[name]
+		return name, ok
+	}
+
+	for {
+		if _, ok := im.names[join(scope, name)]; ok {
+			return join(scope, name), true
+		}
+		if scope == "" {
+			return "", false
+		}
+
+		idx := strings.LastIndex(scope, ".")
+		if idx == -1 {
+			scope = ""
+		} else {
+			scope = scope[:idx]
+		}
+	}
+}
+
+// enumSchema maps a proto enum to a string enum, dropping the zero UNSPECIFIED value
+func enumSchema(e *protoEnum) *yaml.Node {
+	values := sequence()
+	for _, v := range e.values {
+		if v.number == 0 && strings.HasSuffix(v.name, "_UNSPECIFIED") {
+			continue
+		}
+		values.Content = append(values.Content, scalar(v.name))
+	}
+
+	schema := mapping(scalar("type"), scalar("string"), scalar("enum"), values)
+	if e.comment != "" {
+		schema.Content = append([]*yaml.Node{scalar("description"), scalar(e.comment)}, schema.Content...)
+	}
+	return schema
+}
+
+func typed(t [2]string) *yaml.Node {
+	schema := mapping(scalar("type"), scalar(t[0]))
+	if t[1] != "" {
+		set(schema, "format", scalar(t[1]))
+	}
+	return schema
+}
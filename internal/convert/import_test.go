@@ -0,0 +1,256 @@
+package convert_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const userProto = `syntax = "proto3";
+
+package acme.users.v2;
+
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/empty.proto";
+
+option go_package = "github.com/acme/users/v2;users";
+
+// Manages user accounts
+service UserService {
+  // Creates a new user
+  rpc CreateUser(CreateUserRequest) returns (User);
+  // Fetches a user by id
+  rpc GetUser(GetUserRequest) returns (User) {
+    option (google.api.http) = { get: "/v2/users/{id}" };
+  }
+  rpc WatchUsers(google.protobuf.Empty) returns (stream User);
+  rpc Upload(stream User) returns (google.protobuf.Empty);
+}
+
+/* A user account */
+message User {
+  // Unique identifier
+  string id = 1;
+  string displayName = 2 [json_name = "displayName"];
+  google.protobuf.Timestamp created_at = 3;
+  Status status = 4;
+  repeated Address addresses = 5;
+  map<string, string> labels = 6;
+  int64 score = 7; // trailing comment
+  oneof contact {
+    string email = 8;
+    string phone = 9;
+  }
+
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    ACTIVE = 1;
+    DISABLED = 2;
+  }
+
+  message Address {
+    string city = 1;
+  }
+}
+
+message CreateUserRequest {
+  string display_name = 1;
+  User.Status status = 2;
+}
+
+message GetUserRequest {
+  string id = 1;
+}
+`
+
+func TestImportProtoPassesLint(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"import", "proto", protoPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Imported 3 operation(s)")
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"lint", outputPath})
+
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "0 errors")
+}
+
+func TestImportProtoPaths(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var imported convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &imported))
+
+	assert.Len(t, imported.Paths, 3)
+	assert.Contains(t, imported.Paths, "/user.create-user")
+	assert.Contains(t, imported.Paths, "/user.get-user")
+	assert.Contains(t, imported.Paths, "/user.watch-users")
+	assert.Equal(t, "Creates a new user", imported.Paths["/user.create-user"].Post.Description)
+}
+
+func TestImportProtoVersionFromPackage(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var imported convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &imported))
+
+	require.Len(t, imported.Servers, 1)
+	assert.Equal(t, "/v2", imported.Servers[0].URL)
+}
+
+func TestImportProtoErrorResponses(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var imported convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &imported))
+
+	responses := imported.Paths["/user.get-user"].Post.Responses
+	for _, code := range []string{"200", "400", "401", "403", "404", "409", "429", "500"} {
+		assert.Contains(t, responses, code)
+	}
+	assert.Contains(t, imported.Components.Schemas, "Error")
+}
+
+func TestImportProtoSchemas(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var imported convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &imported))
+
+	schemas := imported.Components.Schemas
+	assert.Contains(t, schemas, "CreateUserRequest")
+	assert.Contains(t, schemas, "CreateUserResponse")
+	assert.Contains(t, schemas, "UserAddress")
+	assert.Contains(t, schemas["User"].Properties, "display_name")
+	assert.Contains(t, schemas["User"].Properties, "email")
+	assert.Equal(t, []string{"ACTIVE", "DISABLED"}, schemas["UserStatus"].Enum)
+}
+
+func TestImportProtoSkipsClientStreaming(t *testing.T) {
+	tempDir := t.TempDir()
+	protoPath := filepath.Join(tempDir, "api.proto")
+	outputPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(protoPath, []byte(userProto), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath, "-o", outputPath})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var imported convertedSpec
+	require.NoError(t, yaml.Unmarshal(content, &imported))
+
+	assert.Contains(t, stdout.String(), "Skipped UserService.Upload")
+	assert.NotContains(t, imported.Paths, "/user.upload")
+}
+
+func TestImportProtoErrors(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		proto string
+		err   string
+	}{
+		{
+			name:  "NoServices",
+			proto: "syntax = \"proto3\";\nmessage Empty {}\n",
+			err:   "no services found",
+		},
+		{
+			name:  "UnknownType",
+			proto: "syntax = \"proto3\";\nservice S { rpc Get(Missing) returns (Missing); }\n",
+			err:   "unknown message type 'Missing'",
+		},
+		{
+			name:  "Unterminated",
+			proto: "syntax = \"proto3\";\nmessage User {\n  string id = 1;\n",
+			err:   "failed to parse proto",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			protoPath := filepath.Join(t.TempDir(), "api.proto")
+			require.NoError(t, os.WriteFile(protoPath, []byte(test.proto), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", protoPath})
+
+			assert.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.err)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestImportProtoFileNotFound(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"import", "proto", "/nonexistent/api.proto"})
+
+	assert.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
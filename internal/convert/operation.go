@@ -0,0 +1,416 @@
+package convert
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	versionSegmentRegex = regexp.MustCompile(`^v\d+$`)
+	rpcPathRegex        = regexp.MustCompile(`^/[a-z][a-z0-9-]{0,49}\.[a-z][a-z0-9-]{0,49}$`)
+)
+
+// methods lists the HTTP methods that have a DUH-RPC equivalent, in conversion order
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+// operationFields are copied verbatim from the REST operation
+var operationFields = []string{"tags", "summary", "description", "operationId", "deprecated", "security", "externalDocs"}
+
+// paginationParams are REST paging inputs replaced by the DUH-RPC pagination object
+var paginationParams = map[string]bool{
+	"limit":     true,
+	"offset":    true,
+	"page":      true,
+	"per_page":  true,
+	"page_size": true,
+	"cursor":    true,
+	"first":     true,
+	"after":     true,
+}
+
+// errorCodes maps REST error status codes onto the DUH-RPC allowed set
+var errorCodes = map[string]string{
+	"400": "400",
+	"401": "401",
+	"403": "403",
+	"404": "404",
+	"409": "409",
+	"429": "429",
+	"500": "500",
+	"410": "404",
+	"412": "409",
+	"423": "409",
+}
+
+func (c *converter) convertPath(paths *yaml.Node, path string, item *yaml.Node) {
+	if item == nil || item.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		switch key := item.Content[i].Value; key {
+		case "head", "options", "trace":
+			c.skipped = append(c.skipped, fmt.Sprintf("%s %s: method has no DUH-RPC equivalent", strings.ToUpper(key), path))
+		}
+	}
+
+	for _, method := range methods {
+		op := get(item, method)
+		if op == nil {
+			continue
+		}
+
+		resource, action, err := c.rpcName(method, path, get(item, "patch") != nil)
+		if err != nil {
+			c.skipped = append(c.skipped, fmt.Sprintf("%s %s: %v", strings.ToUpper(method), path, err))
+			continue
+		}
+
+		rpc := "/" + resource + "." + action
+		if get(paths, rpc) != nil {
+			c.skipped = append(c.skipped, fmt.Sprintf("%s %s: %s already exists", strings.ToUpper(method), path, rpc))
+			continue
+		}
+
+		set(paths, rpc, mapping(scalar("post"), c.convertOperation(method, path, resource, action, op, get(item, "parameters"))))
+		c.converted++
+	}
+}
+
+// rpcName maps a RESTful method and path onto a DUH-RPC resource and method.
+// GET /users/{id} becomes users.get, POST /users/{id}/activate becomes users.activate
+func (c *converter) rpcName(method, path string, hasPatch bool) (string, string, error) {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	if len(segments) > 0 && versionSegmentRegex.MatchString(segments[0]) {
+		if c.version == "" {
+			c.version = segments[0]
+		}
+		segments = segments[1:]
+	}
+
+	last := len(segments) - 1
+	if last >= 1 && method == "post" && !isParam(segments[last]) && isParam(segments[last-1]) &&
+		!strings.HasSuffix(segments[last], "s") {
+		for i := last - 2; i >= 0; i-- {
+			if !isParam(segments[i]) {
+				return c.validate(kebab(segments[i]), kebab(segments[last]))
+			}
+		}
+	}
+
+	resource := ""
+	for i := last; i >= 0; i-- {
+		if !isParam(segments[i]) {
+			resource = kebab(segments[i])
+			break
+		}
+	}
+	if resource == "" {
+		return "", "", fmt.Errorf("no resource name found in path")
+	}
+
+	item := last >= 0 && isParam(segments[last])
+	switch method {
+	case "get":
+		if item {
+			return c.validate(resource, "get")
+		}
+		return c.validate(resource, "list")
+	case "post":
+		return c.validate(resource, "create")
+	case "put":
+		if hasPatch {
+			return c.validate(resource, "replace")
+		}
+		return c.validate(resource, "update")
+	case "patch":
+		return c.validate(resource, "update")
+	}
+	return c.validate(resource, "delete")
+}
+
+func (c *converter) validate(resource, action string) (string, string, error) {
+	if !rpcPathRegex.MatchString("/" + resource + "." + action) {
+		return "", "", fmt.Errorf("'/%s.%s' is not a valid DUH-RPC path", resource, action)
+	}
+	return resource, action, nil
+}
+
+func (c *converter) convertOperation(method, path, resource, action string, op, shared *yaml.Node) *yaml.Node {
+	name := pascal(resource) + pascal(action)
+	paginated := action == "list" || action == "search" || action == "query"
+	if paginated {
+		c.paginated = true
+	}
+
+	result := mapping()
+	for _, field := range operationFields {
+		if v := get(op, field); v != nil {
+			set(result, field, v)
+		}
+	}
+	for i := 0; i+1 < len(op.Content); i += 2 {
+		if strings.HasPrefix(op.Content[i].Value, "x-") {
+			set(result, op.Content[i].Value, op.Content[i+1])
+		}
+	}
+
+	if get(result, "description") == nil {
+		description := fmt.Sprintf("Converted from %s %s", strings.ToUpper(method), path)
+		if summary := get(op, "summary"); summary != nil {
+			description = summary.Value
+		}
+		set(result, "description", scalar(description))
+	}
+
+	request, headers := c.convertRequest(op, shared, paginated)
+	if len(headers.Content) > 0 {
+		set(result, "parameters", headers)
+	}
+	set(c.schemas, name+"Request", request)
+	set(result, "requestBody", mapping(
+		scalar("required"), boolean(true),
+		scalar("content"), jsonContent(name+"Request"),
+	))
+
+	set(result, "responses", c.convertResponses(op, name+"Response", paginated))
+	return result
+}
+
+// convertRequest folds path, query and cookie parameters into the request
+// schema alongside the REST request body. Header parameters are returned
+// separately since DUH-RPC still permits them.
+func (c *converter) convertRequest(op, shared *yaml.Node, paginated bool) (*yaml.Node, *yaml.Node) {
+	properties, required, headers := mapping(), sequence(), sequence()
+
+	var params []*yaml.Node
+	if shared != nil {
+		params = append(params, shared.Content...)
+	}
+	if own := get(op, "parameters"); own != nil {
+		params = append(params, own.Content...)
+	}
+
+	for _, p := range params {
+		p = c.resolve(p)
+		in, name := get(p, "in"), get(p, "name")
+		if in == nil || name == nil {
+			continue
+		}
+
+		if in.Value == "header" {
+			headers.Content = append(headers.Content, clone(p))
+			continue
+		}
+
+		field := snake(name.Value)
+		if paginated && paginationParams[field] {
+			continue
+		}
+
+		schema := clone(get(p, "schema"))
+		if schema == nil {
+			schema = mapping(scalar("type"), scalar("string"))
+		}
+		if description := get(p, "description"); description != nil && get(schema, "description") == nil {
+			set(schema, "description", clone(description))
+		}
+		set(properties, field, schema)
+
+		if r := get(p, "required"); in.Value == "path" || (r != nil && r.Value == "true") {
+			appendUnique(required, field)
+		}
+	}
+
+	if body := c.resolve(get(op, "requestBody")); body != nil {
+		if schema := mediaSchema(get(body, "content")); schema != nil {
+			c.fold(properties, required, schema, "body")
+		}
+	}
+
+	if paginated {
+		set(properties, "pagination", ref(paginationRequest))
+	}
+
+	return object(properties, required), headers
+}
+
+func (c *converter) convertResponses(op *yaml.Node, name string, paginated bool) *yaml.Node {
+	responses := mapping()
+	success := mapping(scalar("description"), scalar("Success"))
+
+	properties, required := mapping(), sequence()
+	old := get(op, "responses")
+
+	for i := 0; old != nil && i+1 < len(old.Content); i += 2 {
+		code, resp := old.Content[i].Value, c.resolve(old.Content[i+1])
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		if d := get(resp, "description"); d != nil && d.Value != "" {
+			set(success, "description", clone(d))
+		}
+		if schema := mediaSchema(get(resp, "content")); schema != nil {
+			c.fold(properties, required, schema, "value")
+		}
+		break
+	}
+
+	if paginated {
+		if get(properties, "items") == nil {
+			c.renameArray(properties, required)
+		}
+		set(properties, "pagination", ref(paginationResponse))
+		appendUnique(required, "pagination")
+	}
+
+	set(c.schemas, name, object(properties, required))
+	set(success, "content", jsonContent(name))
+	set(responses, "200", success)
+
+	for i := 0; old != nil && i+1 < len(old.Content); i += 2 {
+		code := errorCode(old.Content[i].Value)
+		if code == "" || get(responses, code) != nil {
+			continue
+		}
+
+		description := http.StatusText(statusInt(code))
+		if d := get(c.resolve(old.Content[i+1]), "description"); d != nil && d.Value != "" {
+			description = d.Value
+		}
+
+		set(responses, code, mapping(
+			scalar("description"), scalar(description),
+			scalar("content"), jsonContent(errorSchema),
+		))
+	}
+
+	return responses
+}
+
+// fold merges the properties of an object schema into properties and
+// required. Non-object schemas are added as a single field named after the
+// field argument, or 'items' when the schema is an array.
+func (c *converter) fold(properties, required, schema *yaml.Node, field string) {
+	resolved := c.resolve(schema)
+
+	if t := get(resolved, "type"); t != nil && t.Value == "array" {
+		set(properties, "items", clone(schema))
+		appendUnique(required, "items")
+		return
+	}
+
+	if get(resolved, "properties") == nil && get(resolved, "allOf") == nil {
+		set(properties, field, clone(schema))
+		return
+	}
+
+	members := []*yaml.Node{resolved}
+	if all := get(resolved, "allOf"); all != nil {
+		for _, m := range all.Content {
+			members = append(members, c.resolve(m))
+		}
+	}
+
+	for _, m := range members {
+		props := get(m, "properties")
+		for i := 0; props != nil && i+1 < len(props.Content); i += 2 {
+			if get(properties, props.Content[i].Value) == nil {
+				set(properties, props.Content[i].Value, clone(props.Content[i+1]))
+			}
+		}
+		if r := get(m, "required"); r != nil {
+			for _, name := range r.Content {
+				appendUnique(required, name.Value)
+			}
+		}
+	}
+}
+
+// renameArray renames the first array property to 'items' so list responses
+// such as {data: [...]} match the DUH-RPC paginated response structure
+func (c *converter) renameArray(properties, required *yaml.Node) {
+	for i := 0; i+1 < len(properties.Content); i += 2 {
+		t := get(c.resolve(properties.Content[i+1]), "type")
+		if t == nil || t.Value != "array" {
+			continue
+		}
+
+		old := properties.Content[i].Value
+		properties.Content[i].Value = "items"
+		for _, r := range required.Content {
+			if r.Value == old {
+				r.Value = "items"
+			}
+		}
+		return
+	}
+}
+
+func errorCode(code string) string {
+	if mapped, ok := errorCodes[code]; ok {
+		return mapped
+	}
+
+	switch {
+	case code == "default", strings.HasPrefix(code, "5"):
+		return "500"
+	case strings.HasPrefix(code, "4"):
+		return "400"
+	}
+	return ""
+}
+
+func statusInt(code string) int {
+	var n int
+	_, _ = fmt.Sscanf(code, "%d", &n)
+	return n
+}
+
+// mediaSchema returns the JSON schema of a content map, falling back to the first media type
+func mediaSchema(content *yaml.Node) *yaml.Node {
+	if media := get(content, "application/json"); media != nil {
+		return get(media, "schema")
+	}
+	if content != nil && content.Kind == yaml.MappingNode && len(content.Content) >= 2 {
+		return get(content.Content[1], "schema")
+	}
+	return nil
+}
+
+func jsonContent(name string) *yaml.Node {
+	return mapping(scalar("application/json"), mapping(scalar("schema"), ref(name)))
+}
+
+func object(properties, required *yaml.Node) *yaml.Node {
+	schema := mapping(scalar("type"), scalar("object"))
+	if len(required.Content) > 0 {
+		set(schema, "required", required)
+	}
+	set(schema, "properties", properties)
+	return schema
+}
+
+func appendUnique(list *yaml.Node, value string) {
+	for _, v := range list.Content {
+		if v.Value == value {
+			return
+		}
+	}
+	list.Content = append(list.Content, scalar(value))
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
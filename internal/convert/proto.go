@@ -0,0 +1,472 @@
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type protoFile struct {
+	pkg      string
+	messages []*protoMessage
+	enums    []*protoEnum
+	services []*protoService
+}
+
+type protoMessage struct {
+	name    string
+	full    string
+	comment string
+	fields  []*protoField
+}
+
+type protoField struct {
+	name     string
+	typ      string
+	key      string
+	comment  string
+	scope    string
+	repeated bool
+	required bool
+}
+
+type protoEnum struct {
+	name    string
+	full    string
+	comment string
+	values  []protoEnumValue
+}
+
+type protoEnumValue struct {
+	name   string
+	number int
+}
+
+type protoService struct {
+	name    string
+	comment string
+	rpcs    []*protoRPC
+}
+
+type protoRPC struct {
+	name         string
+	comment      string
+	request      string
+	response     string
+	clientStream bool
+	serverStream bool
+}
+
+type token struct {
+	text    string
+	comment string
+	line    int
+}
+
+// lex splits a .proto source into tokens. Comments on the lines directly
+// above a token are attached to it so they can become descriptions.
+func lex(src string) ([]token, error) {
+	var (
+		tokens  []token
+		comment []string
+		line    = 1
+		last    = 0
+	)
+
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '\n':
+			line++
+			i++
+		case unicode.IsSpace(r):
+			i++
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			start := i + 2
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			if line != last {
+				comment = append(comment, strings.TrimSpace(string(runes[start:i])))
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			start, trailing := i+2, line == last
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			if !trailing {
+				for _, l := range strings.Split(string(runes[start:i]), "\n") {
+					if l = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l), "*")); l != "" {
+						comment = append(comment, l)
+					}
+				}
+			}
+			i += 2
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			i++
+			tokens = append(tokens, token{text: string(runes[start:i]), line: line})
+			comment, last = nil, line
+		case isIdentRune(r):
+			start := i
+			for i < len(runes) && isIdentRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{text: string(runes[start:i]), comment: strings.Join(comment, " "), line: line})
+			comment, last = nil, line
+		default:
+			tokens = append(tokens, token{text: string(r), line: line})
+			comment, last = nil, line
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type protoParser struct {
+	tokens []token
+	pos    int
+	file   *protoFile
+}
+
+// parseProto parses the subset of proto2/proto3 needed to describe services
+// and their messages. Options, imports and extensions are skipped.
+func parseProto(src string) (*protoFile, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protoParser{tokens: tokens, file: &protoFile{}}
+	for !p.done() {
+		t := p.next()
+		switch t.text {
+		case "syntax", "edition", "import", "option":
+			err = p.skipStatement()
+		case "package":
+			p.file.pkg = p.next().text
+			err = p.expect(";")
+		case "message":
+			err = p.parseMessage("", t.comment)
+		case "enum":
+			err = p.parseEnum("", t.comment)
+		case "service":
+			err = p.parseService(t.comment)
+		case "extend":
+			err = p.skipDefinition()
+		case ";":
+		default:
+			err = fmt.Errorf("line %d: unexpected '%s'", t.line, t.text)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p.file, nil
+}
+
+func (p *protoParser) parseMessage(scope, comment string) error {
+	name := p.next().text
+	msg := &protoMessage{name: name, full: join(scope, name), comment: comment}
+	p.file.messages = append(p.file.messages, msg)
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	return p.parseFields(msg, msg.full)
+}
+
+// parseFields reads message fields until the closing brace. Fields of a
+// oneof are flattened into the enclosing message.
+func (p *protoParser) parseFields(msg *protoMessage, scope string) error {
+	for {
+		if p.done() {
+			return fmt.Errorf("message '%s' is missing a closing brace", msg.name)
+		}
+
+		t := p.next()
+		var err error
+		switch t.text {
+		case "}":
+			return nil
+		case ";":
+		case "message":
+			err = p.parseMessage(scope, t.comment)
+		case "enum":
+			err = p.parseEnum(scope, t.comment)
+		case "option", "reserved", "extensions":
+			err = p.skipStatement()
+		case "extend":
+			err = p.skipDefinition()
+		case "oneof":
+			p.next()
+			if err = p.expect("{"); err == nil {
+				err = p.parseFields(msg, scope)
+			}
+		case "map":
+			err = p.parseMap(msg, scope, t.comment)
+		case "group":
+			err = fmt.Errorf("line %d: groups are not supported", t.line)
+		default:
+			field := &protoField{comment: t.comment, scope: scope, typ: t.text}
+			switch t.text {
+			case "repeated":
+				field.repeated, field.typ = true, p.next().text
+			case "required":
+				field.required, field.typ = true, p.next().text
+			case "optional":
+				field.typ = p.next().text
+			}
+			field.name = p.next().text
+			msg.fields = append(msg.fields, field)
+			err = p.skipStatement()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *protoParser) parseMap(msg *protoMessage, scope, comment string) error {
+	if err := p.expect("<"); err != nil {
+		return err
+	}
+	key := p.next().text
+	if err := p.expect(","); err != nil {
+		return err
+	}
+	value := p.next().text
+	if err := p.expect(">"); err != nil {
+		return err
+	}
+
+	msg.fields = append(msg.fields, &protoField{
+		name:    p.next().text,
+		comment: comment,
+		scope:   scope,
+		typ:     value,
+		key:     key,
+	})
+	return p.skipStatement()
+}
+
+func (p *protoParser) parseEnum(scope, comment string) error {
+	name := p.next().text
+	enum := &protoEnum{name: name, full: join(scope, name), comment: comment}
+	p.file.enums = append(p.file.enums, enum)
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		if p.done() {
+			return fmt.Errorf("enum '%s' is missing a closing brace", name)
+		}
+
+		t := p.next()
+		switch t.text {
+		case "}":
+			return nil
+		case ";":
+			continue
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := p.expect("="); err != nil {
+			return err
+		}
+
+		sign := 1
+		if p.peek() == "-" {
+			sign = -1
+			p.next()
+		}
+
+		n, err := strconv.ParseInt(p.next().text, 0, 32)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid value for enum '%s'", t.line, t.text)
+		}
+		enum.values = append(enum.values, protoEnumValue{name: t.text, number: sign * int(n)})
+
+		if err := p.skipStatement(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *protoParser) parseService(comment string) error {
+	svc := &protoService{name: p.next().text, comment: comment}
+	p.file.services = append(p.file.services, svc)
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		if p.done() {
+			return fmt.Errorf("service '%s' is missing a closing brace", svc.name)
+		}
+
+		t := p.next()
+		switch t.text {
+		case "}":
+			return nil
+		case ";":
+		case "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "rpc":
+			rpc, err := p.parseRPC(t.comment)
+			if err != nil {
+				return err
+			}
+			svc.rpcs = append(svc.rpcs, rpc)
+		default:
+			return fmt.Errorf("line %d: unexpected '%s' in service '%s'", t.line, t.text, svc.name)
+		}
+	}
+}
+
+func (p *protoParser) parseRPC(comment string) (*protoRPC, error) {
+	rpc := &protoRPC{name: p.next().text, comment: comment}
+
+	var err error
+	if rpc.request, rpc.clientStream, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if rpc.response, rpc.serverStream, err = p.parseRPCType(); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == "{" {
+		p.next()
+		return rpc, p.skipBlock()
+	}
+	return rpc, p.expect(";")
+}
+
+func (p *protoParser) parseRPCType() (string, bool, error) {
+	if err := p.expect("("); err != nil {
+		return "", false, err
+	}
+
+	stream := false
+	if p.peek() == "stream" {
+		stream = true
+		p.next()
+	}
+
+	typ := p.next().text
+	return typ, stream, p.expect(")")
+}
+
+// skipStatement advances past the next ';', skipping any nested braces
+// such as aggregate option values
+func (p *protoParser) skipStatement() error {
+	for !p.done() {
+		switch p.next().text {
+		case ";":
+			return nil
+		case "{":
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+// skipDefinition advances past a braced definition such as 'extend Foo { ... }'
+func (p *protoParser) skipDefinition() error {
+	for !p.done() {
+		if p.next().text == "{" {
+			return p.skipBlock()
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+// skipBlock advances past the brace closing an already consumed '{'
+func (p *protoParser) skipBlock() error {
+	depth := 1
+	for !p.done() {
+		switch p.next().text {
+		case "{":
+			depth++
+		case "}":
+			if depth--; depth == 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+func (p *protoParser) expect(text string) error {
+	if p.done() {
+		return fmt.Errorf("expected '%s' but reached end of file", text)
+	}
+	if t := p.next(); t.text != text {
+		return fmt.Errorf("line %d: expected '%s' but found '%s'", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *protoParser) next() token {
+	if p.done() {
+		return token{}
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *protoParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *protoParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
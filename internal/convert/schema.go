@@ -0,0 +1,189 @@
+package convert
+
+import (
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	paginationRequest  = "PaginationRequest"
+	paginationResponse = "PaginationResponse"
+)
+
+// normalize rewrites a schema in place so it satisfies the DUH-RPC schema
+// rules: snake_case property names, explicit integer and number formats, no
+// nullable or read/write-only markers and typed additionalProperties.
+func normalize(schema *yaml.Node) {
+	if schema == nil || schema.Kind != yaml.MappingNode {
+		return
+	}
+
+	remove(schema, "nullable")
+	remove(schema, "readOnly")
+	remove(schema, "writeOnly")
+	remove(schema, "xml")
+
+	if t := get(schema, "type"); t != nil && t.Kind == yaml.SequenceNode {
+		for _, v := range t.Content {
+			if v.Value != "null" {
+				set(schema, "type", scalar(v.Value))
+				break
+			}
+		}
+	}
+
+	format := get(schema, "format")
+	if t := get(schema, "type"); t != nil {
+		switch t.Value {
+		case "integer":
+			if format == nil || (format.Value != "int32" && format.Value != "int64") {
+				set(schema, "format", scalar("int64"))
+			}
+		case "number":
+			if format == nil || (format.Value != "float" && format.Value != "double") {
+				set(schema, "format", scalar("double"))
+			}
+		}
+	}
+
+	if props := get(schema, "properties"); props != nil {
+		for i := 0; i+1 < len(props.Content); i += 2 {
+			name := snake(props.Content[i].Value)
+			props.Content[i].Value = name
+			normalize(props.Content[i+1])
+
+			t, f := get(props.Content[i+1], "type"), get(props.Content[i+1], "format")
+			if (strings.HasSuffix(name, "_at") || strings.HasSuffix(name, "_timestamp")) &&
+				t != nil && t.Value == "string" && f == nil {
+				set(props.Content[i+1], "format", scalar("date-time"))
+			}
+		}
+	}
+
+	if required := get(schema, "required"); required != nil {
+		for _, r := range required.Content {
+			r.Value = snake(r.Value)
+		}
+	}
+
+	switch additional := get(schema, "additionalProperties"); {
+	case additional == nil:
+	case additional.Kind == yaml.ScalarNode && additional.Value == "true",
+		additional.Kind == yaml.MappingNode && get(additional, "type") == nil && get(additional, "$ref") == nil:
+		set(schema, "additionalProperties", mapping(scalar("type"), scalar("string")))
+	default:
+		normalize(additional)
+	}
+
+	normalize(get(schema, "items"))
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		if list := get(schema, key); list != nil {
+			for _, s := range list.Content {
+				normalize(s)
+			}
+		}
+	}
+}
+
+func newErrorSchema() *yaml.Node {
+	return mapping(
+		scalar("description"), scalar("Standard DUH-RPC error response"),
+		scalar("type"), scalar("object"),
+		scalar("required"), sequence(scalar("message")),
+		scalar("properties"), mapping(
+			scalar("message"), mapping(
+				scalar("description"), scalar("Human-readable error message"),
+				scalar("type"), scalar("string"),
+			),
+			scalar("code"), mapping(
+				scalar("description"), scalar("Machine-readable error code"),
+				scalar("type"), scalar("string"),
+			),
+			scalar("details"), mapping(
+				scalar("description"), scalar("Additional error context as key-value pairs"),
+				scalar("type"), scalar("object"),
+				scalar("additionalProperties"), mapping(scalar("type"), scalar("string")),
+			),
+		),
+	)
+}
+
+func newPaginationRequest() *yaml.Node {
+	return mapping(
+		scalar("description"), scalar("Pagination parameters for cursor-based pagination"),
+		scalar("type"), scalar("object"),
+		scalar("properties"), mapping(
+			scalar("first"), mapping(
+				scalar("description"), scalar("Maximum number of items to return"),
+				scalar("type"), scalar("integer"),
+				scalar("format"), scalar("int32"),
+				scalar("minimum"), integer("1"),
+				scalar("maximum"), integer("100"),
+			),
+			scalar("after"), mapping(
+				scalar("description"), scalar("Cursor for the next page of results"),
+				scalar("type"), scalar("string"),
+			),
+		),
+	)
+}
+
+func newPaginationResponse() *yaml.Node {
+	return mapping(
+		scalar("description"), scalar("Pagination response with cursor information"),
+		scalar("type"), scalar("object"),
+		scalar("required"), sequence(scalar("end_cursor")),
+		scalar("properties"), mapping(
+			scalar("end_cursor"), mapping(
+				scalar("description"), scalar("Cursor pointing to the last item in the current page"),
+				scalar("type"), scalar("string"),
+			),
+			scalar("has_next_page"), mapping(
+				scalar("description"), scalar("Whether there are more items available"),
+				scalar("type"), scalar("boolean"),
+			),
+		),
+	)
+}
+
+// snake converts camelCase, kebab-case and similar names to snake_case
+func snake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '-' || r == ' ' || r == '.' || r == '_' {
+			b.WriteRune('_')
+			continue
+		}
+
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' })
+	return strings.Join(parts, "_")
+}
+
+func kebab(name string) string {
+	return strings.ReplaceAll(snake(name), "_", "-")
+}
+
+// pascal converts a kebab-case name to PascalCase, matching the lint naming rules
+func pascal(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
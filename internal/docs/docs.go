@@ -0,0 +1,220 @@
+package docs
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/audience"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Config controls how the HTML reference is produced
+type Config struct {
+	Writer     io.Writer
+	SpecPath   string
+	OutputPath string
+	BaseURL    string
+	Addr       string
+	Serve      bool
+	// Audience leaves out the operations it does not see, see audience.Audiences
+	Audience string
+}
+
+// Run renders the HTML reference for the spec. When Serve is true a local
+// HTTP server is started instead of writing a file; the spec is re-read on
+// every page load so edits show up on refresh.
+func Run(conf Config) error {
+	if conf.Serve {
+		return serve(conf)
+	}
+
+	html, err := render(conf, false)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(conf.OutputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(conf.OutputPath, html, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Generated API reference at %s\n", conf.OutputPath)
+	return nil
+}
+
+func render(conf Config, serving bool) ([]byte, error) {
+	doc, err := audience.Load(conf.SpecPath, conf.Audience)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	page := NewPage(doc, conf.BaseURL)
+	page.Serving = serving
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "index.html.tmpl", page); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func serve(conf Config) error {
+	handler, err := newHandler(conf)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Serving API reference for %s at http://%s\n", conf.SpecPath, conf.Addr)
+	server := &http.Server{
+		ReadHeaderTimeout: 10 * time.Second,
+		Handler:           handler,
+		Addr:              conf.Addr,
+	}
+	return server.ListenAndServe()
+}
+
+// tryHeader must be set on every request to /_try. A cross-site form cannot
+// set it and a cross-site fetch that does is stopped by the CORS preflight,
+// which the docs server never answers.
+const tryHeader = "X-Duh-Try"
+
+// newHandler returns the handler of the docs server
+func newHandler(conf Config) (http.Handler, error) {
+	// Validate the spec before starting so errors are reported immediately
+	if _, err := render(conf, true); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		html, err := render(conf, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(html)
+	})
+	mux.HandleFunc("/_try", func(w http.ResponseWriter, r *http.Request) {
+		tryHandler(conf, w, r)
+	})
+	return mux, nil
+}
+
+// tryHandler forwards a try-it-out request to the API. Proxying through the docs
+// server avoids CORS failures when calling the API from the browser, so only
+// the page served by the docs server may use it, and only to reach the
+// --base-url or one of the servers of the spec.
+func tryHandler(conf Config, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get(tryHeader) == "" {
+		http.Error(w, fmt.Sprintf("the %s header is required", tryHeader), http.StatusForbidden)
+		return
+	}
+	if !localHost(conf.Addr, r.Host) {
+		http.Error(w, fmt.Sprintf("host '%s' is not the docs server", r.Host), http.StatusForbidden)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+r.Host {
+		http.Error(w, fmt.Sprintf("origin '%s' is not the docs server", origin), http.StatusForbidden)
+		return
+	}
+
+	bases, err := allowedBases(conf)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	base := strings.TrimRight(r.URL.Query().Get("base"), "/")
+	if !slices.Contains(bases, base) {
+		http.Error(w, fmt.Sprintf("base URL '%s' is not the --base-url or a server of the spec", base), http.StatusForbidden)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if !strings.HasPrefix(path, "/") {
+		http.Error(w, "path must start with /", http.StatusBadRequest)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, base+path, r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// allowedBases returns the base URLs /_try may forward to. The spec is read
+// again so edits to its servers show up on refresh, like the page itself.
+func allowedBases(conf Config) ([]string, error) {
+	doc, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var bases []string
+	if conf.BaseURL != "" {
+		bases = append(bases, strings.TrimRight(conf.BaseURL, "/"))
+	}
+	for _, server := range doc.Servers {
+		if strings.HasPrefix(server.URL, "http://") || strings.HasPrefix(server.URL, "https://") {
+			bases = append(bases, strings.TrimRight(server.URL, "/"))
+		}
+	}
+	return bases, nil
+}
+
+// localHost reports whether the Host of a request names the docs server. A
+// host name other than the one listened on or localhost is refused, which
+// stops a DNS rebinding page from reaching /_try under its own origin.
+func localHost(addr, host string) bool {
+	if host == addr {
+		return true
+	}
+	name, _, err := net.SplitHostPort(host)
+	if err != nil {
+		name = host
+	}
+	listen, _, _ := net.SplitHostPort(addr)
+	return name == "localhost" || name == listen || net.ParseIP(strings.Trim(name, "[]")) != nil
+}
@@ -0,0 +1,332 @@
+package docs_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const docsSpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  description: Manage the pets in the store
+  version: 1.2.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets.create:
+    post:
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Invalid pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+  /orders.cancel:
+    post:
+      summary: Cancel an order
+      deprecated: true
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CancelRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CancelResponse'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          description: Name of the pet
+          example: Rex
+        born_at:
+          type: string
+          format: date-time
+        owner_email:
+          type: string
+          format: email
+          x-duh-redact: true
+    CreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    CancelRequest:
+      type: object
+      properties:
+        order_id:
+          type: string
+    CancelResponse:
+      type: object
+      properties:
+        success:
+          type: boolean
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          type: string
+`
+
+func TestDocsGeneratesHTML(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "site", "index.html")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(docsSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"docs", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated API reference")
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "<title>Pet Store - API Reference</title>")
+	assert.Contains(t, html, "Manage the pets in the store")
+	assert.Contains(t, html, "/pets.create")
+	assert.Contains(t, html, "/orders.cancel")
+	assert.Contains(t, html, `<span class="deprecated">deprecated</span>`)
+	assert.Contains(t, html, `value="https://api.example.com/v1"`)
+	assert.Contains(t, html, "Invalid pet")
+}
+
+func TestDocsIncludesExampleRequest(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "docs.html")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(docsSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"docs", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "&#34;name&#34;: &#34;Rex&#34;")
+	assert.Contains(t, html, "&#34;born_at&#34;: &#34;2024-01-15T10:30:00Z&#34;")
+}
+
+func TestDocsSchemaTable(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "docs.html")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(docsSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"docs", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, `id="schema-CreateRequest"`)
+	assert.Contains(t, html, "<code>name</code> *</td>")
+	assert.Contains(t, html, `<code>owner_email</code><span class="sensitive" title="Masked in logs, audit records and errors">sensitive</span></td>`)
+	assert.Contains(t, html, "string (date-time)")
+	assert.Contains(t, html, "Name of the pet")
+}
+
+func TestDocsGroupsByTag(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "docs.html")
+
+	spec := strings.Replace(docsSpec, "      summary: Cancel an order\n",
+		"      summary: Cancel an order\n      tags: [billing]\n", 1)
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"docs", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	html := string(content)
+	assert.Contains(t, html, "<h2>billing</h2>")
+	assert.Contains(t, html, "<h2>pets</h2>")
+	assert.NotContains(t, html, "<h2>orders</h2>")
+}
+
+func TestDocsBaseURLOverride(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "docs.html")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(docsSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"docs", specPath, "-o", outputPath, "--base-url", "http://localhost:8080/v1"})
+
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `value="http://localhost:8080/v1"`)
+}
+
+func TestDocsFileNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"docs", filepath.Join(tempDir, "missing.yaml"), "--serve"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
+
+func TestDocsServeTry(t *testing.T) {
+	var gotPath, gotAuth string
+	var calls int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+	defer api.Close()
+
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(docsSpec), 0644))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	// The server runs until the test binary exits
+	go duh.RunCmd(io.Discard, io.Discard, []string{"docs", specPath, "--serve", "--addr", addr, "--base-url", api.URL})
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	tryURL := "http://" + addr + "/_try?path=/pets.create&base="
+
+	req, err := http.NewRequest(http.MethodPost, tryURL+url.QueryEscape(api.URL), strings.NewReader(`{"name":"Rex"}`))
+	require.NoError(t, err)
+	req.Header.Set("X-Duh-Try", "1")
+	req.Header.Set("Origin", "http://"+addr)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Equal(t, `{"id":"1"}`, string(body))
+	assert.Equal(t, "/pets.create", gotPath)
+	assert.Equal(t, "Bearer token", gotAuth)
+
+	for _, test := range []struct {
+		name   string
+		base   string
+		host   string
+		origin string
+		noTry  bool
+		want   string
+	}{
+		{
+			name: "base not a server of the spec",
+			base: "http://169.254.169.254",
+			want: "base URL 'http://169.254.169.254' is not the --base-url or a server of the spec",
+		},
+		{
+			name:   "cross site origin",
+			base:   api.URL,
+			origin: "https://evil.example.com",
+			want:   "origin 'https://evil.example.com' is not the docs server",
+		},
+		{
+			name: "dns rebinding host",
+			base: api.URL,
+			host: "evil.example.com",
+			want: "host 'evil.example.com' is not the docs server",
+		},
+		{
+			name:  "missing try header",
+			base:  api.URL,
+			noTry: true,
+			want:  "the X-Duh-Try header is required",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, tryURL+url.QueryEscape(test.base), strings.NewReader(`{}`))
+			require.NoError(t, err)
+			if test.host != "" {
+				req.Host = test.host
+			}
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			if !test.noTry {
+				req.Header.Set("X-Duh-Try", "1")
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+			assert.Contains(t, string(body), test.want)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
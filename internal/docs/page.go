@@ -0,0 +1,258 @@
+package docs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/duh-rpc/duh-cli/internal/example"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Page is the data rendered by the HTML reference template
+type Page struct {
+	Title       string
+	Description string
+	Version     string
+	BaseURL     string
+	Serving     bool
+	Subjects    []Subject
+	Schemas     []Schema
+}
+
+// Subject groups the operations which share a /{subject}.{method} prefix, or
+// which share their first tag when they are tagged
+type Subject struct {
+	Name       string
+	Operations []Operation
+}
+
+// Operation describes a single DUH-RPC endpoint
+type Operation struct {
+	Path        string
+	Method      string
+	Summary     string
+	Description string
+	Request     string
+	Response    string
+	Example     string
+	Errors      []Status
+	Deprecated  bool
+}
+
+// Status is a non 200 response code documented for an operation
+type Status struct {
+	Code        string
+	Description string
+}
+
+// Schema describes a component schema
+type Schema struct {
+	Name        string
+	Description string
+	Properties  []Property
+}
+
+// Property describes a single property of a component schema
+type Property struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	// Sensitive is set by x-duh-redact, the generated code masks the value in
+	// its logs, audit records and errors
+	Sensitive bool
+}
+
+// redactExtension marks a property holding sensitive data, such as a password
+const redactExtension = "x-duh-redact"
+
+// NewPage builds the page model from an OpenAPI document
+func NewPage(doc *v3.Document, baseURL string) Page {
+	page := Page{BaseURL: baseURL}
+
+	if doc.Info != nil {
+		page.Title = doc.Info.Title
+		page.Description = doc.Info.Description
+		page.Version = doc.Info.Version
+	}
+
+	if page.BaseURL == "" && len(doc.Servers) > 0 {
+		page.BaseURL = doc.Servers[0].URL
+	}
+
+	subjects := make(map[string]*Subject)
+	var order []string
+
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		for path, item := range doc.Paths.PathItems.FromOldest() {
+			if item.Post == nil {
+				continue
+			}
+
+			subject, method := splitPath(path)
+			if len(item.Post.Tags) > 0 {
+				subject = item.Post.Tags[0]
+			}
+			if _, ok := subjects[subject]; !ok {
+				subjects[subject] = &Subject{Name: subject}
+				order = append(order, subject)
+			}
+
+			subjects[subject].Operations = append(subjects[subject].Operations, newOperation(path, method, item.Post))
+		}
+	}
+
+	for _, name := range order {
+		page.Subjects = append(page.Subjects, *subjects[name])
+	}
+
+	if doc.Components != nil && doc.Components.Schemas != nil {
+		for name, proxy := range doc.Components.Schemas.FromOldest() {
+			page.Schemas = append(page.Schemas, newSchema(name, proxy))
+		}
+	}
+
+	return page
+}
+
+func newOperation(path, method string, op *v3.Operation) Operation {
+	operation := Operation{
+		Deprecated:  op.Deprecated != nil && *op.Deprecated,
+		Description: op.Description,
+		Summary:     op.Summary,
+		Method:      method,
+		Path:        path,
+	}
+
+	if op.RequestBody != nil && op.RequestBody.Content != nil {
+		if media := op.RequestBody.Content.GetOrZero("application/json"); media != nil && media.Schema != nil {
+			operation.Request = schemaName(media.Schema)
+			if body, err := example.JSON(media.Schema); err == nil {
+				operation.Example = string(body)
+			}
+		}
+	}
+
+	if op.Responses == nil || op.Responses.Codes == nil {
+		return operation
+	}
+
+	for code, response := range op.Responses.Codes.FromOldest() {
+		if code == "200" {
+			if response.Content != nil {
+				if media := response.Content.GetOrZero("application/json"); media != nil && media.Schema != nil {
+					operation.Response = schemaName(media.Schema)
+				}
+			}
+			continue
+		}
+		operation.Errors = append(operation.Errors, Status{Code: code, Description: response.Description})
+	}
+
+	return operation
+}
+
+func newSchema(name string, proxy *base.SchemaProxy) Schema {
+	s := Schema{Name: name}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return s
+	}
+	s.Description = schema.Description
+
+	if schema.Properties == nil {
+		return s
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, r := range schema.Required {
+		required[r] = true
+	}
+
+	for prop, propProxy := range schema.Properties.FromOldest() {
+		p := Property{Name: prop, Required: required[prop], Type: typeName(propProxy)}
+		if propSchema := propProxy.Schema(); propSchema != nil {
+			p.Description = propSchema.Description
+			p.Sensitive = isSensitive(propSchema)
+		}
+		s.Properties = append(s.Properties, p)
+	}
+
+	sort.SliceStable(s.Properties, func(i, j int) bool {
+		return s.Properties[i].Required && !s.Properties[j].Required
+	})
+
+	return s
+}
+
+// isSensitive reports whether the property is marked x-duh-redact: true
+func isSensitive(schema *base.Schema) bool {
+	if schema.Extensions == nil {
+		return false
+	}
+	node, ok := schema.Extensions.Get(redactExtension)
+	if !ok || node == nil {
+		return false
+	}
+
+	var sensitive bool
+	if err := node.Decode(&sensitive); err != nil {
+		return false
+	}
+	return sensitive
+}
+
+// typeName returns a human readable type for a property
+func typeName(proxy *base.SchemaProxy) string {
+	if proxy.IsReference() {
+		return schemaName(proxy)
+	}
+
+	schema := proxy.Schema()
+	if schema == nil || len(schema.Type) == 0 {
+		return "any"
+	}
+
+	switch schema.Type[0] {
+	case "array":
+		if schema.Items != nil && schema.Items.IsA() {
+			return typeName(schema.Items.A) + "[]"
+		}
+		return "array"
+	case "object":
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+			return "map<string, " + typeName(schema.AdditionalProperties.A) + ">"
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		var values []string
+		for _, v := range schema.Enum {
+			values = append(values, v.Value)
+		}
+		return "enum(" + strings.Join(values, ", ") + ")"
+	}
+
+	if schema.Format != "" {
+		return schema.Type[0] + " (" + schema.Format + ")"
+	}
+	return schema.Type[0]
+}
+
+func schemaName(proxy *base.SchemaProxy) string {
+	if !proxy.IsReference() {
+		return "inline"
+	}
+	ref := proxy.GetReference()
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+func splitPath(path string) (subject, method string) {
+	trimmed := strings.TrimPrefix(path, "/")
+	if idx := strings.LastIndex(trimmed, "."); idx != -1 {
+		return trimmed[:idx], trimmed[idx+1:]
+	}
+	return trimmed, ""
+}
@@ -0,0 +1,250 @@
+package example
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+const maxDepth = 8
+
+// Object is an ordered JSON object which preserves the property order
+// defined in the OpenAPI schema when marshaled.
+type Object []Field
+
+// Field is a single key/value pair within an Object
+type Field struct {
+	Key   string
+	Value any
+}
+
+// MarshalJSON implements json.Marshaler
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Options controls how examples are synthesized
+type Options struct {
+	// Faker fills the values without an example, default or enum with
+	// realistic data picked by the name of the property and its format, such
+	// as a person's name for a name property, instead of placeholders
+	Faker bool
+	// Seed varies the values Faker picks, so the examples of different seeds
+	// differ like the records of a table. Above zero, the examples of the
+	// schema are skipped since they would be the same for every seed.
+	Seed int
+}
+
+// FromSchema synthesizes an example value from the schema. Explicit examples
+// and defaults are preferred, followed by the first enum value, falling back
+// to a placeholder derived from the type and format.
+func FromSchema(proxy *base.SchemaProxy) any {
+	return FromSchemaWith(proxy, Options{})
+}
+
+// FromSchemaWith synthesizes an example value from the schema like
+// FromSchema, with opts
+func FromSchemaWith(proxy *base.SchemaProxy, opts Options) any {
+	return builder{opts: opts}.build(proxy, "", 0)
+}
+
+// JSON synthesizes an example value from the schema and returns it as
+// indented JSON.
+func JSON(proxy *base.SchemaProxy) ([]byte, error) {
+	return JSONWith(proxy, Options{})
+}
+
+// JSONWith synthesizes an example value from the schema with opts and
+// returns it as indented JSON.
+func JSONWith(proxy *base.SchemaProxy, opts Options) ([]byte, error) {
+	return json.MarshalIndent(FromSchemaWith(proxy, opts), "", "  ")
+}
+
+type builder struct {
+	opts Options
+}
+
+// build returns the example of the schema of the property name, which is
+// empty for the schema of a request or response
+func (b builder) build(proxy *base.SchemaProxy, name string, depth int) any {
+	if proxy == nil || depth > maxDepth {
+		return nil
+	}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Example != nil && b.opts.Seed == 0 {
+		if v, ok := decode(schema.Example); ok {
+			return v
+		}
+	}
+
+	if schema.Default != nil {
+		if v, ok := decode(schema.Default); ok {
+			return v
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		if v, ok := decode(schema.Enum[0]); ok {
+			return v
+		}
+	}
+
+	if len(schema.AllOf) > 0 {
+		var merged Object
+		for _, part := range schema.AllOf {
+			if obj, ok := b.build(part, name, depth+1).(Object); ok {
+				merged = append(merged, obj...)
+			}
+		}
+		return append(merged, b.properties(schema, depth)...)
+	}
+
+	if len(schema.OneOf) > 0 {
+		return b.build(schema.OneOf[0], name, depth+1)
+	}
+
+	if len(schema.AnyOf) > 0 {
+		return b.build(schema.AnyOf[0], name, depth+1)
+	}
+
+	switch typeOf(schema) {
+	case "object":
+		return b.properties(schema, depth)
+	case "array":
+		if schema.Items == nil || !schema.Items.IsA() {
+			return []any{}
+		}
+		item := b.build(schema.Items.A, name, depth+1)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	case "string":
+		if b.opts.Faker {
+			return fakeString(name, schema.Format, b.opts.Seed)
+		}
+		return stringFor(schema.Format)
+	case "integer":
+		if b.opts.Faker {
+			return fakeInteger(name, schema.Minimum, schema.Maximum, b.opts.Seed)
+		}
+		if schema.Minimum != nil {
+			return int64(*schema.Minimum)
+		}
+		return 0
+	case "number":
+		if b.opts.Faker {
+			return fakeNumber(name, schema.Minimum, schema.Maximum, b.opts.Seed)
+		}
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 0.0
+	case "boolean":
+		return b.opts.Faker
+	}
+
+	return nil
+}
+
+func (b builder) properties(schema *base.Schema, depth int) Object {
+	obj := Object{}
+	if schema.Properties == nil {
+		return obj
+	}
+
+	for name, prop := range schema.Properties.FromOldest() {
+		obj = append(obj, Field{Key: name, Value: b.build(prop, name, depth+1)})
+	}
+	return obj
+}
+
+func typeOf(schema *base.Schema) string {
+	for _, t := range schema.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	if schema.Properties != nil {
+		return "object"
+	}
+	return ""
+}
+
+func stringFor(format string) string {
+	switch strings.ToLower(format) {
+	case "date-time":
+		return "2024-01-15T10:30:00Z"
+	case "date":
+		return "2024-01-15"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	}
+	return "string"
+}
+
+func decode(node *yaml.Node) (any, bool) {
+	var v any
+	if err := node.Decode(&v); err != nil {
+		return nil, false
+	}
+	return normalize(v), true
+}
+
+// normalize converts yaml decoded maps into values encoding/json can marshal
+func normalize(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, item := range t {
+			t[k] = normalize(item)
+		}
+		return t
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, item := range t {
+			if s, ok := k.(string); ok {
+				m[s] = normalize(item)
+			}
+		}
+		return m
+	case []any:
+		for i, item := range t {
+			t[i] = normalize(item)
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package example_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exampleSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+paths:
+  /v1/users.create:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+          format: email
+        age:
+          type: integer
+          minimum: 18
+        role:
+          type: string
+          enum: [admin, member]
+    User:
+      type: object
+      properties:
+        user_id:
+          type: string
+        name:
+          type: string
+        active:
+          type: boolean
+        created_at:
+          type: string
+          format: date-time
+`
+
+func TestExample(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(exampleSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"example", specPath, "/v1/users.create"})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Equal(t, `Request:
+{
+  "name": "string",
+  "email": "user@example.com",
+  "age": 18,
+  "role": "admin"
+}
+
+Response 200:
+{
+  "user_id": "string",
+  "name": "string",
+  "active": false,
+  "created_at": "2024-01-15T10:30:00Z"
+}
+`, stdout.String())
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"example", specPath, "/v1/users.create", "--response", "--faker"})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Equal(t, `{
+  "user_id": "10a75cda-046c-4cda-8505-046c450510a7",
+  "name": "Alan Turing",
+  "active": true,
+  "created_at": "2025-03-14T09:26:53Z"
+}
+`, stdout.String())
+
+	// Faker keeps enums and respects the minimum
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"example", specPath, "/v1/users.create", "--request", "--faker"})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), `"age": 36`)
+	assert.Contains(t, stdout.String(), `"role": "admin"`)
+	assert.NotContains(t, stdout.String(), `"string"`)
+}
+
+func TestExampleQuiet(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(exampleSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"example", "-q", specPath, "/v1/users.create", "--request"})
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Equal(t, `{
+  "name": "string",
+  "email": "user@example.com",
+  "age": 18,
+  "role": "admin"
+}
+`, stdout.String())
+	assert.Empty(t, stderr.String())
+}
+
+func TestExampleErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(exampleSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"example", specPath, "/v1/users.delete"})
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "Error: operation '/v1/users.delete' not found in paths\n", stderr.String())
+
+	stderr.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"example", specPath, "/v1/users.create", "--request", "--response"})
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "Error: --request and --response cannot be used together\n", stderr.String())
+}
@@ -0,0 +1,168 @@
+package example
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{"Ada", "Grace", "Alan", "Katherine", "Linus", "Margaret"}
+	lastNames  = []string{"Lovelace", "Hopper", "Turing", "Johnson", "Torvalds", "Hamilton"}
+	cities     = []string{"Lisbon", "Toronto", "Osaka", "Nairobi", "Oslo", "Austin"}
+	companies  = []string{"Acme Corp", "Globex", "Initech", "Umbrella Labs", "Hooli", "Stark Industries"}
+	words      = []string{"alpha", "harbor", "quartz", "meadow", "signal", "ember"}
+)
+
+// pick returns an item of list chosen by name, the same item for the same
+// name so examples do not change between runs
+func pick(list []string, name string) string {
+	return list[hash(name)%uint32(len(list))]
+}
+
+// fakeTime is the time of the values with a date or time format, a day
+// earlier for every seed
+var fakeTime = time.Date(2025, time.March, 14, 9, 26, 53, 0, time.UTC)
+
+// seeded returns what the values of the property name are picked by, which
+// is the name itself without a seed
+func seeded(name string, seed int) string {
+	if seed == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s#%d", name, seed)
+}
+
+// fakeString returns a realistic value for the string property name with
+// format, recognizing common formats and property names
+func fakeString(name, format string, seed int) string {
+	id := seeded(name, seed)
+	first, last := pick(firstNames, id), pick(lastNames, id)
+	if seed > 0 {
+		// The names and emails of a seed are those of the same person
+		person := seeded("person", seed)
+		first, last = pick(firstNames, person), pick(lastNames, person+"last")
+	}
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	at := fakeTime.AddDate(0, 0, -seed)
+
+	// Seeds are unique records, so their emails are too
+	email := strings.ToLower(first + "." + last + "@example.com")
+	if seed > 0 {
+		email = strings.ToLower(fmt.Sprintf("%s.%s%d@example.com", first, last, seed))
+	}
+
+	switch strings.ToLower(format) {
+	case "date-time":
+		return at.Format(time.RFC3339)
+	case "date":
+		return at.Format(time.DateOnly)
+	case "time":
+		return at.Format(time.TimeOnly)
+	case "email":
+		return email
+	case "uuid":
+		return fakeUUID(id)
+	case "uri", "url":
+		return "https://" + strings.ToLower(pick(words, id)) + ".example.com"
+	case "hostname":
+		return strings.ToLower(pick(words, id)) + ".example.com"
+	case "ipv4":
+		return "192.0.2.10"
+	case "ipv6":
+		return "2001:db8::10"
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	case "password":
+		return "correct-horse-battery-staple"
+	}
+
+	switch {
+	case strings.Contains(key, "email"):
+		return email
+	case key == "id" || strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID") || strings.HasSuffix(name, "_id"):
+		return fakeUUID(id)
+	case strings.Contains(key, "url") || strings.Contains(key, "uri") || strings.Contains(key, "website"):
+		return "https://" + strings.ToLower(pick(words, id)) + ".example.com"
+	case strings.Contains(key, "firstname") || key == "givenname":
+		return first
+	case strings.Contains(key, "lastname") || key == "familyname" || key == "surname":
+		return last
+	case strings.Contains(key, "company") || strings.Contains(key, "organization"):
+		return pick(companies, id)
+	case key == "name" || strings.HasSuffix(key, "name"):
+		return first + " " + last
+	case strings.Contains(key, "phone"):
+		return "+1 555 0100"
+	case strings.Contains(key, "city"):
+		return pick(cities, id)
+	case strings.Contains(key, "country"):
+		return "PT"
+	case strings.Contains(key, "currency"):
+		return "EUR"
+	case strings.Contains(key, "locale") || strings.Contains(key, "language"):
+		return "en-US"
+	case strings.Contains(key, "address") || strings.Contains(key, "street"):
+		return "12 Harbour Lane"
+	case strings.Contains(key, "description") || strings.Contains(key, "summary"):
+		return "A short description of the " + pick(words, id) + " item."
+	case strings.Contains(key, "title"):
+		return "Quarterly " + pick(words, id) + " report"
+	case strings.Contains(key, "token") || strings.Contains(key, "cursor"):
+		return fmt.Sprintf("%x", hash(id+"token"))
+	}
+	return pick(words, id)
+}
+
+// fakeInteger returns a realistic value for the integer property name
+// within minimum and maximum
+func fakeInteger(name string, minimum, maximum *float64, seed int) int64 {
+	id := seeded(name, seed)
+	key := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	var value int64
+	switch {
+	case key == "age" || strings.HasSuffix(name, "Age") || strings.HasSuffix(name, "_age"):
+		value = 36
+		if seed > 0 {
+			value = int64(hash(id)%50) + 18
+		}
+	case strings.Contains(key, "year"):
+		value = 2025
+	case strings.Contains(key, "first") || strings.Contains(key, "limit") || strings.Contains(key, "size"):
+		value = 20
+	default:
+		value = int64(hash(id)%100) + 1
+	}
+	if minimum != nil && value < int64(*minimum) {
+		value = int64(*minimum)
+	}
+	if maximum != nil && value > int64(*maximum) {
+		value = int64(*maximum)
+	}
+	return value
+}
+
+// fakeNumber returns a realistic value for the number property name within
+// minimum and maximum
+func fakeNumber(name string, minimum, maximum *float64, seed int) float64 {
+	value := float64(hash(seeded(name, seed))%10_000)/100 + 1
+	if minimum != nil && value < *minimum {
+		value = *minimum
+	}
+	if maximum != nil && value > *maximum {
+		value = *maximum
+	}
+	return value
+}
+
+func fakeUUID(name string) string {
+	a, b := hash(name), hash(name+"uuid")
+	return fmt.Sprintf("%08x-%04x-4%03x-8%03x-%08x%04x", a, b>>16, a&0xfff, b&0xfff, b, a>>16)
+}
+
+func hash(name string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	return h.Sum32()
+}
@@ -0,0 +1,103 @@
+package example
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const contentTypeJSON = "application/json"
+
+// Config controls the operation whose examples Run prints
+type Config struct {
+	Writer   io.Writer
+	SpecPath string
+	// Path is the path of the operation, e.g. /v1/users.create
+	Path string
+	// Request and Response print only the request or the response, as bare
+	// JSON which can be piped to other commands
+	Request  bool
+	Response bool
+	Options  Options
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// Run prints an example request of the operation and an example of its
+// first 2XX response, synthesized from their schemas
+func Run(conf Config) error {
+	if conf.Request && conf.Response {
+		return errors.New("--request and --response cannot be used together")
+	}
+
+	start := time.Now()
+	doc, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	var op *v3.Operation
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		if item := doc.Paths.PathItems.GetOrZero(conf.Path); item != nil {
+			op = item.Post
+		}
+	}
+	if op == nil {
+		return fmt.Errorf("operation '%s' not found in paths", conf.Path)
+	}
+
+	var request, response []byte
+	var status string
+	if op.RequestBody != nil && op.RequestBody.Content != nil {
+		if media := op.RequestBody.Content.GetOrZero(contentTypeJSON); media != nil && media.Schema != nil {
+			if request, err = JSONWith(media.Schema, conf.Options); err != nil {
+				return err
+			}
+		}
+	}
+	if op.Responses != nil && op.Responses.Codes != nil {
+		for code, resp := range op.Responses.Codes.FromOldest() {
+			if !strings.HasPrefix(code, "2") || resp.Content == nil {
+				continue
+			}
+			if media := resp.Content.GetOrZero(contentTypeJSON); media != nil && media.Schema != nil {
+				if response, err = JSONWith(media.Schema, conf.Options); err != nil {
+					return err
+				}
+				status = code
+				break
+			}
+		}
+	}
+
+	switch {
+	case conf.Request:
+		if request == nil {
+			return fmt.Errorf("operation '%s' has no application/json request body", conf.Path)
+		}
+		_, _ = fmt.Fprintln(conf.Writer, string(request))
+	case conf.Response:
+		if response == nil {
+			return fmt.Errorf("operation '%s' has no application/json 2XX response", conf.Path)
+		}
+		_, _ = fmt.Fprintln(conf.Writer, string(response))
+	default:
+		if request != nil {
+			_, _ = fmt.Fprintf(conf.Writer, "Request:\n%s\n", request)
+		}
+		if response != nil {
+			if request != nil {
+				_, _ = fmt.Fprintln(conf.Writer)
+			}
+			_, _ = fmt.Fprintf(conf.Writer, "Response %s:\n%s\n", status, response)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/audience"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	componentPrefix   = "#/components/schemas/"
+)
+
+// JSONSchemaConfig controls the JSON Schema export
+type JSONSchemaConfig struct {
+	Writer    io.Writer
+	SpecPath  string
+	OutputDir string
+	// Audience leaves out the schemas only the operations it does not see
+	// use, see audience.Audiences
+	Audience string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// object is a JSON object that keeps the order of its members
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (o object) get(key string) (any, bool) {
+	for _, m := range o {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+func (o *object) set(key string, value any) {
+	for i, m := range *o {
+		if m.key == key {
+			(*o)[i].value = value
+			return
+		}
+	}
+	*o = append(*o, member{key: key, value: value})
+}
+
+func (o *object) remove(key string) {
+	for i, m := range *o {
+		if m.key == key {
+			*o = append((*o)[:i], (*o)[i+1:]...)
+			return
+		}
+	}
+}
+
+// schemaConverter converts OpenAPI 3.0 schemas to JSON Schema draft 2020-12. It
+// records the components referenced so they can be bundled under $defs.
+type schemaConverter struct {
+	components map[string]*yaml.Node
+	root       string
+	refs       []string
+}
+
+// JSONSchema writes a standalone draft 2020-12 JSON Schema file for every schema
+// in components/schemas. Referenced components are bundled under $defs so each
+// file can be used without the others.
+func JSONSchema(conf JSONSchemaConfig) error {
+	start := time.Now()
+	if _, err := audience.Load(conf.SpecPath, conf.Audience); err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	data, err := os.ReadFile(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read spec: %w", err)
+	}
+	if conf.Audience != "" {
+		if data, err = audience.Filter(data, conf.Audience); err != nil {
+			return err
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	var schemas *yaml.Node
+	if len(doc.Content) > 0 {
+		schemas = child(child(doc.Content[0], "components"), "schemas")
+	}
+	if schemas == nil || len(schemas.Content) == 0 {
+		return fmt.Errorf("no schemas found in components/schemas")
+	}
+
+	components := make(map[string]*yaml.Node)
+	var names []string
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name := schemas.Content[i].Value
+		components[name] = schemas.Content[i+1]
+		names = append(names, name)
+	}
+
+	for _, name := range names {
+		schema, err := standalone(components, name)
+		if err != nil {
+			return err
+		}
+		if err := writeJSON(filepath.Join(conf.OutputDir, name+".json"), schema); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Exported %d schema(s) to %s\n", len(names), conf.OutputDir)
+	return nil
+}
+
+// standalone returns the JSON Schema document for the component name with
+// every component it references, directly or not, under $defs
+func standalone(components map[string]*yaml.Node, name string) (object, error) {
+	c := &schemaConverter{components: components, root: name}
+
+	root, err := c.schema(components[name])
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", name, err)
+	}
+
+	var defs object
+	for i := 0; i < len(c.refs); i++ {
+		ref := c.refs[i]
+		def, err := c.schema(components[ref])
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", ref, err)
+		}
+		defs = append(defs, member{key: ref, value: def})
+	}
+
+	doc := object{
+		{key: "$schema", value: jsonSchemaDialect},
+		{key: "$id", value: name + ".json"},
+	}
+	rootObj, ok := root.(object)
+	if !ok {
+		// A boolean schema cannot carry keywords, so wrap it
+		rootObj = object{{key: "allOf", value: []any{root}}}
+	}
+	if _, ok := rootObj.get("title"); !ok {
+		doc = append(doc, member{key: "title", value: name})
+	}
+	doc = append(doc, rootObj...)
+	if len(defs) > 0 {
+		doc = append(doc, member{key: "$defs", value: defs})
+	}
+	return doc, nil
+}
+
+// schema converts a single schema. Keywords that are the same in both dialects
+// are copied, the OpenAPI specific ones are translated or dropped.
+func (c *schemaConverter) schema(node *yaml.Node) (any, error) {
+	if node == nil {
+		return object{}, nil
+	}
+	if node.Kind == yaml.AliasNode {
+		return c.schema(node.Alias)
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		return node.Value == "true", nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a schema object", node.Line)
+	}
+
+	var result object
+	var nullable bool
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+
+		switch key {
+		case "$ref":
+			ref, err := c.ref(value.Value)
+			if err != nil {
+				return nil, err
+			}
+			result.set(key, ref)
+		case "properties":
+			properties := object{}
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				prop, err := c.schema(value.Content[j+1])
+				if err != nil {
+					return nil, err
+				}
+				properties = append(properties, member{key: value.Content[j].Value, value: prop})
+			}
+			result.set(key, properties)
+		case "items", "additionalProperties", "not":
+			s, err := c.schema(value)
+			if err != nil {
+				return nil, err
+			}
+			result.set(key, s)
+		case "allOf", "oneOf", "anyOf":
+			list := make([]any, 0, len(value.Content))
+			for _, item := range value.Content {
+				s, err := c.schema(item)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, s)
+			}
+			result.set(key, list)
+		case "nullable":
+			nullable = value.Value == "true"
+		case "example":
+			result.set("examples", []any{literal(value)})
+		case "discriminator", "xml", "externalDocs":
+			// OpenAPI only keywords without a JSON Schema equivalent
+		default:
+			result.set(key, literal(value))
+		}
+	}
+
+	exclusive(&result, "exclusiveMinimum", "minimum")
+	exclusive(&result, "exclusiveMaximum", "maximum")
+
+	if !nullable {
+		return result, nil
+	}
+
+	if typ, ok := result.get("type"); ok {
+		if s, ok := typ.(string); ok {
+			result.set("type", []any{s, "null"})
+		}
+		if enum, ok := result.get("enum"); ok {
+			if values, ok := enum.([]any); ok {
+				result.set("enum", append(values, nil))
+			}
+		}
+		return result, nil
+	}
+	return object{{key: "anyOf", value: []any{result, object{{key: "type", value: "null"}}}}}, nil
+}
+
+// ref rewrites a component reference to point into $defs, or to the document
+// root for a schema that references itself
+func (c *schemaConverter) ref(ref string) (string, error) {
+	if !strings.HasPrefix(ref, componentPrefix) {
+		return "", fmt.Errorf("unsupported reference '%s': only %s references can be exported", ref, componentPrefix)
+	}
+
+	name, rest, _ := strings.Cut(strings.TrimPrefix(ref, componentPrefix), "/")
+	if _, ok := c.components[name]; !ok {
+		return "", fmt.Errorf("reference '%s' not found in components/schemas", ref)
+	}
+	if rest != "" {
+		rest = "/" + rest
+	}
+
+	if name == c.root {
+		return "#" + rest, nil
+	}
+	if !slices.Contains(c.refs, name) {
+		c.refs = append(c.refs, name)
+	}
+	return "#/$defs/" + name + rest, nil
+}
+
+// exclusive converts the OpenAPI 3.0 boolean form of exclusiveMinimum and
+// exclusiveMaximum to the numeric form used by JSON Schema
+func exclusive(schema *object, keyword, bound string) {
+	value, ok := schema.get(keyword)
+	if !ok {
+		return
+	}
+	flag, ok := value.(bool)
+	if !ok {
+		return
+	}
+
+	schema.remove(keyword)
+	if limit, ok := schema.get(bound); ok && flag {
+		schema.remove(bound)
+		schema.set(keyword, limit)
+	}
+}
+
+// literal converts a YAML value such as an example or enum to JSON, keeping the order of mapping keys
+func literal(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return literal(node.Alias)
+	case yaml.MappingNode:
+		result := object{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			result = append(result, member{key: node.Content[i].Value, value: literal(node.Content[i+1])})
+		}
+		return result
+	case yaml.SequenceNode:
+		list := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			list = append(list, literal(item))
+		}
+		return list
+	}
+
+	if node.Tag == "!!timestamp" {
+		// Keep dates as written instead of converting them to RFC 3339
+		return node.Value
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+	return value
+}
+
+func child(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package export_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonSchemaSpec = `openapi: 3.0.3
+info:
+  title: Org Chart
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Employee:
+      type: object
+      description: A member of staff
+      required: [name]
+      properties:
+        name:
+          type: string
+          example: Alice
+        nickname:
+          type: string
+          nullable: true
+        reports:
+          type: array
+          items:
+            $ref: '#/components/schemas/Employee'
+        office:
+          $ref: '#/components/schemas/Office'
+        rating:
+          type: number
+          minimum: 0
+          exclusiveMinimum: true
+    Office:
+      type: object
+      properties:
+        address:
+          $ref: '#/components/schemas/Address'
+        kind:
+          type: string
+          enum: [remote, onsite]
+          nullable: true
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+`
+
+func TestExportJSONSchema(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "schemas")
+	require.NoError(t, os.WriteFile(specPath, []byte(jsonSchemaSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"export", "jsonschema", specPath, "--out", outputDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Exported 3 schema(s)")
+	assert.Empty(t, stderr.String())
+	assert.FileExists(t, filepath.Join(outputDir, "Office.json"))
+	assert.FileExists(t, filepath.Join(outputDir, "Address.json"))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "Employee.json"))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "Employee.json",
+		"title": "Employee",
+		"type": "object",
+		"description": "A member of staff",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "examples": ["Alice"]},
+			"nickname": {"type": ["string", "null"]},
+			"reports": {"type": "array", "items": {"$ref": "#"}},
+			"office": {"$ref": "#/$defs/Office"},
+			"rating": {"type": "number", "exclusiveMinimum": 0}
+		},
+		"$defs": {
+			"Office": {
+				"type": "object",
+				"properties": {
+					"address": {"$ref": "#/$defs/Address"},
+					"kind": {"type": ["string", "null"], "enum": ["remote", "onsite", null]}
+				}
+			},
+			"Address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}}
+			}
+		}
+	}`, string(content))
+}
+
+func TestExportJSONSchemaErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		spec     string
+		expected string
+	}{
+		{
+			name:     "no schemas",
+			spec:     "openapi: 3.0.3\ninfo:\n  title: Empty\n  version: 1.0.0\npaths: {}\n",
+			expected: "no schemas found in components/schemas",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			specPath := filepath.Join(tempDir, "openapi.yaml")
+			require.NoError(t, os.WriteFile(specPath, []byte(test.spec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"export", "jsonschema", specPath, "--out", filepath.Join(tempDir, "schemas")})
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.expected)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestExportJSONSchemaFileNotFound(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"export", "jsonschema", "/nonexistent/openapi.yaml", "--out", t.TempDir()})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,248 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/audience"
+	"github.com/duh-rpc/duh-cli/internal/example"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+const (
+	postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+	baseURLVar    = "baseUrl"
+	authVar       = "authorization"
+)
+
+// PostmanConfig controls the Postman collection export
+type PostmanConfig struct {
+	Writer          io.Writer
+	SpecPath        string
+	OutputPath      string
+	EnvironmentPath string
+	// Audience leaves out the operations it does not see, see audience.Audiences
+	Audience string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+type collection struct {
+	Info     collectionInfo `json:"info"`
+	Item     []folder       `json:"item"`
+	Variable []variable     `json:"variable"`
+}
+
+type collectionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+type folder struct {
+	Name string `json:"name"`
+	Item []item `json:"item"`
+}
+
+type item struct {
+	Name    string  `json:"name"`
+	Request request `json:"request"`
+}
+
+type request struct {
+	Method      string   `json:"method"`
+	Header      []header `json:"header"`
+	Body        body     `json:"body"`
+	URL         url      `json:"url"`
+	Description string   `json:"description,omitempty"`
+}
+
+type header struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type body struct {
+	Mode    string      `json:"mode"`
+	Raw     string      `json:"raw"`
+	Options bodyOptions `json:"options"`
+}
+
+type bodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type url struct {
+	Raw  string   `json:"raw"`
+	Host []string `json:"host"`
+	Path []string `json:"path"`
+}
+
+type variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type environment struct {
+	Name   string     `json:"name"`
+	Values []envValue `json:"values"`
+}
+
+type envValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Postman writes a Postman v2.1 collection containing one request per operation
+func Postman(conf PostmanConfig) error {
+	start := time.Now()
+	doc, err := audience.Load(conf.SpecPath, conf.Audience)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	c := newCollection(doc)
+
+	if err := writeJSON(conf.OutputPath, c); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Exported %d request(s) to %s\n", countItems(c), conf.OutputPath)
+
+	if conf.EnvironmentPath == "" {
+		return nil
+	}
+
+	env := environment{Name: c.Info.Name}
+	for _, v := range c.Variable {
+		env.Values = append(env.Values, envValue{Key: v.Key, Value: v.Value, Type: v.Type, Enabled: true})
+	}
+
+	if err := writeJSON(conf.EnvironmentPath, env); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Exported environment to %s\n", conf.EnvironmentPath)
+	return nil
+}
+
+func newCollection(doc *v3.Document) collection {
+	c := collection{
+		Info: collectionInfo{Schema: postmanSchema, Name: "DUH-RPC API"},
+	}
+
+	if doc.Info != nil {
+		if doc.Info.Title != "" {
+			c.Info.Name = doc.Info.Title
+		}
+		c.Info.Description = doc.Info.Description
+	}
+
+	var serverURL string
+	if len(doc.Servers) > 0 {
+		serverURL = doc.Servers[0].URL
+	}
+
+	c.Variable = []variable{
+		{Key: baseURLVar, Value: serverURL, Type: "string"},
+		{Key: authVar, Value: "", Type: "secret"},
+	}
+
+	if doc.Paths == nil || doc.Paths.PathItems == nil {
+		return c
+	}
+
+	folders := make(map[string]int)
+	for path, pathItem := range doc.Paths.PathItems.FromOldest() {
+		if pathItem.Post == nil {
+			continue
+		}
+
+		subject := strings.TrimPrefix(path, "/")
+		if idx := strings.LastIndex(subject, "."); idx != -1 {
+			subject = subject[:idx]
+		}
+
+		idx, ok := folders[subject]
+		if !ok {
+			idx = len(c.Item)
+			folders[subject] = idx
+			c.Item = append(c.Item, folder{Name: subject})
+		}
+
+		c.Item[idx].Item = append(c.Item[idx].Item, newItem(path, pathItem.Post))
+	}
+
+	return c
+}
+
+func newItem(path string, op *v3.Operation) item {
+	name := op.Summary
+	if name == "" {
+		name = path
+	}
+
+	raw := "{}"
+	if op.RequestBody != nil && op.RequestBody.Content != nil {
+		if media := op.RequestBody.Content.GetOrZero("application/json"); media != nil && media.Schema != nil {
+			if b, err := example.JSON(media.Schema); err == nil {
+				raw = string(b)
+			}
+		}
+	}
+
+	b := body{Mode: "raw", Raw: raw}
+	b.Options.Raw.Language = "json"
+
+	return item{
+		Name: name,
+		Request: request{
+			Method: "POST",
+			Header: []header{
+				{Key: "Content-Type", Value: "application/json"},
+				{Key: "Authorization", Value: "{{" + authVar + "}}"},
+			},
+			URL: url{
+				Raw:  "{{" + baseURLVar + "}}" + path,
+				Path: []string{strings.TrimPrefix(path, "/")},
+				Host: []string{"{{" + baseURLVar + "}}"},
+			},
+			Description: op.Description,
+			Body:        b,
+		},
+	}
+}
+
+func countItems(c collection) int {
+	var count int
+	for _, f := range c.Item {
+		count += len(f.Item)
+	}
+	return count
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
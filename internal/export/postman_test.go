@@ -0,0 +1,217 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exportSpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  description: Manage the pets in the store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets.create:
+    post:
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreatePetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreatePetResponse'
+  /pets.get:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetPetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreatePetResponse'
+  /orders.cancel:
+    post:
+      summary: Cancel an order
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetPetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreatePetResponse'
+components:
+  schemas:
+    CreatePetRequest:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        age:
+          type: integer
+          format: int32
+          example: 3
+    GetPetRequest:
+      type: object
+      properties:
+        id:
+          type: string
+    CreatePetResponse:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item []struct {
+		Name string `json:"name"`
+		Item []struct {
+			Name    string `json:"name"`
+			Request struct {
+				Method string `json:"method"`
+				Header []struct {
+					Key   string `json:"key"`
+					Value string `json:"value"`
+				} `json:"header"`
+				Body struct {
+					Raw string `json:"raw"`
+				} `json:"body"`
+				URL struct {
+					Raw string `json:"raw"`
+				} `json:"url"`
+			} `json:"request"`
+		} `json:"item"`
+	} `json:"item"`
+	Variable []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"variable"`
+}
+
+func TestExportPostmanCollection(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "collection.json")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(exportSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"export", "postman", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Exported 3 request(s)")
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var c postmanCollection
+	require.NoError(t, json.Unmarshal(content, &c))
+
+	assert.Equal(t, "Pet Store", c.Info.Name)
+	assert.Contains(t, c.Info.Schema, "v2.1.0")
+	require.Len(t, c.Item, 2)
+	assert.Equal(t, "pets", c.Item[0].Name)
+	assert.Equal(t, "orders", c.Item[1].Name)
+	require.Len(t, c.Item[0].Item, 2)
+
+	create := c.Item[0].Item[0]
+	assert.Equal(t, "Create a pet", create.Name)
+	assert.Equal(t, "POST", create.Request.Method)
+	assert.Equal(t, "{{baseUrl}}/pets.create", create.Request.URL.Raw)
+	assert.JSONEq(t, `{"name": "Rex", "age": 3}`, create.Request.Body.Raw)
+
+	assert.Equal(t, "/pets.get", c.Item[0].Item[1].Name)
+}
+
+func TestExportPostmanVariables(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "collection.json")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(exportSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"export", "postman", specPath, "-o", outputPath})
+
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var c postmanCollection
+	require.NoError(t, json.Unmarshal(content, &c))
+
+	require.Len(t, c.Variable, 2)
+	assert.Equal(t, "baseUrl", c.Variable[0].Key)
+	assert.Equal(t, "https://api.example.com/v1", c.Variable[0].Value)
+	assert.Equal(t, "authorization", c.Variable[1].Key)
+
+	headers := c.Item[0].Item[0].Request.Header
+	require.Len(t, headers, 2)
+	assert.Equal(t, "Authorization", headers[1].Key)
+	assert.Equal(t, "{{authorization}}", headers[1].Value)
+}
+
+func TestExportPostmanEnvironment(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputPath := filepath.Join(tempDir, "collection.json")
+	envPath := filepath.Join(tempDir, "env", "local.json")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(exportSpec), 0644))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"export", "postman", specPath, "-o", outputPath, "--env", envPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Exported environment")
+
+	content, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"key": "baseUrl"`)
+	assert.Contains(t, string(content), `"enabled": true`)
+}
+
+func TestExportPostmanFileNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"export", "postman", filepath.Join(tempDir, "missing.yaml")})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,64 @@
+package duh
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// redactExtension marks a property whose value is masked in the requests the
+// AuditSink of the Handler records, such as a password or a card number
+const redactExtension = "x-duh-redact"
+
+// RedactedMessage lists the properties of a component schema marked
+// x-duh-redact, the schema being the proto message of the same name
+type RedactedMessage struct {
+	Message string
+	// Fields are the marked properties, which are the JSON names of the
+	// fields of the message
+	Fields []string
+}
+
+// redactedMessages returns the component schemas with properties marked
+// x-duh-redact, in the order of the spec
+func (p *Parser) redactedMessages() ([]RedactedMessage, error) {
+	if p.spec.Components == nil || p.spec.Components.Schemas == nil {
+		return nil, nil
+	}
+
+	var messages []RedactedMessage
+	for name, proxy := range p.spec.Components.Schemas.FromOldest() {
+		schema := proxy.Schema()
+		if schema == nil || schema.Properties == nil {
+			continue
+		}
+		message := RedactedMessage{Message: name}
+		for property, prop := range schema.Properties.FromOldest() {
+			if prop.Schema() == nil {
+				continue
+			}
+			redact, err := extensionFlag(prop.Schema().Extensions, redactExtension,
+				fmt.Sprintf("property %s of schema %s", property, name))
+			if err != nil {
+				return nil, err
+			}
+			if redact {
+				message.Fields = append(message.Fields, property)
+			}
+		}
+		if len(message.Fields) > 0 {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// redacted reports whether the property is marked x-duh-redact, an invalid
+// value is reported by redactedMessages instead
+func redacted(proxy *base.SchemaProxy) bool {
+	if proxy == nil || proxy.Schema() == nil {
+		return false
+	}
+	redact, _ := extensionFlag(proxy.Schema().Extensions, redactExtension, "")
+	return redact
+}
@@ -0,0 +1,52 @@
+package duh
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// authExtension lists the schemes the calls to an operation authenticate
+// with, the Handler verifies the credentials with an Authenticator and the
+// client sends its credentials for the first scheme it has
+const authExtension = "x-duh-auth"
+
+// authSchemes are the schemes of x-duh-auth and the consts of the generated
+// code naming them: a bearer token in the Authorization header, an API key
+// in the X-API-Key header and a client certificate verified by mTLS
+var authSchemes = map[string]string{
+	"bearer": "AuthBearer",
+	"apikey": "AuthAPIKey",
+	"mtls":   "AuthMTLS",
+}
+
+// duhAuth returns the x-duh-auth of the operation as the Go expression of
+// its schemes, such as AuthBearer, AuthAPIKey, or "" when it has none
+func duhAuth(op *v3.Operation, path string) (string, error) {
+	if op.Extensions == nil {
+		return "", nil
+	}
+	node, ok := op.Extensions.Get(authExtension)
+	if !ok || node == nil {
+		return "", nil
+	}
+
+	invalid := fmt.Errorf("%s on path %s must be a list of bearer, apikey or mtls, e.g. [bearer, apikey]", authExtension, path)
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return "", invalid
+	}
+	var schemes []string
+	for _, item := range node.Content {
+		name, known := authSchemes[item.Value]
+		if item.Kind != yaml.ScalarNode || !known {
+			return "", invalid
+		}
+		if !slices.Contains(schemes, name) {
+			schemes = append(schemes, name)
+		}
+	}
+	return strings.Join(schemes, ", "), nil
+}
@@ -0,0 +1,105 @@
+package duh_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/require"
+)
+
+// largeSpec returns a compliant spec with subjects*len(methods) operations,
+// each with its own request and response schema
+func largeSpec(subjects int) string {
+	methods := []string{"create", "get", "update", "delete", "archive", "restore", "find", "export", "import", "clone",
+		"merge", "split", "lock", "unlock", "approve", "reject", "publish", "retract", "verify", "tally"}
+
+	var paths, schemas strings.Builder
+	for i := 0; i < subjects; i++ {
+		subject := fmt.Sprintf("widget%c%cs", 'a'+i/26, 'a'+i%26)
+		for _, method := range methods {
+			name := strings.ToUpper(subject[:1]) + subject[1:] + strings.ToUpper(method[:1]) + method[1:]
+			_, _ = fmt.Fprintf(&paths, `  /%s.%s:
+    post:
+      description: %s %s
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/%sRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/%sResponse'
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+`, subject, method, method, subject, name, name)
+
+			for _, suffix := range []string{"Request", "Response"} {
+				_, _ = fmt.Fprintf(&schemas, `    %s%s:
+      type: object
+      description: %s %s
+      properties:
+        id:
+          type: string
+          description: The id
+        count:
+          type: integer
+          format: int32
+          description: The count
+`, name, suffix, method, subject)
+			}
+		}
+	}
+
+	return `openapi: 3.0.3
+info:
+  title: Large API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+` + paths.String() + `components:
+  schemas:
+    Error:
+      type: object
+      description: An error
+      required: [code, message]
+      properties:
+        code:
+          type: integer
+          format: int32
+          description: The code
+        message:
+          type: string
+          description: The message
+` + schemas.String()
+}
+
+// BenchmarkGenerateLargeSpec generates the code for a spec with 1,000 operations
+func BenchmarkGenerateLargeSpec(b *testing.B) {
+	dir := b.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(b, os.WriteFile(specPath, []byte(largeSpec(50)), 0644))
+	require.NoError(b, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/example/large\n"), 0644))
+
+	b.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(b, os.Chdir(dir))
+
+	for b.Loop() {
+		var stdout bytes.Buffer
+		require.Equal(b, duh.ExitOK, duh.RunCmd(&stdout, &stdout, []string{"generate", specPath}))
+	}
+}
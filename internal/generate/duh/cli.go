@@ -0,0 +1,170 @@
+package duh
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+var nonCommandChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// reservedFlags are used by the generated CLI itself, properties with these
+// names can only be set with --data
+var reservedFlags = map[string]bool{"data": true, "base-url": true, "token": true, "header": true, "help": true}
+
+// CLISubject groups the commands of a subject, e.g. 'examplectl users create'
+type CLISubject struct {
+	Name string
+	// Var is the Go variable holding the subject command, e.g. userGroupsCmd
+	Var      string
+	Commands []CLICommand
+}
+
+// CLICommand is a subcommand of the generated CLI calling a single operation
+type CLICommand struct {
+	Operation
+	Name  string
+	Flags []CLIFlag
+}
+
+// CLIFlag sets a top level scalar field of the request. Nested fields are set with --data.
+type CLIFlag struct {
+	Name     string
+	JSONName string
+	// Kind is the pflag type: String, Int64, Float64, Bool or StringSlice
+	Kind  string
+	Usage string
+}
+
+// cliName returns the name of the generated CLI, e.g. github.com/acme/billing → billingctl
+func cliName(modulePath string) string {
+	name := nonCommandChars.ReplaceAllString(strings.ToLower(path.Base(modulePath)), "")
+	if name == "" {
+		name = "api"
+	}
+	return name + "ctl"
+}
+
+// cliSubjects groups the operations by subject, creating a command with a flag
+// for every top level scalar property of the request
+func (p *Parser) cliSubjects(ops []Operation) []CLISubject {
+	var subjects []CLISubject
+	index := make(map[string]int)
+
+	for _, op := range ops {
+		subject, method, err := parseSubjectMethod(op.Path)
+		if err != nil {
+			continue
+		}
+		subject = commandName(subject[strings.LastIndex(subject, "/")+1:])
+
+		i, ok := index[subject]
+		if !ok {
+			i = len(subjects)
+			index[subject] = i
+			camel := ToCamelCase(subject)
+			subjects = append(subjects, CLISubject{
+				Name: subject,
+				Var:  strings.ToLower(camel[:1]) + camel[1:] + "Cmd",
+			})
+		}
+
+		requestSchema, _, err := p.getSchemas(op.Path)
+		if err != nil {
+			continue
+		}
+
+		subjects[i].Commands = append(subjects[i].Commands, CLICommand{
+			Operation: op,
+			Name:      commandName(method),
+			Flags:     cliFlags(requestSchema),
+		})
+	}
+	return subjects
+}
+
+func cliFlags(schema *base.SchemaProxy) []CLIFlag {
+	if schema == nil || schema.Schema() == nil || schema.Schema().Properties == nil {
+		return nil
+	}
+
+	var flags []CLIFlag
+	for pair := orderedmap.First(schema.Schema().Properties); pair != nil; pair = pair.Next() {
+		prop := pair.Value().Schema()
+		if prop == nil {
+			continue
+		}
+
+		kind := flagKind(prop)
+		if kind == "" || reservedFlags[commandName(pair.Key())] {
+			continue
+		}
+
+		usage := strings.Join(strings.Fields(prop.Description), " ")
+		if usage == "" {
+			usage = "Sets " + pair.Key() + " in the request"
+		}
+		if len(prop.Enum) > 0 {
+			values := make([]string, 0, len(prop.Enum))
+			for _, v := range prop.Enum {
+				values = append(values, v.Value)
+			}
+			usage += " (one of " + strings.Join(values, ", ") + ")"
+		}
+
+		flags = append(flags, CLIFlag{
+			Name:     commandName(pair.Key()),
+			JSONName: pair.Key(),
+			Kind:     kind,
+			Usage:    usage,
+		})
+	}
+	return flags
+}
+
+// flagKind returns the pflag type for a scalar or string array property, or
+// an empty string for properties that can only be set with --data
+func flagKind(schema *base.Schema) string {
+	if len(schema.Type) == 0 {
+		return ""
+	}
+
+	switch schema.Type[0] {
+	case "string":
+		return "String"
+	case "integer":
+		return "Int64"
+	case "number":
+		return "Float64"
+	case "boolean":
+		return "Bool"
+	case "array":
+		if schema.Items != nil && schema.Items.IsA() {
+			if items := schema.Items.A.Schema(); items != nil && len(items.Type) > 0 && items.Type[0] == "string" {
+				return "StringSlice"
+			}
+		}
+	}
+	return ""
+}
+
+// commandName converts a subject, method or property name to kebab-case
+func commandName(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r += 'a' - 'A'
+		}
+		if r == '_' {
+			r = '-'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
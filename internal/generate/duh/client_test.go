@@ -1,9 +1,11 @@
 package duh_test
 
 import (
+	"bytes"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	duh "github.com/duh-rpc/duh-cli"
@@ -15,7 +17,7 @@ func TestGeneratedClientCompiles(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	protoDir := filepath.Join(tempDir, "proto/v1")
@@ -103,7 +105,7 @@ func TestClientIteratorIntegration(t *testing.T) {
 	specPath, stdout := setupTest(t, specWithListOp)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	clientContent, err := os.ReadFile(filepath.Join(tempDir, "client.go"))
@@ -252,7 +254,7 @@ func TestClientWithoutIterator(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	clientContent, err := os.ReadFile(filepath.Join(tempDir, "client.go"))
@@ -351,7 +353,7 @@ func TestClientStructure(t *testing.T) {
 	specPath, stdout := setupTest(t, multiOpSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
 	assert.Contains(t, stdout.String(), "client.go")
@@ -371,12 +373,18 @@ func TestClientStructure(t *testing.T) {
 	assert.Contains(t, content, "UsersUpdate(ctx context.Context")
 	assert.Contains(t, content, "Close(ctx context.Context) error")
 
-	assert.Contains(t, content, "MaxConnsPerHost:     2_000")
-	assert.Contains(t, content, "MaxIdleConns:        2_000")
-	assert.Contains(t, content, "MaxIdleConnsPerHost: 2_000")
-	assert.Contains(t, content, "MaxConnsPerHost:     5_000")
-	assert.Contains(t, content, "MaxIdleConns:        5_000")
-	assert.Contains(t, content, "MaxIdleConnsPerHost: 5_000")
+	assert.Contains(t, content, "type TransportConfig struct")
+	assert.Contains(t, content, "func NewTransport(conf TransportConfig, tlsConf *tls.Config) *http.Transport")
+	assert.Contains(t, content, "set.Default(&conf.MaxConnsPerHost, 5_000)")
+	assert.Contains(t, content, "DialContext:         (&net.Dialer{Timeout: conf.DialTimeout}).DialContext,")
+	assert.Contains(t, content, "TLSHandshakeTimeout: conf.TLSHandshakeTimeout,")
+	assert.Contains(t, content, "t.Protocols.SetUnencryptedHTTP2(true)")
+	assert.Contains(t, content, "Transport: NewTransport(conf.Transport, conf.TLS),")
+	assert.Contains(t, content, "Transport: TransportConfig{MaxConnsPerHost: 2_000},")
+
+	assert.Contains(t, content, "r.Header.Set(HeaderAPIVersion, APIVersion)")
+	// server.go declares the versions
+	assert.NotContains(t, content, "const HeaderAPIVersion")
 
 	assert.Contains(t, content, "func WithTLS")
 	assert.Contains(t, content, "func WithNoTLS")
@@ -384,3 +392,163 @@ func TestClientStructure(t *testing.T) {
 	assert.Contains(t, content, "Code generated by 'duh generate'")
 	assert.Contains(t, content, "DO NOT EDIT")
 }
+
+func TestClientHeaders(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", tempDir, "--connect"})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err := os.ReadFile(filepath.Join(tempDir, "client.go"))
+	require.NoError(t, err)
+
+	content := string(clientContent)
+	assert.Contains(t, content, "func NewClient(conf ClientConfig, opts ...ClientOption) (*Client, error) {")
+	assert.Contains(t, content, "func WithHeaders(headers map[string]string) ClientOption {")
+	assert.Contains(t, content, "func WithUserAgent(userAgent string) ClientOption {")
+	assert.Contains(t, content, "func WithHeader(key, value string) CallOption {")
+	assert.Contains(t, content, "\tUsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse, opts ...CallOption) error\n")
+	assert.Contains(t, content, "func (c *Client) UsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse, opts ...CallOption) error {")
+	// The headers of the protocol win over those of the caller
+	assert.Contains(t, content, "\tsetHeaders(r.Header, c.conf, opts)\n\tr.Header.Set(\"Content-Type\", contentType)")
+
+	connectContent, err := os.ReadFile(filepath.Join(tempDir, "connect_client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(connectContent), "func (c *ConnectClient) UsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse, opts ...CallOption) error {")
+	assert.Contains(t, string(connectContent), "setHeaders(cr.Header(), c.conf, opts)")
+}
+
+func TestClientNetworkOptions(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err := os.ReadFile(filepath.Join(tempDir, "client.go"))
+	require.NoError(t, err)
+
+	content := string(clientContent)
+	assert.Contains(t, content, "func WithProxy(proxyURL *url.URL) ClientOption {")
+	assert.Contains(t, content, "c.Transport.Proxy = http.ProxyURL(proxyURL)")
+	assert.Contains(t, content, "func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {")
+	assert.Contains(t, content, "func WithRootCAs(pool *x509.CertPool) ClientOption {")
+	assert.Contains(t, content, "c.TLS = c.TLS.Clone()")
+	assert.Contains(t, content, "Proxy:               conf.Proxy,")
+	assert.Contains(t, content, "if conf.DialContext != nil {\n\t\tt.DialContext = conf.DialContext\n\t}")
+}
+
+func TestClientCache(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-cache-ttl: 90s\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	content := string(clientContent)
+	assert.Contains(t, content, "Set(ctx context.Context, key string, reply []byte, ttl clock.Duration)")
+	assert.Contains(t, content, "func WithCache(cache Cache) ClientOption {")
+	assert.Contains(t, content, "entryKey := cacheKey(RPCUsersGet, req)\n\tif c.cached(ctx, entryKey, resp) {\n\t\treturn nil\n\t}")
+	assert.Contains(t, content, "c.store(ctx, entryKey, resp, 90*clock.Second)")
+	assert.Equal(t, 1, strings.Count(content, "entryKey := cacheKey("))
+}
+
+func TestClientWithoutCache(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(clientContent), "Cache")
+	assert.NotContains(t, string(clientContent), `"crypto/sha256"`)
+}
+
+func TestClientCacheInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-cache-ttl: forever\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-cache-ttl 'forever' on path /users.get must be a duration such as 30s or 5m")
+}
+
+func TestConnectClientGeneration(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", tempDir, "--connect"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "connect_client.go")
+
+	connectContent, err := os.ReadFile(filepath.Join(tempDir, "connect_client.go"))
+	require.NoError(t, err)
+
+	content := string(connectContent)
+	assert.Contains(t, content, "Code generated by 'duh generate --connect'")
+	assert.Contains(t, content, "\"connectrpc.com/connect\"")
+	assert.Contains(t, content, "ConnectRPCUsersCreate = \"/duh.api.v1.UsersService/Create\"")
+	assert.Contains(t, content, "var _ ClientInterface = (*ConnectClient)(nil)")
+	assert.Contains(t, content, "func NewConnectClient(conf ClientConfig, opts ...connect.ClientOption)")
+	assert.Contains(t, content, "func (c *ConnectClient) UsersGet(ctx context.Context")
+
+	protoContent, err := os.ReadFile(filepath.Join(tempDir, "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "service UsersService {")
+	assert.Contains(t, string(protoContent), "rpc Create(")
+
+	bufGenContent, err := os.ReadFile(filepath.Join(tempDir, "buf.gen.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(bufGenContent), "buf.build/connectrpc/go")
+}
+
+func TestConnectClientNotGeneratedByDefault(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", tempDir})
+	require.Equal(t, 0, exitCode)
+
+	assert.NoFileExists(t, filepath.Join(tempDir, "connect_client.go"))
+	bufGenContent, err := os.ReadFile(filepath.Join(tempDir, "buf.gen.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(bufGenContent), "connectrpc")
+}
+
+func TestCLIGeneration(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", tempDir, "--cli"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "cmd/testctl/main.go")
+
+	cliContent, err := os.ReadFile(filepath.Join(tempDir, "cmd/testctl/main.go"))
+	require.NoError(t, err)
+
+	content := string(cliContent)
+	assert.Contains(t, content, "Code generated by 'duh generate --cli'")
+	assert.Contains(t, content, "package main")
+	assert.Contains(t, content, "Use:          \"testctl\",")
+	assert.Contains(t, content, "envOr(\"TESTCTL_BASE_URL\", \"http://localhost:8080\")")
+	assert.Contains(t, content, "usersCmd := &cobra.Command{Use: \"users\", Short: \"Operations on users\"}")
+	assert.Contains(t, content, "Use:   \"update\",\n\t\tShort: \"Update a user\",")
+	assert.Contains(t, content, "{name: \"name\", json: \"name\", kind: \"String\", usage: \"Sets name in the request\"},")
+	assert.Contains(t, content, "return &resp, c.UsersUpdate(ctx, &req, &resp)")
+}
+
+func TestCLINotGeneratedByDefault(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", tempDir})
+	require.Equal(t, 0, exitCode)
+
+	assert.NoDirExists(t, filepath.Join(tempDir, "cmd"))
+}
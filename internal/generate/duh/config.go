@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/token"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -16,6 +17,14 @@ type Config struct {
 	ProtoPath    string
 	ProtoImport  string
 	ProtoPackage string
+	// ModulePath overrides the module path otherwise read from go.mod
+	ModulePath string
+	// PathNames names methods after their paths even when operations have an operationId
+	PathNames bool
+	// ProtoOut is the directory the Go code of the proto files is written to
+	ProtoOut string
+	// moduleDir is the root of the module containing OutputDir, set by DetectModulePath
+	moduleDir string
 }
 
 func NewConfig(packageName, outputDir, protoPath, protoImport, protoPackage string) (*Config, error) {
@@ -60,12 +69,50 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// DetectModulePath returns ModulePath when set, otherwise the path of the module
+// containing the output directory, found by walking up from it to the nearest
+// go.mod. When the module is part of a go.work workspace it must be one the
+// workspace uses.
 func (c *Config) DetectModulePath() (string, error) {
-	data, err := os.ReadFile("go.mod")
+	if c.ModulePath != "" {
+		if !strings.Contains(c.ModulePath, "/") {
+			return "", fmt.Errorf("invalid module path: must contain '/': %s", c.ModulePath)
+		}
+		return c.ModulePath, nil
+	}
+
+	outputDir, err := filepath.Abs(c.OutputDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to read go.mod: %w", err)
 	}
 
+	for dir := outputDir; ; dir = filepath.Dir(dir) {
+		goMod := filepath.Join(dir, "go.mod")
+		if data, err := os.ReadFile(goMod); err == nil {
+			modulePath, err := parseModulePath(data, goMod)
+			if err != nil {
+				return "", err
+			}
+			if err := checkWorkspace(dir, modulePath); err != nil {
+				return "", err
+			}
+			c.moduleDir = dir
+			return modulePath, nil
+		}
+
+		// A go.work is the top of a workspace, the modules it uses are below it
+		goWork := filepath.Join(dir, "go.work")
+		if _, err := os.Stat(goWork); err == nil {
+			return "", fmt.Errorf("failed to read go.mod: %s is not inside a module of %s", c.OutputDir, goWork)
+		}
+
+		if filepath.Dir(dir) == dir {
+			return "", fmt.Errorf("failed to read go.mod: not found in %s or any parent directory", c.OutputDir)
+		}
+	}
+}
+
+func parseModulePath(data []byte, goMod string) (string, error) {
 	scanner := bufio.NewScanner(strings.NewReader(string(data)))
 	moduleRegex := regexp.MustCompile(`^module\s+(.+)$`)
 
@@ -76,6 +123,7 @@ func (c *Config) DetectModulePath() (string, error) {
 			if idx := strings.Index(modulePath, "//"); idx != -1 {
 				modulePath = strings.TrimSpace(modulePath[:idx])
 			}
+			modulePath = strings.Trim(modulePath, `"`)
 			if !strings.Contains(modulePath, "/") {
 				return "", fmt.Errorf("invalid module path: must contain '/': %s", modulePath)
 			}
@@ -83,18 +131,128 @@ func (c *Config) DetectModulePath() (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("module declaration not found in go.mod")
+	return "", fmt.Errorf("module declaration not found in %s", goMod)
+}
+
+// checkWorkspace returns an error when the module is below a go.work which does
+// not use it, since the generated imports would not resolve. GOWORK selects the
+// go.work file or disables workspaces the same way it does for the go command.
+func checkWorkspace(moduleDir, modulePath string) error {
+	goWork := os.Getenv("GOWORK")
+	if goWork == "off" {
+		return nil
+	}
+	if goWork == "" {
+		for dir := moduleDir; ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+				goWork = filepath.Join(dir, "go.work")
+				break
+			}
+			if filepath.Dir(dir) == dir {
+				return nil
+			}
+		}
+	}
+
+	data, err := os.ReadFile(goWork)
+	if err != nil {
+		return fmt.Errorf("failed to read go.work: %w", err)
+	}
+
+	for _, use := range parseWorkUses(data) {
+		if !filepath.IsAbs(use) {
+			use = filepath.Join(filepath.Dir(goWork), use)
+		}
+		if filepath.Clean(use) == moduleDir {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("module %s is not used by %s; add it with 'go work use %s'", modulePath, goWork, moduleDir)
+}
+
+// parseWorkUses returns the directories of the use directives of a go.work file,
+// in both the single line and the block form
+func parseWorkUses(data []byte) []string {
+	var uses []string
+	inBlock := false
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			uses = append(uses, strings.Trim(line, `"`))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			uses = append(uses, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+
+	return uses
+}
+
+// CheckLayout returns an error when the Go code of the proto files would be
+// generated into the output package itself, or outside the module, since the
+// pb import would not resolve. DetectModulePath must be called first, the
+// module of ModulePath has no directory to check against. Nothing is checked
+// when ProtoImport names where the Go code of the proto files is.
+func (c *Config) CheckLayout() error {
+	if c.ProtoImport != "" {
+		return nil
+	}
+	if filepath.IsAbs(c.ProtoPath) {
+		return fmt.Errorf("--proto-path %s must be relative to the output directory", c.ProtoPath)
+	}
+	goDir := filepath.Join(c.OutputDir, c.ProtoOut, filepath.Dir(c.ProtoPath))
+	if filepath.Clean(goDir) == filepath.Clean(c.OutputDir) {
+		return fmt.Errorf("the Go code of --proto-path %s would be generated in %s, the directory of the %s package itself; "+
+			"use a subdirectory like proto/v1/api.proto", c.ProtoPath, goDir, c.PackageName)
+	}
+	if _, inside := c.moduleRel(goDir); c.moduleDir != "" && !inside {
+		return fmt.Errorf("the Go code of --proto-path %s would be generated in %s, outside the module in %s; "+
+			"set --proto-import to its import path", c.ProtoPath, goDir, c.moduleDir)
+	}
+	return nil
+}
+
+// moduleRel returns dir relative to the root of the module and whether dir is
+// inside the module
+func (c *Config) moduleRel(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir, false
+	}
+	rel, err := filepath.Rel(c.moduleDir, abs)
+	if err != nil {
+		return dir, false
+	}
+	return rel, rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
 func (c *Config) ConstructProtoImport(modulePath string) string {
 	if c.ProtoImport != "" {
 		return c.ProtoImport
 	}
-	return filepath.Join(c.ConstructPackageImport(modulePath), filepath.Dir(c.ProtoPath))
+	return path.Join(c.ConstructPackageImport(modulePath), filepath.ToSlash(c.ProtoOut), filepath.ToSlash(filepath.Dir(c.ProtoPath)))
 }
 
+// ConstructPackageImport returns the import path of the output directory, relative
+// to the module found by DetectModulePath
 func (c *Config) ConstructPackageImport(modulePath string) string {
-	return filepath.Join(modulePath, c.OutputDir)
+	dir := c.OutputDir
+	if c.moduleDir != "" {
+		dir, _ = c.moduleRel(c.OutputDir)
+	}
+	return path.Join(modulePath, filepath.ToSlash(dir))
 }
 
 func (c *Config) DeriveProtoPackage() string {
@@ -150,7 +150,7 @@ func setupTest(t *testing.T, spec string) (string, *bytes.Buffer) {
 func TestGenerateDuhParsesSimpleSpec(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -160,7 +160,7 @@ func TestGenerateDuhParsesSimpleSpec(t *testing.T) {
 func TestGenerateDuhParsesListOperation(t *testing.T) {
 	specPath, stdout := setupTest(t, specWithListOp)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -171,7 +171,7 @@ func TestGenerateDuhWithCustomPackage(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath, "-p", "myapi"})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "-p", "myapi"})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -184,7 +184,7 @@ func TestGenerateDuhWithCustomPackage(t *testing.T) {
 func TestGenerateDuhRejectsMainPackage(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath, "-p", "main"})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "-p", "main"})
 
 	require.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "package name cannot be 'main'")
@@ -193,7 +193,7 @@ func TestGenerateDuhRejectsMainPackage(t *testing.T) {
 func TestGenerateDuhRejectsInvalidPackage(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath, "-p", "my-api"})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "-p", "my-api"})
 
 	require.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "invalid package name")
@@ -208,7 +208,7 @@ func TestGenerateDuhDetectsModulePath(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(simpleValidSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -226,8 +226,165 @@ func TestGenerateDuhMissingGoMod(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(simpleValidSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 
 	require.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "failed to read go.mod")
 }
+
+func TestGenerateDuhDetectsModulePathInParentDirectory(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "services", "billing"), 0755))
+	require.NoError(t, os.Chdir(filepath.Join(tempDir, "services")))
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", "billing"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "services", "billing", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/example/test/services/billing/proto/v1"`)
+}
+
+func TestGenerateDuhDetectsNestedModule(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+	billingDir := filepath.Join(tempDir, "services", "billing")
+	require.NoError(t, os.MkdirAll(filepath.Join(billingDir, "api"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(billingDir, "go.mod"), []byte("module github.com/example/billing\n"), 0644))
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", "services/billing/api"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(billingDir, "api", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/example/billing/api/proto/v1"`)
+}
+
+func TestGenerateDuhWorkspace(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	var stderr bytes.Buffer
+	tempDir := filepath.Dir(specPath)
+	require.NoError(t, os.Remove(filepath.Join(tempDir, "go.mod")))
+	for _, name := range []string{"billing", "users"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(tempDir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, name, "go.mod"), []byte("module github.com/example/"+name+"\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "go.work"), []byte("go 1.24\n\nuse (\n\t./billing // the billing service\n)\n"), 0644))
+
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath, "--output-dir", "billing"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "billing", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/example/billing/proto/v1"`)
+
+	for _, test := range []struct {
+		name      string
+		outputDir string
+		wantErr   string
+	}{
+		{
+			name:      "ModuleNotUsed",
+			outputDir: "users",
+			wantErr:   "module github.com/example/users is not used by " + filepath.Join(tempDir, "go.work"),
+		},
+		{
+			name:      "OutsideModules",
+			outputDir: ".",
+			wantErr:   "failed to read go.mod: . is not inside a module of " + filepath.Join(tempDir, "go.work"),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			stdout.Reset()
+			exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath, "--output-dir", test.outputDir})
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+func TestGenerateDuhOutputSubdirectory(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+	require.NoError(t, os.Mkdir(filepath.Join(tempDir, "api"), 0755))
+
+	// The proto files are shared by the module, next to the api package
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--full",
+		"--output-dir", "api", "--proto-path", "../proto/v1/api.proto"})
+	require.Equal(t, 0, exitCode)
+	assert.FileExists(t, filepath.Join(tempDir, "proto", "v1", "api.proto"))
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "api", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/example/test/proto/v1"`)
+
+	testContent, err := os.ReadFile(filepath.Join(tempDir, "api", "api_test.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(testContent), "\t\"github.com/example/test/api\"\n")
+}
+
+func TestGenerateDuhLayoutErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "ProtoInPackage",
+			args:    []string{"--output-dir", "api", "--proto-path", "api.proto"},
+			wantErr: "the Go code of --proto-path api.proto would be generated in api, the directory of the api package itself; use a subdirectory like proto/v1/api.proto",
+		},
+		{
+			name:    "ProtoOutInPackage",
+			args:    []string{"--output-dir", "api", "--proto-path", "api/api.proto", "--proto-out", ".."},
+			wantErr: "the Go code of --proto-path api/api.proto would be generated in api, the directory of the api package itself",
+		},
+		{
+			name:    "ProtoOutsideModule",
+			args:    []string{"--output-dir", "api", "--proto-path", "../../proto/api.proto"},
+			wantErr: "the Go code of --proto-path ../../proto/api.proto would be generated in ../proto, outside the module in ",
+		},
+		{
+			name:    "AbsoluteProtoPath",
+			args:    []string{"--proto-path", "/proto/v1/api.proto"},
+			wantErr: "--proto-path /proto/v1/api.proto must be relative to the output directory",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, simpleValidSpec)
+			require.NoError(t, os.Mkdir(filepath.Join(filepath.Dir(specPath), "api"), 0755))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"generate", specPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestGenerateDuhModuleFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.Chdir(tempDir))
+
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(simpleValidSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath, "--module", "github.com/example/scratch"})
+
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/example/scratch/proto/v1"`)
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"generate", specPath, "--module", "scratch"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "invalid module path: must contain '/': scratch")
+}
@@ -1,22 +1,59 @@
 package duh
 
 import (
-	conv "github.com/duh-rpc/openapi-proto.go"
+	"path/filepath"
+
+	"github.com/duh-rpc/duh-cli/internal/proto"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
+const protoLockFile = "proto.lock"
+
 type ProtoConverter interface {
-	Convert(openapi []byte, packageName, packagePath string) ([]byte, error)
+	// Convert returns the proto files for the spec. protoPath is the path of
+	// the single proto file relative to the proto root; when splitting by
+	// subject the files are written to its directory instead. spec is the
+	// parsed openapi, or nil to have the converter parse it.
+	Convert(openapi []byte, spec *v3.Document, packageName, packagePath, protoPath string, lock *proto.Lock) ([]proto.File, error)
+}
+
+// ProtoOptions controls how OpenAPI schemas are mapped to proto definitions
+type ProtoOptions struct {
+	// EnumsAsStrings keeps enum properties as strings instead of generating proto enums
+	EnumsAsStrings bool
+	// SplitBySubject writes a proto file per subject plus a common.proto instead of a single file
+	SplitBySubject bool
+	// Services adds a gRPC service definition mirroring the DUH operations
+	Services bool
 }
 
-func NewProtoConverter() ProtoConverter {
-	return &realProtoConverter{}
+func NewProtoConverter(opts ProtoOptions) ProtoConverter {
+	return &realProtoConverter{opts: opts}
 }
 
-type realProtoConverter struct{}
+type realProtoConverter struct {
+	opts ProtoOptions
+}
+
+func (r *realProtoConverter) Convert(openapi []byte, spec *v3.Document, packageName, packagePath, protoPath string, lock *proto.Lock) ([]proto.File, error) {
+	opts := proto.Options{
+		EnumsAsStrings: r.opts.EnumsAsStrings,
+		PackageName:    packageName,
+		PackagePath:    packagePath,
+		Lock:           lock,
+		Services:       r.opts.Services,
+		Dir:            filepath.ToSlash(filepath.Dir(protoPath)),
+		Document:       spec,
+		SkipOperation:  skipsProto,
+	}
+
+	if r.opts.SplitBySubject {
+		return proto.ConvertBySubject(openapi, opts)
+	}
 
-func (r *realProtoConverter) Convert(openapi []byte, packageName, packagePath string) ([]byte, error) {
-	return conv.Convert(openapi, conv.ConvertOptions{
-		PackageName: packageName,
-		PackagePath: packagePath,
-	})
+	content, err := proto.Convert(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []proto.File{{Path: protoPath, Content: content}}, nil
 }
@@ -0,0 +1,198 @@
+package duh
+
+import (
+	"bytes"
+)
+
+// maxDiffCells bounds the table of diffLines, lines of larger changes are
+// all treated as changed
+const maxDiffCells = 1 << 24
+
+// merge3 merges the edits of yours and of generated, both made to base, line
+// by line. A chunk changed the same way on both sides, or on one side only,
+// is merged; a chunk changed differently on each side is a conflict, written
+// between <<<<<<< and >>>>>>> markers with yours first.
+func merge3(base, yours, generated []byte) ([]byte, int) {
+	o, a, b := splitLines(base), splitLines(yours), splitLines(generated)
+	matchA, matchB := diffLines(o, a), diffLines(o, b)
+
+	var out bytes.Buffer
+	var conflicts int
+	i, ja, jb := 0, 0, 0
+	for i < len(o) || ja < len(a) || jb < len(b) {
+		if i < len(o) && matchA[i] == ja && matchB[i] == jb {
+			out.Write(o[i])
+			i, ja, jb = i+1, ja+1, jb+1
+			continue
+		}
+
+		// The chunk ends at the next base line kept on both sides
+		k := i
+		for k < len(o) && (matchA[k] < 0 || matchB[k] < 0) {
+			k++
+		}
+		endA, endB := len(a), len(b)
+		if k < len(o) {
+			endA, endB = matchA[k], matchB[k]
+		}
+		chunkO, chunkA, chunkB := o[i:k], a[ja:endA], b[jb:endB]
+
+		switch {
+		case sameLines(chunkA, chunkO):
+			writeLines(&out, chunkB)
+		case sameLines(chunkB, chunkO), sameLines(chunkA, chunkB):
+			writeLines(&out, chunkA)
+		default:
+			conflicts++
+			out.WriteString("<<<<<<< yours\n")
+			writeLines(&out, terminated(chunkA))
+			out.WriteString("=======\n")
+			writeLines(&out, terminated(chunkB))
+			out.WriteString(">>>>>>> generated\n")
+		}
+		i, ja, jb = k, endA, endB
+	}
+	return out.Bytes(), conflicts
+}
+
+// diffLines returns, for each line of o, the index of the line of a it is
+// kept as in their longest common subsequence, or -1 when it was changed
+func diffLines(o, a [][]byte) []int {
+	match := make([]int, len(o))
+	for i := range match {
+		match[i] = -1
+	}
+
+	// The common prefix and suffix are matched without the table
+	start := 0
+	for start < len(o) && start < len(a) && bytes.Equal(o[start], a[start]) {
+		match[start] = start
+		start++
+	}
+	endO, endA := len(o), len(a)
+	for endO > start && endA > start && bytes.Equal(o[endO-1], a[endA-1]) {
+		endO, endA = endO-1, endA-1
+		match[endO] = endA
+	}
+
+	n, m := endO-start, endA-start
+	if n == 0 || m == 0 || (n+1)*(m+1) > maxDiffCells {
+		return match
+	}
+	// lcs[i][j] is the length of the common subsequence of o[start+i:endO]
+	// and a[start+j:endA]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if bytes.Equal(o[start+i], a[start+j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case bytes.Equal(o[start+i], a[start+j]):
+			match[start+i] = start + j
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	// Added and removed lines are moved as late as the same lines allow, so
+	// a method added after another ends after the closing brace of the other
+	// instead of taking it over
+	slideDown(match, o)
+	added := make([]int, len(a))
+	for j := range added {
+		added[j] = -1
+	}
+	for i, j := range match {
+		if j >= 0 {
+			added[j] = i
+		}
+	}
+	slideDown(added, a)
+	for i := range match {
+		match[i] = -1
+	}
+	for j, i := range added {
+		if i >= 0 {
+			match[i] = j
+		}
+	}
+	return match
+}
+
+// slideDown moves each run of lines of x without a match, between two lines
+// matched to consecutive lines, down while the line after the run equals its
+// first line
+func slideDown(match []int, x [][]byte) {
+	for s := 0; s < len(match); s++ {
+		if match[s] >= 0 {
+			continue
+		}
+		e := s
+		for e < len(match) && match[e] < 0 {
+			e++
+		}
+		prev := -1
+		if s > 0 {
+			prev = match[s-1]
+		}
+		for e < len(match) && match[e] == prev+1 && bytes.Equal(x[s], x[e]) {
+			match[s], match[e] = match[e], -1
+			prev, s, e = prev+1, s+1, e+1
+		}
+		s = e - 1
+	}
+}
+
+// splitLines splits content after each newline, the last line may have none
+func splitLines(content []byte) [][]byte {
+	var lines [][]byte
+	for len(content) > 0 {
+		n := bytes.IndexByte(content, '\n') + 1
+		if n == 0 {
+			n = len(content)
+		}
+		lines = append(lines, content[:n])
+		content = content[n:]
+	}
+	return lines
+}
+
+func sameLines(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(w *bytes.Buffer, lines [][]byte) {
+	for _, line := range lines {
+		w.Write(line)
+	}
+}
+
+// terminated ends the last of lines with a newline, so the marker after it
+// starts a line of its own
+func terminated(lines [][]byte) [][]byte {
+	if len(lines) == 0 || bytes.HasSuffix(lines[len(lines)-1], []byte("\n")) {
+		return lines
+	}
+	last := append(bytes.Clone(lines[len(lines)-1]), '\n')
+	return append(lines[:len(lines)-1:len(lines)-1], last)
+}
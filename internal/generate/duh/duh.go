@@ -1,171 +1,862 @@
 package duh
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/duh-rpc/duh-cli/internal/audience"
 	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/duh-rpc/duh-cli/internal/proto"
+	"github.com/duh-rpc/duh-cli/internal/remote"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
 func Run(config RunConfig) error {
+	defer config.Progress.Done()
+	// Generation is short lived and most of what it allocates stays reachable
+	// until the files are written, so unless GOGC says otherwise the collector
+	// waits for the heap to reach generationHeap instead of running each time
+	// it doubles, which cost a third of the run on large specs
+	if os.Getenv("GOGC") == "" {
+		defer debug.SetMemoryLimit(debug.SetMemoryLimit(min(debug.SetMemoryLimit(-1), generationHeap)))
+		defer debug.SetGCPercent(debug.SetGCPercent(-1))
+	}
+	if config.ClientOnlyFlag && (config.FullFlag || config.DockerFlag || config.K8sFlag || config.CI != "" ||
+		config.ServeSpecFlag || config.IntrospectFlag) {
+		return errors.New("--client-only cannot be combined with --full, --docker, --k8s, --ci, --serve-spec or --introspect")
+	}
+	if config.Force && (config.SkipExisting || config.Interactive) {
+		return errors.New("--force cannot be combined with --skip-existing or --interactive")
+	}
+	if config.SkipExisting && config.Interactive {
+		return errors.New("--skip-existing cannot be combined with --interactive")
+	}
+	if config.K8sFlag {
+		config.DockerFlag = true
+	}
+	if config.DockerFlag {
+		config.FullFlag = true
+	}
+	if config.CI != "" {
+		if _, ok := ciWorkflows[config.CI]; !ok {
+			return fmt.Errorf("unknown --ci value '%s': must be github or gitlab", config.CI)
+		}
+		config.FullFlag = true
+	}
+	if config.UpdateService && (config.FullFlag || config.ClientOnlyFlag) {
+		return errors.New("--update-service cannot be combined with --full, which merges the regenerated code into service.go, or --client-only")
+	}
+	if config.ProtoTool != "" && config.ProtoTool != "buf" && config.ProtoTool != "protoc" {
+		return fmt.Errorf("unknown --proto-tool value '%s': must be buf or protoc", config.ProtoTool)
+	}
+	for _, target := range config.LintTargets {
+		if !slices.Contains(lintTargets, target) {
+			return fmt.Errorf("unknown --lint-targets value '%s': must be spec, proto or vet", target)
+		}
+	}
+	if config.BufModule != "" && !bufModuleName.MatchString(config.BufModule) {
+		return fmt.Errorf("invalid --buf-module value '%s': must be remote/owner/module like buf.build/acme/api", config.BufModule)
+	}
+	for _, dep := range config.BufDeps {
+		if !bufDepName.MatchString(dep) {
+			return fmt.Errorf("invalid --buf-dep value '%s': must be remote/owner/module[:label]", dep)
+		}
+	}
+	if (config.BufModule != "" || len(config.BufDeps) > 0) && config.ProtoTool == "protoc" {
+		return errors.New("--buf-module and --buf-dep configure buf.yaml, which --proto-tool protoc does not generate")
+	}
+	if config.BasePath != "" && !basePathRegex.MatchString(config.BasePath) {
+		return fmt.Errorf("invalid --base-path value '%s': must start with / and not end with one, like /api", config.BasePath)
+	}
+	if err := audience.Check(config.Audience); err != nil {
+		return err
+	}
+	if config.Checksum != "" && !remote.ValidChecksum(config.Checksum) {
+		return fmt.Errorf("invalid --checksum value '%s': must be sha256:<hex>", config.Checksum)
+	}
+
+	if len(config.MergeSpecs) > 0 {
+		file, err := writeMergedSpec(config)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.RemoveAll(filepath.Dir(file)) }()
+		_, _ = fmt.Fprintf(config.Writer, "✓ Merged %d specs\n", len(config.MergeSpecs)+1)
+		config.SpecPath = file
+	} else if remote.IsURL(config.SpecPath) {
+		// The Makefile and CI workflow regenerate the code from a spec in the project
+		if config.FullFlag {
+			return fmt.Errorf("--full needs a local spec file, download %s first", config.SpecPath)
+		}
+		start := time.Now()
+		config.Progress.Step("fetch", 1, 1, config.SpecPath)
+		file, checksum, err := remote.Fetch(config.SpecPath, config.Checksum)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.RemoveAll(filepath.Dir(file)) }()
+		config.Log.Phase("fetch", start)
+		_, _ = fmt.Fprintf(config.Writer, "✓ Fetched %s (%s)\n", config.SpecPath, checksum)
+		config.SpecPath = file
+	} else if config.Checksum != "" {
+		return errors.New("--checksum verifies a spec URL, not a file")
+	}
+
+	start := time.Now()
+	config.Progress.Step("parse", 1, 1, config.SpecPath)
 	spec, err := lint.Load(config.SpecPath)
 	if err != nil {
 		return err
 	}
+	config.Log.Parsed(config.SpecPath, start)
+
+	specContent, err := os.ReadFile(config.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	// The operations hidden from the audience are left out of the spec, so
+	// nothing generated, not even the spec of --serve-spec, has them. The
+	// whole spec is linted, so the lines of the violations are its own.
+	filtered := spec
+	if config.Audience != "" {
+		if specContent, err = audience.Filter(specContent, config.Audience); err != nil {
+			return err
+		}
+		if filtered, err = lint.Parse(specContent); err != nil {
+			return err
+		}
+	}
+
+	if hasVersionedPaths(filtered) {
+		// The Handler of handler.go routes by the first element of the path
+		if basePath, err := specBasePath(spec); config.BasePath != "" || basePath != "" || err != nil {
+			return errors.New("--base-path and x-duh-base-path are not supported for specs with versioned paths")
+		}
+		versions, err := splitVersions(specContent)
+		if err != nil {
+			return err
+		}
+		return runVersions(config, versions)
+	}
 
 	result := lint.Validate(spec, config.SpecPath, nil)
+	config.Log.Rules(result)
 	if !result.Valid() {
-		return fmt.Errorf("OpenAPI validation failed")
+		config.Output.AddViolations(config.SpecPath, result.Violations)
+		if len(config.MergeSpecs) > 0 {
+			// The merged spec only exists in memory and cannot be linted
+			lint.Print(config.Writer, result)
+		}
+		return lint.ErrValidation
 	}
 
-	isFullTemplate := IsInitTemplateSpec(spec)
-
-	genConfig, err := NewConfig(config.PackageName, config.OutputDir, config.ProtoPath, config.ProtoImport, config.ProtoPackage)
+	filesGenerated, changes, err := generate(config, filtered, specContent)
 	if err != nil {
 		return err
 	}
 
-	parser := NewParser(spec, genConfig, isFullTemplate)
-	data, err := parser.Parse()
-	if err != nil {
-		return err
+	printGenerated(config, filesGenerated)
+	printChanges(config, changes)
+
+	_, _ = fmt.Fprintf(config.Writer, "\nNext steps:\n")
+	_, _ = fmt.Fprintf(config.Writer, "  1. Run '%s' to generate Go code from proto files\n", protoCommand(config))
+	_, _ = fmt.Fprintf(config.Writer, "  2. Run 'go mod tidy' to update dependencies\n")
+	return nil
+}
+
+func printGenerated(config RunConfig, filesGenerated []string) {
+	_, _ = fmt.Fprintf(config.Writer, "✓ Generated %d file(s) in %s\n", len(filesGenerated), config.OutputDir)
+	for _, file := range filesGenerated {
+		_, _ = fmt.Fprintf(config.Writer, "  - %s\n", file)
+		config.Output.AddFiles(filepath.Join(config.OutputDir, file))
 	}
+}
 
-	generator, err := NewGenerator()
+// printChanges summarizes what the generation changed since the previous run,
+// which the first run has none of
+func printChanges(config RunConfig, changes output.Changes) {
+	config.Output.AddChanges(changes)
+	if changes.FirstRun {
+		return
+	}
+	_, _ = fmt.Fprintf(config.Writer, "\nChanges since the last run:\n")
+	_, _ = fmt.Fprintf(config.Writer, "  files: %d added, %d updated, %d unchanged\n",
+		len(changes.FilesAdded), len(changes.FilesUpdated), len(changes.FilesUnchanged))
+	for _, op := range changes.OperationsAdded {
+		_, _ = fmt.Fprintf(config.Writer, "  + operation %s\n", op)
+	}
+	for _, op := range changes.OperationsRemoved {
+		_, _ = fmt.Fprintf(config.Writer, "  - operation %s\n", op)
+	}
+	for _, message := range changes.MessagesAdded {
+		_, _ = fmt.Fprintf(config.Writer, "  + message %s\n", message)
+	}
+}
+
+// missingKeys returns the sorted keys of m which are not in other
+func missingKeys[V any](m, other map[string]V) []string {
+	var keys []string
+	for key := range m {
+		if _, ok := other[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Parse validates the spec and returns the data the Go templates are rendered
+// from, without writing anything. Specs with versioned paths are not supported
+// since each version is generated from its own data.
+func Parse(config RunConfig) (*TemplateData, error) {
+	spec, err := lint.Load(config.SpecPath)
 	if err != nil {
-		return fmt.Errorf("failed to create generator: %w", err)
+		return nil, err
+	}
+	if hasVersionedPaths(spec) {
+		return nil, fmt.Errorf("%s has versioned paths, which are generated from one TemplateData per version", config.SpecPath)
 	}
 
-	serverCode, err := generator.RenderServer(data)
+	result := lint.Validate(spec, config.SpecPath, nil)
+	if !result.Valid() {
+		return nil, lint.ErrValidation
+	}
+
+	specContent, err := os.ReadFile(config.SpecPath)
 	if err != nil {
-		return fmt.Errorf("failed to render server.go: %w", err)
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
 	}
 
-	serverPath := filepath.Join(config.OutputDir, "server.go")
-	if err := writeFile(serverPath, serverCode); err != nil {
-		return fmt.Errorf("failed to write server.go: %w", err)
+	parsed, err := parse(config, spec, specContent)
+	if err != nil {
+		return nil, err
 	}
+	return parsed.data, nil
+}
 
-	filesGenerated := []string{"server.go"}
+// parsedSpec is a validated spec after the x-duh-name schema renames
+type parsedSpec struct {
+	data      *TemplateData
+	genConfig *Config
+	// spec and content are renamed, the proto converter uses them
+	spec    *v3.Document
+	content []byte
+}
 
-	clientCode, err := generator.RenderClient(data)
+// parse applies the x-duh-name schema renames and parses the template data of
+// a validated spec
+func parse(config RunConfig, spec *v3.Document, specContent []byte) (*parsedSpec, error) {
+	checksum := sha256.Sum256(specContent)
+	specContent, renamed, err := applySchemaNames(specContent)
 	if err != nil {
-		return fmt.Errorf("failed to render client.go: %w", err)
+		return nil, err
+	}
+	if renamed {
+		if spec, err = lint.Parse(specContent); err != nil {
+			return nil, err
+		}
 	}
 
-	clientPath := filepath.Join(config.OutputDir, "client.go")
-	if err := writeFile(clientPath, clientCode); err != nil {
-		return fmt.Errorf("failed to write client.go: %w", err)
+	initTemplate, isFullTemplate := MatchInitTemplate(spec)
+
+	genConfig, err := NewConfig(config.PackageName, config.OutputDir, config.ProtoPath, config.ProtoImport, config.ProtoPackage)
+	if err != nil {
+		return nil, err
 	}
+	genConfig.ModulePath = config.ModulePath
+	genConfig.PathNames = config.PathNames
+	genConfig.ProtoOut = config.ProtoOut
 
-	filesGenerated = append(filesGenerated, "client.go")
+	parser := NewParser(spec, genConfig, initTemplate, isFullTemplate)
+	data, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	data.Connect = config.ConnectFlag
+	data.Introspect = config.IntrospectFlag
+	data.ClientOnly = config.ClientOnlyFlag
+	data.SpecChecksum = "sha256:" + hex.EncodeToString(checksum[:])
+	data.Docker = config.DockerFlag
+	if config.ServeSpecFlag {
+		data.SpecFile, data.SpecContentType = specFile(config.SpecPath, config.Audience)
+	}
+	data.SpecPath = relativeSpec(config)
+	data.GenerateArgs = generateArgs(config, genConfig)
+	data.ProtoTool = cmp.Or(config.ProtoTool, "buf")
+	data.ProtoOut = filepath.ToSlash(cmp.Or(config.ProtoOut, "."))
+	data.ProtoDir = filepath.ToSlash(filepath.Dir(genConfig.ProtoPath))
+	data.LintTargets = config.LintTargets
+	data.BufModule = config.BufModule
+	data.BufDeps = config.BufDeps
+	if data.BasePath, err = specBasePath(spec); err != nil {
+		return nil, err
+	}
+	data.BasePath = cmp.Or(config.BasePath, data.BasePath)
+	if data.BufPlugins, err = bufPlugins(config); err != nil {
+		return nil, err
+	}
+	return &parsedSpec{data: data, genConfig: genConfig, spec: spec, content: specContent}, nil
+}
 
-	specContent, err := os.ReadFile(config.SpecPath)
+// generate writes the code for a validated spec, returning the files written
+// relative to config.OutputDir
+func generate(config RunConfig, spec *v3.Document, specContent []byte) ([]string, output.Changes, error) {
+	parsed, err := parse(config, spec, specContent)
 	if err != nil {
-		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+		return nil, output.Changes{}, err
 	}
+	data, genConfig := parsed.data, parsed.genConfig
 
-	protoCode, err := config.Converter.Convert(specContent, data.ProtoPackage, data.ProtoImport)
+	generator, err := NewGenerator()
 	if err != nil {
-		return fmt.Errorf("failed to convert OpenAPI to proto: %w", err)
+		return nil, output.Changes{}, fmt.Errorf("failed to create generator: %w", err)
 	}
+	defer config.Progress.Done()
 
-	protoFilePath := filepath.Join(config.OutputDir, config.ProtoPath)
-	if err := writeFile(protoFilePath, protoCode); err != nil {
-		return fmt.Errorf("failed to write proto file: %w", err)
+	// code is written before the proto files and scaffold after them, which
+	// keeps the order of the files listed in the summary
+	var code []renderStep
+	if !config.ClientOnlyFlag {
+		code = append(code, renderStep{path: "server.go", render: omit(generator.RenderServer, skipServer), inputs: specInputs})
+	}
+	code = append(code, renderStep{path: "client.go", render: omit(generator.RenderClient, skipClient), inputs: specInputs})
+	if config.ConnectFlag {
+		code = append(code, renderStep{path: "connect_client.go", render: omit(generator.RenderConnectClient, skipClient), inputs: apiInputs})
+	}
+	// go:embed cannot reach files outside the package, so the spec is copied
+	// next to server.go unless it already is that file
+	if data.SpecFile != "" && !sameFile(config.SpecPath, filepath.Join(config.OutputDir, data.SpecFile)) {
+		code = append(code, renderStep{path: data.SpecFile, inputs: specInputs, render: func(*TemplateData) ([]byte, error) {
+			return specContent, nil
+		}})
+	}
+	if (config.FullFlag || config.CLIFlag) && len(data.omitting(skipClient).CLISubjects) > 0 {
+		code = append(code, renderStep{path: filepath.Join("cmd", data.CLIName, "main.go"), render: omit(generator.RenderCLI, skipClient), inputs: apiInputs})
 	}
 
-	filesGenerated = append(filesGenerated, config.ProtoPath)
+	// Files the project may already have are not overwritten
+	var existing []renderStep
+	if data.ProtoTool == "buf" {
+		existing = append(existing,
+			renderStep{path: "buf.yaml", render: generator.RenderBufYaml},
+			renderStep{path: "buf.gen.yaml", render: generator.RenderBufGenYaml},
+		)
+	}
+	if config.FullFlag {
+		existing = append(existing,
+			renderStep{path: "README.md", render: generator.RenderReadme, inputs: apiInputs},
+			renderStep{path: ".gitignore", render: generator.RenderGitignore},
+		)
+	}
 
-	bufYamlPath := filepath.Join(config.OutputDir, "buf.yaml")
-	if _, err := os.Stat(bufYamlPath); os.IsNotExist(err) {
-		bufYamlCode, err := generator.RenderBufYaml(data)
-		if err != nil {
-			return fmt.Errorf("failed to render buf.yaml: %w", err)
+	var scaffold []renderStep
+	for _, step := range existing {
+		path := filepath.Join(config.OutputDir, step.path)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			config.Log.Skipped(path)
+			continue
 		}
-
-		if err := writeFile(bufYamlPath, bufYamlCode); err != nil {
-			return fmt.Errorf("failed to write buf.yaml: %w", err)
+		scaffold = append(scaffold, step)
+	}
+	if config.FullFlag {
+		scaffold = append(scaffold,
+			renderStep{path: "daemon.go", render: generator.RenderDaemon},
+			renderStep{path: "config.go", render: generator.RenderConfig},
+			renderStep{path: "service.go", render: omit(generator.RenderService, skipServer), inputs: apiInputs},
+		)
+		// The records the service of the init template starts with
+		if len(data.SeedRecords) > 0 {
+			scaffold = append(scaffold, renderStep{path: "seed.go", render: generator.RenderSeed, inputs: apiInputs})
+		}
+		// The tests call the operations through the client and the server
+		if len(data.omitting(skipServer, skipClient).Operations) > 0 {
+			scaffold = append(scaffold, renderStep{path: "api_test.go", render: omit(generator.RenderApiTest, skipServer, skipClient), inputs: apiInputs})
+		}
+		scaffold = append(scaffold, renderStep{path: "Makefile", render: generator.RenderMakefile})
+	}
+	if config.DockerFlag {
+		scaffold = append(scaffold,
+			renderStep{path: filepath.Join("cmd", data.DaemonName, "main.go"), render: generator.RenderDaemonMain},
+			renderStep{path: "Dockerfile", render: generator.RenderDockerfile},
+			renderStep{path: ".dockerignore", render: generator.RenderDockerignore},
+			renderStep{path: "docker-compose.yaml", render: generator.RenderCompose},
+		)
+	}
+	if config.CI != "" {
+		scaffold = append(scaffold, renderStep{path: ciWorkflows[config.CI], render: generator.RenderCI(config.CI)})
+	}
+	if config.K8sFlag {
+		for _, file := range []string{"deployment.yaml", "service.yaml", "hpa.yaml", "kustomization.yaml"} {
+			scaffold = append(scaffold, renderStep{path: filepath.Join("k8s", file), render: generator.RenderK8s(file)})
 		}
+	}
 
-		filesGenerated = append(filesGenerated, "buf.yaml")
+	protoFilePath := filepath.Join(config.OutputDir, genConfig.ProtoPath)
+	lockPath := filepath.Join(filepath.Dir(protoFilePath), protoLockFile)
+	lock, err := proto.LoadLock(lockPath)
+	if err != nil {
+		return nil, output.Changes{}, err
 	}
+	lockedMessages := maps.Clone(lock.Messages)
 
-	bufGenYamlPath := filepath.Join(config.OutputDir, "buf.gen.yaml")
-	if _, err := os.Stat(bufGenYamlPath); os.IsNotExist(err) {
-		bufGenYamlCode, err := generator.RenderBufGenYaml(data)
-		if err != nil {
-			return fmt.Errorf("failed to render buf.gen.yaml: %w", err)
-		}
+	// The proto files are converted before anything is written so a spec the
+	// converter rejects leaves the output directory untouched
+	start := time.Now()
+	config.Progress.Step("proto convert", 1, 1, config.SpecPath)
+	protoFiles, err := config.Converter.Convert(parsed.content, parsed.spec, data.ProtoPackage, data.ProtoImport, genConfig.ProtoPath, lock)
+	if err != nil {
+		return nil, output.Changes{}, fmt.Errorf("failed to convert OpenAPI to proto: %w", err)
+	}
+	config.Log.Phase("proto convert", start)
 
-		if err := writeFile(bufGenYamlPath, bufGenYamlCode); err != nil {
-			return fmt.Errorf("failed to write buf.gen.yaml: %w", err)
+	// Files whose inputs are the same as in the manifest of the last run are
+	// not rendered again, unless --force is given
+	manifestPath := filepath.Join(config.OutputDir, manifestFile)
+	changes := output.Changes{FirstRun: !fileExists(manifestPath)}
+	loaded, err := loadManifest(manifestPath)
+	if err != nil {
+		return nil, output.Changes{}, err
+	}
+	previous := loaded
+	if config.Force {
+		previous = &Manifest{}
+	}
+	manifest := newManifest(parsed.spec, data)
+	project := projectHash(data)
+	changes.OperationsAdded = missingKeys(manifest.Operations, loaded.Operations)
+	changes.OperationsRemoved = missingKeys(loaded.Operations, manifest.Operations)
+	changes.MessagesAdded = missingKeys(lock.Messages, lockedMessages)
+
+	// Each file is written as soon as it is rendered, so only one rendered
+	// file is held in memory at a time
+	var rendering, writing time.Duration
+	var filesGenerated []string
+	written := func(path string, existed bool) {
+		filesGenerated = append(filesGenerated, path)
+		if existed {
+			changes.FilesUpdated = append(changes.FilesUpdated, path)
+		} else {
+			changes.FilesAdded = append(changes.FilesAdded, path)
+		}
+	}
+	write := func(path string, content []byte) error {
+		start := time.Now()
+		existed := fileExists(filepath.Join(config.OutputDir, path))
+		if err := writeFile(filepath.Join(config.OutputDir, path), content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		writing += time.Since(start)
+		written(path, existed)
+		return nil
+	}
+	// The scaffold is the project's to edit. The regenerated code is merged
+	// into a file edited since the last run, against the code that run
+	// generated. A file without that code is kept unless --force is given or
+	// the prompt of -i is answered yes, and --skip-existing keeps every file
+	// which exists.
+	var kept, merged, conflicted []string
+	var prompt *bufio.Scanner
+	if config.Interactive {
+		prompt = bufio.NewScanner(config.Reader)
+	}
+	// keep returns whether to keep the file at path, and its content when the
+	// regenerated code is to be merged into it
+	keep := func(path string) (bool, []byte, error) {
+		file := filepath.Join(config.OutputDir, path)
+		switch {
+		case config.Force || !fileExists(file):
+			return false, nil, nil
+		case config.SkipExisting:
+			return true, nil, nil
+		case previous.unedited(file, path):
+			return false, nil, nil
+		case previous.Files[path].Base != "":
+			current, err := os.ReadFile(file)
+			if err != nil {
+				return false, nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			return false, current, nil
+		case prompt != nil:
+			overwrite, err := confirmOverwrite(prompt, config.Writer, path)
+			return !overwrite, nil, err
 		}
+		return true, nil, nil
+	}
 
-		filesGenerated = append(filesGenerated, "buf.gen.yaml")
+	var n int
+	total := len(code) + len(scaffold)
+	render := func(steps []renderStep, editable bool) error {
+		for _, step := range steps {
+			n++
+			config.Progress.Step("render", n, total, step.path)
+			inputs := manifest.fileHash(step.path, step.inputs, project, data)
+			if previous.unchanged(filepath.Join(config.OutputDir, step.path), step.path, inputs) {
+				manifest.Files[step.path] = previous.Files[step.path]
+				config.Log.Unchanged(step.path)
+				changes.FilesUnchanged = append(changes.FilesUnchanged, step.path)
+				continue
+			}
+			var edited []byte
+			if editable {
+				skip, current, err := keep(step.path)
+				if err != nil {
+					return err
+				}
+				if skip {
+					if entry, ok := previous.Files[step.path]; ok {
+						manifest.Files[step.path] = entry
+					}
+					config.Log.Skipped(step.path)
+					kept = append(kept, step.path)
+					continue
+				}
+				edited = current
+			}
+			config.Log.Rendering(step.path)
+			start := time.Now()
+			content, err := step.render(data)
+			if err != nil {
+				return fmt.Errorf("failed to render %s: %w", step.path, err)
+			}
+			rendering += time.Since(start)
+
+			entry := ManifestFile{Inputs: inputs, Content: contentHash(content)}
+			if editable {
+				entry.Base = string(content)
+			}
+			manifest.Files[step.path] = entry
+			if edited != nil {
+				result, conflicts := merge3([]byte(previous.Files[step.path].Base), edited, content)
+				if bytes.Equal(result, edited) {
+					config.Log.Skipped(step.path)
+					continue
+				}
+				content = result
+				if conflicts > 0 {
+					conflicted = append(conflicted, step.path)
+				} else {
+					merged = append(merged, step.path)
+				}
+			}
+			if err := write(step.path, content); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
-	if config.FullFlag {
-		daemonCode, err := generator.RenderDaemon(data)
-		if err != nil {
-			return fmt.Errorf("failed to render daemon.go: %w", err)
+	if err := render(code, false); err != nil {
+		return nil, output.Changes{}, err
+	}
+	// The proto files have no timestamp, so they are compared with the files
+	// on disk instead, and the lock follows the files it records
+	lockExisted := fileExists(lockPath)
+	protoChanged := config.Force || !lockExisted
+	for _, file := range protoFiles {
+		current, err := os.ReadFile(filepath.Join(config.OutputDir, file.Path))
+		if err == nil && bytes.Equal(current, file.Content) && !config.Force {
+			config.Log.Unchanged(file.Path)
+			changes.FilesUnchanged = append(changes.FilesUnchanged, file.Path)
+			continue
+		}
+		protoChanged = true
+		if err := write(file.Path, file.Content); err != nil {
+			return nil, output.Changes{}, err
+		}
+	}
+	// The lock is listed after the proto files it records
+	if protoChanged {
+		start = time.Now()
+		if err := lock.Save(lockPath); err != nil {
+			return nil, output.Changes{}, err
+		}
+		writing += time.Since(start)
+		written(filepath.Join(filepath.Dir(genConfig.ProtoPath), protoLockFile), lockExisted)
+	}
+	if err := render(scaffold, true); err != nil {
+		return nil, output.Changes{}, err
+	}
+	var update serviceUpdate
+	if config.UpdateService {
+		if update, err = updateService(config, generator, data.omitting(skipServer)); err != nil {
+			return nil, output.Changes{}, err
 		}
+		if len(update.Added) > 0 {
+			written("service.go", true)
+		}
+	}
+	// Handlers of operations removed from the spec are dead code left behind
+	// in service.go
+	var stale []string
+	if !config.ClientOnlyFlag {
+		stale = staleHandlers(filepath.Join(config.OutputDir, "service.go"), data.omitting(skipServer))
+	}
+	// The files of a run with other flags, such as the scaffold of --full,
+	// keep their entries for the next run with those flags
+	for path, entry := range loaded.Files {
+		if _, ok := manifest.Files[path]; !ok {
+			manifest.Files[path] = entry
+		}
+	}
+	config.Log.Spent("render", rendering)
+	config.Log.Spent("write", writing)
 
-		daemonPath := filepath.Join(config.OutputDir, "daemon.go")
-		if err := writeFile(daemonPath, daemonCode); err != nil {
-			return fmt.Errorf("failed to write daemon.go: %w", err)
+	if err := manifest.save(manifestPath); err != nil {
+		return nil, output.Changes{}, err
+	}
+	if unchanged := len(changes.FilesUnchanged); unchanged > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Skipped %d file(s) unchanged since the last run (--force regenerates them)\n", unchanged)
+	}
+	if len(merged) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Merged the regenerated code into %d edited file(s)\n", len(merged))
+		for _, path := range merged {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", path)
+		}
+	}
+	if len(conflicted) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✗ %d edited file(s) conflict with the regenerated code, resolve the <<<<<<< markers\n", len(conflicted))
+		for _, path := range conflicted {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", path)
+		}
+	}
+	if len(update.Added) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Added %d method(s) to service.go\n", len(update.Added))
+		for _, method := range update.Added {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", method)
+		}
+	}
+	if len(update.Mismatched) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✗ %d method(s) of service.go do not match the spec\n", len(update.Mismatched))
+		for _, method := range update.Mismatched {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", method)
+		}
+	}
+	if len(stale) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✗ %d method(s) of service.go have no operation in the spec, remove them or restore their endpoints\n", len(stale))
+		for _, method := range stale {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", method)
 		}
+	}
+	if len(kept) > 0 {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Kept %d existing file(s) (--force overwrites them)\n", len(kept))
+		for _, path := range kept {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", path)
+		}
+	}
+	if config.Strict && len(stale) > 0 {
+		return nil, output.Changes{}, fmt.Errorf("--strict: service.go has %d handler(s) without an operation in the spec", len(stale))
+	}
 
-		filesGenerated = append(filesGenerated, "daemon.go")
+	return filesGenerated, changes, nil
+}
 
-		serviceCode, err := generator.RenderService(data)
-		if err != nil {
-			return fmt.Errorf("failed to render service.go: %w", err)
-		}
+// generationHeap is the heap size at which the collector runs during Run
+const generationHeap = 1 << 30
 
-		servicePath := filepath.Join(config.OutputDir, "service.go")
-		if err := writeFile(servicePath, serviceCode); err != nil {
-			return fmt.Errorf("failed to write service.go: %w", err)
-		}
+// ciWorkflows are the files the CI workflow of --ci is written to
+var ciWorkflows = map[string]string{
+	"github": filepath.Join(".github", "workflows", "duh.yaml"),
+	"gitlab": ".gitlab-ci.yml",
+}
 
-		filesGenerated = append(filesGenerated, "service.go")
+// lintTargets are the values of --lint-targets, each adding a lint-<target> to
+// the Makefile
+var lintTargets = []string{"spec", "proto", "vet"}
 
-		apiTestCode, err := generator.RenderApiTest(data)
-		if err != nil {
-			return fmt.Errorf("failed to render api_test.go: %w", err)
+// bufModuleName and bufDepName match the module names of the Buf Schema
+// Registry, deps may pin a label or commit
+var (
+	bufModuleName = regexp.MustCompile(`^[a-z0-9.-]+\.[a-z]+/[a-z0-9-]+/[a-z0-9-]+$`)
+	bufDepName    = regexp.MustCompile(`^[a-z0-9.-]+\.[a-z]+/[a-z0-9-]+/[a-z0-9-]+(:[A-Za-z0-9._-]+)?$`)
+)
+
+// bufRemotes are the remote plugins of the protoc-gen-* names --buf-plugin
+// accepts, other names without a / are local plugins found on the PATH
+var bufRemotes = map[string]string{
+	"protoc-gen-go":           "buf.build/protocolbuffers/go",
+	"protoc-gen-go-grpc":      "buf.build/grpc/go",
+	"protoc-gen-connect-go":   "buf.build/connectrpc/go",
+	"protoc-gen-validate":     "buf.build/bufbuild/validate-go",
+	"protoc-gen-grpc-gateway": "buf.build/grpc-ecosystem/gateway",
+	"protoc-gen-openapiv2":    "buf.build/grpc-ecosystem/openapiv2",
+}
+
+// bufPlugins returns the plugins of buf.gen.yaml: the Go and gRPC plugins,
+// Connect with --connect, and each --buf-plugin name[=version], which pins
+// the version of a plugin already listed or adds it
+func bufPlugins(config RunConfig) ([]BufPlugin, error) {
+	plugins := []BufPlugin{{Remote: bufRemotes["protoc-gen-go"]}, {Remote: bufRemotes["protoc-gen-go-grpc"]}}
+	if config.ConnectFlag {
+		plugins = append(plugins, BufPlugin{Remote: bufRemotes["protoc-gen-connect-go"]})
+	}
+
+	for _, flag := range config.BufPlugins {
+		name, version, _ := strings.Cut(flag, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid --buf-plugin value '%s': must be name[=version]", flag)
+		}
+		plugin := BufPlugin{Remote: bufRemotes[name]}
+		if plugin.Remote == "" && strings.Contains(name, "/") {
+			plugin.Remote = name
+		}
+		if plugin.Remote == "" {
+			if version != "" {
+				return nil, fmt.Errorf("invalid --buf-plugin value '%s': only remote plugins have a version", flag)
+			}
+			plugin.Local = name
 		}
 
-		apiTestPath := filepath.Join(config.OutputDir, "api_test.go")
-		if err := writeFile(apiTestPath, apiTestCode); err != nil {
-			return fmt.Errorf("failed to write api_test.go: %w", err)
+		i := slices.IndexFunc(plugins, func(p BufPlugin) bool {
+			return p.Remote == plugin.Remote && p.Local == plugin.Local
+		})
+		if i < 0 {
+			plugins = append(plugins, plugin)
+			i = len(plugins) - 1
 		}
+		plugins[i].Version = version
+	}
+	return plugins, nil
+}
 
-		filesGenerated = append(filesGenerated, "api_test.go")
+// protoCommand is the command the next steps suggest for the proto files
+func protoCommand(config RunConfig) string {
+	switch {
+	case config.ProtoTool != "protoc":
+		return "buf generate"
+	case config.FullFlag:
+		return "make proto"
+	}
+	return "protoc"
+}
 
-		makefileCode, err := generator.RenderMakefile(data)
-		if err != nil {
-			return fmt.Errorf("failed to render Makefile: %w", err)
-		}
+// relativeSpec returns the spec path relative to the output directory
+func relativeSpec(config RunConfig) string {
+	spec := config.SpecPath
+	if rel, err := filepath.Rel(config.OutputDir, spec); err == nil {
+		spec = rel
+	}
+	return filepath.ToSlash(spec)
+}
 
-		makefilePath := filepath.Join(config.OutputDir, "Makefile")
-		if err := writeFile(makefilePath, makefileCode); err != nil {
-			return fmt.Errorf("failed to write Makefile: %w", err)
+// generateArgs returns the flags of 'duh generate' which regenerate the code
+// without the scaffolding of --full. The flags of the proto converter are not
+// known here.
+func generateArgs(config RunConfig, genConfig *Config) string {
+	var args []string
+	if genConfig.PackageName != "api" {
+		args = append(args, "--package", genConfig.PackageName)
+	}
+	if genConfig.ProtoPath != "proto/v1/api.proto" {
+		args = append(args, "--proto-path", genConfig.ProtoPath)
+	}
+	if genConfig.ProtoImport != "" {
+		args = append(args, "--proto-import", genConfig.ProtoImport)
+	}
+	if genConfig.ProtoPackage != "" {
+		args = append(args, "--proto-package", genConfig.ProtoPackage)
+	}
+	if config.ProtoTool == "protoc" {
+		args = append(args, "--proto-tool", "protoc")
+	}
+	if config.ProtoOut != "" && config.ProtoOut != "." {
+		args = append(args, "--proto-out", filepath.ToSlash(config.ProtoOut))
+	}
+	if config.BasePath != "" {
+		args = append(args, "--base-path", config.BasePath)
+	}
+	if config.Audience != "" {
+		args = append(args, "--audience", config.Audience)
+	}
+	for _, flag := range []struct {
+		name string
+		set  bool
+	}{
+		{"--path-names", config.PathNames},
+		{"--connect", config.ConnectFlag},
+		{"--cli", config.CLIFlag || config.FullFlag},
+		{"--serve-spec", config.ServeSpecFlag},
+		{"--introspect", config.IntrospectFlag},
+	} {
+		if flag.set {
+			args = append(args, flag.name)
 		}
+	}
+	return strings.Join(args, " ")
+}
 
-		filesGenerated = append(filesGenerated, "Makefile")
+// specFile returns the name the spec is embedded as and the Content-Type it is
+// served with. The spec filtered for an audience, which is yaml, is named
+// after it so it never overwrites the whole spec next to server.go.
+func specFile(specPath, audience string) (string, string) {
+	switch {
+	case audience != "":
+		return "openapi." + audience + ".yaml", "application/yaml"
+	case strings.EqualFold(filepath.Ext(specPath), ".json"):
+		return "openapi.json", "application/json"
 	}
+	return "openapi.yaml", "application/yaml"
+}
 
-	_, _ = fmt.Fprintf(config.Writer, "✓ Generated %d file(s) in %s\n", len(filesGenerated), config.OutputDir)
-	for _, file := range filesGenerated {
-		_, _ = fmt.Fprintf(config.Writer, "  - %s\n", file)
+// confirmOverwrite asks whether to overwrite a file edited since the last run,
+// a blank answer keeps it
+func confirmOverwrite(prompt *bufio.Scanner, w io.Writer, path string) (bool, error) {
+	for {
+		_, _ = fmt.Fprintf(w, "%s was edited since it was generated, overwrite it? (y/N): ", path)
+		if !prompt.Scan() {
+			if err := prompt.Err(); err != nil {
+				return false, fmt.Errorf("failed to read input: %w", err)
+			}
+			return false, errors.New("unexpected end of input")
+		}
+		switch strings.ToLower(strings.TrimSpace(prompt.Text())) {
+		case "", "n", "no":
+			return false, nil
+		case "y", "yes":
+			return true, nil
+		}
+		_, _ = fmt.Fprintln(w, "  Please answer y or n")
 	}
+}
 
-	_, _ = fmt.Fprintf(config.Writer, "\nNext steps:\n")
-	_, _ = fmt.Fprintf(config.Writer, "  1. Run 'buf generate' to generate Go code from proto files\n")
-	_, _ = fmt.Fprintf(config.Writer, "  2. Run 'go mod tidy' to update dependencies\n")
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
-	return nil
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(aInfo, bInfo)
+}
+
+// renderStep renders one file from the template data, path being relative to
+// the output directory
+type renderStep struct {
+	path   string
+	render func(*TemplateData) ([]byte, error)
+	// inputs are what the file depends on, it is rendered again when they change
+	inputs fileInputs
 }
 
 func writeFile(path string, content []byte) error {
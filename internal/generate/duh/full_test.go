@@ -2,6 +2,7 @@ package duh_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -40,10 +41,10 @@ func TestGenerateDuhWithFullFlagAndInitSpec(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 9 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 15 file(s)")
 
 	_, err = os.Stat("buf.yaml")
 	require.NoError(t, err)
@@ -74,10 +75,59 @@ func TestGenerateDuhWithFullFlagAndInitSpec(t *testing.T) {
 	assert.Contains(t, string(apiTestContent), "func TestUsersGet(t *testing.T)")
 	assert.Contains(t, string(apiTestContent), "func TestUsersList(t *testing.T)")
 	assert.Contains(t, string(apiTestContent), "func TestUsersUpdate(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "func TestShutdownOnSignal(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "func BenchmarkUsersGet(b *testing.B)")
+	assert.Contains(t, string(apiTestContent), "r := httptest.NewRequest(http.MethodPost, api.RPCUsersGet, bytes.NewReader(body))")
 
 	daemonContent, err := os.ReadFile("daemon.go")
 	require.NoError(t, err)
-	assert.Contains(t, string(daemonContent), "YOU CAN EDIT")
+	daemonStr := string(daemonContent)
+	assert.Contains(t, daemonStr, "YOU CAN EDIT")
+	assert.Contains(t, daemonStr, "Config\n\tServiceConfig ServiceConfig")
+	assert.Contains(t, daemonStr, `"require-and-verify": tls.RequireAndVerifyClientCert,`)
+	assert.Contains(t, daemonStr, "return certs.config(clientAuth), nil")
+	assert.Contains(t, daemonStr, "func (c *certReloader) reload() error")
+	assert.Contains(t, daemonStr, "PreStop func(ctx context.Context) error")
+	assert.Contains(t, daemonStr, "func SystemdListener() (net.Listener, error)")
+	assert.Contains(t, daemonStr, "func (r *requests) drain(ctx context.Context) error")
+	assert.Contains(t, daemonStr, "duh.ReplyWithCode(w, r, duh.CodeRetryRequest, nil, \"service is shutting down\")")
+
+	configContent, err := os.ReadFile("config.go")
+	require.NoError(t, err)
+	configStr := string(configContent)
+	assert.Contains(t, configStr, "YOU CAN EDIT")
+	assert.Contains(t, configStr, "func LoadConfig(args []string) (Config, error)")
+	assert.Contains(t, configStr, "TLS      TLSConfig `yaml:\"tls\"`")
+	assert.Contains(t, configStr, "CertFile string `yaml:\"cert_file\"`")
+	assert.Contains(t, configStr, `const EnvPrefix = "EXAMPLE_"`)
+
+	assert.Contains(t, configStr, "Seed bool `yaml:\"seed\"`")
+	assert.Contains(t, daemonStr, "d.conf.ServiceConfig.Seed = true")
+	assert.Contains(t, string(serviceContent), "if conf.Seed {\n\t\tif err := s.seed(); err != nil {")
+
+	seedContent, err := os.ReadFile("seed.go")
+	require.NoError(t, err)
+	seedStr := string(seedContent)
+	assert.Contains(t, seedStr, "YOU CAN EDIT")
+	assert.Contains(t, seedStr, "s.records[r.UserId] = &r")
+	assert.Contains(t, seedStr, `"name":"Alan Turing","email":"alan.turing1@example.com"`)
+
+	// The records differ, so they do not collide in the service
+	ids := make(map[string]bool)
+	for _, line := range strings.Split(seedStr, "\n") {
+		record, ok := strings.CutPrefix(strings.TrimSpace(line), "`")
+		if !ok {
+			continue
+		}
+		var user struct {
+			UserID string `json:"user_id"`
+			Email  string `json:"email"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimSuffix(record, "`,")), &user))
+		assert.False(t, ids[user.UserID] || ids[user.Email], user.Email)
+		ids[user.UserID], ids[user.Email] = true, true
+	}
+	assert.Len(t, ids, 20)
 
 	makefileContent, err := os.ReadFile("Makefile")
 	require.NoError(t, err)
@@ -117,20 +167,61 @@ func TestGenerateDuhWithFullFlagAndCustomSpec(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 9 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 14 file(s)")
 
 	serviceContent, err := os.ReadFile("service.go")
 	require.NoError(t, err)
-	assert.Contains(t, string(serviceContent), "func (s *Service) ProductsCreate")
+	assert.Contains(t, string(serviceContent), "func (s *Service) CreateProduct")
 	assert.Contains(t, string(serviceContent), "CodeNotImplemented")
+	assert.NotContains(t, string(serviceContent), "Seed bool")
+
+	// Only the service of the init template has records to seed
+	_, err = os.Stat("seed.go")
+	assert.True(t, os.IsNotExist(err))
 
 	apiTestContent, err := os.ReadFile("api_test.go")
 	require.NoError(t, err)
 	assert.Contains(t, string(apiTestContent), "TODO")
-	assert.Contains(t, string(apiTestContent), "func TestProductsCreate(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "func TestShutdownOnSignal(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "func TestCreateProduct(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), `require.NoError(t, protojson.Unmarshal([]byte("{\"name\":\"string\"}"), &req))`)
+}
+
+func TestGenerateDuhWithFullFlagAndInitSubject(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", "--subject", "line-items", "--version", "v2"})
+	require.Equal(t, 0, exitCode)
+
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"})
+	require.Equal(t, 0, exitCode)
+
+	serviceContent, err := os.ReadFile("service.go")
+	require.NoError(t, err)
+	serviceStr := string(serviceContent)
+	assert.Contains(t, serviceStr, "func (s *Service) CreateLineItem")
+	assert.Contains(t, serviceStr, "s.records[req.LineItemId]")
+	assert.Contains(t, serviceStr, `"line item not found"`)
+	assert.Contains(t, serviceStr, `"line_item_id is required"`)
+	assert.NotContains(t, serviceStr, "CodeNotImplemented")
+
+	apiTestContent, err := os.ReadFile("api_test.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(apiTestContent), "func TestCreateLineItem(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "func TestUpdateLineItemNotFound(t *testing.T)")
+	assert.Contains(t, string(apiTestContent), "LineItemId: created.LineItemId,")
 }
 
 func TestGenerateDuhWithoutFullFlag(t *testing.T) {
@@ -150,10 +241,10 @@ func TestGenerateDuhWithoutFullFlag(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 5 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 6 file(s)")
 
 	_, err = os.Stat("buf.yaml")
 	require.NoError(t, err)
@@ -171,6 +262,256 @@ func TestGenerateDuhWithoutFullFlag(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestGenerateDuhWithFullFlagWritesReadme(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/users-service\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"}))
+
+	readme, err := os.ReadFile("README.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(readme), "| `POST /users.create` |")
+	assert.Contains(t, string(readme), "curl -X POST http://localhost:8080/users.create")
+	assert.Contains(t, string(readme), "go run ./cmd/users-servicectl --base-url http://localhost:8080 users create")
+	assert.Contains(t, string(readme), "`github.com/klauspost/compress` for\nthe zstd compression of `server.go`")
+
+	gitignore, err := os.ReadFile(".gitignore")
+	require.NoError(t, err)
+	assert.Contains(t, string(gitignore), "*.test")
+
+	// A README and .gitignore of the project are kept, even with --force
+	require.NoError(t, os.WriteFile("README.md", []byte("# Users\n"), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full", "--force"}))
+	assert.Contains(t, stdout.String(), "Generated 10 file(s)")
+
+	readme, err = os.ReadFile("README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Users\n", string(readme))
+}
+
+func TestGenerateDuhWithDockerFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/users-service\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--docker"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Generated 18 file(s)")
+
+	_, err := os.Stat("daemon.go")
+	require.NoError(t, err)
+	_, err = os.Stat(".dockerignore")
+	require.NoError(t, err)
+
+	mainContent, err := os.ReadFile(filepath.Join("cmd", "users-serviced", "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mainContent), "conf, err := api.LoadConfig(args)")
+	assert.Contains(t, string(mainContent), "return api.Run(context.Background(), daemon, &scaffold.Options{Log: log})")
+
+	dockerfile, err := os.ReadFile("Dockerfile")
+	require.NoError(t, err)
+	assert.Contains(t, string(dockerfile), "go build -trimpath -ldflags=\"-s -w\" -o /out/users-serviced ./cmd/users-serviced")
+	assert.Contains(t, string(dockerfile), `ENTRYPOINT ["/users-serviced"]`)
+
+	compose, err := os.ReadFile("docker-compose.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(compose), "  users-serviced:\n    build: .")
+	assert.Contains(t, string(compose), "USERS_SERVICE_LOG_LEVEL: info")
+}
+
+func TestGenerateDuhWithCIFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--ci", "github", "--serve-spec"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), filepath.Join(".github", "workflows", "duh.yaml"))
+
+	_, err := os.Stat("service.go")
+	require.NoError(t, err)
+
+	workflow, err := os.ReadFile(filepath.Join(".github", "workflows", "duh.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(workflow), "run: duh lint openapi.yaml")
+	assert.Contains(t, string(workflow), "          make regen\n")
+	assert.Contains(t, string(workflow), "git diff --exit-code -I '^// Code generated by'")
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "regen:\n\tduh generate openapi.yaml --cli --serve-spec\n\t$(MAKE) proto\n")
+
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--ci", "gitlab", "-p", "users"})
+	require.Equal(t, 0, exitCode)
+
+	workflow, err = os.ReadFile(".gitlab-ci.yml")
+	require.NoError(t, err)
+	assert.Contains(t, string(workflow), "- make regen\n")
+	assert.Contains(t, string(workflow), "- go test ./...")
+
+	makefile, err = os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "\tduh generate openapi.yaml --package users --cli\n")
+}
+
+func TestGenerateDuhWithProtocMakefile(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full",
+		"--proto-tool", "protoc", "--proto-out", "gen", "--lint-targets", "spec,vet"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Run 'make proto' to generate Go code from proto files")
+
+	_, err := os.Stat("buf.yaml")
+	require.Error(t, err)
+	_, err = os.Stat("buf.gen.yaml")
+	require.Error(t, err)
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "--go_out=gen --go_opt=paths=source_relative")
+	assert.Contains(t, string(makefile), "$(wildcard proto/v1/*.proto)")
+	assert.Contains(t, string(makefile), "\tduh generate openapi.yaml --proto-tool protoc --proto-out gen --cli\n")
+	assert.Contains(t, string(makefile), "lint: lint-spec lint-vet\n")
+	assert.Contains(t, string(makefile), "lint-spec:\n\tduh lint openapi.yaml\n")
+	assert.NotContains(t, string(makefile), "lint-proto:")
+
+	serverContent, err := os.ReadFile("server.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `pb "github.com/test/example/gen/proto/v1"`)
+
+	protoContent, err := os.ReadFile(filepath.Join("proto", "v1", "api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), `option go_package = "github.com/test/example/gen/proto/v1";`)
+}
+
+func TestGenerateDuhWithInvalidMakefileFlags(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "ProtoTool",
+			args:    []string{"--proto-tool", "make"},
+			wantErr: "unknown --proto-tool value 'make': must be buf or protoc",
+		},
+		{
+			name:    "LintTarget",
+			args:    []string{"--full", "--lint-targets", "spec,fmt"},
+			wantErr: "unknown --lint-targets value 'fmt': must be spec, proto or vet",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"generate", specPath, "--module", "github.com/test/example"}, test.args...)
+			require.Equal(t, 2, duh.RunCmd(&stdout, &stderr, args))
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestGenerateDuhWithUnknownCI(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath, "--ci", "jenkins", "--module", "github.com/test/example"})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "unknown --ci value 'jenkins': must be github or gitlab")
+	assert.Empty(t, stdout.String())
+}
+
+func TestGenerateDuhWithK8sFlag(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/users-service\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--k8s"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "Generated 22 file(s)")
+
+	_, err := os.Stat("Dockerfile")
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join("k8s", "service.yaml"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join("k8s", "hpa.yaml"))
+	require.NoError(t, err)
+
+	deployment, err := os.ReadFile(filepath.Join("k8s", "deployment.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(deployment), "  name: users-service\n")
+	assert.Contains(t, string(deployment), "image: users-serviced")
+	assert.Contains(t, string(deployment), "readinessProbe:\n            httpGet:\n              path: /readyz")
+	assert.Contains(t, string(deployment), "- name: USERS_SERVICE_SHUTDOWN_GRACE_PERIOD")
+
+	kustomization, err := os.ReadFile(filepath.Join("k8s", "kustomization.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(kustomization), "newName: registry.example.com/users-serviced")
+}
+
 func TestRegenerateWithFullFlagOverwrites(t *testing.T) {
 	tempDir := t.TempDir()
 	specPath := filepath.Join(tempDir, "openapi.yaml")
@@ -188,21 +529,200 @@ func TestRegenerateWithFullFlagOverwrites(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 	require.Equal(t, 0, exitCode)
 
 	const customContent = "// MY CUSTOM EDIT"
-	err = os.WriteFile("service.go", []byte(customContent), 0644)
+	generated, err := os.ReadFile("service.go")
+	require.NoError(t, err)
+	err = os.WriteFile("service.go", append(generated, customContent...), 0644)
 	require.NoError(t, err)
 
+	// An edited scaffold file keeps the edit
 	var stdout2 bytes.Buffer
-	exitCode = duh.RunCmd(&stdout2, args)
+	exitCode = duh.RunCmd(&stdout2, &stdout2, args)
 	require.Equal(t, 0, exitCode)
 
 	serviceContent, err := os.ReadFile("service.go")
 	require.NoError(t, err)
+	assert.Contains(t, string(serviceContent), customContent)
+
+	var stdout3 bytes.Buffer
+	exitCode = duh.RunCmd(&stdout3, &stdout3, append(args, "--force"))
+	require.Equal(t, 0, exitCode)
+
+	serviceContent, err = os.ReadFile("service.go")
+	require.NoError(t, err)
 	assert.NotContains(t, string(serviceContent), customContent)
-	assert.Contains(t, string(serviceContent), "func (s *Service) UsersCreate")
+	assert.Contains(t, string(serviceContent), "func (s *Service) CreateUser")
+}
+
+func TestRegenerateWithFullFlagSkipExisting(t *testing.T) {
+	specPath, stdout := setupTest(t, initTemplateSpec)
+	args := []string{"generate", specPath, "--full"}
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+
+	// Without the manifest every scaffold file counts as edited
+	require.NoError(t, os.Remove(filepath.Join(filepath.Dir(specPath), ".duh-manifest.yaml")))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, append(args, "--skip-existing")))
+	assert.Contains(t, stdout.String(), "  - server.go\n")
+	assert.Contains(t, stdout.String(), "✓ Kept 5 existing file(s) (--force overwrites them)\n"+
+		"  - daemon.go\n  - config.go\n  - service.go\n  - api_test.go\n  - Makefile\n")
+}
+
+func TestRegenerateWithFullFlagInteractive(t *testing.T) {
+	specPath, stdout := setupTest(t, initTemplateSpec)
+	dir := filepath.Dir(specPath)
+	args := []string{"generate", specPath, "--full", "-i"}
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+
+	const customContent = "// MY CUSTOM EDIT"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), []byte(customContent), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "daemon.go"), []byte(customContent), 0644))
+
+	// Without the manifest there is no generated code to merge the edits with
+	require.NoError(t, os.Remove(filepath.Join(dir, ".duh-manifest.yaml")))
+
+	// daemon.go is rendered before service.go
+	stdout.Reset()
+	// The answers are for daemon.go, config.go, service.go, api_test.go and the Makefile
+	withStdin(t, "maybe\ny\n\nn\n\n\n", func() {
+		require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+	})
+	assert.Contains(t, stdout.String(), "daemon.go was edited since it was generated, overwrite it? (y/N): ")
+	assert.Contains(t, stdout.String(), "  Please answer y or n")
+	assert.Contains(t, stdout.String(), "✓ Kept 4 existing file(s) (--force overwrites them)\n"+
+		"  - config.go\n  - service.go\n  - api_test.go\n  - Makefile\n")
+
+	daemon, err := os.ReadFile(filepath.Join(dir, "daemon.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(daemon), customContent)
+
+	service, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Equal(t, customContent, string(service))
+}
+
+func TestRegenerateWithFullFlagMerges(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	dir := filepath.Dir(specPath)
+	args := []string{"generate", specPath, "--full"}
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+
+	const stub = `return duh.NewServiceError(duh.CodeNotImplemented, "UsersCreate not implemented", nil, nil)`
+	const handler = "resp.Id = \"user-\" + req.Name\n\treturn nil"
+	service, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	require.Contains(t, string(service), stub)
+	service = []byte(strings.Replace(string(service), stub, handler, 1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), service, 0644))
+
+	// The spec adds /users.get and /users.update after /users.create
+	require.NoError(t, os.WriteFile(specPath, []byte(multiOpSpec), 0644))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+	assert.Contains(t, stdout.String(), "✓ Merged the regenerated code into 1 edited file(s)\n  - service.go\n")
+
+	merged, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(merged), "resp *pb.CreateResponse) error {\n\t"+handler+"\n}\n\n"+
+		"func (s *Service) UsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse) error {\n")
+	assert.Contains(t, string(merged), "func (s *Service) UsersUpdate(")
+	assert.NotContains(t, string(merged), "<<<<<<<")
+
+	// Nothing is left to merge on the next run
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+	assert.Contains(t, stdout.String(), "✓ Skipped 9 file(s) unchanged since the last run")
+	again, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(merged), string(again))
+}
+
+func TestRegenerateWithFullFlagConflicts(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	dir := filepath.Dir(specPath)
+	args := []string{"generate", specPath, "--full"}
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+
+	const stub = `return duh.NewServiceError(duh.CodeNotImplemented, "UsersCreate not implemented", nil, nil)`
+	service, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	service = []byte(strings.Replace(string(service), stub, "return nil", 1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), service, 0644))
+
+	// The edited method is renamed by the spec
+	renamed := strings.NewReplacer("/users.create", "/users.register",
+		"CreateRequest", "RegisterRequest", "CreateResponse", "RegisterResponse").Replace(simpleValidSpec)
+	require.NoError(t, os.WriteFile(specPath, []byte(renamed), 0644))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, args))
+	assert.Contains(t, stdout.String(), "✗ 1 edited file(s) conflict with the regenerated code, resolve the <<<<<<< markers\n  - service.go\n")
+
+	merged, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(merged), "<<<<<<< yours\n"+
+		"func (s *Service) UsersCreate(ctx context.Context, req *pb.CreateRequest, resp *pb.CreateResponse) error {\n"+
+		"\treturn nil\n"+
+		"=======\n"+
+		"func (s *Service) UsersRegister(ctx context.Context, req *pb.RegisterRequest, resp *pb.RegisterResponse) error {\n"+
+		"\treturn duh.NewServiceError(duh.CodeNotImplemented, \"UsersRegister not implemented\", nil, nil)\n"+
+		">>>>>>> generated\n")
+}
+
+func TestRegenerateOverwriteFlagErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "ForceSkipExisting",
+			args:    []string{"--force", "--skip-existing"},
+			wantErr: "--force cannot be combined with --skip-existing or --interactive",
+		},
+		{
+			name:    "ForceInteractive",
+			args:    []string{"--force", "-i"},
+			wantErr: "--force cannot be combined with --skip-existing or --interactive",
+		},
+		{
+			name:    "SkipExistingInteractive",
+			args:    []string{"--skip-existing", "-i"},
+			wantErr: "--skip-existing cannot be combined with --interactive",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, initTemplateSpec)
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"generate", specPath, "--full"}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+// withStdin runs fn with os.Stdin reading input
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	require.NoError(t, os.WriteFile(path, []byte(input), 0644))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	original := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = original }()
+
+	fn()
 }
 
 func TestMakefileGoesToProjectRoot(t *testing.T) {
@@ -224,7 +744,7 @@ func TestMakefileGoesToProjectRoot(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--output-dir", "api", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 	require.Equal(t, 0, exitCode)
 
 	_, err = os.Stat(filepath.Join("api", "Makefile"))
@@ -257,7 +777,7 @@ func TestFullGeneratedCodeFormat(t *testing.T) {
 
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 	require.Equal(t, 0, exitCode)
 
 	goFiles := []string{"daemon.go", "service.go", "api_test.go", "server.go", "client.go"}
@@ -301,10 +821,10 @@ func TestBufFilesNotOverwrittenWhenExist(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 3 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 4 file(s)")
 
 	bufYamlContent, err := os.ReadFile("buf.yaml")
 	require.NoError(t, err)
@@ -582,7 +1102,7 @@ func TestGenerateDuhWithFullFlagAndExtraEndpoint(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml", "--full"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
 
@@ -0,0 +1,139 @@
+package duh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+)
+
+// GatewayConfig configures 'duh generate gateway'
+type GatewayConfig struct {
+	Writer io.Writer
+	// SpecPaths are the specs of the services behind the gateway
+	SpecPaths []string
+	OutputDir string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// RunGateway writes a reverse proxy main to the output directory which routes
+// the paths of every spec to the backend of its service, checks the JSON
+// requests against the specs and reports the health of the backends. The
+// backends are read from gateway.yaml, which is written as an example unless
+// it exists.
+func RunGateway(config GatewayConfig) error {
+	data := &GatewayTemplateData{}
+	services := make(map[string]string)
+	paths := make(map[string]string)
+
+	for _, specPath := range config.SpecPaths {
+		service := gatewayService(specPath)
+		if other, taken := services[service]; taken {
+			return fmt.Errorf("%s and %s are both named %s; rename one of the specs", other, specPath, service)
+		}
+		services[service] = specPath
+
+		spec, err := lint.Load(specPath)
+		if err != nil {
+			return err
+		}
+		result := lint.Validate(spec, specPath, nil)
+		config.Log.Rules(result)
+		if !result.Valid() {
+			lint.Print(config.Writer, result)
+			return fmt.Errorf("%w for %s", lint.ErrValidation, specPath)
+		}
+
+		parser := NewParser(spec, &Config{}, InitTemplate{}, false)
+		ops, err := parser.extractOperations()
+		if err != nil {
+			return fmt.Errorf("%s: %w", specPath, err)
+		}
+
+		// Rejects an invalid x-duh-redact, the rules of the gateway mask the rest
+		if _, err := parser.redactedMessages(); err != nil {
+			return fmt.Errorf("%s: %w", specPath, err)
+		}
+
+		// The backend does not serve the operations x-duh-skip leaves out of the server
+		ops = slices.DeleteFunc(ops, func(op Operation) bool { return op.skipped(skipServer) })
+		prefix := service + "."
+		for _, op := range ops {
+			if other, taken := paths[op.Path]; taken {
+				return fmt.Errorf("path %s is served by both %s and %s", op.Path, other, service)
+			}
+			paths[op.Path] = service
+			data.Routes = append(data.Routes, GatewayRoute{
+				Path:    op.Path,
+				Service: service,
+				Schema:  prefix + strings.TrimPrefix(op.RequestType, "pb."),
+			})
+		}
+		data.Services = append(data.Services, service)
+		data.ValidationSchemas = append(data.ValidationSchemas, parser.requestValidationSchemas(ops, prefix)...)
+	}
+
+	generator, err := NewGenerator()
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	config.Log.Rendering("main.go")
+	code, err := generator.RenderGateway(data)
+	if err != nil {
+		return fmt.Errorf("failed to render main.go: %w", err)
+	}
+	if err := writeFile(filepath.Join(config.OutputDir, "main.go"), code); err != nil {
+		return fmt.Errorf("failed to write main.go: %w", err)
+	}
+	filesGenerated := []string{"main.go"}
+
+	mapping := filepath.Join(config.OutputDir, "gateway.yaml")
+	if _, err := os.Stat(mapping); errors.Is(err, os.ErrNotExist) {
+		if err := writeFile(mapping, gatewayMapping(data.Services)); err != nil {
+			return fmt.Errorf("failed to write gateway.yaml: %w", err)
+		}
+		filesGenerated = append(filesGenerated, "gateway.yaml")
+	}
+
+	printGenerated(RunConfig{Writer: config.Writer, OutputDir: config.OutputDir}, filesGenerated)
+	_, _ = fmt.Fprintf(config.Writer, "\nNext steps:\n")
+	_, _ = fmt.Fprintf(config.Writer, "  1. Set the url of each service in %s\n", mapping)
+	_, _ = fmt.Fprintf(config.Writer, "  2. Run 'go run ./%s -config %s'\n", filepath.ToSlash(config.OutputDir), filepath.ToSlash(mapping))
+	return nil
+}
+
+// gatewayService names the service of a spec after its file, or after its
+// directory when the file is the default openapi.yaml
+func gatewayService(specPath string) string {
+	base := filepath.Base(specPath)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if name == "openapi" {
+		if dir := filepath.Base(filepath.Dir(specPath)); dir != "." && dir != string(filepath.Separator) {
+			return dir
+		}
+	}
+	return name
+}
+
+// gatewayMapping is the example gateway.yaml, with a backend per service
+func gatewayMapping(services []string) []byte {
+	var b strings.Builder
+	b.WriteString("# The backend of each service behind the gateway. The health url is fetched\n")
+	b.WriteString("# by /healthz, without one the gateway only dials the url.\n")
+	b.WriteString("services:\n")
+	for i, service := range services {
+		port := 8081 + i
+		_, _ = fmt.Fprintf(&b, "  %s:\n", service)
+		_, _ = fmt.Fprintf(&b, "    url: http://localhost:%d\n", port)
+		_, _ = fmt.Fprintf(&b, "    health: http://localhost:%d/healthz\n", port)
+	}
+	return []byte(b.String())
+}
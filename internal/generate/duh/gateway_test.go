@@ -0,0 +1,95 @@
+package duh_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateGateway(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	billingPath := filepath.Join(filepath.Dir(specPath), "billing.yaml")
+	require.NoError(t, os.WriteFile(billingPath, []byte(strings.ReplaceAll(multiOpSpec, "/users.", "/billing.")), 0644))
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", "gateway", specPath, billingPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+	assert.Contains(t, stdout.String(), "✓ Generated 2 file(s) in gateway")
+
+	mainContent, err := os.ReadFile(filepath.Join("gateway", "main.go"))
+	require.NoError(t, err)
+
+	gateway := string(mainContent)
+	assert.Contains(t, gateway, "package main")
+	assert.Contains(t, gateway, `"/users.create":`)
+	assert.Contains(t, gateway, `"/billing.create":`)
+	assert.Contains(t, gateway, "var responseSchemas = map[string]*responseSchema{")
+	assert.Contains(t, gateway, "(&responseRule{ref: schema}).check(value, \"$\", &errs)")
+	assert.Contains(t, gateway, "func (g *Gateway) handleHealth(w http.ResponseWriter, r *http.Request) {")
+
+	mapping, err := os.ReadFile(filepath.Join("gateway", "gateway.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(mapping), "  billing:\n    url: http://localhost:8082\n")
+
+	// An existing gateway.yaml is kept
+	require.NoError(t, os.WriteFile(filepath.Join("gateway", "gateway.yaml"), []byte("services: {}\n"), 0644))
+	stdout.Reset()
+	exitCode = duh.RunCmd(stdout, stdout, []string{"generate", "gateway", specPath, billingPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+	assert.Contains(t, stdout.String(), "✓ Generated 1 file(s) in gateway")
+
+	mapping, err = os.ReadFile(filepath.Join("gateway", "gateway.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "services: {}\n", string(mapping))
+}
+
+func TestGenerateGatewayErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		file    string
+		spec    string
+		wantErr string
+	}{
+		{
+			name:    "DuplicatePath",
+			file:    "billing.yaml",
+			spec:    multiOpSpec,
+			wantErr: "path /users.create is served by both users and billing",
+		},
+		{
+			name:    "DuplicateService",
+			file:    filepath.Join("users", "openapi.yaml"),
+			spec:    strings.ReplaceAll(multiOpSpec, "/users.", "/billing."),
+			wantErr: "are both named users; rename one of the specs",
+		},
+		{
+			name: "InvalidRedact",
+			file: "billing.yaml",
+			spec: strings.Replace(strings.ReplaceAll(multiOpSpec, "/users.", "/billing."),
+				"    GetRequest:\n      type: object\n      properties:\n        id:\n          type: string\n",
+				"    GetRequest:\n      type: object\n      properties:\n        id:\n          type: string\n          x-duh-redact: 1\n", 1),
+			wantErr: "x-duh-redact '1' on property id of schema GetRequest must be true or false",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, multiOpSpec)
+			usersPath := filepath.Join(filepath.Dir(specPath), "users.yaml")
+			require.NoError(t, os.Rename(specPath, usersPath))
+			otherPath := filepath.Join(filepath.Dir(specPath), test.file)
+			require.NoError(t, os.MkdirAll(filepath.Dir(otherPath), 0755))
+			require.NoError(t, os.WriteFile(otherPath, []byte(test.spec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "gateway", usersPath, otherPath})
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
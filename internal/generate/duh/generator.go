@@ -3,6 +3,7 @@ package duh
 import (
 	"bytes"
 	"go/format"
+	"sync"
 	"text/template"
 	"time"
 )
@@ -12,8 +13,13 @@ type Generator struct {
 	timestamp string
 }
 
+// parseTemplates compiles the embedded templates once for every Generator
+var parseTemplates = sync.OnceValues(func() (*template.Template, error) {
+	return template.ParseFS(templateFS, "templates/*.tmpl")
+})
+
 func NewGenerator() (*Generator, error) {
-	tmpl, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	tmpl, err := parseTemplates()
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +52,28 @@ func (g *Generator) RenderClient(data *TemplateData) ([]byte, error) {
 	return g.FormatCode(buf.Bytes())
 }
 
+func (g *Generator) RenderConnectClient(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "connect_client.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
+func (g *Generator) RenderCLI(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "cli.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
 func (g *Generator) RenderDaemon(data *TemplateData) ([]byte, error) {
 	data.Timestamp = g.timestamp
 
@@ -57,6 +85,17 @@ func (g *Generator) RenderDaemon(data *TemplateData) ([]byte, error) {
 	return g.FormatCode(buf.Bytes())
 }
 
+func (g *Generator) RenderConfig(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "config.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
 func (g *Generator) RenderService(data *TemplateData) ([]byte, error) {
 	data.Timestamp = g.timestamp
 
@@ -68,6 +107,17 @@ func (g *Generator) RenderService(data *TemplateData) ([]byte, error) {
 	return g.FormatCode(buf.Bytes())
 }
 
+func (g *Generator) RenderSeed(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "seed.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
 func (g *Generator) RenderApiTest(data *TemplateData) ([]byte, error) {
 	data.Timestamp = g.timestamp
 
@@ -79,6 +129,40 @@ func (g *Generator) RenderApiTest(data *TemplateData) ([]byte, error) {
 	return g.FormatCode(buf.Bytes())
 }
 
+func (g *Generator) RenderVersions(data *VersionsTemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "versions.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
+func (g *Generator) RenderGateway(data *GatewayTemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "gateway.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
+// RenderServiceStubs renders the not implemented methods of service.go for
+// ops, unformatted since they are added to an existing file
+func (g *Generator) RenderServiceStubs(ops []Operation) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, op := range ops {
+		if err := g.templates.ExecuteTemplate(&buf, "serviceStub", op); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
 func (g *Generator) RenderMakefile(data *TemplateData) ([]byte, error) {
 	data.Timestamp = g.timestamp
 
@@ -90,6 +174,96 @@ func (g *Generator) RenderMakefile(data *TemplateData) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (g *Generator) RenderDaemonMain(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "daemon_main.go.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return g.FormatCode(buf.Bytes())
+}
+
+func (g *Generator) RenderDockerfile(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "Dockerfile.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *Generator) RenderDockerignore(data *TemplateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "dockerignore.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *Generator) RenderCompose(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "docker-compose.yaml.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RenderCI returns the renderer of the CI workflow of --ci, github or gitlab
+func (g *Generator) RenderCI(ci string) func(*TemplateData) ([]byte, error) {
+	return func(data *TemplateData) ([]byte, error) {
+		data.Timestamp = g.timestamp
+
+		var buf bytes.Buffer
+		if err := g.templates.ExecuteTemplate(&buf, "ci_"+ci+".yaml.tmpl", data); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+}
+
+// RenderK8s returns the renderer of the manifest k8s/<file> of --k8s
+func (g *Generator) RenderK8s(file string) func(*TemplateData) ([]byte, error) {
+	return func(data *TemplateData) ([]byte, error) {
+		data.Timestamp = g.timestamp
+
+		var buf bytes.Buffer
+		if err := g.templates.ExecuteTemplate(&buf, "k8s_"+file+".tmpl", data); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+}
+
+func (g *Generator) RenderReadme(data *TemplateData) ([]byte, error) {
+	data.Timestamp = g.timestamp
+
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "README.md.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (g *Generator) RenderGitignore(data *TemplateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, "gitignore.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func (g *Generator) RenderBufYaml(data *TemplateData) ([]byte, error) {
 	var buf bytes.Buffer
 	if err := g.templates.ExecuteTemplate(&buf, "buf.yaml.tmpl", data); err != nil {
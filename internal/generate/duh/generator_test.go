@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/duh-rpc/duh-cli"
@@ -27,7 +28,7 @@ func TestRenderBufGenYaml(t *testing.T) {
 
 	var err error
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml"})
 
 	require.Equal(t, 0, exitCode)
 
@@ -41,6 +42,179 @@ func TestRenderBufGenYaml(t *testing.T) {
 	assert.Contains(t, content, "paths=source_relative")
 }
 
+func TestRenderBufGenYamlWithPlugins(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml",
+		"--buf-plugin", "protoc-gen-go=v1.34.0",
+		"--buf-plugin", "protoc-gen-validate",
+		"--buf-plugin", "buf.build/bufbuild/es=v2.2.0",
+		"--buf-plugin", "protoc-gen-custom",
+	})
+	require.Equal(t, 0, exitCode)
+
+	bufGenContent, err := os.ReadFile("buf.gen.yaml")
+	require.NoError(t, err)
+
+	content := string(bufGenContent)
+	assert.Contains(t, content, "remote: buf.build/protocolbuffers/go:v1.34.0\n")
+	assert.Contains(t, content, "remote: buf.build/grpc/go\n")
+	assert.Contains(t, content, "remote: buf.build/bufbuild/validate-go\n")
+	assert.Contains(t, content, "remote: buf.build/bufbuild/es:v2.2.0\n")
+	assert.Contains(t, content, "local: protoc-gen-custom\n")
+	assert.Equal(t, 5, strings.Count(content, "paths=source_relative"))
+}
+
+func TestRenderBufGenYamlWithInvalidPlugin(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+
+	for _, test := range []struct {
+		name    string
+		plugin  string
+		wantErr string
+	}{
+		{
+			name:    "EmptyName",
+			plugin:  "=v1.0.0",
+			wantErr: "invalid --buf-plugin value '=v1.0.0': must be name[=version]",
+		},
+		{
+			name:    "LocalVersion",
+			plugin:  "protoc-gen-custom=v1.0.0",
+			wantErr: "invalid --buf-plugin value 'protoc-gen-custom=v1.0.0': only remote plugins have a version",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []string{"generate", specPath, "--module", "github.com/test/example", "--output-dir", t.TempDir(), "--buf-plugin", test.plugin}
+			require.Equal(t, 2, duh.RunCmd(&stdout, &stderr, args))
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestRenderBufYamlWithModule(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full",
+		"--buf-module", "buf.build/acme/api",
+		"--buf-dep", "buf.build/googleapis/googleapis",
+		"--buf-dep", "buf.build/bufbuild/protovalidate:v0.11.0",
+	})
+	require.Equal(t, 0, exitCode)
+
+	bufContent, err := os.ReadFile("buf.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(bufContent), `version: v2
+modules:
+  - path: .
+    name: buf.build/acme/api
+deps:
+  - buf.build/googleapis/googleapis
+  - buf.build/bufbuild/protovalidate:v0.11.0
+lint:
+`)
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), " proto push regen ")
+	assert.Contains(t, string(makefile), `# Publishes the proto files to the Buf Schema Registry as buf.build/acme/api
+push:
+	buf dep update
+	buf push
+`)
+}
+
+func TestRenderBufYamlWithoutModule(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"})
+	require.Equal(t, 0, exitCode)
+
+	bufContent, err := os.ReadFile("buf.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(bufContent), "version: v2\nlint:\n")
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.NotContains(t, string(makefile), "push")
+}
+
+func TestRenderBufYamlWithInvalidModule(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "Module",
+			args:    []string{"--buf-module", "acme/api"},
+			wantErr: "invalid --buf-module value 'acme/api': must be remote/owner/module like buf.build/acme/api",
+		},
+		{
+			name:    "Dep",
+			args:    []string{"--buf-dep", "https://buf.build/googleapis/googleapis"},
+			wantErr: "invalid --buf-dep value 'https://buf.build/googleapis/googleapis': must be remote/owner/module[:label]",
+		},
+		{
+			name:    "Protoc",
+			args:    []string{"--buf-module", "buf.build/acme/api", "--proto-tool", "protoc"},
+			wantErr: "--buf-module and --buf-dep configure buf.yaml, which --proto-tool protoc does not generate",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []string{"generate", specPath, "--module", "github.com/test/example", "--output-dir", t.TempDir()}
+			require.Equal(t, 2, duh.RunCmd(&stdout, &stderr, append(args, test.args...)))
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
 func TestBufFilesGeneratedWithoutFullFlag(t *testing.T) {
 	tempDir := t.TempDir()
 	specPath := filepath.Join(tempDir, "openapi.yaml")
@@ -57,10 +231,10 @@ func TestBufFilesGeneratedWithoutFullFlag(t *testing.T) {
 
 	var err error
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml"})
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 5 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 6 file(s)")
 
 	_, err = os.Stat("buf.yaml")
 	require.NoError(t, err)
@@ -89,10 +263,10 @@ func TestBufFilesGeneratedWithFullFlag(t *testing.T) {
 
 	var err error
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml", "--full"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"})
 
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "Generated 9 file(s)")
+	assert.Contains(t, stdout.String(), "Generated 14 file(s)")
 
 	_, err = os.Stat("buf.yaml")
 	require.NoError(t, err)
@@ -124,7 +298,7 @@ func TestMakefileWrittenToOutputDir(t *testing.T) {
 
 	var err error
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml", "--output-dir", "api", "--full"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--output-dir", "api", "--full"})
 	require.Equal(t, 0, exitCode)
 
 	_, err = os.Stat(filepath.Join("api", "Makefile"))
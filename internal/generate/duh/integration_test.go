@@ -298,11 +298,11 @@ func TestEndToEndGeneration(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
-	assert.Contains(t, stdout.String(), "5 file(s)")
+	assert.Contains(t, stdout.String(), "6 file(s)")
 
 	_, err := os.Stat(filepath.Join(tempDir, "server.go"))
 	require.NoError(t, err)
@@ -325,7 +325,7 @@ func TestGeneratedCodeCompiles(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	protoDir := filepath.Join(tempDir, "proto/v1")
@@ -502,9 +502,9 @@ func TestGeneratedCodeCompilesWithListOps(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
-	assert.Contains(t, stdout.String(), "5 file(s)")
+	assert.Contains(t, stdout.String(), "6 file(s)")
 
 	clientContent, err := os.ReadFile(filepath.Join(tempDir, "client.go"))
 	require.NoError(t, err)
@@ -530,7 +530,7 @@ func TestGeneratedCodeStructure(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
@@ -568,7 +568,7 @@ func TestProtoImportPaths(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(simpleValidSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
@@ -589,7 +589,7 @@ func TestTimestampInHeaders(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
@@ -624,7 +624,7 @@ func TestNonAtomicGeneration(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(invalidSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 
 	require.Equal(t, 2, exitCode)
 }
@@ -638,11 +638,11 @@ func TestFullPipelineWithDependencies(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(fullSpec), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath, "-p", "myapi", "--proto-path", "api/v1/service.proto"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath, "-p", "myapi", "--proto-path", "api/v1/service.proto"})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
-	assert.Contains(t, stdout.String(), "5 file(s)")
+	assert.Contains(t, stdout.String(), "6 file(s)")
 
 	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
 	require.NoError(t, err)
@@ -836,11 +836,11 @@ func TestNoListOperations(t *testing.T) {
 	require.NoError(t, os.WriteFile(specPath, []byte(specWithoutListOps), 0644))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
-	assert.Contains(t, stdout.String(), "5 file(s)")
+	assert.Contains(t, stdout.String(), "6 file(s)")
 
 	_, err := os.Stat(filepath.Join(tempDir, "server.go"))
 	require.NoError(t, err)
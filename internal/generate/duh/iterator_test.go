@@ -14,7 +14,7 @@ func TestGenerateDuhCreatesIteratorWithListOps(t *testing.T) {
 	specPath, stdout := setupTest(t, specWithListOp)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -36,7 +36,7 @@ func TestGenerateDuhSkipsIteratorWithoutListOps(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -51,7 +51,7 @@ func TestIteratorStructure(t *testing.T) {
 	specPath, stdout := setupTest(t, specWithListOp)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 
@@ -66,6 +66,13 @@ func TestIteratorStructure(t *testing.T) {
 	assert.NotContains(t, content, "GenericIterator")
 	assert.NotContains(t, content, "FetchPage")
 
+	assert.Contains(t, content, "func (c *Client) UsersListIter(first int32, opts ...CallOption) *PageIterator[*pb.User] {")
+	assert.Contains(t, content, "func (it *PageIterator[T]) WithRetry(policy retry.Policy) *PageIterator[T] {")
+	assert.Contains(t, content, "func (it *PageIterator[T]) ResumeAfter(cursor string) *PageIterator[T] {")
+	assert.Contains(t, content, "func (it *PageIterator[T]) Progress() IteratorProgress {")
+	assert.Contains(t, content, "func (it *PageIterator[T]) Err() error {")
+	assert.Contains(t, content, `"github.com/duh-rpc/duh.go/v2/retry"`)
+
 	assert.NotContains(t, content, "//go:build")
 	assert.Contains(t, content, "Code generated by 'duh generate'")
 	assert.Contains(t, content, "DO NOT EDIT")
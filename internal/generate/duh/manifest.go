@@ -0,0 +1,208 @@
+package duh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	yamlv4 "go.yaml.in/yaml/v4"
+	"gopkg.in/yaml.v3"
+)
+
+const manifestFile = ".duh-manifest.yaml"
+
+const manifestHeader = "# Generated by duh. Regenerating skips the files whose inputs are unchanged and merges the\n# regenerated code into edited scaffold files, --force rewrites them all.\n"
+
+// Manifest records the hash of every operation and schema of the spec and of
+// each file generated, so regenerating only renders the files whose inputs
+// changed and leaves the others, and their timestamps, alone
+type Manifest struct {
+	Operations map[string]string       `yaml:"operations,omitempty"`
+	Schemas    map[string]string       `yaml:"schemas,omitempty"`
+	Files      map[string]ManifestFile `yaml:"files,omitempty"`
+}
+
+// ManifestFile holds the hash of the inputs a file was rendered from and of
+// its content, a file edited since is rendered again. Base is the content of
+// a scaffold file, which the regenerated code is merged into an edited copy
+// of the file against.
+type ManifestFile struct {
+	Inputs  string `yaml:"inputs"`
+	Content string `yaml:"content"`
+	Base    string `yaml:"base,omitempty"`
+}
+
+// unchanged reports whether the file at path was rendered from the same inputs
+// by the last run and is still as it was written. A scaffold file may have been
+// edited since, the code of those inputs was merged into it.
+func (m *Manifest) unchanged(path, file, inputs string) bool {
+	entry, ok := m.Files[file]
+	switch {
+	case !ok || entry.Inputs != inputs:
+		return false
+	case entry.Base != "":
+		return fileExists(path)
+	}
+	return m.unedited(path, file)
+}
+
+// unedited reports whether the file at path is still as the last run wrote it
+func (m *Manifest) unedited(path, file string) bool {
+	entry, ok := m.Files[file]
+	if !ok {
+		return false
+	}
+	content, err := os.ReadFile(path)
+	return err == nil && contentHash(content) == entry.Content
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// fileInputs are what a file is rendered from besides the templates and the
+// options of the project, which every file depends on
+type fileInputs int
+
+const (
+	projectInputs fileInputs = iota
+	// apiInputs are the operations and schemas of the spec
+	apiInputs
+	// specInputs are the whole spec, for the files which embed its checksum
+	specInputs
+)
+
+// loadManifest reads the manifest, returning an empty one if it does not exist yet
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// save writes the manifest unless the file already holds the same content
+func (m *Manifest) save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(manifestHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(m); err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	_ = enc.Close()
+
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, buf.Bytes()) {
+		return nil
+	}
+	if err := writeFile(path, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// newManifest hashes the operations and component schemas of the spec
+func newManifest(spec *v3.Document, data *TemplateData) *Manifest {
+	m := &Manifest{
+		Operations: make(map[string]string),
+		Schemas:    make(map[string]string),
+		Files:      make(map[string]ManifestFile),
+	}
+	for _, op := range data.Operations {
+		h := sha256.New()
+		b, _ := json.Marshal(op)
+		h.Write(b)
+		if item := spec.Paths.PathItems.GetOrZero(op.Path); item != nil && item.Post != nil {
+			h.Write(nodeBytes(item.Post.GoLow().RootNode))
+		}
+		m.Operations[op.Path] = hex.EncodeToString(h.Sum(nil))
+	}
+	if spec.Components != nil && spec.Components.Schemas != nil {
+		for name, proxy := range spec.Components.Schemas.FromOldest() {
+			sum := sha256.Sum256(nodeBytes(proxy.GoLow().GetValueNode()))
+			m.Schemas[name] = hex.EncodeToString(sum[:])
+		}
+	}
+	return m
+}
+
+// nodeBytes returns the yaml of a node of the spec. The Hash of libopenapi is
+// not used since it caches hashes by node across documents.
+func nodeBytes(node *yamlv4.Node) []byte {
+	if node == nil {
+		return nil
+	}
+	b, _ := yamlv4.Marshal(node)
+	return b
+}
+
+// fileHash returns the hash of the inputs the file at path is rendered from,
+// project is the projectHash of the template data
+func (m *Manifest) fileHash(path string, inputs fileInputs, project string, data *TemplateData) string {
+	h := sha256.New()
+	h.Write(templatesHash)
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00", path, project)
+	if inputs >= apiInputs {
+		for _, hashes := range []map[string]string{m.Operations, m.Schemas} {
+			keys := make([]string, 0, len(hashes))
+			for key := range hashes {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			for _, key := range keys {
+				_, _ = fmt.Fprintf(h, "%s=%s\x00", key, hashes[key])
+			}
+		}
+	}
+	if inputs == specInputs {
+		h.Write([]byte(data.SpecChecksum))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// projectHash hashes the template data without the timestamp and what is
+// derived from the operations and schemas
+func projectHash(data *TemplateData) string {
+	project := *data
+	project.Timestamp, project.SpecChecksum = "", ""
+	project.Operations, project.Consts, project.ListOps, project.TagServices = nil, nil, nil, nil
+	project.CLISubjects, project.ValidationSchemas, project.SeedRecords, project.Redacted = nil, nil, nil, nil
+	project.HasListOps, project.HasIdempotentOps, project.HasCachedOps, project.HasETagOps, project.HasStreamOps, project.HasAuthOps = false, false, false, false, false, false
+	project.HasReplayOps = false
+	b, _ := json.Marshal(project)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// templatesHash changes with the templates, so a new duh regenerates everything
+var templatesHash = func() []byte {
+	h := sha256.New()
+	_ = fs.WalkDir(templateFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := templateFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00", path)
+		h.Write(b)
+		return nil
+	})
+	return h.Sum(nil)
+}()
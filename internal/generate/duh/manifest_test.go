@@ -0,0 +1,90 @@
+package duh_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSkipsUnchangedFiles(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	dir := filepath.Dir(specPath)
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.FileExists(t, filepath.Join(dir, ".duh-manifest.yaml"))
+
+	// The timestamps of unchanged files are kept
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, file := range []string{"server.go", "client.go"} {
+		require.NoError(t, os.Chtimes(filepath.Join(dir, file), past, past))
+	}
+	modTime := func(file string) time.Time {
+		info, err := os.Stat(filepath.Join(dir, file))
+		require.NoError(t, err)
+		return info.ModTime()
+	}
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "✓ Skipped 3 file(s) unchanged since the last run (--force regenerates them)")
+	assert.Contains(t, stdout.String(), "✓ Generated 0 file(s)")
+	assert.Equal(t, past, modTime("server.go"))
+
+	// A file edited since the last run is rendered again
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.go"), []byte("package api\n"), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "✓ Generated 1 file(s)")
+	assert.Contains(t, stdout.String(), "  - client.go\n")
+	assert.Equal(t, past, modTime("server.go"))
+
+	// A change of an operation renders the files of the api again
+	changed := strings.Replace(multiOpSpec, "/users.get:\n    post:\n", "/users.get:\n    post:\n      deprecated: true\n", 1)
+	require.NotEqual(t, multiOpSpec, changed)
+	require.NoError(t, os.WriteFile(specPath, []byte(changed), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "  - server.go\n")
+	assert.Contains(t, stdout.String(), "  - client.go\n")
+	assert.NotEqual(t, past, modTime("server.go"))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--force"}))
+	assert.Contains(t, stdout.String(), "✓ Generated 4 file(s)")
+	assert.NotContains(t, stdout.String(), "unchanged since the last run")
+}
+
+func TestGenerateReportsChanges(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+
+	// The first run has nothing to compare with
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.NotContains(t, stdout.String(), "Changes since the last run")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(multiOpSpec), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "\nChanges since the last run:\n"+
+		"  files: 0 added, 4 updated, 0 unchanged\n"+
+		"  + operation /users.get\n"+
+		"  + operation /users.update\n"+
+		"  + message GetRequest\n"+
+		"  + message GetResponse\n"+
+		"  + message UpdateRequest\n"+
+		"  + message UpdateResponse\n\n"+
+		"Next steps:\n")
+
+	require.NoError(t, os.WriteFile(specPath, []byte(simpleValidSpec), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "\nChanges since the last run:\n"+
+		"  files: 0 added, 4 updated, 0 unchanged\n"+
+		"  - operation /users.get\n"+
+		"  - operation /users.update\n\n")
+}
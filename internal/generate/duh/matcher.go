@@ -1,43 +1,111 @@
 package duh
 
 import (
+	"strings"
+
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
 )
 
-// IsInitTemplateSpec checks if the OpenAPI spec contains all 4 required endpoints
-// from the duh init template:
-// - /users.create
-// - /users.get
-// - /users.list
-// - /users.update
+// initTemplateMethods are the methods of the duh init template and the request
+// schema each one references. The subject is chosen with 'duh init --subject'.
+var initTemplateMethods = map[string]string{
+	"create": "CreateRequest",
+	"get":    "GetRequest",
+	"list":   "ListRequest",
+	"update": "UpdateRequest",
+}
+
+// InitTemplate describes the resource of a spec created by duh init, which the
+// full service and test templates are rendered for
+type InitTemplate struct {
+	// Subject is the resource of the paths, e.g. users
+	Subject string
+	// Entity names a single resource in messages, e.g. user
+	Entity string
+	// IDProperty is the identifier property of GetRequest, e.g. user_id
+	IDProperty string
+	// IDField is the Go field of IDProperty, e.g. UserId
+	IDField string
+}
+
+// MatchInitTemplate finds the 4 endpoints of the duh init template for any subject:
+// - /{subject}.create
+// - /{subject}.get
+// - /{subject}.list
+// - /{subject}.update
 //
-// Additional endpoints beyond these 4 are allowed and don't affect the match.
-// Returns true only if ALL 4 required paths exist.
-func IsInitTemplateSpec(spec *v3.Document) bool {
+// Each must reference the request schema of the template, and GetRequest must have an
+// identifier property ending in _id. Additional endpoints beyond these 4 are allowed
+// and don't affect the match.
+func MatchInitTemplate(spec *v3.Document) (InitTemplate, bool) {
 	if spec == nil || spec.Paths == nil || spec.Paths.PathItems == nil {
-		return false
+		return InitTemplate{}, false
 	}
 
-	requiredPaths := []string{
-		"/users.create",
-		"/users.get",
-		"/users.list",
-		"/users.update",
-	}
+	for pair := orderedmap.First(spec.Paths.PathItems); pair != nil; pair = pair.Next() {
+		subject, method, err := parseSubjectMethod(pair.Key())
+		if err != nil || method != "create" {
+			continue
+		}
 
-	for _, requiredPath := range requiredPaths {
-		found := false
-		for pair := orderedmap.First(spec.Paths.PathItems); pair != nil; pair = pair.Next() {
-			if pair.Key() == requiredPath {
-				found = true
-				break
-			}
+		if !hasInitTemplateMethods(spec, subject) {
+			continue
 		}
-		if !found {
-			return false
+
+		idProperty := initTemplateIDProperty(spec)
+		if idProperty == "" {
+			continue
 		}
+
+		return InitTemplate{
+			Subject:    subject,
+			Entity:     strings.ReplaceAll(strings.TrimSuffix(idProperty, "_id"), "_", " "),
+			IDProperty: idProperty,
+			IDField:    ToCamelCase(idProperty),
+		}, true
 	}
 
+	return InitTemplate{}, false
+}
+
+func hasInitTemplateMethods(spec *v3.Document, subject string) bool {
+	for method, request := range initTemplateMethods {
+		item := spec.Paths.PathItems.GetOrZero("/" + subject + "." + method)
+		if item == nil || item.Post == nil || requestSchemaName(item.Post) != request {
+			return false
+		}
+	}
 	return true
 }
+
+// initTemplateIDProperty returns the first property of GetRequest ending in _id
+func initTemplateIDProperty(spec *v3.Document) string {
+	if spec.Components == nil || spec.Components.Schemas == nil {
+		return ""
+	}
+
+	proxy := spec.Components.Schemas.GetOrZero("GetRequest")
+	if proxy == nil || proxy.Schema() == nil || proxy.Schema().Properties == nil {
+		return ""
+	}
+
+	for name := range proxy.Schema().Properties.KeysFromOldest() {
+		if strings.HasSuffix(name, "_id") {
+			return name
+		}
+	}
+	return ""
+}
+
+func requestSchemaName(op *v3.Operation) string {
+	if op.RequestBody == nil || op.RequestBody.Content == nil {
+		return ""
+	}
+
+	mediaType := op.RequestBody.Content.GetOrZero("application/json")
+	if mediaType == nil || mediaType.Schema == nil || !mediaType.Schema.IsReference() {
+		return ""
+	}
+	return extractSchemaName(mediaType.Schema.GetReference())
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -410,7 +411,7 @@ func TestIsInitTemplateSpecWithFullMatch(t *testing.T) {
 
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	if exitCode != 0 {
 		t.Logf("Command output: %s", stdout.String())
@@ -435,7 +436,7 @@ func TestIsInitTemplateSpecWithPartialMatch(t *testing.T) {
 
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
 }
@@ -456,7 +457,7 @@ func TestIsInitTemplateSpecWithNoMatch(t *testing.T) {
 
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
 }
@@ -477,7 +478,7 @@ func TestIsInitTemplateSpecWithExtraEndpoints(t *testing.T) {
 
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
 }
@@ -499,7 +500,7 @@ func TestRunWithFullFlagFalse(t *testing.T) {
 	var err error
 	var stdout bytes.Buffer
 	args := []string{"generate", "openapi.yaml"}
-	exitCode := duh.RunCmd(&stdout, args)
+	exitCode := duh.RunCmd(&stdout, &stdout, args)
 
 	require.Equal(t, 0, exitCode)
 
@@ -515,3 +516,26 @@ func TestRunWithFullFlagFalse(t *testing.T) {
 	_, err = os.Stat("Makefile")
 	require.Error(t, err)
 }
+
+func TestMatchInitTemplateIgnoresCRUDEndpoints(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(tempDir, "go.mod"),
+		[]byte("module github.com/test/example\n\ngo 1.24\n"),
+		0644,
+	))
+
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "--template", "minimal"}))
+	// The create, get, list and update methods exist but use their own schemas
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"add", "--crud", "/orders", "Order"}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"}))
+
+	serviceContent, err := os.ReadFile("service.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(serviceContent), `"OrdersCreate not implemented"`)
+	assert.NotContains(t, string(serviceContent), "map[string]*pb.GetResponse")
+}
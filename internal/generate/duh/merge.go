@@ -0,0 +1,183 @@
+package duh
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/remote"
+	"gopkg.in/yaml.v3"
+)
+
+// writeMergedSpec validates the spec of config and every one of MergeSpecs on
+// their own, merges them and writes the result to openapi.yaml in a temporary
+// directory, which the caller removes
+func writeMergedSpec(config RunConfig) (string, error) {
+	if config.FullFlag {
+		return "", errors.New("--full regenerates the code from a single spec and cannot be combined with several specs")
+	}
+	specPaths := append([]string{config.SpecPath}, config.MergeSpecs...)
+	for _, specPath := range specPaths {
+		if remote.IsURL(specPath) {
+			return "", fmt.Errorf("only local spec files can be merged, download %s first", specPath)
+		}
+		spec, err := lint.Load(specPath)
+		if err != nil {
+			return "", err
+		}
+		result := lint.Validate(spec, specPath, nil)
+		config.Log.Rules(result)
+		if !result.Valid() {
+			lint.Print(config.Writer, result)
+			config.Output.AddViolations(specPath, result.Violations)
+			return "", fmt.Errorf("%w for %s", lint.ErrValidation, specPath)
+		}
+	}
+
+	content, err := mergeSpecs(specPaths)
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "duh-merge-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	file := filepath.Join(dir, "openapi.yaml")
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to write the merged spec: %w", err)
+	}
+	return file, nil
+}
+
+// mergeSpecs merges the paths, components and tags of every spec into the
+// first, which keeps its info, servers and extensions. A path defined by two
+// specs is an error, as is a component two specs define differently; the
+// same component in several specs, such as a shared error reply, is kept once.
+func mergeSpecs(specPaths []string) ([]byte, error) {
+	var merged *yaml.Node
+	pathOrigin := make(map[string]string)
+	componentOrigin := make(map[string]string)
+
+	for _, specPath := range specPaths {
+		content, err := os.ReadFile(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+		}
+		var root yaml.Node
+		if err := yaml.Unmarshal(content, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", specPath, err)
+		}
+		if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not an OpenAPI spec", specPath)
+		}
+		doc := root.Content[0]
+
+		if merged == nil {
+			merged = doc
+			paths := mapContent(doc, "paths")
+			for i := 0; i+1 < len(paths); i += 2 {
+				pathOrigin[paths[i].Value] = specPath
+			}
+			components := mapValue(doc, "components")
+			for i := 0; components != nil && i+1 < len(components.Content); i += 2 {
+				kind := components.Content[i].Value
+				entries := components.Content[i+1].Content
+				for j := 0; j+1 < len(entries); j += 2 {
+					componentOrigin[kind+"/"+entries[j].Value] = specPath
+				}
+			}
+			continue
+		}
+
+		paths := mapContent(doc, "paths")
+		for i := 0; i+1 < len(paths); i += 2 {
+			path := paths[i].Value
+			if other, ok := pathOrigin[path]; ok {
+				return nil, fmt.Errorf("path %s is defined in both %s and %s", path, other, specPath)
+			}
+			pathOrigin[path] = specPath
+			appendEntry(merged, "paths", paths[i], paths[i+1])
+		}
+
+		components := mapValue(doc, "components")
+		for i := 0; components != nil && i+1 < len(components.Content); i += 2 {
+			kind := components.Content[i].Value
+			entries := components.Content[i+1].Content
+			for j := 0; j+1 < len(entries); j += 2 {
+				name := entries[j].Value
+				other, ok := componentOrigin[kind+"/"+name]
+				if !ok {
+					componentOrigin[kind+"/"+name] = specPath
+					target := mapValue(merged, "components")
+					if target == nil {
+						target = &yaml.Node{Kind: yaml.MappingNode}
+						appendEntry(merged, "", &yaml.Node{Kind: yaml.ScalarNode, Value: "components"}, target)
+					}
+					appendEntry(target, kind, entries[j], entries[j+1])
+					continue
+				}
+				if !sameNode(mapValue(mapValue(mapValue(merged, "components"), kind), name), entries[j+1]) {
+					return nil, fmt.Errorf("%s %s is defined differently in %s and %s; rename one of them", kind, name, other, specPath)
+				}
+			}
+		}
+
+		tags := mapValue(doc, "tags")
+		for i := 0; tags != nil && i < len(tags.Content); i++ {
+			name := mapValue(tags.Content[i], "name")
+			if name == nil || hasTag(mapValue(merged, "tags"), name.Value) {
+				continue
+			}
+			if mapValue(merged, "tags") == nil {
+				appendEntry(merged, "", &yaml.Node{Kind: yaml.ScalarNode, Value: "tags"}, &yaml.Node{Kind: yaml.SequenceNode})
+			}
+			target := mapValue(merged, "tags")
+			target.Content = append(target.Content, tags.Content[i])
+		}
+	}
+
+	return encodeYAML(merged)
+}
+
+// appendEntry adds key and value to the mapping under name of node, creating
+// it when missing, or to node itself when name is empty
+func appendEntry(node *yaml.Node, name string, key, value *yaml.Node) {
+	if name != "" {
+		target := mapValue(node, name)
+		if target == nil {
+			target = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, target)
+		}
+		node = target
+	}
+	node.Content = append(node.Content, key, value)
+}
+
+func mapContent(node *yaml.Node, key string) []*yaml.Node {
+	if value := mapValue(node, key); value != nil {
+		return value.Content
+	}
+	return nil
+}
+
+// sameNode compares the values of two nodes, ignoring comments and style
+func sameNode(a, b *yaml.Node) bool {
+	var av, bv any
+	if a == nil || a.Decode(&av) != nil || b.Decode(&bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func hasTag(tags *yaml.Node, name string) bool {
+	for i := 0; tags != nil && i < len(tags.Content); i++ {
+		if tag := mapValue(tags.Content[i], "name"); tag != nil && tag.Value == name {
+			return true
+		}
+	}
+	return false
+}
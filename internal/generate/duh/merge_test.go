@@ -0,0 +1,86 @@
+package duh_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// billingSpec is simpleValidSpec as the billing domain, sharing its ErrorDetails
+var billingSpec = strings.NewReplacer(
+	"/users.create", "/billing.charge",
+	"CreateRequest", "ChargeRequest",
+	"CreateResponse", "ChargeResponse",
+).Replace(simpleValidSpec)
+
+func TestGenerateMergesSpecs(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	billingPath := filepath.Join(filepath.Dir(specPath), "billing.yaml")
+	require.NoError(t, os.WriteFile(billingPath, []byte(billingSpec), 0644))
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, billingPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+	assert.Contains(t, stdout.String(), "✓ Merged 2 specs")
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, `"/users.create"`)
+	assert.Contains(t, server, `"/billing.charge"`)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto", "v1", "api.proto"))
+	require.NoError(t, err)
+	proto := string(protoContent)
+	assert.Contains(t, proto, "message CreateRequest {")
+	assert.Contains(t, proto, "message ChargeRequest {")
+	assert.Equal(t, 1, strings.Count(proto, "message ErrorDetails {"))
+}
+
+func TestGenerateMergeSpecsErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		spec    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "DuplicatePath",
+			spec: strings.NewReplacer(
+				"/billing.charge", "/users.create",
+				"ChargeRequest", "UsersCreateRequest",
+				"ChargeResponse", "UsersCreateResponse",
+			).Replace(billingSpec),
+			wantErr: "path /users.create is defined in both",
+		},
+		{
+			name:    "ConflictingSchema",
+			spec:    billingSpec + "        code:\n          type: string\n",
+			wantErr: "schemas ErrorDetails is defined differently in",
+		},
+		{
+			name:    "Full",
+			spec:    billingSpec,
+			args:    []string{"--full"},
+			wantErr: "--full regenerates the code from a single spec and cannot be combined with several specs",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, simpleValidSpec)
+			billingPath := filepath.Join(filepath.Dir(specPath), "billing.yaml")
+			require.NoError(t, os.WriteFile(billingPath, []byte(test.spec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"generate", specPath, billingPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
@@ -27,7 +27,7 @@ func TestReminderMessageDisplayed(t *testing.T) {
 	require.NoError(t, os.Chdir(tempDir))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml"})
 
 	require.Equal(t, 0, exitCode)
 
@@ -52,7 +52,7 @@ func TestReminderMessageWithFullFlag(t *testing.T) {
 	require.NoError(t, os.Chdir(tempDir))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml", "--full"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml", "--full"})
 
 	require.Equal(t, 0, exitCode)
 
@@ -77,7 +77,7 @@ func TestReminderMessageFormat(t *testing.T) {
 	require.NoError(t, os.Chdir(tempDir))
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "openapi.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "openapi.yaml"})
 
 	require.Equal(t, 0, exitCode)
 
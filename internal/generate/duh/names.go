@@ -0,0 +1,119 @@
+package duh
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// nameExtension overrides the generated name of an operation or schema
+const nameExtension = "x-duh-name"
+
+var messageNameRegex = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// duhName returns the x-duh-name of the operation, or "" when it has none
+func duhName(op *v3.Operation, path string) (string, error) {
+	if op.Extensions == nil {
+		return "", nil
+	}
+	node, ok := op.Extensions.Get(nameExtension)
+	if !ok || node == nil {
+		return "", nil
+	}
+
+	if !token.IsIdentifier(node.Value) || !token.IsExported(node.Value) {
+		return "", fmt.Errorf("%s '%s' on path %s must be an exported Go identifier, e.g. GetProfile",
+			nameExtension, node.Value, path)
+	}
+	return node.Value, nil
+}
+
+// applySchemaNames renames the schemas which have an x-duh-name and updates every
+// reference to them, so the proto messages and the Go code using them share the
+// chosen name. It reports whether any schema was renamed.
+func applySchemaNames(content []byte) ([]byte, bool, error) {
+	// Most specs rename nothing, which spares large specs a yaml parse
+	if !bytes.Contains(content, []byte(nameExtension)) {
+		return content, false, nil
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, false, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return content, false, nil
+	}
+
+	schemas := mapValue(mapValue(root.Content[0], "components"), "schemas")
+	if schemas == nil {
+		return content, false, nil
+	}
+
+	renames := make(map[string]string)
+	renamed := make(map[string]string)
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		from := schemas.Content[i].Value
+		name := mapValue(schemas.Content[i+1], nameExtension)
+		if name == nil || name.Value == from {
+			continue
+		}
+
+		to := name.Value
+		if !messageNameRegex.MatchString(to) {
+			return nil, false, fmt.Errorf("%s '%s' on schema %s must start with an upper case letter and contain only letters and digits",
+				nameExtension, to, from)
+		}
+		if other, ok := renamed[to]; ok {
+			return nil, false, fmt.Errorf("schemas %s and %s both have the %s %s", other, from, nameExtension, to)
+		}
+		if mapValue(schemas, to) != nil && !hasRename(schemas, to) {
+			return nil, false, fmt.Errorf("%s %s on schema %s is already the name of a schema", nameExtension, to, from)
+		}
+		renames[from] = to
+		renamed[to] = from
+	}
+	if len(renames) == 0 {
+		return content, false, nil
+	}
+
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		if to, ok := renames[schemas.Content[i].Value]; ok {
+			schemas.Content[i].Value = to
+		}
+	}
+	walkScalars(&root, func(n *yaml.Node) {
+		if name, ok := schemaRefName(n.Value); ok {
+			if to, ok := renames[name]; ok {
+				n.Value = schemaRefPrefix + to + strings.TrimPrefix(n.Value, schemaRefPrefix+name)
+			}
+		}
+	})
+
+	out, err := encodeYAML(root.Content[0])
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// hasRename reports whether the schema is itself renamed, freeing its name for another
+func hasRename(schemas *yaml.Node, name string) bool {
+	ext := mapValue(mapValue(schemas, name), nameExtension)
+	return ext != nil && ext.Value != name
+}
+
+func encodeYAML(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{doc}}); err != nil {
+		return nil, fmt.Errorf("failed to write OpenAPI spec: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -17,6 +17,12 @@ func GenerateOperationName(path string) (string, error) {
 	return subjectCamel + methodCamel, nil
 }
 
+// GenerateOperationIDName returns the method name for an operationId, e.g. getUserById
+// becomes GetUserById, or "" when it cannot be used as a Go identifier
+func GenerateOperationIDName(operationID string) string {
+	return pascalIdentifier(operationID)
+}
+
 func GenerateConstName(operationName string) string {
 	return "RPC" + operationName
 }
@@ -49,3 +55,22 @@ func ToCamelCase(s string) string {
 
 	return result.String()
 }
+
+// pascalIdentifier joins the alphanumeric words of s with their first letters
+// capitalized, returning "" when the result is not a Go identifier
+func pascalIdentifier(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	})
+
+	var result strings.Builder
+	for _, part := range parts {
+		result.WriteString(capitalizeFirst(part))
+	}
+
+	name := result.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return ""
+	}
+	return name
+}
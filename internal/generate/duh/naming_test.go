@@ -263,7 +263,7 @@ func getServerContent(t *testing.T, specPath string) string {
 func TestGenerateOperationNameUsersCreate(t *testing.T) {
 	specPath, stdout := setupTest(t, usersCreateSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContent(t, specPath)
@@ -274,7 +274,7 @@ func TestGenerateOperationNameUsersCreate(t *testing.T) {
 func TestGenerateOperationNameUserProfilesGetById(t *testing.T) {
 	specPath, stdout := setupTest(t, userProfilesGetByIdSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContent(t, specPath)
@@ -285,7 +285,7 @@ func TestGenerateOperationNameUserProfilesGetById(t *testing.T) {
 func TestGenerateOperationNameWithUnderscores(t *testing.T) {
 	specPath, stdout := setupTest(t, underscoreSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	// Underscore paths are now rejected by PATH_HYPHEN_SEPARATOR rule
 	require.Equal(t, 2, exitCode)
@@ -295,7 +295,7 @@ func TestGenerateOperationNameWithUnderscores(t *testing.T) {
 func TestGenerateOperationNamePathWithoutVersionPrefix(t *testing.T) {
 	specPath, stdout := setupTest(t, invalidPathNoVersionSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContent(t, specPath)
@@ -305,7 +305,7 @@ func TestGenerateOperationNamePathWithoutVersionPrefix(t *testing.T) {
 func TestGenerateOperationNameInvalidPathNoMethod(t *testing.T) {
 	specPath, stdout := setupTest(t, invalidPathNoMethodSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 2, exitCode)
 }
@@ -313,7 +313,7 @@ func TestGenerateOperationNameInvalidPathNoMethod(t *testing.T) {
 func TestGenerateConstNamePrefixesRPC(t *testing.T) {
 	specPath, stdout := setupTest(t, usersCreateSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContent(t, specPath)
@@ -323,7 +323,7 @@ func TestGenerateConstNamePrefixesRPC(t *testing.T) {
 func TestToCamelCaseWithHyphens(t *testing.T) {
 	specPath, stdout := setupTest(t, userProfilesGetByIdSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContent(t, specPath)
@@ -333,7 +333,7 @@ func TestToCamelCaseWithHyphens(t *testing.T) {
 func TestToCamelCaseWithUnderscores(t *testing.T) {
 	specPath, stdout := setupTest(t, underscoreSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	// Underscore paths are now rejected by PATH_HYPHEN_SEPARATOR rule
 	require.Equal(t, 2, exitCode)
@@ -1,25 +1,33 @@
 package duh
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/duh-rpc/duh-cli/internal/example"
+	"github.com/duh-rpc/duh-cli/internal/proto"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
 )
 
 type Parser struct {
 	spec           *v3.Document
 	config         *Config
+	initTemplate   InitTemplate
 	isFullTemplate bool
 }
 
-func NewParser(spec *v3.Document, config *Config, isFullTemplate bool) *Parser {
+func NewParser(spec *v3.Document, config *Config, initTemplate InitTemplate, isFullTemplate bool) *Parser {
 	return &Parser{
 		spec:           spec,
 		config:         config,
+		initTemplate:   initTemplate,
 		isFullTemplate: isFullTemplate,
 	}
 }
@@ -29,6 +37,9 @@ func (p *Parser) Parse() (*TemplateData, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := p.config.CheckLayout(); err != nil {
+		return nil, err
+	}
 
 	operations, err := p.extractOperations()
 	if err != nil {
@@ -40,25 +51,91 @@ func (p *Parser) Parse() (*TemplateData, error) {
 		return nil, err
 	}
 
+	tagServices, err := groupByTag(operations)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted, err := p.redactedMessages()
+	if err != nil {
+		return nil, err
+	}
+
 	timestamp := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
+	name := cliName(modulePath)
+	service := strings.TrimSuffix(name, "ctl")
+
+	var title, description, version string
+	if p.spec.Info != nil {
+		title, description = p.spec.Info.Title, strings.TrimSpace(p.spec.Info.Description)
+		version = p.spec.Info.Version
+	}
 
 	return &TemplateData{
-		PackageImport:  p.config.ConstructPackageImport(modulePath),
-		Package:        p.config.PackageName,
-		ModulePath:     modulePath,
-		ProtoImport:    p.config.ConstructProtoImport(modulePath),
-		ProtoPackage:   p.config.DeriveProtoPackage(),
-		Operations:     operations,
-		ListOps:        listOps,
-		HasListOps:     len(listOps) > 0,
-		Timestamp:      timestamp,
-		IsFullTemplate: p.isFullTemplate,
-		GoModule:       modulePath,
+		PackageImport:     p.config.ConstructPackageImport(modulePath),
+		Package:           p.config.PackageName,
+		ModulePath:        modulePath,
+		ProtoImport:       p.config.ConstructProtoImport(modulePath),
+		ProtoPackage:      p.config.DeriveProtoPackage(),
+		Operations:        operations,
+		Consts:            operations,
+		ListOps:           listOps,
+		HasListOps:        len(listOps) > 0,
+		HasIdempotentOps:  slices.ContainsFunc(operations, func(op Operation) bool { return op.Idempotent }),
+		HasCachedOps:      slices.ContainsFunc(operations, func(op Operation) bool { return op.CacheTTL != "" }),
+		HasETagOps:        slices.ContainsFunc(operations, func(op Operation) bool { return op.ETag }),
+		HasStreamOps:      slices.ContainsFunc(operations, func(op Operation) bool { return op.StreamRequest }),
+		HasAuthOps:        slices.ContainsFunc(operations, func(op Operation) bool { return op.Auth != "" }),
+		HasReplayOps:      slices.ContainsFunc(operations, func(op Operation) bool { return op.ReplayProtected }),
+		Timestamp:         timestamp,
+		IsFullTemplate:    p.isFullTemplate,
+		Init:              p.initTemplate,
+		SeedRecords:       p.seedRecords(),
+		GoModule:          modulePath,
+		CLIName:           name,
+		CLIEnvPrefix:      strings.ToUpper(strings.ReplaceAll(name, "-", "_")),
+		DaemonEnvPrefix:   strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_",
+		ServiceName:       service,
+		DaemonName:        service + "d",
+		CLISubjects:       p.cliSubjects(operations),
+		TagServices:       tagServices,
+		ValidationSchemas: p.validationSchemas(operations),
+		Redacted:          redacted,
+		Title:             title,
+		Description:       description,
+		APIVersion:        version,
 	}, nil
 }
 
+// seedCount is the number of records seed.go starts the service with
+const seedCount = 10
+
+// seedRecords returns seedCount records of the GetResponse schema of the init
+// template, each with its own faker values
+func (p *Parser) seedRecords() []string {
+	if !p.isFullTemplate || p.spec.Components == nil || p.spec.Components.Schemas == nil {
+		return nil
+	}
+	proxy := p.spec.Components.Schemas.GetOrZero("GetResponse")
+	if proxy == nil {
+		return nil
+	}
+
+	var records []string
+	for seed := 1; seed <= seedCount; seed++ {
+		b, err := json.Marshal(example.FromSchemaWith(proxy, example.Options{Faker: true, Seed: seed}))
+		if err != nil {
+			return nil
+		}
+		// A backquote is escaped so the record fits a raw string literal
+		records = append(records, strings.ReplaceAll(string(b), "`", `\u0060`))
+	}
+	return records
+}
+
 func (p *Parser) extractOperations() ([]Operation, error) {
 	var operations []Operation
+	methods := make(map[string]string)
 
 	if p.spec.Paths == nil || p.spec.Paths.PathItems == nil {
 		return operations, nil
@@ -77,8 +154,56 @@ func (p *Parser) extractOperations() ([]Operation, error) {
 		if err != nil {
 			continue
 		}
+		if operation.OperationId != "" && !p.config.PathNames {
+			operationName = GenerateOperationIDName(operation.OperationId)
+			if operationName == "" {
+				return nil, fmt.Errorf("operationId '%s' on path %s cannot be used as a Go identifier", operation.OperationId, path)
+			}
+		}
+		name, err := duhName(operation, path)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			operationName = name
+		}
+		if other, taken := methods[operationName]; taken {
+			return nil, fmt.Errorf("paths %s and %s both generate the method %s (use --path-names to name methods after their paths)",
+				other, path, operationName)
+		}
+		methods[operationName] = path
+
+		isIdempotent, err := flag(operation, idempotentExtension, path)
+		if err != nil {
+			return nil, err
+		}
+
+		hasETag, err := flag(operation, etagExtension, path)
+		if err != nil {
+			return nil, err
+		}
+
+		replayProtected, err := flag(operation, replayProtectExtension, path)
+		if err != nil {
+			return nil, err
+		}
+
+		ttl, err := cacheTTL(operation, path)
+		if err != nil {
+			return nil, err
+		}
 
-		requestType := ""
+		skip, err := duhSkip(operation, path)
+		if err != nil {
+			return nil, err
+		}
+
+		auth, err := duhAuth(operation, path)
+		if err != nil {
+			return nil, err
+		}
+
+		requestType, streamRequest, requestExample := "", false, ""
 		if operation.RequestBody != nil && operation.RequestBody.Content != nil {
 			for contentPair := orderedmap.First(operation.RequestBody.Content); contentPair != nil; contentPair = contentPair.Next() {
 				mediaType := contentPair.Value()
@@ -86,6 +211,10 @@ func (p *Parser) extractOperations() ([]Operation, error) {
 					if mediaType.Schema.IsReference() {
 						ref := mediaType.Schema.GetReference()
 						requestType = "pb." + extractSchemaName(ref)
+						streamRequest = hasLargeList(mediaType.Schema.Schema())
+						if b, err := json.Marshal(example.FromSchema(mediaType.Schema)); err == nil {
+							requestExample = string(b)
+						}
 						break
 					} else {
 						return nil, fmt.Errorf("inline schema not supported for request body in path %s", path)
@@ -126,6 +255,11 @@ func (p *Parser) extractOperations() ([]Operation, error) {
 			continue
 		}
 
+		tag := ""
+		if len(operation.Tags) > 0 {
+			tag = operation.Tags[0]
+		}
+
 		summary := ""
 		if operation.Summary != "" {
 			summary = operation.Summary
@@ -134,19 +268,79 @@ func (p *Parser) extractOperations() ([]Operation, error) {
 		}
 
 		operations = append(operations, Operation{
-			IsInitTemplateMethod: p.isFullTemplate && isInitTemplateMethod(path),
+			IsInitTemplateMethod: p.isFullTemplate && p.isInitTemplateMethod(path),
 			ConstName:            GenerateConstName(operationName),
 			MethodName:           operationName,
 			ResponseType:         responseType,
+			ResponseSchema:       strings.TrimPrefix(responseType, "pb."),
 			RequestType:          requestType,
 			Summary:              summary,
 			Path:                 path,
+			Deprecated:           operation.Deprecated != nil && *operation.Deprecated,
+			Idempotent:           isIdempotent,
+			ReplayProtected:      replayProtected,
+			ETag:                 hasETag,
+			CacheTTL:             ttl,
+			Auth:                 auth,
+			StreamRequest:        streamRequest,
+			RequestExample:       requestExample,
+			Tag:                  tag,
+			Skip:                 skip,
+			ConnectProcedure:     connectProcedure(p.config.DeriveProtoPackage(), path),
 		})
 	}
 
 	return operations, nil
 }
 
+// groupByTag collects the tagged operations into one TagService per tag, in the
+// order each tag is first seen
+func groupByTag(ops []Operation) ([]TagService, error) {
+	var services []TagService
+	index := make(map[string]int)
+	names := make(map[string]string)
+
+	for _, op := range ops {
+		if op.Tag == "" {
+			continue
+		}
+
+		i, ok := index[op.Tag]
+		if !ok {
+			name := tagServiceName(op.Tag)
+			if name == "" {
+				return nil, fmt.Errorf("tag '%s' on path %s cannot be used as a Go identifier", op.Tag, op.Path)
+			}
+			if other, taken := names[name]; taken {
+				return nil, fmt.Errorf("tags '%s' and '%s' both generate the interface %s", other, op.Tag, name)
+			}
+			names[name] = op.Tag
+
+			i = len(services)
+			index[op.Tag] = i
+			services = append(services, TagService{Name: name, Tag: op.Tag})
+		}
+		services[i].Operations = append(services[i].Operations, op)
+	}
+	return services, nil
+}
+
+// tagServiceName returns the interface name for a tag, e.g. 'user accounts' becomes
+// UserAccountsService. It returns an empty string when the tag has no usable letters.
+func tagServiceName(tag string) string {
+	name := pascalIdentifier(tag)
+	if name == "" {
+		return ""
+	}
+	return name + "Service"
+}
+
+// connectProcedure returns the Connect procedure of the rpc generated by --proto-service for path
+func connectProcedure(protoPackage, path string) string {
+	service, method := proto.RPCName(path)
+	return fmt.Sprintf("/%s.%s/%s", protoPackage, service, method)
+}
+
 func (p *Parser) detectListOperations(ops []Operation) ([]ListOperation, error) {
 	var listOps []ListOperation
 
@@ -309,18 +503,126 @@ func extractSchemaName(ref string) string {
 	return parts[len(parts)-1]
 }
 
-func isInitTemplateMethod(path string) bool {
-	initTemplatePaths := []string{
-		"/users.create",
-		"/users.get",
-		"/users.list",
-		"/users.update",
+func (p *Parser) isInitTemplateMethod(path string) bool {
+	subject, method, err := parseSubjectMethod(path)
+	if err != nil || subject != p.initTemplate.Subject {
+		return false
+	}
+	_, ok := initTemplateMethods[method]
+	return ok
+}
+
+// idempotentExtension marks an operation whose retries the server deduplicates
+// by the Idempotency-Key header the client sends
+const idempotentExtension = "x-duh-idempotent"
+
+// replayProtectExtension marks a mutating operation whose calls the server
+// accepts once, by the nonce and the timestamp headers the client sends
+const replayProtectExtension = "x-duh-replay-protect"
+
+// etagExtension marks a read operation whose replies carry an ETag, a polling
+// client sending it back as If-None-Match is replied 304 Not Modified while
+// the reply is unchanged
+const etagExtension = "x-duh-etag"
+
+// flag reports whether the operation has the extension set to true, such as
+// x-duh-idempotent: true
+func flag(op *v3.Operation, extension, path string) (bool, error) {
+	return extensionFlag(op.Extensions, extension, "path "+path)
+}
+
+// extensionFlag reports whether extensions has the extension set to true,
+// where names what has them in the error of a value which is not a boolean
+func extensionFlag(extensions *orderedmap.Map[string, *yaml.Node], extension, where string) (bool, error) {
+	if extensions == nil {
+		return false, nil
+	}
+	node, ok := extensions.Get(extension)
+	if !ok || node == nil {
+		return false, nil
+	}
+
+	var value bool
+	if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" || node.Decode(&value) != nil {
+		return false, fmt.Errorf("%s '%s' on %s must be true or false", extension, node.Value, where)
+	}
+	return value, nil
+}
+
+// cacheTTLExtension is how long a client with a Cache keeps the replies of an
+// operation, such as 30s or 5m
+const cacheTTLExtension = "x-duh-cache-ttl"
+
+// cacheTTL returns the x-duh-cache-ttl of the operation as a Go expression,
+// such as 30 * clock.Second, or "" when it has none
+func cacheTTL(op *v3.Operation, path string) (string, error) {
+	if op.Extensions == nil {
+		return "", nil
+	}
+	node, ok := op.Extensions.Get(cacheTTLExtension)
+	if !ok || node == nil {
+		return "", nil
 	}
 
-	for _, templatePath := range initTemplatePaths {
-		if path == templatePath {
+	ttl, err := time.ParseDuration(node.Value)
+	if node.Kind != yaml.ScalarNode || err != nil || ttl <= 0 {
+		return "", fmt.Errorf("%s '%s' on path %s must be a duration such as 30s or 5m", cacheTTLExtension, node.Value, path)
+	}
+	for _, unit := range []struct {
+		name string
+		size time.Duration
+	}{{"Hour", time.Hour}, {"Minute", time.Minute}, {"Second", time.Second}, {"Millisecond", time.Millisecond}} {
+		if ttl%unit.size == 0 {
+			return fmt.Sprintf("%d * clock.%s", ttl/unit.size, unit.name), nil
+		}
+	}
+	return fmt.Sprintf("clock.Duration(%d)", ttl), nil
+}
+
+// largeListItems is the maxItems above which a list makes a request large,
+// lists without a maxItems make it large too
+const largeListItems = 1_000
+
+// hasLargeList reports whether the request has a list of messages which may
+// hold more than largeListItems items, the server decodes such requests as
+// it reads them rather than reading them whole first
+func hasLargeList(schema *base.Schema) bool {
+	if schema == nil || schema.Properties == nil {
+		return false
+	}
+	for _, proxy := range schema.Properties.FromOldest() {
+		property := proxy.Schema()
+		if property == nil || !slices.Contains(property.Type, "array") || property.Items == nil || !property.Items.IsA() {
+			continue
+		}
+		items := property.Items.A
+		if !items.IsReference() && (items.Schema() == nil || !slices.Contains(items.Schema().Type, "object")) {
+			continue
+		}
+		if property.MaxItems == nil || *property.MaxItems > largeListItems {
 			return true
 		}
 	}
 	return false
 }
+
+// basePathExtension is the route prefix of the service, for services behind
+// an ingress which routes by path
+const basePathExtension = "x-duh-base-path"
+
+var basePathRegex = regexp.MustCompile(`^(/[A-Za-z0-9._~-]+)+$`)
+
+// specBasePath returns the x-duh-base-path of the spec, or "" when it has none
+func specBasePath(spec *v3.Document) (string, error) {
+	if spec.Extensions == nil {
+		return "", nil
+	}
+	node, ok := spec.Extensions.Get(basePathExtension)
+	if !ok || node == nil {
+		return "", nil
+	}
+	if node.Kind != yaml.ScalarNode || !basePathRegex.MatchString(node.Value) {
+		return "", fmt.Errorf("%s '%s' must start with / and not end with one, like /api", basePathExtension, node.Value)
+	}
+	return node.Value, nil
+}
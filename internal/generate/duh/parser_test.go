@@ -1,8 +1,10 @@
 package duh_test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -455,7 +457,7 @@ components:
 func TestParseOperationsExtractsMultiple(t *testing.T) {
 	specPath, stdout := setupTest(t, multiOperationSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContentForParser(t, specPath)
@@ -467,7 +469,7 @@ func TestParseOperationsExtractsMultiple(t *testing.T) {
 func TestParseOperationsExtractsPbPrefixedTypes(t *testing.T) {
 	specPath, stdout := setupTest(t, multiOperationSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContentForParser(t, specPath)
@@ -478,7 +480,7 @@ func TestParseOperationsExtractsPbPrefixedTypes(t *testing.T) {
 func TestDetectListOperationsWith3Criteria(t *testing.T) {
 	specPath, stdout := setupTest(t, specWithListOp)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -487,7 +489,7 @@ func TestDetectListOperationsWith3Criteria(t *testing.T) {
 func TestDetectListOperationsMultipleVariants(t *testing.T) {
 	specPath, stdout := setupTest(t, listOperationVariantsSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -496,7 +498,7 @@ func TestDetectListOperationsMultipleVariants(t *testing.T) {
 func TestIsListOperationChecksMethodPortion(t *testing.T) {
 	specPath, stdout := setupTest(t, listOperationVariantsSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -505,7 +507,7 @@ func TestIsListOperationChecksMethodPortion(t *testing.T) {
 func TestIsListOperationWithoutPage(t *testing.T) {
 	specPath, stdout := setupTest(t, notListNoPageSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -514,7 +516,7 @@ func TestIsListOperationWithoutPage(t *testing.T) {
 func TestFindFirstArrayFieldInYAMLOrder(t *testing.T) {
 	specPath, stdout := setupTest(t, arrayOrderSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -523,7 +525,7 @@ func TestFindFirstArrayFieldInYAMLOrder(t *testing.T) {
 func TestInlineSchemaReturnsError(t *testing.T) {
 	specPath, stdout := setupTest(t, inlineSchemaSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "OpenAPI validation failed")
@@ -532,7 +534,7 @@ func TestInlineSchemaReturnsError(t *testing.T) {
 func TestParseExtractsModulePathAndProtoImport(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContentForParser(t, specPath)
@@ -542,7 +544,7 @@ func TestParseExtractsModulePathAndProtoImport(t *testing.T) {
 func TestParseGeneratesTimestampInCorrectFormat(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContentForParser(t, specPath)
@@ -552,9 +554,30 @@ func TestParseGeneratesTimestampInCorrectFormat(t *testing.T) {
 func TestParseExtractsOperationSummary(t *testing.T) {
 	specPath, stdout := setupTest(t, multiOperationSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	content := getServerContentForParser(t, specPath)
 	assert.Contains(t, content, "// Create a new user")
 }
+
+func TestParsePropagatesOperationDeprecation(t *testing.T) {
+	spec := strings.Replace(multiOperationSpec, "      summary: Get user by ID\n",
+		"      summary: Get user by ID\n      deprecated: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	var stderr bytes.Buffer
+
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 0, exitCode)
+	deprecation := "// Deprecated: /users.get is marked deprecated in the OpenAPI spec."
+	server := getServerContentForParser(t, specPath)
+	assert.Contains(t, server, "// Get user by ID\n\t//\n\t"+deprecation+"\n\tUsersGet(ctx")
+	assert.Equal(t, 1, strings.Count(server, "Deprecated:"))
+
+	client, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(client), "\t"+deprecation+"\n\tUsersGet(ctx")
+	assert.Contains(t, string(client), "\n"+deprecation+"\nfunc (c *Client) UsersGet(")
+	assert.Equal(t, 2, strings.Count(string(client), "Deprecated:"))
+}
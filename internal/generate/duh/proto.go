@@ -0,0 +1,101 @@
+package duh
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/duh-rpc/duh-cli/internal/proto"
+)
+
+// ProtoConfig controls standalone proto generation
+type ProtoConfig struct {
+	Writer       io.Writer
+	SpecPath     string
+	OutputPath   string
+	ProtoImport  string
+	ProtoPackage string
+	Converter    ProtoConverter
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// RunProto converts the OpenAPI spec into a proto file without generating client or server code
+func RunProto(config ProtoConfig) error {
+	start := time.Now()
+	spec, err := lint.Load(config.SpecPath)
+	if err != nil {
+		return err
+	}
+	config.Log.Parsed(config.SpecPath, start)
+
+	result := lint.Validate(spec, config.SpecPath, nil)
+	config.Log.Rules(result)
+	if !result.Valid() {
+		return lint.ErrValidation
+	}
+
+	genConfig, err := NewConfig("", "", config.OutputPath, config.ProtoImport, config.ProtoPackage)
+	if err != nil {
+		return err
+	}
+
+	protoImport := config.ProtoImport
+	if protoImport == "" {
+		modulePath, err := genConfig.DetectModulePath()
+		if err != nil {
+			return fmt.Errorf("%w (use --proto-import to set the go_package explicitly)", err)
+		}
+		protoImport = genConfig.ConstructProtoImport(modulePath)
+	}
+
+	specContent, err := os.ReadFile(config.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	specContent, renamed, err := applySchemaNames(specContent)
+	if err != nil {
+		return err
+	}
+	if renamed {
+		// The converter parses the renamed spec itself
+		spec = nil
+	}
+
+	lockPath := filepath.Join(filepath.Dir(genConfig.ProtoPath), protoLockFile)
+	lock, err := proto.LoadLock(lockPath)
+	if err != nil {
+		return err
+	}
+
+	protoFiles, err := config.Converter.Convert(specContent, spec, genConfig.DeriveProtoPackage(), protoImport, genConfig.ProtoPath, lock)
+	if err != nil {
+		return fmt.Errorf("failed to convert OpenAPI to proto: %w", err)
+	}
+
+	for _, file := range protoFiles {
+		if err := writeFile(file.Path, file.Content); err != nil {
+			return fmt.Errorf("failed to write proto file: %w", err)
+		}
+	}
+
+	if err := lock.Save(lockPath); err != nil {
+		return err
+	}
+
+	if len(protoFiles) == 1 {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Generated %s\n", protoFiles[0].Path)
+	} else {
+		_, _ = fmt.Fprintf(config.Writer, "✓ Generated %d proto file(s) in %s\n", len(protoFiles), filepath.Dir(genConfig.ProtoPath))
+		for _, file := range protoFiles {
+			_, _ = fmt.Fprintf(config.Writer, "  - %s\n", file.Path)
+		}
+	}
+	_, _ = fmt.Fprintf(config.Writer, "  - %s\n", lockPath)
+	return nil
+}
@@ -1,6 +1,7 @@
 package duh_test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -97,7 +98,7 @@ func TestGenerateDuhCreatesProtoFile(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -137,7 +138,7 @@ func TestProtoWithCustomPath(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{
+	exitCode := duh.RunCmd(stdout, stdout, []string{
 		"generate", specPath,
 		"--proto-path", "custom/path/api.proto",
 	})
@@ -158,7 +159,7 @@ func TestProtoSchemaExtraction(t *testing.T) {
 	specPath, stdout := setupTest(t, multiSchemaSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 0, exitCode)
 
@@ -219,9 +220,837 @@ components:
 
 	specPath, stdout := setupTest(t, invalidFieldSpec)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 
 	require.Equal(t, 2, exitCode)
 	output := stdout.String()
 	require.NotEmpty(t, output)
 }
+
+func TestProtoCommandGeneratesOnlyProto(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated proto/v1/api.proto")
+
+	protoContent, err := os.ReadFile(filepath.Join(tempDir, "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "package duh.api.v1")
+	assert.Contains(t, string(protoContent), "option go_package = \"github.com/example/test/proto/v1\";")
+
+	for _, file := range []string{"server.go", "client.go", "buf.yaml", "buf.gen.yaml"} {
+		_, err := os.Stat(filepath.Join(tempDir, file))
+		assert.True(t, os.IsNotExist(err))
+	}
+}
+
+func TestProtoCommandWithCustomOutputAndPackage(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{
+		"proto", specPath,
+		"-o", "schema/v2/users.proto",
+		"--package", "acme.users.v2",
+		"--proto-import", "github.com/acme/users/v2",
+	})
+
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(tempDir, "schema/v2/users.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "package acme.users.v2")
+	assert.Contains(t, string(protoContent), "option go_package = \"github.com/acme/users/v2\";")
+}
+
+func TestProtoCommandDerivesPackageFromOutput(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath, "-o", "proto/v3/api.proto"})
+
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(tempDir, "proto/v3/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "package duh.api.v3")
+}
+
+func TestProtoCommandMissingGoMod(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	var stderr bytes.Buffer
+	require.NoError(t, os.Remove("go.mod"))
+
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"proto", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "--proto-import")
+}
+
+func TestProtoCommandInvalidSpec(t *testing.T) {
+	specPath, stdout := setupTest(t, invalidSpec)
+	var stderr bytes.Buffer
+
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"proto", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "OpenAPI validation failed")
+}
+
+const protoSpecHeader = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /events.create:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+components:
+  schemas:
+    ErrorDetails:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+    CreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+func TestProtoMapsWellKnownTypes(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        metadata:
+          type: object
+          description: Arbitrary metadata
+        created_at:
+          type: string
+          format: date-time
+        timeout:
+          type: string
+          format: duration
+        payload:
+          type: string
+          format: byte
+        total:
+          type: integer
+          format: int64
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "import \"google/protobuf/duration.proto\";")
+	assert.Contains(t, content, "import \"google/protobuf/struct.proto\";")
+	assert.Contains(t, content, "import \"google/protobuf/timestamp.proto\";")
+	assert.Contains(t, content, "google.protobuf.Struct metadata = 1")
+	assert.Contains(t, content, "google.protobuf.Timestamp created_at = 2")
+	assert.Contains(t, content, "google.protobuf.Duration timeout = 3")
+	assert.Contains(t, content, "bytes payload = 4")
+	assert.Contains(t, content, "int64 total = 5")
+}
+
+func TestProtoOmitsUnusedImports(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.NotContains(t, content, "import ")
+}
+
+const enumSchemas = `    CreateRequest:
+      type: object
+      properties:
+        status:
+          type: string
+          description: Current status
+          enum: [active, in progress, 2fa-pending]
+        priority:
+          $ref: '#/components/schemas/Priority'
+    Priority:
+      type: string
+      enum: [low, high]
+    UpdateRequest:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [active, in progress, 2fa-pending]
+`
+
+func TestProtoGeneratesEnums(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+enumSchemas)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "enum Status {\n"+
+		"  STATUS_UNSPECIFIED = 0;\n"+
+		"  STATUS_ACTIVE = 1;\n"+
+		"  STATUS_IN_PROGRESS = 2;\n"+
+		"  STATUS_2FA_PENDING = 3;\n"+
+		"}")
+	assert.Contains(t, content, "PRIORITY_HIGH = 2;")
+	assert.Contains(t, content, "Status status = 1")
+	assert.Contains(t, content, "Priority priority = 2")
+}
+
+func TestProtoReusesIdenticalEnums(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+enumSchemas)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Equal(t, 1, strings.Count(content, "enum Status {"))
+	assert.NotContains(t, content, "Status_2")
+}
+
+func TestProtoEnumsAsStrings(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+enumSchemas)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath, "--enums-as-strings"})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.NotContains(t, content, "enum ")
+	assert.Contains(t, content, "  // Current status\n"+
+		"  // Allowed values: active, in progress, 2fa-pending\n"+
+		"  string status = 1")
+	assert.Contains(t, content, "  // Allowed values: low, high\n"+
+		"  string priority = 2")
+}
+
+func TestProtoMapsAdditionalProperties(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        labels:
+          type: object
+          description: Free form labels
+          additionalProperties:
+            type: string
+        counts:
+          type: object
+          additionalProperties:
+            type: integer
+            format: int64
+        owners:
+          type: object
+          additionalProperties:
+            $ref: '#/components/schemas/Owner'
+        notes:
+          type: object
+          additionalProperties:
+            type: object
+            properties:
+              text:
+                type: string
+    Owner:
+      type: object
+      properties:
+        name:
+          type: string
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "  // Free form labels\n  map<string, string> labels = 1")
+	assert.Contains(t, content, "map<string, int64> counts = 2")
+	assert.Contains(t, content, "map<string, Owner> owners = 3")
+	assert.Contains(t, content, "  message NotesValue {\n    string text = 1")
+	assert.Contains(t, content, "map<string, NotesValue> notes = 4")
+}
+
+func TestProtoNestsInlineObjects(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        settings:
+          type: object
+          description: Event settings
+          properties:
+            notify:
+              type: boolean
+        line_items:
+          type: array
+          items:
+            type: object
+            properties:
+              sku:
+                type: string
+        categories:
+          type: array
+          items:
+            type: string
+            enum: [music, sports]
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "  // Event settings\n  message Settings {\n    bool notify = 1")
+	assert.Contains(t, content, "  message LineItem {\n    string sku = 1")
+	assert.Contains(t, content, "enum Category {")
+	assert.Contains(t, content, "Settings settings = 1")
+	assert.Contains(t, content, "repeated LineItem line_items = 2")
+	assert.Contains(t, content, "repeated Category categories = 3")
+}
+
+func TestProtoRejectsArrayMapValues(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        groups:
+          type: object
+          additionalProperties:
+            type: array
+            items:
+              type: string
+`)
+
+	var stderr bytes.Buffer
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"proto", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "map values cannot be arrays")
+}
+
+func TestProtoGeneratesOneOfGroups(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        payment:
+          description: How the order is paid
+          oneOf:
+            - $ref: '#/components/schemas/Card'
+            - $ref: '#/components/schemas/BankTransfer'
+          discriminator:
+            propertyName: type
+            mapping:
+              card: '#/components/schemas/Card'
+              bank: '#/components/schemas/BankTransfer'
+        note:
+          type: string
+    Card:
+      type: object
+      properties:
+        type:
+          type: string
+        number:
+          type: string
+    BankTransfer:
+      type: object
+      properties:
+        type:
+          type: string
+        iban:
+          type: string
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "  string name = 1 [json_name = \"name\"];\n"+
+		"  // How the order is paid\n"+
+		"  oneof payment {\n"+
+		"    Card card = 2 [json_name = \"card\"];\n"+
+		"    BankTransfer bank_transfer = 3 [json_name = \"bank_transfer\"];\n"+
+		"  }\n"+
+		"  string note = 4 [json_name = \"note\"];\n")
+}
+
+func TestProtoMergesAllOfProperties(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        owner:
+          description: The owner of the event
+          allOf:
+            - $ref: '#/components/schemas/Person'
+            - type: object
+              properties:
+                email:
+                  type: string
+                name:
+                  type: string
+                  description: Display name
+        contact:
+          description: Who to contact
+          allOf:
+            - $ref: '#/components/schemas/Person'
+    Person:
+      type: object
+      properties:
+        name:
+          type: string
+        age:
+          type: integer
+          format: int32
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "  // The owner of the event\n  message Owner {\n"+
+		"    // Display name\n"+
+		"    string name = 1 [json_name = \"name\"];\n"+
+		"    int32 age = 2 [json_name = \"age\"];\n"+
+		"    string email = 3 [json_name = \"email\"];\n")
+	assert.Contains(t, content, "  Owner owner = 1")
+	assert.Contains(t, content, "  // Who to contact\n  Person contact = 2")
+}
+
+func TestProtoCompositionErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		property string
+		wantErr  string
+	}{
+		{
+			name: "AnyOf",
+			property: `          anyOf:
+            - type: string
+            - type: integer
+              format: int32`,
+			wantErr: "uses 'anyOf' which is not supported",
+		},
+		{
+			name: "AllOfWithOneOf",
+			property: `          allOf:
+            - type: object
+              properties:
+                id:
+                  type: string
+          oneOf:
+            - type: string
+            - type: boolean`,
+			wantErr: "combines 'allOf' and 'oneOf' which is not supported",
+		},
+		{
+			name: "AllOfNonObject",
+			property: `          allOf:
+            - type: string`,
+			wantErr: "allOf[0] is not an object",
+		},
+		{
+			name: "ArrayVariant",
+			property: `          oneOf:
+            - type: string
+            - type: array
+              items:
+                type: string`,
+			wantErr: "oneOf[1] is an array",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        value:
+`+test.property+"\n")
+
+			var stderr bytes.Buffer
+			exitCode := duh.RunCmd(stdout, &stderr, []string{"proto", specPath})
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+func TestProtoLockKeepsFieldNumbers(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        email:
+          type: string
+        age:
+          type: integer
+          format: int32
+`)
+	tempDir := filepath.Dir(specPath)
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"proto", specPath}))
+
+	lock, err := os.ReadFile(filepath.Join(tempDir, "proto/v1/proto.lock"))
+	require.NoError(t, err)
+	assert.Contains(t, string(lock), "CreateRequest:")
+
+	// Reorder the properties, drop 'email' and add 'phone'
+	require.NoError(t, os.WriteFile(specPath, []byte(protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        phone:
+          type: string
+        age:
+          type: integer
+          format: int32
+        name:
+          type: string
+`), 0644))
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"proto", specPath}))
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "message CreateRequest {\n"+
+		"  reserved 2;\n"+
+		"  reserved \"email\";\n"+
+		"  string phone = 4 [json_name = \"phone\"];\n"+
+		"  int32 age = 3 [json_name = \"age\"];\n"+
+		"  string name = 1 [json_name = \"name\"];\n"+
+		"}")
+}
+
+func TestProtoLockKeepsEnumNumbers(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [active, inactive, suspended]
+`)
+	tempDir := filepath.Dir(specPath)
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"proto", specPath}))
+
+	require.NoError(t, os.WriteFile(specPath, []byte(protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        status:
+          type: string
+          enum: [pending, active, suspended]
+`), 0644))
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"proto", specPath}))
+
+	content, err := os.ReadFile(filepath.Join(tempDir, "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "enum Status {\n"+
+		"  reserved 2;\n"+
+		"  reserved \"STATUS_INACTIVE\";\n"+
+		"  STATUS_UNSPECIFIED = 0;\n"+
+		"  STATUS_PENDING = 4;\n"+
+		"  STATUS_ACTIVE = 1;\n"+
+		"  STATUS_SUSPENDED = 3;\n"+
+		"}")
+}
+
+func TestGenerateWritesProtoLock(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "proto/v1/proto.lock")
+
+	_, err := os.Stat(filepath.Join(filepath.Dir(specPath), "proto/v1/proto.lock"))
+	assert.NoError(t, err)
+}
+
+const subjectsSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users.create:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/UsersCreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UsersCreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+  /products.create:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/ProductsCreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ProductsCreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+components:
+  schemas:
+    ErrorDetails:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+    Address:
+      type: object
+      properties:
+        street:
+          type: string
+        city:
+          type: string
+    UsersCreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        role:
+          type: string
+          enum: [admin, member]
+    UsersCreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+        created_at:
+          type: string
+          format: date-time
+        address:
+          $ref: '#/components/schemas/Address'
+    ProductsCreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        warehouse:
+          $ref: '#/components/schemas/Address'
+    ProductsCreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+`
+
+func TestProtoSplitBySubject(t *testing.T) {
+	specPath, stdout := setupTest(t, subjectsSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath, "--split-by-subject"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 3 proto file(s) in proto/v1")
+
+	dir := filepath.Join(filepath.Dir(specPath), "proto/v1")
+	assert.NoFileExists(t, filepath.Join(dir, "api.proto"))
+
+	users, err := os.ReadFile(filepath.Join(dir, "users.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(users), "import \"google/protobuf/timestamp.proto\";\nimport \"proto/v1/common.proto\";\n")
+	assert.Contains(t, string(users), "message UsersCreateRequest {")
+	assert.Contains(t, string(users), "enum Role {")
+	assert.Contains(t, string(users), "message UsersCreateResponse {")
+	assert.NotContains(t, string(users), "message Product")
+
+	products, err := os.ReadFile(filepath.Join(dir, "products.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(products), "import \"proto/v1/common.proto\";\n")
+	assert.NotContains(t, string(products), "timestamp.proto")
+	assert.Contains(t, string(products), "message ProductsCreateRequest {")
+	assert.Contains(t, string(products), "message ProductsCreateResponse {")
+
+	common, err := os.ReadFile(filepath.Join(dir, "common.proto"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(common), "import")
+	assert.Contains(t, string(common), "package duh.api.v1;")
+	assert.Contains(t, string(common), "message ErrorDetails {")
+	assert.Contains(t, string(common), "message Address {")
+	assert.NotContains(t, string(common), "message User")
+}
+
+func TestGenerateSplitBySubject(t *testing.T) {
+	specPath, stdout := setupTest(t, subjectsSpec)
+	dir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", dir, "--split-by-subject"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "proto/v1/users.proto")
+	assert.Contains(t, stdout.String(), "proto/v1/products.proto")
+	assert.Contains(t, stdout.String(), "proto/v1/common.proto")
+	assert.FileExists(t, filepath.Join(dir, "proto/v1/proto.lock"))
+	assert.NoFileExists(t, filepath.Join(dir, "proto/v1/api.proto"))
+}
+
+func TestProtoMarksDeprecatedFields(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+        nickname:
+          type: string
+          deprecated: true
+        legacy:
+          type: object
+          deprecated: true
+          properties:
+            id:
+              type: string
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.Contains(t, content, "string name = 1 [json_name = \"name\"];")
+	assert.Contains(t, content, "string nickname = 2 [json_name = \"nickname\", deprecated = true];")
+	assert.Contains(t, content, "  message Legacy {\n    option deprecated = true;\n")
+	assert.Contains(t, content, "Legacy legacy = 3 [json_name = \"legacy\", deprecated = true];")
+}
+
+func TestProtoServiceDefinitions(t *testing.T) {
+	spec := strings.Replace(subjectsSpec, "  /products.create:\n    post:\n",
+		"  /products.create:\n    post:\n      summary: Create a product\n      deprecated: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath, "--proto-service"})
+
+	require.Equal(t, 0, exitCode)
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "service UsersService {\n"+
+		"  rpc Create(UsersCreateRequest) returns (UsersCreateResponse);\n"+
+		"}\n")
+	assert.Contains(t, string(content), "service ProductsService {\n"+
+		"  // Create a product\n"+
+		"  rpc Create(ProductsCreateRequest) returns (ProductsCreateResponse) {\n"+
+		"    option deprecated = true;\n"+
+		"  }\n"+
+		"}\n")
+}
+
+func TestProtoServiceSplitBySubject(t *testing.T) {
+	specPath, stdout := setupTest(t, subjectsSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath, "--proto-service", "--split-by-subject"})
+
+	require.Equal(t, 0, exitCode)
+	dir := filepath.Join(filepath.Dir(specPath), "proto/v1")
+
+	users, err := os.ReadFile(filepath.Join(dir, "users.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(users), "service UsersService {")
+	assert.NotContains(t, string(users), "ProductsService")
+
+	common, err := os.ReadFile(filepath.Join(dir, "common.proto"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(common), "service")
+}
+
+func TestProtoOmitsServiceByDefault(t *testing.T) {
+	specPath, stdout := setupTest(t, protoSpecHeader+`    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+`)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"proto", specPath})
+	require.Equal(t, 0, exitCode)
+
+	protoContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "proto/v1/api.proto"))
+	require.NoError(t, err)
+	content := string(protoContent)
+
+	assert.NotContains(t, content, "service")
+}
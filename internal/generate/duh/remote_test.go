@@ -0,0 +1,129 @@
+package duh_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFromURLClientOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/users@v1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(initTemplateSpec))
+	}))
+	defer srv.Close()
+	outputDir := t.TempDir()
+	sum := sha256.Sum256([]byte(initTemplateSpec))
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", srv.URL + "/org/users@v1.2.0",
+		"--client-only", "--checksum", checksum, "--output-dir", outputDir, "--module", "github.com/test/example"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Fetched "+srv.URL+"/org/users@v1.2.0 ("+checksum+")")
+
+	assert.FileExists(t, filepath.Join(outputDir, "proto", "v1", "api.proto"))
+	assert.NoFileExists(t, filepath.Join(outputDir, "server.go"))
+
+	// The paths of server.go are declared by the client
+	client, err := os.ReadFile(filepath.Join(outputDir, "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(client), `RPCCreateUser  = "/users.create"`)
+	assert.Contains(t, string(client), `const HeaderAPIVersion = "X-API-Version"`)
+}
+
+func TestGenerateFromURLWithoutChecksum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/users@v1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(initTemplateSpec))
+	}))
+	defer srv.Close()
+	outputDir := t.TempDir()
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", srv.URL + "/org/users@v1.2.0",
+		"--output-dir", outputDir, "--module", "github.com/test/example"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "(sha256:")
+
+	assert.FileExists(t, filepath.Join(outputDir, "client.go"))
+	assert.FileExists(t, filepath.Join(outputDir, "server.go"))
+}
+
+func TestGenerateFromURLErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/users@v1.2.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(initTemplateSpec))
+	}))
+	defer srv.Close()
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(initTemplateSpec), 0644))
+	specURL := srv.URL + "/org/users@v1.2.0"
+	mismatch := "sha256:" + hex.EncodeToString(make([]byte, sha256.Size))
+
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "ChecksumMismatch",
+			args:    []string{specURL, "--checksum", mismatch},
+			wantErr: "checksum mismatch for " + specURL,
+		},
+		{
+			name:    "InvalidChecksum",
+			args:    []string{specURL, "--checksum", "md5:abc"},
+			wantErr: "invalid --checksum value 'md5:abc': must be sha256:<hex>",
+		},
+		{
+			name:    "ChecksumOfFile",
+			args:    []string{specPath, "--checksum", mismatch},
+			wantErr: "--checksum verifies a spec URL, not a file",
+		},
+		{
+			name:    "NotFound",
+			args:    []string{srv.URL + "/org/billing@v1.0.0"},
+			wantErr: "failed to fetch " + srv.URL + "/org/billing@v1.0.0: 404 Not Found",
+		},
+		{
+			name:    "Full",
+			args:    []string{specURL, "--full"},
+			wantErr: "--full needs a local spec file, download " + specURL + " first",
+		},
+		{
+			name:    "ClientOnlyWithServer",
+			args:    []string{specURL, "--client-only", "--serve-spec"},
+			wantErr: "--client-only cannot be combined with --full, --docker, --k8s, --ci, --serve-spec or --introspect",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"generate"}, test.args...)
+			args = append(args, "--output-dir", outputDir, "--module", "github.com/test/example")
+			require.Equal(t, 2, duh.RunCmd(&stdout, &stderr, args))
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+			assert.NoFileExists(t, filepath.Join(outputDir, "client.go"))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package duh_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -132,7 +135,7 @@ func TestGeneratedServerCompiles(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	protoDir := filepath.Join(tempDir, "proto/v1")
@@ -213,7 +216,7 @@ func TestGeneratedServerStructure(t *testing.T) {
 	specPath, stdout := setupTest(t, simpleValidSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
 	assert.Contains(t, stdout.String(), "server.go")
@@ -240,11 +243,125 @@ func TestGeneratedServerStructure(t *testing.T) {
 	assert.Contains(t, content, "DO NOT EDIT")
 }
 
+func TestServerServesSpec(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	outputDir := filepath.Join(filepath.Dir(specPath), "api")
+	require.NoError(t, os.Mkdir(outputDir, 0755))
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--output-dir", outputDir, "--serve-spec"})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "  - openapi.yaml\n")
+
+	embedded, err := os.ReadFile(filepath.Join(outputDir, "openapi.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, multiOpSpec, string(embedded))
+
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "_ \"embed\"")
+	assert.Contains(t, content, "const RPCOpenAPIGet = \"/v1/openapi.get\"")
+	assert.Contains(t, content, "//go:embed openapi.yaml\nvar openAPISpec []byte")
+	assert.Contains(t, content, "case RPCOpenAPIGet:\n\t\th.handleOpenAPIGet(w, r)")
+	assert.Contains(t, content, "func (h *Handler) handleOpenAPIGet(w http.ResponseWriter, r *http.Request) {\n\tif h.handleCORS(w, r) {")
+	assert.Contains(t, content, "w.Header().Set(\"Content-Type\", \"application/yaml\")")
+}
+
+func TestServerServesSpecInOutputDir(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--serve-spec"})
+	require.Equal(t, 0, exitCode)
+	// The spec already sits next to server.go so it is not rewritten
+	assert.NotContains(t, stdout.String(), "  - openapi.yaml\n")
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), "//go:embed openapi.yaml")
+}
+
+func TestServerDoesNotServeSpecOrRPCsByDefault(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "embed")
+	assert.NotContains(t, string(serverContent), "RPCOpenAPIGet")
+	assert.NotContains(t, string(serverContent), "RPCRPCList")
+}
+
+func TestServerAPIVersion(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(multiOpSpec))
+	content := string(serverContent)
+	assert.Contains(t, content, "APIVersion   = \"1.0.0\"")
+	assert.Contains(t, content, "SpecChecksum = \"sha256:"+hex.EncodeToString(sum[:])+"\"")
+	assert.Contains(t, content, "const HeaderAPIVersion = \"X-API-Version\"")
+	assert.Contains(t, content, "\tCheckVersion bool\n")
+	assert.Contains(t, content, "if !h.checkVersion(w, r) {\n\t\t\treturn true\n\t\t}\n\t\th.handleUsersCreate(w, r)")
+	assert.Contains(t, content, "majorVersion(version) == majorVersion(APIVersion)")
+}
+
+func TestServerAPIVersionQuoted(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  version: 1.0.0\n", "  version: '1.0.0 \"beta\"'\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `APIVersion   = "1.0.0 \"beta\""`)
+
+	clientDir := t.TempDir()
+	exitCode = duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--client-only", "--output-dir", clientDir, "--module", "github.com/test/example"})
+	require.Equal(t, 0, exitCode, stdout.String())
+
+	clientContent, err := os.ReadFile(filepath.Join(clientDir, "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clientContent), `APIVersion   = "1.0.0 \"beta\""`)
+}
+
+func TestServerIntrospection(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "      summary: Get user by ID\n", "      summary: Get user by ID\n      deprecated: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--introspect"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "const RPCRPCList = \"/v1/rpc.list\"")
+	assert.Contains(t, content, `		Name:     "RPCUsersCreate",
+		Path:     RPCUsersCreate,
+		Request:  string((&pb.CreateRequest{}).ProtoReflect().Descriptor().FullName()),
+		Response: string((&pb.CreateResponse{}).ProtoReflect().Descriptor().FullName()),
+	},`)
+	assert.Contains(t, content, `		Response:   string((&pb.GetResponse{}).ProtoReflect().Descriptor().FullName()),
+		Deprecated: true,`)
+	assert.Contains(t, content, "case RPCRPCList:\n\t\th.handleRPCList(w, r)")
+	assert.Contains(t, content, "func (h *Handler) handleRPCList(w http.ResponseWriter, r *http.Request) {\n\tif h.handleCORS(w, r) {")
+	assert.NotContains(t, content, "RPCOpenAPIGet")
+}
+
 func TestServerWithMultipleOperations(t *testing.T) {
 	specPath, stdout := setupTest(t, multiOpSpec)
 	tempDir := filepath.Dir(specPath)
 
-	exitCode := duh.RunCmd(stdout, []string{"generate", specPath})
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
 	require.Equal(t, 0, exitCode)
 
 	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
@@ -271,3 +388,864 @@ func TestServerWithMultipleOperations(t *testing.T) {
 	summaryCount := strings.Count(content, "// Create a new user")
 	assert.Equal(t, 1, summaryCount)
 }
+
+func TestServerGroupsOperationsByTag(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "      summary: Create a new user\n",
+		"      summary: Create a new user\n      tags: [user accounts]\n", 1)
+	spec = strings.Replace(spec, "      summary: Get user by ID\n",
+		"      summary: Get user by ID\n      tags: [user accounts, billing]\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+
+	assert.Contains(t, content, "type UserAccountsService interface")
+	assert.NotContains(t, content, "type BillingService interface")
+	assert.Contains(t, content, "type ServiceInterface interface {\n\tUserAccountsService\n")
+	assert.Equal(t, 1, strings.Count(content, "UsersCreate(ctx context.Context"))
+	assert.Equal(t, 1, strings.Count(content, "UsersGet(ctx context.Context"))
+	assert.Equal(t, 1, strings.Count(content, "UsersUpdate(ctx context.Context"))
+}
+
+func TestServerInvalidTag(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "      summary: Create a new user\n",
+		"      summary: Create a new user\n      tags: ['42']\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	var stderr bytes.Buffer
+
+	exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "tag '42' on path /users.create cannot be used as a Go identifier")
+}
+
+func TestServerNamesMethodsFromOperationID(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "      summary: Create a new user\n",
+		"      summary: Create a new user\n      operationId: createAccount\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
+	require.NoError(t, err)
+	content := string(serverContent)
+	assert.Contains(t, content, `RPCCreateAccount = "/users.create"`)
+	assert.Contains(t, content, "CreateAccount(ctx context.Context")
+	assert.Contains(t, content, "UsersGet(ctx context.Context")
+	assert.NotContains(t, content, "UsersCreate")
+
+	exitCode = duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--path-names"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err = os.ReadFile(filepath.Join(tempDir, "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `RPCUsersCreate = "/users.create"`)
+	assert.NotContains(t, string(serverContent), "CreateAccount")
+}
+
+func TestServerOperationIDErrors(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		operationID string
+		wantErr     string
+	}{
+		{
+			name:        "InvalidIdentifier",
+			operationID: "'42'",
+			wantErr:     "operationId '42' on path /users.create cannot be used as a Go identifier",
+		},
+		{
+			name:        "Collision",
+			operationID: "usersGet",
+			wantErr:     "paths /users.create and /users.get both generate the method UsersGet",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := strings.Replace(multiOpSpec, "      summary: Create a new user\n",
+				"      summary: Create a new user\n      operationId: "+test.operationID+"\n", 1)
+			specPath, stdout := setupTest(t, spec)
+			var stderr bytes.Buffer
+
+			exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath})
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+func TestServerDuhName(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "      summary: Get user by ID\n",
+		"      summary: Get user by ID\n      operationId: getUserById\n      x-duh-name: GetProfile\n", 1)
+	spec = strings.Replace(spec, "    GetRequest:\n", "    GetRequest:\n      x-duh-name: GetProfileRequest\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(tempDir, "server.go"))
+	require.NoError(t, err)
+	content := string(serverContent)
+	assert.Contains(t, content, `RPCGetProfile  = "/users.get"`)
+	assert.Contains(t, content, "GetProfile(ctx context.Context, req *pb.GetProfileRequest, resp *pb.GetResponse) error")
+	assert.NotContains(t, content, "GetUserById")
+
+	protoContent, err := os.ReadFile(filepath.Join(tempDir, "proto", "v1", "api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "message GetProfileRequest {")
+	assert.NotContains(t, string(protoContent), "message GetRequest {")
+}
+
+func TestServerDuhNameErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		from    string
+		to      string
+		wantErr string
+	}{
+		{
+			name:    "UnexportedOperation",
+			from:    "      summary: Get user by ID\n",
+			to:      "      summary: Get user by ID\n      x-duh-name: getProfile\n",
+			wantErr: "x-duh-name 'getProfile' on path /users.get must be an exported Go identifier",
+		},
+		{
+			name:    "InvalidSchema",
+			from:    "    GetRequest:\n",
+			to:      "    GetRequest:\n      x-duh-name: Get-Profile\n",
+			wantErr: "x-duh-name 'Get-Profile' on schema GetRequest must start with an upper case letter",
+		},
+		{
+			name:    "ExistingSchema",
+			from:    "    GetRequest:\n",
+			to:      "    GetRequest:\n      x-duh-name: UpdateRequest\n",
+			wantErr: "x-duh-name UpdateRequest on schema GetRequest is already the name of a schema",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, stdout := setupTest(t, strings.Replace(multiOpSpec, test.from, test.to, 1))
+			var stderr bytes.Buffer
+
+			exitCode := duh.RunCmd(stdout, &stderr, []string{"generate", specPath})
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+const validationSpec = `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users.get:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/GetResponse'
+components:
+  schemas:
+    GetRequest:
+      type: object
+      properties:
+        id:
+          type: string
+    GetResponse:
+      type: object
+      required: [id, name, age, address]
+      properties:
+        id:
+          type: string
+          format: uuid
+        name:
+          type: string
+          minLength: 1
+          maxLength: 64
+          pattern: '^[A-Z]'
+        age:
+          type: integer
+          format: int32
+          minimum: 0
+          exclusiveMaximum: true
+          maximum: 150
+        tags:
+          type: array
+          maxItems: 10
+          items:
+            type: string
+            minLength: 1
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      required: [city]
+      properties:
+        city:
+          type: string
+`
+
+func TestServerResponseValidation(t *testing.T) {
+	specPath, stdout := setupTest(t, validationSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "func WithResponseValidation(enabled bool) HandlerOption {")
+	assert.Contains(t, content, "func WithValidationLog(log *slog.Logger) HandlerOption {")
+	assert.Contains(t, content, "func NewHandler(s ServiceInterface, opts ...HandlerOption) *Handler {")
+	assert.Contains(t, content, "if h.ValidateResponses && !h.validateResponse(w, r, \"GetResponse\", resp) {")
+	// Only the strings and messages of required can be told from their zero value
+	assert.Contains(t, content, `	"GetResponse": {
+		required: []string{"id", "name", "address"},
+		properties: []responseProperty{
+			{"id", responseRule{format: "uuid"}},
+			{"name", responseRule{pattern: regexp.MustCompile("^[A-Z]"), minLength: ptrTo(1), maxLength: ptrTo(64)}},
+			{"age", responseRule{minimum: ptrTo(float64(0)), maximum: ptrTo(float64(150)), exclusiveMaximum: true}},
+			{"tags", responseRule{items: &responseRule{minLength: ptrTo(1)}, maxItems: ptrTo(10)}},
+			{"address", responseRule{ref: "Address"}},
+		},
+	},
+	"Address": {
+		required: []string{"city"},
+	},`)
+}
+
+func TestServerResponseValidationRedact(t *testing.T) {
+	spec := strings.Replace(validationSpec, "          pattern: '^[A-Z]'\n", "          pattern: '^[A-Z]'\n          x-duh-redact: true\n", 1)
+	spec = strings.Replace(spec, "          maxItems: 10\n", "          maxItems: 10\n          x-duh-redact: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode, stdout.String())
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, `{"id", responseRule{format: "uuid"}},`)
+	assert.Contains(t, content, `{"name", responseRule{pattern: regexp.MustCompile("^[A-Z]"), minLength: ptrTo(1), maxLength: ptrTo(64), redact: true}},`)
+	assert.Contains(t, content, `{"tags", responseRule{items: &responseRule{minLength: ptrTo(1), redact: true}, maxItems: ptrTo(10), redact: true}},`)
+	assert.Contains(t, content, `errorf("'%v' does not match pattern '%s'", r.shown(value), r.pattern)`)
+}
+
+func TestServerCORS(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "func NewHandler(s ServiceInterface, opts ...HandlerOption) *Handler {")
+	assert.Contains(t, content, "func WithCORS(config CORSConfig) HandlerOption {")
+	assert.Contains(t, content, "\tAllowedOrigins []string\n")
+	assert.Contains(t, content, "\tAllowedHeaders []string\n")
+	assert.Contains(t, content, "case RPCUsersGet:\n\t\tif h.handleCORS(w, r) {\n\t\t\treturn true\n\t\t}\n\t\tif r.Method != http.MethodPost {")
+	assert.Contains(t, content, `preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""`)
+	assert.Contains(t, content, `header.Set("Access-Control-Allow-Methods", http.MethodPost)`)
+	assert.Contains(t, content, `strings.Join(append([]string{"Content-Type", HeaderAPIVersion}, h.CORS.AllowedHeaders...), ", "))`)
+	assert.Contains(t, content, `header.Set("Access-Control-Expose-Headers", HeaderAPIVersion)`)
+	assert.Contains(t, content, "w.WriteHeader(http.StatusNoContent)")
+}
+
+func TestServerBasePath(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--base-path", "/api/billing"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, `const BasePath = "/api/billing"`)
+	assert.Contains(t, server, `RPCUsersCreate = "/users.create"`)
+	assert.Contains(t, server, "path, ok := strings.CutPrefix(r.URL.Path, BasePath)\n\tif !ok {\n\t\treturn false\n\t}\n\tswitch path {")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clientContent), `fmt.Sprintf("%s%s", c.conf.Endpoint, BasePath+RPCUsersCreate)`)
+}
+
+func TestServerBasePathExtension(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "servers:\n", "x-duh-base-path: /api\nservers:\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--client-only"})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clientContent), `const BasePath = "/api"`)
+	assert.Contains(t, string(clientContent), "BasePath+RPCUsersGet")
+
+	// The flag overrides the extension
+	exitCode = duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--base-path", "/edge"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(serverContent), `const BasePath = "/edge"`)
+}
+
+func TestServerWithoutBasePath(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "BasePath")
+	assert.Contains(t, string(serverContent), "switch r.URL.Path {")
+}
+
+func TestServerBasePathErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		spec    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "TrailingSlash",
+			spec:    multiOpSpec,
+			args:    []string{"--base-path", "/api/"},
+			wantErr: "invalid --base-path value '/api/': must start with / and not end with one, like /api",
+		},
+		{
+			name:    "Relative",
+			spec:    multiOpSpec,
+			args:    []string{"--base-path", "api"},
+			wantErr: "invalid --base-path value 'api': must start with / and not end with one, like /api",
+		},
+		{
+			name:    "Extension",
+			spec:    strings.Replace(multiOpSpec, "servers:\n", "x-duh-base-path: api/v1\nservers:\n", 1),
+			wantErr: "x-duh-base-path 'api/v1' must start with / and not end with one, like /api",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, test.spec)
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"generate", specPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestServerJSONOptions(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	server := string(serverContent)
+	assert.Contains(t, server, "type JSONOptions struct {")
+	assert.Contains(t, server, "func WithJSONOptions(opts JSONOptions) HandlerOption {")
+	assert.Contains(t, server, "if err := h.readRequest(r, req); err != nil {")
+	assert.Contains(t, server, "\th.reply(w, r, resp)\n}")
+	assert.Contains(t, server, "protojson.UnmarshalOptions{DiscardUnknown: h.JSON.DiscardUnknown}")
+	assert.Contains(t, server, "protojson.MarshalOptions{EmitUnpopulated: h.JSON.EmitUnpopulated, UseProtoNames: h.JSON.UseProtoNames}")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+
+	client := string(clientContent)
+	assert.Contains(t, client, "func WithJSONRequests(opts JSONOptions) ClientOption {")
+	assert.Contains(t, client, "return c.do(r, resp)")
+	assert.Contains(t, client, "opts.DiscardUnknown = c.conf.JSON.DiscardUnknown")
+	// server.go declares JSONOptions
+	assert.NotContains(t, client, "type JSONOptions struct")
+}
+
+func TestServerRateLimiter(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "Allow(ctx context.Context, rpc string) (allowed bool, retryAfter time.Duration)")
+	assert.Contains(t, content, "func WithRateLimiter(limiter Limiter) HandlerOption {")
+	assert.Contains(t, content, "func (h *Handler) handleUsersCreate(w http.ResponseWriter, r *http.Request) {\n\taudit := h.startAudit(&w, RPCUsersCreate)\n\tdefer audit.finish(r)\n\tif !h.allow(w, r, RPCUsersCreate) {\n\t\treturn\n\t}\n\tif !h.authorize(w, r, RPCUsersCreate) {\n\t\treturn\n\t}\n\treq := requestsUsersCreate.Get().(*pb.CreateRequest)")
+	assert.Contains(t, content, `w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))`)
+	assert.Contains(t, content, "duh.ReplyWithCode(w, r, duh.CodeTooManyRequests, nil,")
+}
+
+func TestServerAuthorizer(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-auth: [bearer]\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	content := string(serverContent)
+	assert.Contains(t, content, "type Authorizer func(ctx context.Context, rpc string) error")
+	assert.Contains(t, content, "func WithAuthorizer(authorize Authorizer) HandlerOption {")
+	assert.Contains(t, content, "err = duh.NewServiceError(duh.CodeForbidden, err.Error(), nil, nil)")
+	// Every rpc is authorized, those marked x-duh-auth once authenticated
+	assert.Equal(t, 3, strings.Count(content, "if !h.authorize(w, r, RPC"))
+	assert.Contains(t, content, "r, ok := h.authenticate(w, r, RPCUsersGet, AuthBearer)\n\tif !ok {\n\t\treturn\n\t}\n\tif !h.authorize(w, r, RPCUsersGet) {")
+}
+
+func TestServerIdempotency(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.create:\n    post:\n", "  /users.create:\n    post:\n      x-duh-idempotent: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, `const HeaderIdempotencyKey = "Idempotency-Key"`)
+	assert.Contains(t, server, "Get(ctx context.Context, rpc, key string) (reply []byte, ok bool, err error)")
+	assert.Contains(t, server, "func WithIdempotencyStore(store IdempotencyStore) HandlerOption {")
+	assert.Contains(t, server, "if h.replay(w, r, RPCUsersCreate, key, resp) {")
+	assert.Contains(t, server, "_ = h.IdempotencyStore.Put(r.Context(), RPCUsersCreate, key, reply)")
+	assert.NotContains(t, server, "RPCUsersGet, key")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func WithIdempotencyKey(ctx context.Context, key string) context.Context {")
+	assert.Contains(t, client, "func NewIdempotencyKey() string {")
+	assert.Equal(t, 1, strings.Count(client, "r.Header.Set(HeaderIdempotencyKey, key)"))
+}
+
+func TestServerWithoutIdempotency(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "Idempotency")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(clientContent), "Idempotency")
+	assert.NotContains(t, string(clientContent), `"crypto/rand"`)
+}
+
+func TestServerReplayProtection(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.create:\n    post:\n", "  /users.create:\n    post:\n      x-duh-replay-protect: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, "\tHeaderNonce     = \"X-Nonce\"\n\tHeaderTimestamp = \"X-Timestamp\"\n")
+	assert.Contains(t, server, "Use(ctx context.Context, rpc, nonce string, expires time.Time) (fresh bool, err error)")
+	assert.Contains(t, server, "func WithReplayProtection(store NonceStore, window time.Duration) HandlerOption {")
+	// Checked once the caller is authorized, before the request is read
+	assert.Contains(t, server, "\tif !h.authorize(w, r, RPCUsersCreate) {\n\t\treturn\n\t}\n\tif !h.checkReplay(w, r, RPCUsersCreate) {\n\t\treturn\n\t}\n\treq := requestsUsersCreate.Get()")
+	assert.Equal(t, 1, strings.Count(server, "if !h.checkReplay("))
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func setReplayHeaders(h http.Header) {")
+	assert.Contains(t, client, "h.Set(HeaderTimestamp, strconv.FormatInt(clock.Now().Unix(), 10))")
+	assert.Equal(t, 1, strings.Count(client, "\tsetReplayHeaders(r.Header)\n"))
+	assert.NotContains(t, client, "HeaderNonce     =")
+}
+
+func TestServerWithoutReplayProtection(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "Nonce")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(clientContent), "Nonce")
+	assert.NotContains(t, string(clientContent), `"strconv"`)
+}
+
+func TestServerCompression(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, `"github.com/klauspost/compress/zstd"`)
+	assert.Contains(t, server, "func WithResponseCompression(c Compression) HandlerOption {")
+	assert.Contains(t, server, `decompressed, err := decompress(r.Header.Get("Content-Encoding"), r.Body)`)
+	assert.Contains(t, server, `if encoding := h.Compression.accepted(r.Header.Get("Accept-Encoding")); encoding != "" {`)
+	assert.Contains(t, server, "func compress(encoding string, body []byte) ([]byte, error) {")
+	// The encoder and decoders are shared rather than built for each request
+	assert.Contains(t, server, "return zstdEncoder.EncodeAll(body, nil), nil")
+	assert.Contains(t, server, "decoder, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))")
+	assert.Contains(t, server, "decoder := zstdDecoders.Get().(*zstd.Decoder)")
+	assert.NotContains(t, server, "zstd.NewWriter(&buf)")
+	assert.Contains(t, server, "if acceptWeight(acceptEncoding, encoding) > 0 {")
+	assert.Contains(t, server, "weight, _ = strconv.ParseFloat(strings.TrimSpace(value), 64)")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func WithCompression(c Compression) ClientOption {")
+	assert.Equal(t, 3, strings.Count(client, "c.encode(r, payload)"))
+	assert.Contains(t, client, `reader, err := decompress(hr.Header.Get("Content-Encoding"), hr.Body)`)
+	assert.Contains(t, client, "defer func() { _ = reader.Close() }()")
+	// server.go declares Compression
+	assert.NotContains(t, client, "type Compression struct")
+	assert.NotContains(t, client, `"github.com/klauspost/compress/zstd"`)
+
+	// A client without server.go declares it itself
+	exitCode = duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--client-only"})
+	require.Equal(t, 0, exitCode)
+
+	clientContent, err = os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clientContent), "type Compression struct")
+	assert.Contains(t, string(clientContent), `"github.com/klauspost/compress/zstd"`)
+	assert.Contains(t, string(clientContent), "var zstdDecoders = sync.Pool{")
+}
+
+func TestServerStreamRequest(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "    CreateRequest:\n      type: object\n      properties:\n",
+		"    CreateRequest:\n      type: object\n      properties:\n        users:\n          type: array\n"+
+			"          items:\n            $ref: '#/components/schemas/GetRequest'\n", 1)
+	// A list with a small maxItems is read whole
+	spec = strings.Replace(spec, "    UpdateRequest:\n      type: object\n      properties:\n",
+		"    UpdateRequest:\n      type: object\n      properties:\n        users:\n          type: array\n"+
+			"          maxItems: 10\n          items:\n            $ref: '#/components/schemas/GetRequest'\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(content)
+	assert.Contains(t, server, "func WithStreamLimits(limits StreamLimits) HandlerOption {")
+	assert.Contains(t, server, "func (h *Handler) decodeStream(dec *json.Decoder, msg proto.Message, maxItems int) error {")
+	assert.Equal(t, 1, strings.Count(server, "if err := h.streamRequest(r, req); err != nil {"))
+	assert.Equal(t, 2, strings.Count(server, "if err := h.readRequest(r, req); err != nil {"))
+
+	handler := server[strings.Index(server, "func (h *Handler) handleUsersCreate("):]
+	assert.Contains(t, handler[:strings.Index(handler, "\n}\n")], "h.streamRequest(r, req)")
+}
+
+func TestServerWithoutStreamRequest(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "StreamLimits")
+	assert.NotContains(t, string(content), "streamRequest")
+}
+
+func TestServerPooling(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(content)
+	assert.Contains(t, server, "requestsUsersGet = sync.Pool{New: func() any { return new(pb.GetRequest) }}")
+	assert.Contains(t, server, "repliesUsersGet  = sync.Pool{New: func() any { return new(pb.GetResponse) }}")
+	assert.Equal(t, 3, strings.Count(server, "defer release(&requestsUsers"))
+	assert.Equal(t, 3, strings.Count(server, "defer release(&repliesUsers"))
+	assert.Contains(t, server, "b, err := opts.MarshalAppend(buf.AvailableBuffer(), resp)")
+
+	readRequest := server[strings.Index(server, "func (h *Handler) readRequest("):]
+	readRequest = readRequest[:strings.Index(readRequest, "\n}\n")]
+	assert.Contains(t, readRequest, "buf := buffers.Get().(*bytes.Buffer)")
+	assert.Contains(t, readRequest, "if err := opts.Unmarshal(buf.Bytes(), req); err != nil {")
+	assert.Contains(t, server, "w.Header()[apiVersionKey] = apiVersionValue")
+	assert.NotContains(t, server, "accepted := make(map[string]bool)")
+}
+
+func TestServerETag(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-etag: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, "ETag(ctx context.Context, rpc string, req proto.Message) (string, error)")
+	assert.Contains(t, server, "func WithETagger(etagger ETagger) HandlerOption {")
+	assert.Contains(t, server, "h.ETagger, _ = s.(ETagger)")
+	assert.Contains(t, server, "if h.notModified(w, r, RPCUsersGet, req) {")
+	assert.Contains(t, server, "if h.ETagger == nil && h.matchETag(w, r, replyETag(resp)) {")
+	assert.Equal(t, 1, strings.Count(server, "h.notModified(w, r,"))
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func WithETag(ctx context.Context, etag *string) context.Context {")
+	assert.Contains(t, client, `var ErrNotModified = errors.New("not modified")`)
+	assert.Contains(t, client, "r.Header.Set(HeaderIfNoneMatch, *etag)")
+	assert.Equal(t, 1, strings.Count(client, "return c.doETag(r, resp)"))
+}
+
+func TestServerWithoutETag(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "ETag")
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(clientContent), "ETag")
+}
+
+func TestServerETagInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-etag: strong\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-etag 'strong' on path /users.get must be true or false")
+}
+
+func TestServerAuth(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-auth: [bearer, apikey]\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--cli"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, `const HeaderAPIKey = "X-API-Key"`)
+	assert.Contains(t, server, "Authenticate(ctx context.Context, rpc string, creds Credentials) (Principal, error)")
+	assert.Contains(t, server, "func WithAuthenticator(auth Authenticator) HandlerOption {")
+	assert.Contains(t, server, "func PrincipalFrom(ctx context.Context) (principal Principal, ok bool) {")
+	assert.Contains(t, server, "h.Authenticator, _ = s.(Authenticator)")
+	assert.Contains(t, server, "r, ok := h.authenticate(w, r, RPCUsersGet, AuthBearer, AuthAPIKey)")
+	assert.Contains(t, server, "return Credentials{Scheme: scheme, Certificates: r.TLS.VerifiedChains[0]}, true")
+	assert.Equal(t, 1, strings.Count(server, "h.authenticate(w, r,"))
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func WithCredentials(creds ClientCredentials) ClientOption {")
+	assert.Contains(t, client, "if err := c.authorize(ctx, r, AuthBearer, AuthAPIKey); err != nil {")
+	assert.Equal(t, 1, strings.Count(client, "c.authorize(ctx, r,"))
+	// The constants are those of server.go
+	assert.NotContains(t, client, `const HeaderAPIKey`)
+
+	cliContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "cmd", "testctl", "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cliContent), "Credentials: api.ClientCredentials{APIKey: flags.apiKey},")
+}
+
+func TestServerWithoutAuth(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serverContent), "Authenticator")
+	assert.NotContains(t, string(serverContent), `"crypto/x509"`)
+
+	clientContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "client.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(clientContent), "Credentials")
+}
+
+func TestServerAuthInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-auth: [basic]\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-auth on path /users.get must be a list of bearer, apikey or mtls, e.g. [bearer, apikey]")
+}
+
+func TestServerAudit(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "    UpdateRequest:\n      type: object\n      properties:\n        id:\n          type: string\n        name:\n          type: string\n",
+		"    UpdateRequest:\n      type: object\n      properties:\n        id:\n          type: string\n        name:\n          type: string\n          x-duh-redact: true\n", 1)
+	specPath, stdout := setupTest(t, spec)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(filepath.Dir(specPath), "server.go"))
+	require.NoError(t, err)
+
+	content := string(serverContent)
+	assert.Contains(t, content, "type AuditSink interface {\n\tRecord(ctx context.Context, record AuditRecord)\n}")
+	assert.Contains(t, content, "func WithAuditSink(sink AuditSink) HandlerOption {")
+	assert.NotContains(t, content, "Principal Principal")
+	assert.Equal(t, 3, strings.Count(content, "\taudit.request(req)\n"))
+	assert.Contains(t, content, "var redactedFields = map[protoreflect.Name][]string{\n\t\"UpdateRequest\": {\"name\"},\n}")
+}
+
+func TestServerRedactInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "    GetRequest:\n      type: object\n      properties:\n        id:\n          type: string\n",
+		"    GetRequest:\n      type: object\n      properties:\n        id:\n          type: string\n          x-duh-redact: yes\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-redact 'yes' on property id of schema GetRequest must be true or false")
+}
+
+func TestServerSkip(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-skip: [server, proto]\n", 1)
+	spec = strings.Replace(spec, "  /users.update:\n    post:\n", "  /users.update:\n    post:\n      x-duh-skip: [client]\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	dir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--full", "--proto-service"})
+	require.Equal(t, 0, exitCode)
+
+	serverContent, err := os.ReadFile(filepath.Join(dir, "server.go"))
+	require.NoError(t, err)
+	server := string(serverContent)
+	assert.Contains(t, server, "\tRPCUsersGet    = \"/users.get\"\n")
+	assert.NotContains(t, server, "UsersGet(ctx context.Context")
+	assert.NotContains(t, server, "handleUsersGet")
+	assert.Contains(t, server, "func (h *Handler) handleUsersUpdate(")
+
+	clientContent, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	require.NoError(t, err)
+	client := string(clientContent)
+	assert.Contains(t, client, "func (c *Client) UsersGet(")
+	assert.NotContains(t, client, "UsersUpdate")
+
+	serviceContent, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(serviceContent), "UsersGet")
+	assert.Contains(t, string(serviceContent), "func (s *Service) UsersUpdate(")
+
+	testContent, err := os.ReadFile(filepath.Join(dir, "api_test.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(testContent), "UsersGet")
+	assert.NotContains(t, string(testContent), "UsersUpdate")
+
+	protoContent, err := os.ReadFile(filepath.Join(dir, "proto/v1/api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(protoContent), "message GetRequest {")
+	assert.NotContains(t, string(protoContent), "rpc Get(")
+	assert.Contains(t, string(protoContent), "rpc Update(UpdateRequest) returns (UpdateResponse);")
+}
+
+func TestServerSkipInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-skip: [docs]\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-skip on path /users.get must be a list of server, client or proto, e.g. [server, proto]")
+	assert.Empty(t, stdout.String())
+}
+
+func TestServerAudience(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.get:\n    post:\n", "  /users.get:\n    post:\n      x-duh-audience: internal\n", 1)
+	specPath, stdout := setupTest(t, spec)
+	dir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--audience", "public", "--serve-spec"})
+	require.Equal(t, 0, exitCode)
+
+	// The spec next to server.go is the whole spec, the filtered one is embedded
+	for _, file := range []string{"server.go", "client.go", "openapi.public.yaml", "proto/v1/api.proto"} {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "users.get", file)
+		assert.NotContains(t, string(content), "GetRequest", file)
+	}
+	clientContent, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(clientContent), "func (c *Client) UsersUpdate(")
+}
+
+func TestServerReplayProtectionInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.create:\n    post:\n", "  /users.create:\n    post:\n      x-duh-replay-protect: always\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-replay-protect 'always' on path /users.create must be true or false")
+}
+
+func TestServerIdempotencyInvalid(t *testing.T) {
+	spec := strings.Replace(multiOpSpec, "  /users.create:\n    post:\n", "  /users.create:\n    post:\n      x-duh-idempotent: yes please\n", 1)
+	specPath, _ := setupTest(t, spec)
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", specPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "x-duh-idempotent 'yes please' on path /users.create must be true or false")
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,111 @@
+package duh
+
+import (
+	"fmt"
+	"slices"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// skipExtension lists the artifacts an operation is left out of, for
+// endpoints which stay documented in the spec but are internal or served by
+// another implementation
+const skipExtension = "x-duh-skip"
+
+// The artifacts x-duh-skip leaves an operation out of: the handler and
+// service of server, the client and CLI of client and the rpc of the proto
+// service of proto. The messages of the operation are still generated.
+const (
+	skipServer = "server"
+	skipClient = "client"
+	skipProto  = "proto"
+)
+
+var skipTargets = []string{skipServer, skipClient, skipProto}
+
+// duhSkip returns the x-duh-skip of the operation, or nil when it has none
+func duhSkip(op *v3.Operation, path string) ([]string, error) {
+	if op.Extensions == nil {
+		return nil, nil
+	}
+	node, ok := op.Extensions.Get(skipExtension)
+	if !ok || node == nil {
+		return nil, nil
+	}
+
+	invalid := fmt.Errorf("%s on path %s must be a list of server, client or proto, e.g. [server, proto]", skipExtension, path)
+	if node.Kind != yaml.SequenceNode {
+		return nil, invalid
+	}
+	var skip []string
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode || !slices.Contains(skipTargets, item.Value) {
+			return nil, invalid
+		}
+		if !slices.Contains(skip, item.Value) {
+			skip = append(skip, item.Value)
+		}
+	}
+	return skip, nil
+}
+
+// skipsProto reports whether x-duh-skip leaves the operation out of the proto
+// service, an invalid x-duh-skip is reported by the parser
+func skipsProto(path string, op *v3.Operation) bool {
+	skip, _ := duhSkip(op, path)
+	return slices.Contains(skip, skipProto)
+}
+
+// skipped reports whether x-duh-skip leaves the operation out of any of targets
+func (op Operation) skipped(targets ...string) bool {
+	return slices.ContainsFunc(targets, func(target string) bool {
+		return slices.Contains(op.Skip, target)
+	})
+}
+
+// omitting returns a copy of the template data without the operations
+// x-duh-skip leaves out of any of targets, or data itself when there are none
+func (d *TemplateData) omitting(targets ...string) *TemplateData {
+	if !slices.ContainsFunc(d.Operations, func(op Operation) bool { return op.skipped(targets...) }) {
+		return d
+	}
+	data := *d
+	data.Operations = slices.DeleteFunc(slices.Clone(d.Operations), func(op Operation) bool {
+		return op.skipped(targets...)
+	})
+	data.ListOps = slices.DeleteFunc(slices.Clone(d.ListOps), func(op ListOperation) bool {
+		return op.skipped(targets...)
+	})
+	// HasIdempotentOps and HasReplayOps are kept like the RPC consts, the
+	// client uses the HeaderIdempotencyKey and HeaderNonce of server.go
+	data.HasListOps = len(data.ListOps) > 0
+
+	data.TagServices = nil
+	for _, service := range d.TagServices {
+		service.Operations = slices.DeleteFunc(slices.Clone(service.Operations), func(op Operation) bool {
+			return op.skipped(targets...)
+		})
+		if len(service.Operations) > 0 {
+			data.TagServices = append(data.TagServices, service)
+		}
+	}
+	data.CLISubjects = nil
+	for _, subject := range d.CLISubjects {
+		subject.Commands = slices.DeleteFunc(slices.Clone(subject.Commands), func(command CLICommand) bool {
+			return command.skipped(targets...)
+		})
+		if len(subject.Commands) > 0 {
+			data.CLISubjects = append(data.CLISubjects, subject)
+		}
+	}
+	return &data
+}
+
+// omit renders the template data without the operations x-duh-skip leaves out
+// of any of targets
+func omit(render func(*TemplateData) ([]byte, error), targets ...string) func(*TemplateData) ([]byte, error) {
+	return func(data *TemplateData) ([]byte, error) {
+		return render(data.omitting(targets...))
+	}
+}
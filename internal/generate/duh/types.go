@@ -1,41 +1,231 @@
 package duh
 
-import "io"
+import (
+	"io"
+
+	"github.com/duh-rpc/duh-cli/internal/output"
+)
 
 type RunConfig struct {
-	Writer       io.Writer
-	SpecPath     string
+	Writer   io.Writer
+	SpecPath string
+	// MergeSpecs are more specs whose paths and components are merged with
+	// the ones of SpecPath into a single generation
+	MergeSpecs   []string
 	PackageName  string
 	OutputDir    string
 	ProtoPath    string
 	ProtoImport  string
 	ProtoPackage string
+	ModulePath   string
+	PathNames    bool
 	FullFlag     bool
-	Converter    ProtoConverter
+	ConnectFlag  bool
+	CLIFlag      bool
+	// ClientOnlyFlag generates the client without server.go
+	ClientOnlyFlag bool
+	// Checksum is the sha256:<hex> the spec at a URL must match
+	Checksum string
+	// ServeSpecFlag embeds the spec in the server and serves it at /v1/openapi.get
+	ServeSpecFlag bool
+	// IntrospectFlag serves the list of rpcs at /v1/rpc.list
+	IntrospectFlag bool
+	// DockerFlag adds a daemon main, Dockerfile and docker-compose.yaml, it implies FullFlag
+	DockerFlag bool
+	// CI is github or gitlab to add a CI workflow, it implies FullFlag
+	CI string
+	// ProtoTool is buf, the default, or protoc for the proto target of the Makefile
+	ProtoTool string
+	// ProtoOut is the directory the Go code of the proto files is written to,
+	// relative to the output directory
+	ProtoOut string
+	// LintTargets adds lint targets to the Makefile: spec, proto or vet
+	LintTargets []string
+	// BufPlugins are the name[=version] of --buf-plugin, pinning or adding
+	// plugins of buf.gen.yaml
+	BufPlugins []string
+	// BufModule is the Buf Schema Registry module of --buf-module, like
+	// buf.build/org/api, naming the module of buf.yaml and adding a push
+	// target to the Makefile. BufDeps are the modules it depends on.
+	BufModule string
+	BufDeps   []string
+	// BasePath of --base-path prefixes the paths the Handler serves and the
+	// client calls, overriding the x-duh-base-path of the spec
+	BasePath string
+	// Force renders every file, even those whose inputs are unchanged since
+	// the manifest of the last run, and overwrites the edited scaffold
+	Force bool
+	// SkipExisting keeps every scaffold file which exists, Interactive asks
+	// on Writer whether to overwrite each one edited since the last run,
+	// reading the answers from Reader
+	SkipExisting bool
+	Interactive  bool
+	Reader       io.Reader
+	// UpdateService adds a not implemented method to the existing service.go
+	// for each operation without one, without --full
+	UpdateService bool
+	// Audience generates only the operations x-duh-audience shows to it, see
+	// audience.Audiences
+	Audience string
+	// Strict fails the run when service.go has handlers of operations which
+	// are no longer in the spec
+	Strict bool
+	// K8sFlag adds Kubernetes manifests under k8s/, it implies DockerFlag
+	K8sFlag   bool
+	Converter ProtoConverter
+	// Output records the files written for --output json, it may be nil
+	Output *output.Result
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+	// Progress shows the phase and file being generated on a terminal, it may be nil
+	Progress *output.Progress
 }
 
 type TemplateData struct {
-	PackageImport  string
-	Package        string
-	ModulePath     string
-	ProtoImport    string
-	ProtoPackage   string
-	Operations     []Operation
-	ListOps        []ListOperation
-	HasListOps     bool
+	PackageImport string
+	Package       string
+	ModulePath    string
+	ProtoImport   string
+	ProtoPackage  string
+	Operations    []Operation
+	// Consts are the operations the RPC path consts are declared for, all of
+	// them, since the client and the server share the consts whatever
+	// x-duh-skip leaves out of either
+	Consts     []Operation
+	ListOps    []ListOperation
+	HasListOps bool
+	// HasIdempotentOps adds the Idempotency-Key support of x-duh-idempotent
+	HasIdempotentOps bool
+	// HasCachedOps adds the Cache of the client for x-duh-cache-ttl
+	HasCachedOps bool
+	// HasETagOps adds the ETag support of x-duh-etag
+	HasETagOps bool
+	// HasStreamOps adds the StreamLimits of the server for the operations
+	// with large lists in their requests
+	HasStreamOps bool
+	// HasReplayOps adds the NonceStore of the server and the nonce of the
+	// client for the operations marked x-duh-replay-protect
+	HasReplayOps bool
+	// HasAuthOps adds the Authenticator of the server and the credentials of
+	// the client for the operations marked x-duh-auth
+	HasAuthOps     bool
 	Timestamp      string
 	IsFullTemplate bool
+	Init           InitTemplate
 	GoModule       string
+	Connect        bool
+	CLIName        string
+	CLIEnvPrefix   string
+	// DaemonEnvPrefix starts the environment variables read by the config.go of --full
+	DaemonEnvPrefix string
+	// ServiceName names the Kubernetes objects of --k8s
+	ServiceName string
+	// DaemonName names the daemon main of --docker, cmd/<DaemonName>/main.go
+	DaemonName  string
+	CLISubjects []CLISubject
+	TagServices []TagService
+	// SpecFile is the spec copied next to server.go for go:embed, empty
+	// unless --serve-spec is given
+	SpecFile        string
+	SpecContentType string
+	// Introspect adds the /v1/rpc.list handler to the server
+	Introspect bool
+	// APIVersion is the info.version of the spec and SpecChecksum the sha256
+	// of its content, both are compiled into the generated code
+	APIVersion   string
+	SpecChecksum string
+	// ClientOnly declares the rpc paths and versions in client.go since
+	// server.go is not generated
+	ClientOnly bool
+	// BasePath prefixes the rpc paths served by the Handler and called by the
+	// client, the spec keeps the canonical paths
+	BasePath string
+	// Title and Description are the info of the spec, for the README of --full
+	Title       string
+	Description string
+	// Docker tells the README of --full that the daemon main of --docker exists
+	Docker bool
+	// SpecPath is the spec relative to the output directory and GenerateArgs
+	// the flags the CI workflow of --ci regenerates the code with
+	SpecPath     string
+	GenerateArgs string
+	// ProtoTool, ProtoOut and LintTargets shape the Makefile of --full,
+	// ProtoDir holds the proto files
+	ProtoTool   string
+	ProtoOut    string
+	ProtoDir    string
+	LintTargets []string
+	// BufPlugins are the plugins of buf.gen.yaml
+	BufPlugins []BufPlugin
+	// BufModule names the module of buf.yaml, which depends on BufDeps
+	BufModule string
+	BufDeps   []string
+	// ValidationSchemas are the schemas the responses of the server are
+	// checked against with WithResponseValidation
+	ValidationSchemas []ValidationSchema
+	// Redacted are the messages with fields the AuditSink of the server
+	// records masked
+	Redacted []RedactedMessage
+	// SeedRecords are the faker generated GetResponse records in JSON the
+	// seed.go of --full starts the service of the init template with
+	SeedRecords []string
+}
+
+// BufPlugin is a plugin of buf.gen.yaml, either Remote, pinned to Version
+// when it is not empty, or Local
+type BufPlugin struct {
+	Remote  string
+	Version string
+	Local   string
 }
 
 type Operation struct {
-	MethodName           string
-	Path                 string
-	ConstName            string
-	Summary              string
-	RequestType          string
-	ResponseType         string
+	MethodName   string
+	Path         string
+	ConstName    string
+	Summary      string
+	RequestType  string
+	ResponseType string
+	// ResponseSchema is the component schema of ResponseType
+	ResponseSchema       string
 	IsInitTemplateMethod bool
+	Deprecated           bool
+	// Idempotent is set by x-duh-idempotent, the client sends an
+	// Idempotency-Key with every call and the server deduplicates retries
+	Idempotent bool
+	// ReplayProtected is set by x-duh-replay-protect, the client sends a new
+	// nonce and the time with every call and the server rejects a call whose
+	// nonce it has seen or whose time is too far from its own
+	ReplayProtected bool
+	// CacheTTL is the x-duh-cache-ttl of the operation as a Go expression, the
+	// client keeps its replies in the Cache of WithCache that long
+	CacheTTL string
+	// ETag is set by x-duh-etag, the server sets the ETag of the reply and
+	// replies 304 Not Modified to a matching If-None-Match
+	ETag bool
+	// Auth is the x-duh-auth of the operation as a Go expression of its
+	// schemes, the server authenticates the calls with one of them and the
+	// client sends its credentials
+	Auth string
+	// StreamRequest is set when the request has a list of messages without a
+	// maxItems of at most 1,000, the server decodes it as it reads it
+	StreamRequest bool
+	// RequestExample is the request synthesized from the examples of the
+	// spec, as JSON, which the generated test sends
+	RequestExample string
+	// Tag is the first tag of the operation, which places it in a TagService
+	Tag string
+	// Skip are the artifacts x-duh-skip leaves the operation out of
+	Skip []string
+	// ConnectProcedure is the Connect procedure of the matching rpc in the proto service
+	ConnectProcedure string
+}
+
+// TagService groups the operations which share a tag into their own interface
+type TagService struct {
+	Name       string
+	Tag        string
+	Operations []Operation
 }
 
 type ListOperation struct {
@@ -44,3 +234,38 @@ type ListOperation struct {
 	ItemType      string
 	ResponseField string
 }
+
+// VersionsTemplateData describes the handler which routes requests to the
+// package generated for each version of a spec with versioned paths
+type VersionsTemplateData struct {
+	Package   string
+	Timestamp string
+	Versions  []VersionPackage
+}
+
+type VersionPackage struct {
+	// Name is the version prefix of the paths, such as v1, which is also the package name
+	Name string
+	// Field is the name of the Handler field which holds the version handler
+	Field  string
+	Import string
+}
+
+// GatewayTemplateData describes the reverse proxy of 'duh generate gateway',
+// which routes the paths of several specs to the backend serving each
+type GatewayTemplateData struct {
+	Timestamp string
+	Services  []string
+	Routes    []GatewayRoute
+	// ValidationSchemas are the request schemas of every spec, named
+	// <service>.<schema> so the specs cannot clash
+	ValidationSchemas []ValidationSchema
+}
+
+// GatewayRoute sends the requests of Path to Service after checking them
+// against Schema
+type GatewayRoute struct {
+	Path    string
+	Service string
+	Schema  string
+}
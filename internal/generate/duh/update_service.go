@@ -0,0 +1,217 @@
+package duh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// serviceUpdate is what updateService changed in service.go
+type serviceUpdate struct {
+	// Added are the methods of the operations service.go had no method for
+	Added []string
+	// Mismatched are the methods whose signature differs from the spec, each
+	// with the signature the spec expects
+	Mismatched []string
+}
+
+// updateService adds a not implemented method to service.go for each
+// operation which has none, before Shutdown or at the end of the file, and
+// reports the methods whose parameters no longer match the spec. The methods
+// already there are left as they are.
+func updateService(config RunConfig, generator *Generator, data *TemplateData) (serviceUpdate, error) {
+	var update serviceUpdate
+	path := filepath.Join(config.OutputDir, "service.go")
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return update, fmt.Errorf("%s does not exist, run 'duh generate --full' to scaffold it", path)
+	}
+	if err != nil {
+		return update, fmt.Errorf("failed to read service.go: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return update, fmt.Errorf("failed to parse service.go: %w", err)
+	}
+	methods := serviceMethods(file.Decls)
+
+	var missing []Operation
+	for _, op := range data.Operations {
+		method, ok := methods[op.MethodName]
+		if !ok {
+			missing = append(missing, op)
+			update.Added = append(update.Added, op.MethodName)
+			continue
+		}
+		want := fmt.Sprintf("(context.Context, *%s, *%s) error", op.RequestType, op.ResponseType)
+		if have := signature(method.Type); have != want {
+			update.Mismatched = append(update.Mismatched, fmt.Sprintf("%s%s, the spec expects %s",
+				op.MethodName, have, want))
+		}
+	}
+	if len(missing) == 0 {
+		return update, nil
+	}
+
+	stubs, err := generator.RenderServiceStubs(missing)
+	if err != nil {
+		return update, fmt.Errorf("failed to render service.go: %w", err)
+	}
+	offset := len(content)
+	if shutdown, ok := methods["Shutdown"]; ok {
+		start := shutdown.Pos()
+		if shutdown.Doc != nil {
+			start = shutdown.Doc.Pos()
+		}
+		offset = fset.Position(start).Offset
+	}
+	var buf bytes.Buffer
+	buf.Write(content[:offset])
+	// Each stub starts with a newline, gofmt removes the extra blank lines
+	buf.Write(stubs)
+	buf.WriteString("\n")
+	buf.Write(content[offset:])
+
+	code, err := format.Source(addImports(buf.Bytes(), fset, file, "github.com/duh-rpc/duh.go/v2", data.ProtoImport))
+	if err != nil {
+		return update, fmt.Errorf("failed to format service.go: %w", err)
+	}
+	if err := writeFile(path, code); err != nil {
+		return update, fmt.Errorf("failed to write service.go: %w", err)
+	}
+	return update, nil
+}
+
+// staleHandlers returns the methods of Service in the service.go at path which
+// are shaped like a handler but have no operation in the spec, in the order
+// they are declared. A missing service.go has none, and one which does not
+// parse, such as one left with merge conflicts, is not checked.
+func staleHandlers(path string, data *TemplateData) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	operations := make(map[string]bool, len(data.Operations))
+	for _, op := range data.Operations {
+		operations[op.MethodName] = true
+	}
+
+	var stale []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || operations[fn.Name.Name] || len(serviceMethods([]ast.Decl{fn})) == 0 {
+			continue
+		}
+		if handlerSignature.MatchString(signature(fn.Type)) {
+			stale = append(stale, fn.Name.Name)
+		}
+	}
+	return stale
+}
+
+// handlerSignature matches the signature of the handler of an operation
+var handlerSignature = regexp.MustCompile(`^\(context\.Context, \*[\w.]+, \*[\w.]+\) error$`)
+
+// serviceMethods returns the methods of Service among decls by name
+func serviceMethods(decls []ast.Decl) map[string]*ast.FuncDecl {
+	methods := make(map[string]*ast.FuncDecl)
+	for _, decl := range decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if ident, ok := recv.(*ast.Ident); ok && ident.Name == "Service" {
+			methods[fn.Name.Name] = fn
+		}
+	}
+	return methods
+}
+
+// signature returns the parameter and result types of a method, like
+// (context.Context, *pb.GetRequest, *pb.GetResponse) error
+func signature(fn *ast.FuncType) string {
+	var params []string
+	for _, field := range fn.Params.List {
+		for range max(len(field.Names), 1) {
+			params = append(params, types.ExprString(field.Type))
+		}
+	}
+	var results []string
+	if fn.Results != nil {
+		for _, field := range fn.Results.List {
+			for range max(len(field.Names), 1) {
+				results = append(results, types.ExprString(field.Type))
+			}
+		}
+	}
+	sig := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return sig
+	case 1:
+		return sig + " " + results[0]
+	}
+	return sig + " (" + strings.Join(results, ", ") + ")"
+}
+
+// addImports adds the paths file does not import yet to the first import
+// declaration of content, which file was parsed from, or after the package
+// clause without one. The stubs use duh and the proto package as pb. Only the
+// part of content before the methods may have changed since it was parsed.
+func addImports(content []byte, fset *token.FileSet, file *ast.File, paths ...string) []byte {
+	imported := make(map[string]bool)
+	for _, spec := range file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			imported[path] = true
+		}
+	}
+	var lines []string
+	for _, path := range paths {
+		if imported[path] {
+			continue
+		}
+		line := strconv.Quote(path)
+		if path != "github.com/duh-rpc/duh.go/v2" {
+			line = "pb " + line
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return content
+	}
+	specs := strings.Join(lines, "\n\t")
+	for _, decl := range file.Decls {
+		imports, ok := decl.(*ast.GenDecl)
+		if !ok || imports.Tok != token.IMPORT {
+			continue
+		}
+		start, end := fset.Position(imports.Pos()).Offset, fset.Position(imports.End()).Offset
+		if imports.Lparen.IsValid() {
+			at := fset.Position(imports.Lparen).Offset + 1
+			return slices.Concat(content[:at], []byte("\n\t"+specs), content[at:])
+		}
+		// A single import becomes a block with the new ones
+		single := string(content[start+len("import") : end])
+		block := "import (\n\t" + strings.TrimSpace(single) + "\n\t" + specs + "\n)"
+		return slices.Concat(content[:start], []byte(block), content[end:])
+	}
+	end := fset.Position(file.Name.End()).Offset
+	return slices.Concat(content[:end], []byte("\n\nimport (\n\t"+specs+"\n)"), content[end:])
+}
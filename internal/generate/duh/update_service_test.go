@@ -0,0 +1,143 @@
+package duh_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateUpdateService(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	dir := filepath.Dir(specPath)
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--full"}))
+
+	const stub = `return duh.NewServiceError(duh.CodeNotImplemented, "UsersCreate not implemented", nil, nil)`
+	service, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	service = []byte(strings.Replace(string(service), stub, "return nil", 1))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), service, 0644))
+
+	// The spec adds /users.get and /users.update after /users.create
+	require.NoError(t, os.WriteFile(specPath, []byte(multiOpSpec), 0644))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--update-service"}))
+	assert.Contains(t, stdout.String(), "✓ Added 2 method(s) to service.go\n  - UsersGet\n  - UsersUpdate\n")
+	assert.Contains(t, stdout.String(), "  - service.go\n")
+	assert.NotContains(t, stdout.String(), "do not match")
+
+	updated, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(updated), "resp *pb.CreateResponse) error {\n\treturn nil\n}\n\n"+
+		"func (s *Service) UsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse) error {\n"+
+		"\treturn duh.NewServiceError(duh.CodeNotImplemented, \"UsersGet not implemented\", nil, nil)\n}\n\n"+
+		"func (s *Service) UsersUpdate(")
+	assert.Contains(t, string(updated), "not implemented\", nil, nil)\n}\n\nfunc (s *Service) Shutdown(ctx context.Context) error {\n")
+
+	// Every operation has a method now
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--update-service"}))
+	assert.NotContains(t, stdout.String(), "Added")
+	again, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Equal(t, string(updated), string(again))
+}
+
+func TestGenerateUpdateServiceMismatch(t *testing.T) {
+	specPath, stdout := setupTest(t, multiOpSpec)
+	dir := filepath.Dir(specPath)
+
+	// A hand written service.go without the duh and proto imports
+	service := "package api\n\nimport \"context\"\n\ntype Service struct{}\n\n" +
+		"func (s *Service) UsersCreate(ctx context.Context, req *CreateRequest) error {\n\treturn nil\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), []byte(service), 0644))
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--update-service"}))
+	assert.Contains(t, stdout.String(), "✓ Added 2 method(s) to service.go\n  - UsersGet\n  - UsersUpdate\n")
+	assert.Contains(t, stdout.String(), "✗ 1 method(s) of service.go do not match the spec\n"+
+		"  - UsersCreate(context.Context, *CreateRequest) error, "+
+		"the spec expects (context.Context, *pb.CreateRequest, *pb.CreateResponse) error\n")
+
+	updated, err := os.ReadFile(filepath.Join(dir, "service.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(updated), "import (\n\t\"context\"\n\t\"github.com/duh-rpc/duh.go/v2\"\n"+
+		"\tpb \"github.com/example/test/proto/v1\"\n)\n")
+	assert.True(t, strings.HasSuffix(string(updated), "return nil\n}\n\n"+
+		"func (s *Service) UsersGet(ctx context.Context, req *pb.GetRequest, resp *pb.GetResponse) error {\n"+
+		"\treturn duh.NewServiceError(duh.CodeNotImplemented, \"UsersGet not implemented\", nil, nil)\n}\n\n"+
+		"func (s *Service) UsersUpdate(ctx context.Context, req *pb.UpdateRequest, resp *pb.UpdateResponse) error {\n"+
+		"\treturn duh.NewServiceError(duh.CodeNotImplemented, \"UsersUpdate not implemented\", nil, nil)\n}\n"))
+}
+
+func TestGenerateUpdateServiceErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "NoService",
+			args:    []string{"--update-service"},
+			wantErr: "service.go does not exist, run 'duh generate --full' to scaffold it",
+		},
+		{
+			name:    "Full",
+			args:    []string{"--update-service", "--full"},
+			wantErr: "--update-service cannot be combined with --full, which merges the regenerated code into service.go, or --client-only",
+		},
+		{
+			name:    "ClientOnly",
+			args:    []string{"--update-service", "--client-only"},
+			wantErr: "--update-service cannot be combined with --full, which merges the regenerated code into service.go, or --client-only",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, _ := setupTest(t, simpleValidSpec)
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"generate", specPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestGenerateReportsStaleHandlers(t *testing.T) {
+	specPath, stdout := setupTest(t, simpleValidSpec)
+	dir := filepath.Dir(specPath)
+
+	// UsersDelete lost its endpoint, validate is not a handler
+	service := "package api\n\nimport (\n\t\"context\"\n\n\tpb \"github.com/example/test/proto/v1\"\n)\n\n" +
+		"type Service struct{}\n\n" +
+		"func (s *Service) UsersCreate(ctx context.Context, req *pb.CreateRequest, resp *pb.CreateResponse) error {\n\treturn nil\n}\n\n" +
+		"func (s *Service) UsersDelete(ctx context.Context, req *pb.DeleteRequest, resp *pb.DeleteResponse) error {\n\treturn nil\n}\n\n" +
+		"func (s *Service) validate(ctx context.Context) error {\n\treturn nil\n}\n\n" +
+		"func (s *Service) Shutdown(ctx context.Context) error {\n\treturn nil\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), []byte(service), 0644))
+
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath}))
+	assert.Contains(t, stdout.String(), "✗ 1 method(s) of service.go have no operation in the spec, "+
+		"remove them or restore their endpoints\n  - UsersDelete\n")
+	assert.Contains(t, stdout.String(), "✓ Generated")
+
+	var stdout2, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout2, &stderr, []string{"generate", specPath, "--strict"})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stdout2.String(), "  - UsersDelete\n")
+	assert.Contains(t, stderr.String(), "--strict: service.go has 1 handler(s) without an operation in the spec")
+
+	// Removing the handler passes --strict
+	service = strings.Replace(service, "func (s *Service) UsersDelete(ctx context.Context, req *pb.DeleteRequest, resp *pb.DeleteResponse) error {\n\treturn nil\n}\n\n", "", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), []byte(service), 0644))
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(stdout, stdout, []string{"generate", specPath, "--strict"}))
+	assert.NotContains(t, stdout.String(), "no operation in the spec")
+}
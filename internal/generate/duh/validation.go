@@ -0,0 +1,216 @@
+package duh
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+// ValidationSchema is a schema a response uses, reduced to the constraints of
+// the spec the proto types cannot enforce, which WithResponseValidation checks
+type ValidationSchema struct {
+	Name string
+	// Required are the required string and object properties, proto3 cannot
+	// tell an unset number, boolean or list from its zero value
+	Required   []string
+	Properties []ValidationProperty
+}
+
+// ValidationProperty is a property with constraints, Rule is the Go literal
+// of the rule checking its value
+type ValidationProperty struct {
+	Name string
+	Rule string
+}
+
+// validationSchemas collects the response schemas of the operations and every
+// schema they reference, in the order they are first reached
+func (p *Parser) validationSchemas(ops []Operation) []ValidationSchema {
+	c := &schemaCollector{seen: make(map[string]bool)}
+	for _, op := range ops {
+		c.add(op.ResponseSchema, p.resolveSchemaRef(op.ResponseSchema))
+	}
+	return c.schemas
+}
+
+// requestValidationSchemas collects the request schemas of the operations and
+// every schema they reference, their names prefixed with prefix so the schemas
+// of several specs share one map in the gateway
+func (p *Parser) requestValidationSchemas(ops []Operation, prefix string) []ValidationSchema {
+	c := &schemaCollector{seen: make(map[string]bool), prefix: prefix}
+	for _, op := range ops {
+		schema := strings.TrimPrefix(op.RequestType, "pb.")
+		c.add(schema, p.resolveSchemaRef(schema))
+	}
+	return c.schemas
+}
+
+type schemaCollector struct {
+	seen    map[string]bool
+	schemas []ValidationSchema
+	// prefix starts the names of the collected schemas
+	prefix string
+}
+
+func (c *schemaCollector) add(name string, proxy *base.SchemaProxy) {
+	if c.seen[name] || proxy == nil || proxy.Schema() == nil {
+		return
+	}
+	c.seen[name] = true
+
+	// The schemas of allOf are merged into one message by the proto converter
+	parts := []*base.Schema{proxy.Schema()}
+	for _, part := range proxy.Schema().AllOf {
+		if part.Schema() != nil {
+			parts = append(parts, part.Schema())
+		}
+	}
+
+	// Appended before its properties are walked, which may append others
+	idx := len(c.schemas)
+	c.schemas = append(c.schemas, ValidationSchema{Name: c.prefix + name})
+
+	var required []string
+	var props []ValidationProperty
+	for _, schema := range parts {
+		if schema.Properties == nil {
+			continue
+		}
+		for prop, propProxy := range schema.Properties.FromOldest() {
+			if slices.Contains(schema.Required, prop) && presence(propProxy) {
+				required = append(required, prop)
+			}
+			if r := c.rule(name+"."+prop, propProxy, redacted(propProxy)); r != "" {
+				props = append(props, ValidationProperty{Name: prop, Rule: r})
+			}
+		}
+	}
+	c.schemas[idx].Required = required
+	c.schemas[idx].Properties = props
+}
+
+// rule returns the Go literal of the rule checking a value of the schema, or
+// "" when nothing of it can be checked. name is used for an inline object and
+// redact leaves the value of a property marked x-duh-redact out of the errors.
+func (c *schemaCollector) rule(name string, proxy *base.SchemaProxy, redact bool) string {
+	if proxy == nil || proxy.Schema() == nil {
+		return ""
+	}
+	schema := proxy.Schema()
+
+	var fields []string
+	if ref := c.ref(name, proxy); ref != "" {
+		fields = append(fields, "ref: "+strconv.Quote(ref))
+	}
+	if schema.Items != nil && schema.Items.IsA() {
+		if items := c.rule(name, schema.Items.A, redact); items != "" {
+			fields = append(fields, "items: &"+items)
+		}
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+		if values := c.rule(name, schema.AdditionalProperties.A, redact); values != "" {
+			fields = append(fields, "values: &"+values)
+		}
+	}
+	if validationFormats[schema.Format] {
+		fields = append(fields, "format: "+strconv.Quote(schema.Format))
+	}
+	if schema.Pattern != "" {
+		fields = append(fields, fmt.Sprintf("pattern: regexp.MustCompile(%s)", strconv.Quote(schema.Pattern)))
+	}
+	fields = appendInt(fields, "minLength", schema.MinLength)
+	fields = appendInt(fields, "maxLength", schema.MaxLength)
+	fields = appendInt(fields, "minItems", schema.MinItems)
+	fields = appendInt(fields, "maxItems", schema.MaxItems)
+
+	// exclusiveMinimum and exclusiveMaximum are booleans in OpenAPI 3.0 and
+	// bounds of their own in 3.1
+	minimum, maximum := schema.Minimum, schema.Maximum
+	var exclusiveMinimum, exclusiveMaximum bool
+	if e := schema.ExclusiveMinimum; e != nil {
+		exclusiveMinimum = e.IsA() && e.A
+		if e.IsB() {
+			minimum, exclusiveMinimum = &e.B, true
+		}
+	}
+	if e := schema.ExclusiveMaximum; e != nil {
+		exclusiveMaximum = e.IsA() && e.A
+		if e.IsB() {
+			maximum, exclusiveMaximum = &e.B, true
+		}
+	}
+	if minimum != nil {
+		fields = append(fields, "minimum: ptrTo(float64("+strconv.FormatFloat(*minimum, 'g', -1, 64)+"))")
+		if exclusiveMinimum {
+			fields = append(fields, "exclusiveMinimum: true")
+		}
+	}
+	if maximum != nil {
+		fields = append(fields, "maximum: ptrTo(float64("+strconv.FormatFloat(*maximum, 'g', -1, 64)+"))")
+		if exclusiveMaximum {
+			fields = append(fields, "exclusiveMaximum: true")
+		}
+	}
+
+	if len(fields) == 0 {
+		return ""
+	}
+	if redact {
+		fields = append(fields, "redact: true")
+	}
+	return "responseRule{" + strings.Join(fields, ", ") + "}"
+}
+
+// ref adds the object the schema describes to the collected schemas and
+// returns its name, or "" when the schema is not an object
+func (c *schemaCollector) ref(name string, proxy *base.SchemaProxy) string {
+	if proxy.IsReference() {
+		ref := extractSchemaName(proxy.GetReference())
+		if !isObject(proxy.Schema()) {
+			return ""
+		}
+		c.add(ref, proxy)
+		return c.prefix + ref
+	}
+	if proxy.Schema().Properties == nil || proxy.Schema().Properties.Len() == 0 {
+		return ""
+	}
+	c.add(name, proxy)
+	return c.prefix + name
+}
+
+// validationFormats are the string formats WithResponseValidation checks
+var validationFormats = map[string]bool{
+	"date-time": true,
+	"date":      true,
+	"uuid":      true,
+	"email":     true,
+}
+
+// presence reports whether an unset value of the schema is missing from the
+// JSON of a proto message rather than its zero value
+func presence(proxy *base.SchemaProxy) bool {
+	schema := proxy.Schema()
+	if schema == nil {
+		return false
+	}
+	return isObject(schema) || slices.Contains(schema.Type, "string")
+}
+
+func isObject(schema *base.Schema) bool {
+	if schema == nil {
+		return false
+	}
+	return slices.Contains(schema.Type, "object") || (len(schema.Type) == 0 && schema.Properties != nil) ||
+		len(schema.AllOf) > 0
+}
+
+func appendInt(fields []string, name string, value *int64) []string {
+	if value == nil {
+		return fields
+	}
+	return append(fields, fmt.Sprintf("%s: ptrTo(%d)", name, *value))
+}
@@ -0,0 +1,392 @@
+package duh
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var versionPathRegex = regexp.MustCompile(`^/(v\d+)(/.+)$`)
+
+// specVersion is the part of a spec served under a single version prefix,
+// rewritten as a standalone spec without the prefix
+type specVersion struct {
+	Name    string
+	Content []byte
+}
+
+// hasVersionedPaths reports whether any path of the parsed spec starts with a
+// version, which saves splitVersions parsing the yaml of unversioned specs
+func hasVersionedPaths(spec *v3.Document) bool {
+	if spec.Paths == nil || spec.Paths.PathItems == nil {
+		return false
+	}
+	for path := range spec.Paths.PathItems.KeysFromOldest() {
+		if versionPathRegex.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitVersions splits a spec whose paths start with a version prefix such as
+// /v1/users.create into one spec per version. It returns nil when no path has
+// a version prefix.
+func splitVersions(content []byte) ([]specVersion, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+
+	paths := mapValue(root.Content[0], "paths")
+	if paths == nil {
+		return nil, nil
+	}
+
+	var names, unversioned []string
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		matches := versionPathRegex.FindStringSubmatch(paths.Content[i].Value)
+		if matches == nil {
+			unversioned = append(unversioned, paths.Content[i].Value)
+			continue
+		}
+		if !seen[matches[1]] {
+			seen[matches[1]] = true
+			names = append(names, matches[1])
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if len(unversioned) > 0 {
+		return nil, fmt.Errorf("path %s has no version prefix: every path must start with a version such as /%s/ when any path does",
+			unversioned[0], names[0])
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return versionNumber(names[i]) < versionNumber(names[j])
+	})
+
+	var versions []specVersion
+	for _, name := range names {
+		doc, err := versionDocument(root.Content[0], name)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := encodeYAML(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		versions = append(versions, specVersion{Name: name, Content: out})
+	}
+	return versions, nil
+}
+
+// versionDocument copies the spec keeping only the paths of the version, with the
+// prefix moved from the paths to the server URLs and the schemas the version does
+// not use removed
+func versionDocument(source *yaml.Node, version string) (*yaml.Node, error) {
+	doc := copyNode(source)
+
+	paths := mapValue(doc, "paths")
+	var content []*yaml.Node
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		matches := versionPathRegex.FindStringSubmatch(paths.Content[i].Value)
+		if matches[1] != version {
+			continue
+		}
+		paths.Content[i].Value = matches[2]
+		content = append(content, paths.Content[i], paths.Content[i+1])
+	}
+	paths.Content = content
+
+	if servers := mapValue(doc, "servers"); servers != nil {
+		for _, server := range servers.Content {
+			if url := mapValue(server, "url"); url != nil {
+				url.Value = strings.TrimSuffix(url.Value, "/") + "/" + version
+			}
+		}
+	}
+
+	schemas := mapValue(mapValue(doc, "components"), "schemas")
+	if schemas == nil {
+		return doc, nil
+	}
+
+	reachable := reachableSchemas(doc, schemas)
+	content = nil
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		if reachable[schemas.Content[i].Value] {
+			content = append(content, schemas.Content[i], schemas.Content[i+1])
+		}
+	}
+	schemas.Content = content
+
+	// Schemas named after the version, such as V2CreateRequest, lose the prefix
+	// so the generated code of every version uses the same names
+	prefix := strings.ToUpper(version[:1]) + version[1:]
+	renames := make(map[string]string)
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name := schemas.Content[i].Value
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" || rest[0] < 'A' || rest[0] > 'Z' {
+			continue
+		}
+		if mapValue(schemas, rest) != nil {
+			return nil, fmt.Errorf("schemas %s and %s are both named %s in the %s package", name, rest, rest, version)
+		}
+		renames[name] = rest
+	}
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		if to, ok := renames[schemas.Content[i].Value]; ok {
+			schemas.Content[i].Value = to
+		}
+	}
+	walkScalars(doc, func(n *yaml.Node) {
+		if name, ok := schemaRefName(n.Value); ok {
+			if to, ok := renames[name]; ok {
+				n.Value = schemaRefPrefix + to + strings.TrimPrefix(n.Value, schemaRefPrefix+name)
+			}
+		}
+	})
+
+	return doc, nil
+}
+
+// reachableSchemas returns every schema referenced from outside components/schemas,
+// directly or through other schemas
+func reachableSchemas(doc, schemas *yaml.Node) map[string]bool {
+	var names []string
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "components" {
+			names = append(names, schemaRefs(doc.Content[i+1])...)
+			continue
+		}
+		components := doc.Content[i+1]
+		for j := 0; j+1 < len(components.Content); j += 2 {
+			if components.Content[j].Value != "schemas" {
+				names = append(names, schemaRefs(components.Content[j+1])...)
+			}
+		}
+	}
+
+	result := make(map[string]bool)
+	for len(names) > 0 {
+		name := names[0]
+		names = names[1:]
+		if result[name] {
+			continue
+		}
+		result[name] = true
+		if schema := mapValue(schemas, name); schema != nil {
+			names = append(names, schemaRefs(schema)...)
+		}
+	}
+	return result
+}
+
+func schemaRefs(node *yaml.Node) []string {
+	var names []string
+	walkScalars(node, func(n *yaml.Node) {
+		if name, ok := schemaRefName(n.Value); ok {
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// schemaRefName returns the schema a reference such as #/components/schemas/User
+// points to
+func schemaRefName(value string) (string, bool) {
+	rest, ok := strings.CutPrefix(value, schemaRefPrefix)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name, name != ""
+}
+
+// walkScalars calls fn for every scalar value in the tree, mapping keys excluded
+func walkScalars(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkScalars(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walkScalars(node.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		fn(node)
+	}
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+func versionNumber(version string) int {
+	n, _ := strconv.Atoi(version[1:])
+	return n
+}
+
+// runVersions generates a package per version below the output directory and a
+// handler in the output directory that routes requests to them by version prefix
+func runVersions(config RunConfig, versions []specVersion) error {
+	if config.FullFlag || config.CLIFlag {
+		return fmt.Errorf("--full and --cli are not supported for specs with versioned paths")
+	}
+	if config.ServeSpecFlag || config.IntrospectFlag {
+		return fmt.Errorf("--serve-spec and --introspect are not supported for specs with versioned paths")
+	}
+	if config.ProtoImport != "" || config.ProtoPackage != "" {
+		return fmt.Errorf("--proto-import and --proto-package are not supported for specs with versioned paths")
+	}
+	if config.ProtoTool == "protoc" || (config.ProtoOut != "" && config.ProtoOut != ".") {
+		return fmt.Errorf("--proto-tool protoc and --proto-out are not supported for specs with versioned paths")
+	}
+
+	genConfig, err := NewConfig(config.PackageName, config.OutputDir, config.ProtoPath, "", "")
+	if err != nil {
+		return err
+	}
+	genConfig.ModulePath = config.ModulePath
+	genConfig.PathNames = config.PathNames
+	modulePath, err := genConfig.DetectModulePath()
+	if err != nil {
+		return err
+	}
+
+	// Every version is validated before any code is written. The violations are
+	// printed since the version specs only exist in memory and cannot be linted.
+	specs := make([]*v3.Document, len(versions))
+	for i, version := range versions {
+		spec, err := lint.Parse(version.Content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", version.Name, err)
+		}
+
+		result := lint.Validate(spec, config.SpecPath, nil)
+		config.Log.Rules(result)
+		if !result.Valid() {
+			lint.Print(config.Writer, result)
+			config.Output.AddViolations(config.SpecPath, result.Violations)
+			return fmt.Errorf("%w for %s", lint.ErrValidation, version.Name)
+		}
+		specs[i] = spec
+	}
+
+	data := &VersionsTemplateData{Package: genConfig.PackageName}
+	var filesGenerated []string
+	changes := output.Changes{FirstRun: true}
+	for i, version := range versions {
+		outputDir := filepath.Join(genConfig.OutputDir, version.Name)
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", outputDir, err)
+		}
+
+		versionConfig := config
+		versionConfig.PackageName = version.Name
+		versionConfig.OutputDir = outputDir
+		versionConfig.ProtoPath = filepath.Join("proto", version.Name, "api.proto")
+
+		files, versionChanges, err := generate(versionConfig, specs[i], version.Content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", version.Name, err)
+		}
+		for _, file := range files {
+			filesGenerated = append(filesGenerated, filepath.Join(version.Name, file))
+		}
+		// The paths of the operations already start with the version
+		changes.FirstRun = changes.FirstRun && versionChanges.FirstRun
+		for _, list := range []struct{ from, to *[]string }{
+			{&versionChanges.FilesAdded, &changes.FilesAdded},
+			{&versionChanges.FilesUpdated, &changes.FilesUpdated},
+			{&versionChanges.FilesUnchanged, &changes.FilesUnchanged},
+		} {
+			for _, file := range *list.from {
+				*list.to = append(*list.to, filepath.Join(version.Name, file))
+			}
+		}
+		for _, message := range versionChanges.MessagesAdded {
+			changes.MessagesAdded = append(changes.MessagesAdded, version.Name+"."+message)
+		}
+		changes.OperationsAdded = append(changes.OperationsAdded, versionChanges.OperationsAdded...)
+		changes.OperationsRemoved = append(changes.OperationsRemoved, versionChanges.OperationsRemoved...)
+
+		data.Versions = append(data.Versions, VersionPackage{
+			Name:   version.Name,
+			Field:  strings.ToUpper(version.Name[:1]) + version.Name[1:],
+			Import: path.Join(genConfig.ConstructPackageImport(modulePath), version.Name),
+		})
+	}
+
+	generator, err := NewGenerator()
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	config.Log.Rendering("handler.go")
+	handlerCode, err := generator.RenderVersions(data)
+	if err != nil {
+		return fmt.Errorf("failed to render handler.go: %w", err)
+	}
+
+	handlerPath := filepath.Join(genConfig.OutputDir, "handler.go")
+	if fileExists(handlerPath) {
+		changes.FilesUpdated = append(changes.FilesUpdated, "handler.go")
+	} else {
+		changes.FilesAdded = append(changes.FilesAdded, "handler.go")
+	}
+	if err := writeFile(handlerPath, handlerCode); err != nil {
+		return fmt.Errorf("failed to write handler.go: %w", err)
+	}
+	filesGenerated = append(filesGenerated, "handler.go")
+
+	printGenerated(config, filesGenerated)
+	printChanges(config, changes)
+
+	var dirs []string
+	for _, version := range data.Versions {
+		dirs = append(dirs, filepath.Join(genConfig.OutputDir, version.Name))
+	}
+	_, _ = fmt.Fprintf(config.Writer, "\nNext steps:\n")
+	_, _ = fmt.Fprintf(config.Writer, "  1. Run 'buf generate' in %s to generate Go code from proto files\n", strings.Join(dirs, ", "))
+	_, _ = fmt.Fprintf(config.Writer, "  2. Run 'go mod tidy' to update dependencies\n")
+	return nil
+}
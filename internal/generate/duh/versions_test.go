@@ -0,0 +1,344 @@
+package duh_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	duh "github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateVersionedPaths(t *testing.T) {
+	specPath, stdout := setupTest(t, `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /v1/users.create:
+    post:
+      summary: Create a new user
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+  /v1/users.get:
+    post:
+      summary: Get user by ID
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/GetResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+  /v2/users.update:
+    post:
+      summary: Update a user
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/V2UpdateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UpdateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+    GetRequest:
+      type: object
+      properties:
+        id:
+          type: string
+    V2UpdateRequest:
+      type: object
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+    CreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    GetResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    UpdateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    ErrorDetails:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+`)
+	tempDir := filepath.Dir(specPath)
+
+	exitCode := duh.RunCmd(stdout, stdout, []string{"generate", specPath})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "v1/server.go")
+	assert.Contains(t, stdout.String(), "v2/server.go")
+	assert.Contains(t, stdout.String(), "handler.go")
+
+	v1Server, err := os.ReadFile(filepath.Join(tempDir, "v1", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(v1Server), "package v1\n")
+	assert.Contains(t, string(v1Server), `RPCUsersCreate = "/users.create"`)
+	assert.NotContains(t, string(v1Server), "UsersUpdate")
+
+	v2Server, err := os.ReadFile(filepath.Join(tempDir, "v2", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(v2Server), "package v2\n")
+	assert.Contains(t, string(v2Server), `RPCUsersUpdate = "/users.update"`)
+	assert.Contains(t, string(v2Server), "req *pb.UpdateRequest")
+	assert.NotContains(t, string(v2Server), "UsersCreate")
+
+	v1Proto, err := os.ReadFile(filepath.Join(tempDir, "v1", "proto", "v1", "api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(v1Proto), "package duh.api.v1;")
+	assert.Contains(t, string(v1Proto), "message CreateRequest {")
+	assert.NotContains(t, string(v1Proto), "message UpdateRequest {")
+
+	v2Proto, err := os.ReadFile(filepath.Join(tempDir, "v2", "proto", "v2", "api.proto"))
+	require.NoError(t, err)
+	assert.Contains(t, string(v2Proto), "package duh.api.v2;")
+	assert.Contains(t, string(v2Proto), "message UpdateRequest {")
+	assert.NotContains(t, string(v2Proto), "message CreateRequest {")
+
+	handler, err := os.ReadFile(filepath.Join(tempDir, "handler.go"))
+	require.NoError(t, err)
+	content := string(handler)
+	assert.Contains(t, content, "package api\n")
+	assert.Contains(t, content, `v1 "github.com/example/test/v1"`)
+	assert.Contains(t, content, `v2 "github.com/example/test/v2"`)
+	assert.Contains(t, content, "func NewHandler(v1Service v1.ServiceInterface, v2Service v2.ServiceInterface) *Handler")
+	assert.Contains(t, content, "case \"v2\":\n\t\treturn h.V2.ServeHTTP(w, r)")
+}
+
+func TestGenerateVersionedPathsErrors(t *testing.T) {
+	spec := `openapi: 3.0.0
+info:
+  title: Test API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /v1/users.create:
+    post:
+      summary: Create a new user
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+  /v1/users.get:
+    post:
+      summary: Get user by ID
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/GetResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+  /v2/users.update:
+    post:
+      summary: Update a user
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/V2UpdateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/UpdateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ErrorDetails'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      properties:
+        name:
+          type: string
+    GetRequest:
+      type: object
+      properties:
+        id:
+          type: string
+    V2UpdateRequest:
+      type: object
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+    CreateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    GetResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    UpdateResponse:
+      type: object
+      properties:
+        id:
+          type: string
+    ErrorDetails:
+      type: object
+      required:
+        - message
+      properties:
+        message:
+          type: string
+`
+
+	for _, test := range []struct {
+		name    string
+		spec    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "MixedPaths",
+			spec:    strings.Replace(spec, "  /v2/users.update:", "  /users.update:", 1),
+			wantErr: "path /users.update has no version prefix",
+		},
+		{
+			name:    "FullFlag",
+			spec:    spec,
+			args:    []string{"--full"},
+			wantErr: "--full and --cli are not supported for specs with versioned paths",
+		},
+		{
+			name:    "ServeSpec",
+			spec:    spec,
+			args:    []string{"--serve-spec"},
+			wantErr: "--serve-spec and --introspect are not supported for specs with versioned paths",
+		},
+		{
+			name:    "BasePath",
+			spec:    spec,
+			args:    []string{"--base-path", "/api"},
+			wantErr: "--base-path and x-duh-base-path are not supported for specs with versioned paths",
+		},
+		{
+			name: "SchemaCollision",
+			spec: strings.NewReplacer(
+				"schemas/UpdateResponse'", "schemas/UpdateRequest'",
+				"    UpdateResponse:", "    UpdateRequest:",
+			).Replace(spec),
+			wantErr: "schemas V2UpdateRequest and UpdateRequest are both named UpdateRequest in the v2 package",
+		},
+		{
+			name: "InvalidVersion",
+			spec: strings.Replace(spec, "    V2UpdateRequest:\n      type: object\n      properties:\n",
+				"    V2UpdateRequest:\n      type: object\n      properties:\n        count:\n          type: integer\n", 1),
+			wantErr: "OpenAPI validation failed for v2",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath, stdout := setupTest(t, test.spec)
+			var stderr bytes.Buffer
+
+			exitCode := duh.RunCmd(stdout, &stderr, append([]string{"generate", specPath}, test.args...))
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+// ProtocolVersion is sent with every Request so plugins can reject a protocol
+// they do not understand
+const ProtocolVersion = 1
+
+// Prefix is prepended to the plugin name to find its executable on PATH
+const Prefix = "duh-gen-"
+
+// Config controls generation by a plugin
+type Config struct {
+	Writer io.Writer
+	// ErrWriter receives what the plugin writes to its stderr
+	ErrWriter io.Writer
+	// Name is the plugin name, 'kotlin' runs duh-gen-kotlin
+	Name      string
+	SpecPath  string
+	OutputDir string
+	// PackageName and ModulePath are used for the Go fields of the TemplateData
+	PackageName string
+	ModulePath  string
+	// Options are passed to the plugin as they are
+	Options map[string]string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// Request is written as JSON to the stdin of the plugin
+type Request struct {
+	Version  int    `json:"version"`
+	SpecPath string `json:"spec_path"`
+	// Spec is the OpenAPI document converted from YAML to JSON
+	Spec any `json:"spec"`
+	// Data is what the built-in Go templates are rendered from
+	Data    *duh.TemplateData `json:"data"`
+	Options map[string]string `json:"options"`
+}
+
+// Response is read as JSON from the stdout of the plugin
+type Response struct {
+	// Files maps paths relative to the output directory to their content
+	Files map[string]string `json:"files"`
+	// Error fails the generation with a message for the user
+	Error string `json:"error,omitempty"`
+}
+
+// Run validates the spec, sends it to the duh-gen-<name> executable and writes
+// the files it returns to the output directory
+func Run(conf Config) error {
+	executable := Prefix + conf.Name
+	path, err := exec.LookPath(executable)
+	if err != nil {
+		return fmt.Errorf("plugin '%s' not found: %s is not on your PATH", conf.Name, executable)
+	}
+
+	start := time.Now()
+	spec, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	result := lint.Validate(spec, conf.SpecPath, nil)
+	conf.Log.Rules(result)
+	if !result.Valid() {
+		return lint.ErrValidation
+	}
+
+	content, err := os.ReadFile(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("%w: %w", lint.ErrParse, err)
+	}
+
+	if err := os.MkdirAll(conf.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	genConfig, err := duh.NewConfig(conf.PackageName, conf.OutputDir, "", "", "")
+	if err != nil {
+		return err
+	}
+	genConfig.ModulePath = conf.ModulePath
+
+	initTemplate, isFullTemplate := duh.MatchInitTemplate(spec)
+	data, err := duh.NewParser(spec, genConfig, initTemplate, isFullTemplate).Parse()
+	if err != nil {
+		return fmt.Errorf("%w (use --module to set the module path for plugins which do not generate Go)", err)
+	}
+
+	options := conf.Options
+	if options == nil {
+		options = map[string]string{}
+	}
+	request, err := json.Marshal(Request{
+		Version:  ProtocolVersion,
+		SpecPath: conf.SpecPath,
+		Spec:     jsonValue(doc),
+		Data:     data,
+		Options:  options,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	conf.Log.Printf("running %s", path)
+	var stdout bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = conf.ErrWriter
+	start = time.Now()
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("plugin %s failed: %w", executable, err)
+	}
+	conf.Log.Printf("%s finished in %s", executable, time.Since(start).Round(time.Millisecond))
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return fmt.Errorf("plugin %s returned invalid JSON: %w", executable, err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("plugin %s: %s", executable, response.Error)
+	}
+
+	names := make([]string, 0, len(response.Files))
+	for name := range response.Files {
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("plugin %s returned %s which is outside the output directory", executable, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filePath := filepath.Join(conf.OutputDir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(filePath, []byte(response.Files[name]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Generated %d file(s) in %s\n", len(names), conf.OutputDir)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(conf.Writer, "  - %s\n", filepath.ToSlash(name))
+	}
+	return nil
+}
+
+// ParseOptions turns the key=value pairs of --opt into the options of the Request
+func ParseOptions(pairs []string) (map[string]string, error) {
+	options := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --opt '%s': must be key=value", pair)
+		}
+		options[key] = value
+	}
+	return options, nil
+}
+
+// jsonValue converts what yaml decodes into values encoding/json accepts,
+// since yaml allows mapping keys such as 200 which are not strings
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			v[key] = jsonValue(value)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = jsonValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,125 @@
+package plugin_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/internal/generate/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installPlugin writes a duh-gen-<name> shell script to a directory put first on PATH
+func installPlugin(t *testing.T, name, script string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, plugin.Prefix+name), []byte("#!/bin/sh\n"+script), 0755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestGeneratePlugin(t *testing.T) {
+	requestPath := filepath.Join(t.TempDir(), "request.json")
+	installPlugin(t, "kotlin", `cat > "`+requestPath+`"
+echo "generating kotlin" >&2
+printf '{"files": {"Client.kt": "class Client\\n", "models/User.kt": "class User\\n"}}'
+`)
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+	outputDir := filepath.Join(t.TempDir(), "kotlin")
+
+	stdout.Reset()
+	stderr.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "plugin", "kotlin", specPath,
+		"--out", outputDir, "--module", "github.com/example/api", "--opt", "package=com.example", "--opt", "async=true"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 2 file(s) in "+outputDir)
+	assert.Contains(t, stdout.String(), "  - models/User.kt\n")
+	assert.Equal(t, "generating kotlin\n", stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "models", "User.kt"))
+	require.NoError(t, err)
+	assert.Equal(t, "class User\n", string(content))
+
+	data, err := os.ReadFile(requestPath)
+	require.NoError(t, err)
+	var request struct {
+		plugin.Request
+		Spec map[string]any `json:"spec"`
+	}
+	require.NoError(t, json.Unmarshal(data, &request))
+	assert.Equal(t, plugin.ProtocolVersion, request.Version)
+	assert.Equal(t, specPath, request.SpecPath)
+	assert.Equal(t, map[string]string{"package": "com.example", "async": "true"}, request.Options)
+	assert.Equal(t, "3.0.3", request.Spec["openapi"])
+	assert.Contains(t, request.Spec["paths"], "/users.create")
+	assert.Equal(t, "github.com/example/api", request.Data.ModulePath)
+	require.Len(t, request.Data.Operations, 4)
+	assert.Equal(t, "/users.create", request.Data.Operations[0].Path)
+}
+
+func TestGeneratePluginErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		script  string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "NotFound",
+			args:    []string{"missing"},
+			wantErr: "plugin 'missing' not found: duh-gen-missing is not on your PATH",
+		},
+		{
+			name:    "InvalidOption",
+			script:  `printf '{"files": {}}'`,
+			args:    []string{"test", "--opt", "async"},
+			wantErr: "invalid --opt 'async': must be key=value",
+		},
+		{
+			name:    "PluginFailed",
+			script:  "exit 3",
+			args:    []string{"test"},
+			wantErr: "plugin duh-gen-test failed: exit status 3",
+		},
+		{
+			name:    "InvalidJSON",
+			script:  "echo not json",
+			args:    []string{"test"},
+			wantErr: "plugin duh-gen-test returned invalid JSON",
+		},
+		{
+			name:    "PluginError",
+			script:  `printf '{"error": "unsupported list operation"}'`,
+			args:    []string{"test"},
+			wantErr: "plugin duh-gen-test: unsupported list operation",
+		},
+		{
+			name:    "OutsideOutputDir",
+			script:  `printf '{"files": {"../escape.kt": ""}}'`,
+			args:    []string{"test"},
+			wantErr: "plugin duh-gen-test returned ../escape.kt which is outside the output directory",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			installPlugin(t, "test", test.script)
+			specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+
+			args := append([]string{"generate", "plugin"}, test.args[0], specPath,
+				"--out", t.TempDir(), "--module", "github.com/example/api")
+			stdout.Reset()
+			stderr.Reset()
+			exitCode := duh.RunCmd(&stdout, &stderr, append(args, test.args[1:]...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,484 @@
+package python
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+var (
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	separatorRegex  = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// reservedNames are valid identifiers that cannot be used as pydantic field names
+var reservedNames = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+	"copy": true, "dict": true, "json": true, "schema": true, "validate": true,
+}
+
+// modelRenderer collects the classes and type aliases of models.py. Inline
+// objects become classes of their own, named after their parent and property.
+type modelRenderer struct {
+	blocks  []block
+	classes []string
+	names   []string
+}
+
+// block is a class or type alias along with the base classes it inherits from
+type block struct {
+	name  string
+	text  string
+	bases []string
+}
+
+// renderModels returns a pydantic model or type alias for every schema in components/schemas
+func renderModels(schemas []namedSchema) string {
+	r := &modelRenderer{}
+	for _, s := range schemas {
+		name := className(s.name)
+		schema := s.proxy.Schema()
+		if schema == nil {
+			r.alias(name, "Any")
+			continue
+		}
+
+		if isClass(schema) {
+			r.class(name, schema)
+			continue
+		}
+		r.alias(name, r.pyType(s.proxy, name, true))
+	}
+
+	var b strings.Builder
+	b.WriteString("from __future__ import annotations\n\n")
+	b.WriteString("from typing import Any, Dict, List, Literal, Optional, Union\n\n")
+	b.WriteString("from pydantic import BaseModel, ConfigDict, Field\n\n")
+
+	all := append([]string{"Model"}, r.names...)
+	b.WriteString("__all__ = [\n")
+	for _, name := range all {
+		fmt.Fprintf(&b, "    %s,\n", quote(name))
+	}
+	b.WriteString("]\n\n\n")
+
+	b.WriteString("class Model(BaseModel):\n")
+	b.WriteString("    \"\"\"Model is the base of every generated model. Unknown fields are kept so\n")
+	b.WriteString("    replies from newer versions of the service still validate.\"\"\"\n\n")
+	b.WriteString("    model_config = ConfigDict(populate_by_name=True, extra=\"allow\")\n")
+
+	for _, text := range r.ordered() {
+		b.WriteString("\n\n")
+		b.WriteString(text)
+	}
+
+	if len(r.classes) > 0 {
+		b.WriteString("\n\n# Resolve the forward references between models\n")
+		b.WriteString("for _model in (\n")
+		for _, name := range r.classes {
+			fmt.Fprintf(&b, "    %s,\n", name)
+		}
+		b.WriteString("):\n    _model.model_rebuild()\n")
+	}
+	return b.String()
+}
+
+// isClass reports whether a schema is an object that becomes a pydantic model
+func isClass(schema *base.Schema) bool {
+	if len(schema.AllOf) > 0 {
+		return true
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 || len(schema.Enum) > 0 {
+		return false
+	}
+	if schema.Properties != nil && schema.Properties.Len() > 0 {
+		return true
+	}
+	return hasType(schema, "object") && schema.AdditionalProperties == nil
+}
+
+func (r *modelRenderer) alias(name, typ string) {
+	r.names = append(r.names, name)
+	r.blocks = append(r.blocks, block{name: name, text: fmt.Sprintf("%s = %s\n", name, typ)})
+}
+
+// class renders a model for an object schema. The referenced schemas of an
+// allOf become base classes and the properties of inline allOf entries are merged.
+func (r *modelRenderer) class(name string, schema *base.Schema) {
+	r.names = append(r.names, name)
+	r.classes = append(r.classes, name)
+	index := len(r.blocks)
+	r.blocks = append(r.blocks, block{name: name})
+
+	var bases []string
+	properties := []*base.Schema{schema}
+	for _, sub := range schema.AllOf {
+		if sub.IsReference() {
+			bases = append(bases, referenceName(sub.GetReference()))
+			continue
+		}
+		if s := sub.Schema(); s != nil {
+			properties = append(properties, s)
+		}
+	}
+	if len(bases) == 0 {
+		bases = []string{"Model"}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(%s):\n", name, strings.Join(bases, ", "))
+	doc := docstring(schema.Description, isDeprecated(schema), "    ")
+	b.WriteString(doc)
+
+	var fields int
+	for _, s := range properties {
+		if s.Properties == nil {
+			continue
+		}
+
+		required := make(map[string]bool, len(s.Required))
+		for _, n := range s.Required {
+			required[n] = true
+		}
+
+		for prop, proxy := range s.Properties.FromOldest() {
+			if fields == 0 && doc != "" {
+				b.WriteString("\n")
+			}
+			fields++
+			b.WriteString(r.field(name, prop, proxy, required[prop]))
+		}
+	}
+	if fields == 0 && doc == "" {
+		b.WriteString("    pass\n")
+	}
+	r.blocks[index].text = b.String()
+	r.blocks[index].bases = bases
+}
+
+// ordered returns the blocks in declaration order, except that a class is moved
+// after its base classes since those must exist when the class is defined
+func (r *modelRenderer) ordered() []string {
+	index := make(map[string]int, len(r.blocks))
+	for i, b := range r.blocks {
+		index[b.name] = i
+	}
+
+	var result []string
+	done := make(map[int]bool, len(r.blocks))
+	var visit func(i int)
+	visit = func(i int) {
+		if done[i] {
+			return
+		}
+		done[i] = true
+		for _, base := range r.blocks[i].bases {
+			if j, ok := index[base]; ok {
+				visit(j)
+			}
+		}
+		result = append(result, r.blocks[i].text)
+	}
+
+	for i := range r.blocks {
+		visit(i)
+	}
+	return result
+}
+
+// field renders a model attribute. Properties that are not valid snake_case
+// identifiers are renamed and keep their JSON name as the alias.
+func (r *modelRenderer) field(parent, prop string, proxy *base.SchemaProxy, required bool) string {
+	attr := fieldName(prop)
+	typ := r.pyType(proxy, parent+pascalCase(prop), false)
+
+	var args []string
+	if !required {
+		typ = optional(typ)
+		args = append(args, "default=None")
+	}
+	if attr != prop {
+		args = append(args, "alias="+quote(prop))
+	}
+	if s := proxy.Schema(); s != nil && !proxy.IsReference() {
+		if d := strings.Join(strings.Fields(s.Description), " "); d != "" {
+			args = append(args, "description="+quote(d))
+		}
+		if isDeprecated(s) {
+			args = append(args, "deprecated=True")
+		}
+	}
+
+	switch {
+	case len(args) == 0:
+		return fmt.Sprintf("    %s: %s\n", attr, typ)
+	case len(args) == 1 && args[0] == "default=None":
+		return fmt.Sprintf("    %s: %s = None\n", attr, typ)
+	}
+	return fmt.Sprintf("    %s: %s = Field(%s)\n", attr, typ, strings.Join(args, ", "))
+}
+
+// pyType returns the Python type of a schema. Inline objects with properties are
+// rendered as a class named hint. References are quoted in type aliases because
+// aliases are evaluated before the classes they refer to may exist.
+func (r *modelRenderer) pyType(proxy *base.SchemaProxy, hint string, alias bool) string {
+	if proxy.IsReference() {
+		name := referenceName(proxy.GetReference())
+		if alias {
+			return quote(name)
+		}
+		return name
+	}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return "Any"
+	}
+
+	typ := r.baseType(schema, hint, alias)
+	if isNullable(schema) && typ != "Any" {
+		return optional(typ)
+	}
+	return typ
+}
+
+func (r *modelRenderer) baseType(schema *base.Schema, hint string, alias bool) string {
+	switch {
+	case len(schema.OneOf) > 0:
+		return r.union(schema.OneOf, hint, alias)
+	case len(schema.AnyOf) > 0:
+		return r.union(schema.AnyOf, hint, alias)
+	case len(schema.AllOf) > 0:
+		r.class(hint, schema)
+		return r.ref(hint, alias)
+	case len(schema.Enum) > 0:
+		return enumType(schema)
+	}
+
+	var types []string
+	for _, t := range schema.Type {
+		if t == "null" {
+			continue
+		}
+		types = append(types, r.scalarType(t, schema, hint, alias))
+	}
+
+	switch len(types) {
+	case 0:
+		if schema.Properties != nil && schema.Properties.Len() > 0 {
+			r.class(hint, schema)
+			return r.ref(hint, alias)
+		}
+		return "Any"
+	case 1:
+		return types[0]
+	}
+	return "Union[" + strings.Join(types, ", ") + "]"
+}
+
+func (r *modelRenderer) scalarType(typ string, schema *base.Schema, hint string, alias bool) string {
+	switch typ {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		if schema.Items == nil || !schema.Items.IsA() {
+			return "List[Any]"
+		}
+		return "List[" + r.pyType(schema.Items.A, hint+"Item", alias) + "]"
+	case "object":
+		if schema.Properties != nil && schema.Properties.Len() > 0 {
+			r.class(hint, schema)
+			return r.ref(hint, alias)
+		}
+		if additional := schema.AdditionalProperties; additional != nil && additional.IsA() {
+			return "Dict[str, " + r.pyType(additional.A, hint+"Value", alias) + "]"
+		}
+		return "Dict[str, Any]"
+	}
+	return "Any"
+}
+
+func (r *modelRenderer) union(proxies []*base.SchemaProxy, hint string, alias bool) string {
+	types := make([]string, len(proxies))
+	for i, p := range proxies {
+		types[i] = r.pyType(p, fmt.Sprintf("%sOption%d", hint, i+1), alias)
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return "Union[" + strings.Join(types, ", ") + "]"
+}
+
+func (r *modelRenderer) ref(name string, alias bool) string {
+	if alias {
+		return quote(name)
+	}
+	return name
+}
+
+func enumType(schema *base.Schema) string {
+	values := make([]string, 0, len(schema.Enum))
+	for _, v := range schema.Enum {
+		if v == nil {
+			continue
+		}
+		if hasType(schema, "string") || len(schema.Type) == 0 {
+			values = append(values, quote(v.Value))
+			continue
+		}
+		switch v.Value {
+		case "true":
+			values = append(values, "True")
+		case "false":
+			values = append(values, "False")
+		default:
+			values = append(values, v.Value)
+		}
+	}
+	return "Literal[" + strings.Join(values, ", ") + "]"
+}
+
+func optional(typ string) string {
+	if strings.HasPrefix(typ, "Optional[") || typ == "Any" {
+		return typ
+	}
+	return "Optional[" + typ + "]"
+}
+
+// docstring returns the description and deprecation of a schema as an indented docstring
+func docstring(description string, deprecated bool, indent string) string {
+	var lines []string
+	if d := strings.TrimSpace(description); d != "" {
+		lines = strings.Split(d, "\n")
+	}
+	if deprecated {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, ".. deprecated:: marked deprecated in the OpenAPI spec.")
+	}
+
+	switch len(lines) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("%s\"\"\"%s\"\"\"\n", indent, escapeDocstring(lines[0]))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\"\"\"%s\n", indent, escapeDocstring(lines[0]))
+	for _, line := range lines[1:] {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&b, "%s%s\n", indent, escapeDocstring(line))
+	}
+	fmt.Fprintf(&b, "%s\"\"\"\n", indent)
+	return b.String()
+}
+
+func escapeDocstring(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"""`, `\"\"\"`)
+	if strings.HasSuffix(s, `"`) {
+		s = s[:len(s)-1] + `\"`
+	}
+	return s
+}
+
+// fieldName returns the snake_case attribute name of a property
+func fieldName(prop string) string {
+	name := snakeCase(prop)
+	if !identifierRegex.MatchString(name) {
+		name = "field_" + strings.Trim(separatorRegex.ReplaceAllString(name, "_"), "_")
+	}
+	if reservedNames[name] || strings.HasPrefix(name, "model_") || strings.HasPrefix(name, "_") {
+		name = strings.TrimLeft(name, "_") + "_"
+	}
+	return name
+}
+
+// className returns a valid class name for a schema name
+func className(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	return pascalCase(name)
+}
+
+// snakeCase converts camelCase, PascalCase and kebab-case to snake_case
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, c := range runes {
+		switch {
+		case c == '-' || c == ' ' || c == '.':
+			b.WriteRune('_')
+		case c >= 'A' && c <= 'Z':
+			if i > 0 && (isLowerOrDigit(runes[i-1]) || (i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z' && runes[i-1] != '_')) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(c + ('a' - 'A'))
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func isLowerOrDigit(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+// pascalCase converts snake_case, kebab-case and camelCase to PascalCase
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, part := range separatorRegex.Split(s, -1) {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func isDeprecated(schema *base.Schema) bool {
+	return schema.Deprecated != nil && *schema.Deprecated
+}
+
+func isNullable(schema *base.Schema) bool {
+	return (schema.Nullable != nil && *schema.Nullable) || hasType(schema, "null")
+}
+
+func hasType(schema *base.Schema, typ string) bool {
+	for _, t := range schema.Type {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceName extracts the schema name from a reference such as '#/components/schemas/Address'
+func referenceName(ref string) string {
+	return className(ref[strings.LastIndex(ref, "/")+1:])
+}
@@ -0,0 +1,320 @@
+package python
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Config controls Python client generation
+type Config struct {
+	Writer    io.Writer
+	SpecPath  string
+	OutputDir string
+	// Package is the name of the Python package, derived from the spec title when empty
+	Package string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+type namedSchema struct {
+	name  string
+	proxy *base.SchemaProxy
+}
+
+type templateData struct {
+	Timestamp  string
+	Package    string
+	Project    string
+	Title      string
+	Version    string
+	Imports    []string
+	ErrorType  string
+	Operations []operation
+	ListOps    []listOperation
+}
+
+type operation struct {
+	MethodName   string
+	Path         string
+	RequestType  string
+	ResponseType string
+	Summary      string
+	Deprecated   bool
+}
+
+type listOperation struct {
+	operation
+	IteratorName    string
+	ItemType        string
+	PaginationField string
+	PaginationAlias string
+	ResponseField   string
+}
+
+// Run writes a Python package with pydantic models, a sync and an async httpx
+// client and typed exceptions for the spec to the output directory
+func Run(conf Config) error {
+	start := time.Now()
+	spec, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	result := lint.Validate(spec, conf.SpecPath, nil)
+	conf.Log.Rules(result)
+	if !result.Valid() {
+		return lint.ErrValidation
+	}
+
+	var schemas []namedSchema
+	if spec.Components != nil && spec.Components.Schemas != nil {
+		for name, proxy := range spec.Components.Schemas.FromOldest() {
+			schemas = append(schemas, namedSchema{name: name, proxy: proxy})
+		}
+	}
+
+	data, err := parseOperations(spec)
+	if err != nil {
+		return err
+	}
+	data.Timestamp = time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
+	data.Title, data.Version = "API", "0.1.0"
+	if spec.Info != nil {
+		if spec.Info.Title != "" {
+			data.Title = strings.ReplaceAll(strings.Join(strings.Fields(spec.Info.Title), " "), `"`, `'`)
+		}
+		if spec.Info.Version != "" {
+			data.Version = spec.Info.Version
+		}
+	}
+
+	data.Package = conf.Package
+	if data.Package == "" {
+		data.Package = packageName(data.Title)
+	}
+	if !identifierRegex.MatchString(data.Package) || reservedNames[data.Package] {
+		return fmt.Errorf("invalid package name '%s': must be a valid Python identifier", data.Package)
+	}
+	data.Project = strings.ReplaceAll(data.Package, "_", "-")
+
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	pkg := filepath.Join(conf.OutputDir, data.Package)
+	names := []string{
+		"pyproject.toml",
+		filepath.Join(data.Package, "__init__.py"),
+		filepath.Join(data.Package, "models.py"),
+		filepath.Join(data.Package, "errors.py"),
+		filepath.Join(data.Package, "client.py"),
+	}
+
+	files := map[string][]byte{
+		names[2]: []byte(fmt.Sprintf("# Code generated by 'duh generate python' on %s. DO NOT EDIT.\n\n%s",
+			data.Timestamp, renderModels(schemas))),
+	}
+
+	templates := map[string]string{
+		names[0]: "pyproject.toml.tmpl",
+		names[1]: "__init__.py.tmpl",
+		names[3]: "errors.py.tmpl",
+		names[4]: "client.py.tmpl",
+	}
+	for name, tmplName := range templates {
+		conf.Log.Rendering(name)
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, tmplName, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(conf.OutputDir, name), files[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Generated %d file(s) in %s\n", len(names), conf.OutputDir)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(conf.Writer, "  - %s\n", filepath.ToSlash(name))
+	}
+	return nil
+}
+
+// parseOperations returns a client method for every POST operation whose
+// request and response bodies reference component schemas
+func parseOperations(spec *v3.Document) (*templateData, error) {
+	data := &templateData{}
+	if spec.Paths == nil || spec.Paths.PathItems == nil {
+		return data, nil
+	}
+
+	imports := make(map[string]bool)
+	for path, item := range spec.Paths.PathItems.FromOldest() {
+		op := item.Post
+		if op == nil || op.RequestBody == nil {
+			continue
+		}
+
+		name, err := duh.GenerateOperationName(path)
+		if err != nil {
+			continue
+		}
+
+		request, err := bodySchema(op.RequestBody.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w for request body in path %s", err, path)
+		}
+
+		var response *base.SchemaProxy
+		if op.Responses != nil && op.Responses.Codes != nil {
+			for code, resp := range op.Responses.Codes.FromOldest() {
+				if !strings.HasPrefix(code, "2") {
+					// The first error schema is the one the exceptions carry
+					if data.ErrorType == "" {
+						if s, err := bodySchema(resp.Content); err == nil && s != nil {
+							data.ErrorType = referenceName(s.GetReference())
+						}
+					}
+					continue
+				}
+				if response != nil {
+					continue
+				}
+				if response, err = bodySchema(resp.Content); err != nil {
+					return nil, fmt.Errorf("%w for response body in path %s", err, path)
+				}
+			}
+		}
+
+		if request == nil || response == nil {
+			continue
+		}
+
+		summary := op.Summary
+		if summary == "" {
+			summary = op.Description
+		}
+
+		o := operation{
+			MethodName:   snakeCase(name),
+			Path:         path,
+			RequestType:  referenceName(request.GetReference()),
+			ResponseType: referenceName(response.GetReference()),
+			Summary:      escapeDocstring(strings.Join(strings.Fields(summary), " ")),
+			Deprecated:   op.Deprecated != nil && *op.Deprecated,
+		}
+		data.Operations = append(data.Operations, o)
+		imports[o.RequestType], imports[o.ResponseType] = true, true
+
+		if listOp, ok := detectListOperation(o, request.Schema(), response.Schema()); ok {
+			data.ListOps = append(data.ListOps, listOp)
+			imports[listOp.ItemType] = true
+		}
+	}
+
+	for name := range imports {
+		data.Imports = append(data.Imports, name)
+	}
+	sort.Strings(data.Imports)
+	return data, nil
+}
+
+// bodySchema returns the referenced schema of a request or response body
+func bodySchema(content *orderedmap.Map[string, *v3.MediaType]) (*base.SchemaProxy, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	for _, media := range content.FromOldest() {
+		if media.Schema == nil {
+			continue
+		}
+		if !media.Schema.IsReference() {
+			return nil, fmt.Errorf("inline schema not supported")
+		}
+		return media.Schema, nil
+	}
+	return nil, nil
+}
+
+// detectListOperation matches the same operations as the Go client iterators: a
+// list method whose request has a pagination property and whose response has an
+// array of referenced items
+func detectListOperation(op operation, request, response *base.Schema) (listOperation, bool) {
+	method := op.Path[strings.LastIndex(op.Path, ".")+1:]
+	if !strings.Contains(strings.ToLower(method), "list") || request == nil || response == nil {
+		return listOperation{}, false
+	}
+
+	var pagination string
+	if request.Properties != nil {
+		for name := range request.Properties.KeysFromOldest() {
+			if strings.ToLower(name) == "pagination" {
+				pagination = name
+				break
+			}
+		}
+	}
+	if pagination == "" || response.Properties == nil {
+		return listOperation{}, false
+	}
+
+	for name, prop := range response.Properties.FromOldest() {
+		s := prop.Schema()
+		if s == nil || !hasType(s, "array") || s.Items == nil || !s.Items.IsA() || !s.Items.A.IsReference() {
+			continue
+		}
+
+		return listOperation{
+			operation:       op,
+			IteratorName:    op.MethodName + "_iter",
+			ItemType:        referenceName(s.Items.A.GetReference()),
+			PaginationField: fieldName(pagination),
+			PaginationAlias: pagination,
+			ResponseField:   fieldName(name),
+		}, true
+	}
+	return listOperation{}, false
+}
+
+// packageName derives a package name from the spec title, e.g. "Pet Store" → pet_store_client
+func packageName(title string) string {
+	name := strings.Trim(separatorRegex.ReplaceAllString(snakeCase(title), "_"), "_")
+	for strings.Contains(name, "__") {
+		name = strings.ReplaceAll(name, "__", "_")
+	}
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "api" + name
+	}
+	if !strings.HasSuffix(name, "_client") {
+		name += "_client"
+	}
+	return name
+}
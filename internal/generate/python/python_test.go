@@ -0,0 +1,263 @@
+package python_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pythonSpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets.create:
+    post:
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+  /pets.list:
+    post:
+      summary: List pets
+      deprecated: true
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/ListRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ListResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          type: string
+          description: Human-readable error message
+    CreateRequest:
+      type: object
+      description: A pet to add to the store
+      required: [name]
+      properties:
+        name:
+          type: string
+        kind:
+          type: string
+          enum: [cat, dog]
+        tags:
+          type: array
+          items:
+            type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        nickname:
+          type: string
+          deprecated: true
+        from:
+          type: string
+          description: Shelter the pet came from
+    CreateResponse:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Pet:
+      type: object
+      properties:
+        id:
+          type: string
+        age:
+          type: integer
+          format: int32
+    ListRequest:
+      type: object
+      properties:
+        pagination:
+          $ref: '#/components/schemas/PaginationRequest'
+    PaginationRequest:
+      type: object
+      properties:
+        first:
+          type: integer
+          format: int32
+          minimum: 1
+          maximum: 100
+        after:
+          type: string
+    ListResponse:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+        pagination:
+          $ref: '#/components/schemas/PaginationResponse'
+    PaginationResponse:
+      type: object
+      properties:
+        end_cursor:
+          type: string
+        has_more:
+          type: boolean
+`
+
+func TestGeneratePythonModels(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "python")
+	require.NoError(t, os.WriteFile(specPath, []byte(pythonSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "python", specPath, "--out", outputDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 5 file(s)")
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "pet_store_client", "models.py"))
+	require.NoError(t, err)
+
+	models := string(content)
+	assert.Contains(t, models, "class CreateRequest(Model):\n"+
+		"    \"\"\"A pet to add to the store\"\"\"\n\n"+
+		"    name: str\n"+
+		"    kind: Optional[Literal[\"cat\", \"dog\"]] = None\n"+
+		"    tags: Optional[List[str]] = None\n"+
+		"    labels: Optional[Dict[str, str]] = None\n"+
+		"    nickname: Optional[str] = Field(default=None, deprecated=True)\n"+
+		"    from_: Optional[str] = Field(default=None, alias=\"from\", description=\"Shelter the pet came from\")\n")
+	assert.Contains(t, models, "class ListResponse(Model):\n"+
+		"    items: Optional[List[Pet]] = None\n"+
+		"    pagination: Optional[PaginationResponse] = None\n")
+	assert.Contains(t, models, "class Error(Model):\n    message: str = Field(description=\"Human-readable error message\")\n")
+}
+
+func TestGeneratePythonClient(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "python")
+	require.NoError(t, os.WriteFile(specPath, []byte(pythonSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "python", specPath, "--out", outputDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 5 file(s)")
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "pet_store_client", "client.py"))
+	require.NoError(t, err)
+
+	client := string(content)
+	assert.Contains(t, client, "    def pets_create(self, req: CreateRequest) -> CreateResponse:\n"+
+		"        \"\"\"Create a pet\"\"\"\n"+
+		"        return self._call(\"/pets.create\", req, CreateResponse)\n")
+	assert.Contains(t, client, "    async def pets_create(self, req: CreateRequest) -> CreateResponse:\n")
+	assert.Contains(t, client, "warnings.warn(\"/pets.list is deprecated\", DeprecationWarning, stacklevel=2)")
+	assert.Contains(t, client, "def pets_list_iter(self, req: ListRequest, first: int = 20) -> Iterator[Pet]:")
+	assert.Contains(t, client, "async def pets_list_iter(self, req: ListRequest, first: int = 20) -> AsyncIterator[Pet]:")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "pet_store_client", "errors.py"))
+	require.NoError(t, err)
+
+	errors := string(content)
+	assert.Contains(t, errors, "from .models import Error\n")
+	assert.Contains(t, errors, "class NotFoundError(DuhError):")
+	assert.Contains(t, errors, "reply = Error.model_validate(data)")
+}
+
+func TestGeneratePythonPackageName(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "python")
+	require.NoError(t, os.WriteFile(specPath, []byte(pythonSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "python", specPath, "--out", outputDir, "--package", "pets"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 5 file(s)")
+	assert.Empty(t, stderr.String())
+
+	assert.FileExists(t, filepath.Join(outputDir, "pets", "__init__.py"))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "pyproject.toml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "name = \"pets\"\n")
+	assert.Contains(t, string(content), "packages = [\"pets\"]\n")
+}
+
+func TestGeneratePythonErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		specFile string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "file not found",
+			specFile: "missing.yaml",
+			expected: "file not found",
+		},
+		{
+			name:     "invalid package name",
+			specFile: "openapi.yaml",
+			args:     []string{"--package", "my-client"},
+			expected: "invalid package name 'my-client'",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tempDir, "openapi.yaml"), []byte(pythonSpec), 0644))
+
+			var stdout, stderr bytes.Buffer
+			args := []string{"generate", "python", filepath.Join(tempDir, test.specFile), "--out", tempDir}
+			exitCode := duh.RunCmd(&stdout, &stderr, append(args, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.expected)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package ts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Config controls TypeScript client generation
+type Config struct {
+	Writer    io.Writer
+	SpecPath  string
+	OutputDir string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+type namedSchema struct {
+	name  string
+	proxy *base.SchemaProxy
+}
+
+type templateData struct {
+	Timestamp  string
+	Imports    string
+	Operations []operation
+	ListOps    []listOperation
+}
+
+type operation struct {
+	MethodName   string
+	Path         string
+	RequestType  string
+	ResponseType string
+	Summary      string
+	Deprecated   bool
+}
+
+type listOperation struct {
+	operation
+	IteratorName    string
+	ItemType        string
+	PaginationField string
+	ResponseField   string
+}
+
+// Run writes types.ts, client.ts and index.ts for the spec to the output directory
+func Run(conf Config) error {
+	start := time.Now()
+	spec, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	result := lint.Validate(spec, conf.SpecPath, nil)
+	conf.Log.Rules(result)
+	if !result.Valid() {
+		return lint.ErrValidation
+	}
+
+	var schemas []namedSchema
+	if spec.Components != nil && spec.Components.Schemas != nil {
+		for name, proxy := range spec.Components.Schemas.FromOldest() {
+			schemas = append(schemas, namedSchema{name: name, proxy: proxy})
+		}
+	}
+
+	data, err := parseOperations(spec)
+	if err != nil {
+		return err
+	}
+	data.Timestamp = time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
+
+	tmpl, err := template.ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	files := map[string][]byte{
+		"types.ts": []byte(fmt.Sprintf("// Code generated by 'duh generate ts' on %s. DO NOT EDIT.\n\n%s",
+			data.Timestamp, renderTypes(schemas))),
+	}
+
+	for _, name := range []string{"client.ts", "index.ts"} {
+		conf.Log.Rendering(name)
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name+".tmpl", data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+
+	if err := os.MkdirAll(conf.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	names := []string{"types.ts", "client.ts", "index.ts"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(conf.OutputDir, name), files[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Generated %d file(s) in %s\n", len(names), conf.OutputDir)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(conf.Writer, "  - %s\n", name)
+	}
+	return nil
+}
+
+// parseOperations returns a client method for every POST operation whose
+// request and response bodies reference component schemas
+func parseOperations(spec *v3.Document) (*templateData, error) {
+	data := &templateData{}
+	if spec.Paths == nil || spec.Paths.PathItems == nil {
+		return data, nil
+	}
+
+	imports := make(map[string]bool)
+	for path, item := range spec.Paths.PathItems.FromOldest() {
+		op := item.Post
+		if op == nil || op.RequestBody == nil {
+			continue
+		}
+
+		name, err := duh.GenerateOperationName(path)
+		if err != nil {
+			continue
+		}
+
+		request, err := bodySchema(op.RequestBody.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w for request body in path %s", err, path)
+		}
+
+		var response *base.SchemaProxy
+		if op.Responses != nil && op.Responses.Codes != nil {
+			for code, resp := range op.Responses.Codes.FromOldest() {
+				if !strings.HasPrefix(code, "2") {
+					continue
+				}
+				if response, err = bodySchema(resp.Content); err != nil {
+					return nil, fmt.Errorf("%w for response body in path %s", err, path)
+				}
+				break
+			}
+		}
+
+		if request == nil || response == nil {
+			continue
+		}
+
+		summary := op.Summary
+		if summary == "" {
+			summary = op.Description
+		}
+
+		o := operation{
+			MethodName:   strings.ToLower(name[:1]) + name[1:],
+			Path:         path,
+			RequestType:  referenceName(request.GetReference()),
+			ResponseType: referenceName(response.GetReference()),
+			Summary:      escapeComment(strings.Join(strings.Fields(summary), " ")),
+			Deprecated:   op.Deprecated != nil && *op.Deprecated,
+		}
+		data.Operations = append(data.Operations, o)
+		imports[o.RequestType], imports[o.ResponseType] = true, true
+
+		if listOp, ok := detectListOperation(o, request.Schema(), response.Schema()); ok {
+			data.ListOps = append(data.ListOps, listOp)
+			imports[listOp.ItemType] = true
+		}
+	}
+
+	list := make([]string, 0, len(imports))
+	for name := range imports {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	data.Imports = strings.Join(list, ", ")
+	return data, nil
+}
+
+// bodySchema returns the referenced schema of a request or response body
+func bodySchema(content *orderedmap.Map[string, *v3.MediaType]) (*base.SchemaProxy, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	for _, media := range content.FromOldest() {
+		if media.Schema == nil {
+			continue
+		}
+		if !media.Schema.IsReference() {
+			return nil, fmt.Errorf("inline schema not supported")
+		}
+		return media.Schema, nil
+	}
+	return nil, nil
+}
+
+// detectListOperation matches the same operations as the Go client iterators: a
+// list method whose request has a pagination property and whose response has an
+// array of referenced items
+func detectListOperation(op operation, request, response *base.Schema) (listOperation, bool) {
+	method := op.Path[strings.LastIndex(op.Path, ".")+1:]
+	if !strings.Contains(strings.ToLower(method), "list") || request == nil || response == nil {
+		return listOperation{}, false
+	}
+
+	var pagination string
+	if request.Properties != nil {
+		for name := range request.Properties.KeysFromOldest() {
+			if strings.ToLower(name) == "pagination" {
+				pagination = name
+				break
+			}
+		}
+	}
+	if pagination == "" || response.Properties == nil {
+		return listOperation{}, false
+	}
+
+	for name, prop := range response.Properties.FromOldest() {
+		s := prop.Schema()
+		if s == nil || !hasType(s, "array") || s.Items == nil || !s.Items.IsA() || !s.Items.A.IsReference() {
+			continue
+		}
+
+		return listOperation{
+			operation:       op,
+			IteratorName:    op.MethodName + "Iter",
+			ItemType:        referenceName(s.Items.A.GetReference()),
+			PaginationField: pagination,
+			ResponseField:   name,
+		}, true
+	}
+	return listOperation{}, false
+}
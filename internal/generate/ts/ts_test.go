@@ -0,0 +1,207 @@
+package ts_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tsSpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets.create:
+    post:
+      summary: Create a pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+  /pets.list:
+    post:
+      summary: List pets
+      deprecated: true
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/ListRequest'
+      responses:
+        '200':
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ListResponse'
+        '400':
+          description: Bad Request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          type: string
+          description: Human-readable error message
+    CreateRequest:
+      type: object
+      description: A pet to add to the store
+      required: [name]
+      properties:
+        name:
+          type: string
+        kind:
+          type: string
+          enum: [cat, dog]
+        tags:
+          type: array
+          items:
+            type: string
+        labels:
+          type: object
+          additionalProperties:
+            type: string
+        nickname:
+          type: string
+          deprecated: true
+    CreateResponse:
+      type: object
+      properties:
+        pet:
+          $ref: '#/components/schemas/Pet'
+    Pet:
+      type: object
+      properties:
+        id:
+          type: string
+        age:
+          type: integer
+          format: int32
+    ListRequest:
+      type: object
+      properties:
+        pagination:
+          $ref: '#/components/schemas/PaginationRequest'
+    PaginationRequest:
+      type: object
+      properties:
+        first:
+          type: integer
+          format: int32
+          minimum: 1
+          maximum: 100
+        after:
+          type: string
+    ListResponse:
+      type: object
+      properties:
+        items:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+        pagination:
+          $ref: '#/components/schemas/PaginationResponse'
+    PaginationResponse:
+      type: object
+      properties:
+        end_cursor:
+          type: string
+        has_more:
+          type: boolean
+`
+
+func TestGenerateTsTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "web")
+	require.NoError(t, os.WriteFile(specPath, []byte(tsSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "ts", specPath, "--out", outputDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 3 file(s)")
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "types.ts"))
+	require.NoError(t, err)
+
+	types := string(content)
+	assert.Contains(t, types, "/** A pet to add to the store */\nexport interface CreateRequest {\n"+
+		"  name: string;\n"+
+		"  kind?: \"cat\" | \"dog\";\n"+
+		"  tags?: string[];\n"+
+		"  labels?: Record<string, string>;\n"+
+		"  /** @deprecated */\n"+
+		"  nickname?: string;\n"+
+		"}\n")
+	assert.Contains(t, types, "export interface Pet {\n  id?: string;\n  age?: number;\n}\n")
+	assert.Contains(t, types, "  items?: Pet[];\n  pagination?: PaginationResponse;\n")
+}
+
+func TestGenerateTsClient(t *testing.T) {
+	tempDir := t.TempDir()
+	specPath := filepath.Join(tempDir, "openapi.yaml")
+	outputDir := filepath.Join(tempDir, "web")
+	require.NoError(t, os.WriteFile(specPath, []byte(tsSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "ts", specPath, "--out", outputDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 3 file(s)")
+	assert.Empty(t, stderr.String())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "client.ts"))
+	require.NoError(t, err)
+
+	client := string(content)
+	assert.Contains(t, client, "import type { CreateRequest, CreateResponse, ListRequest, ListResponse, Pet } from './types';")
+	assert.Contains(t, client, "export class DuhError extends Error {")
+	assert.Contains(t, client, "  /** Create a pet */\n"+
+		"  petsCreate(req: CreateRequest, signal?: AbortSignal): Promise<CreateResponse> {\n"+
+		"    return this.call<CreateRequest, CreateResponse>('/pets.create', req, signal);\n")
+	assert.Contains(t, client, "   * @deprecated /pets.list is marked deprecated in the OpenAPI spec.\n")
+	assert.Contains(t, client, "async *petsListIter(req: ListRequest, first = 20, signal?: AbortSignal): AsyncGenerator<Pet> {")
+	assert.Contains(t, client, "yield* resp.items ?? [];")
+}
+
+func TestGenerateTsFileNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "ts", filepath.Join(tempDir, "missing.yaml")})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+	assert.Empty(t, stdout.String())
+}
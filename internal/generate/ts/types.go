@@ -0,0 +1,237 @@
+package ts
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// renderTypes returns an exported interface or type alias for every schema in components/schemas
+func renderTypes(schemas []namedSchema) string {
+	var b strings.Builder
+	for i, s := range schemas {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		schema := s.proxy.Schema()
+		if schema == nil {
+			fmt.Fprintf(&b, "export type %s = unknown;\n", s.name)
+			continue
+		}
+
+		writeDoc(&b, schema, "")
+		if isInterface(schema) {
+			fmt.Fprintf(&b, "export interface %s %s\n", s.name, objectType(schema, ""))
+			continue
+		}
+		fmt.Fprintf(&b, "export type %s = %s;\n", s.name, tsType(s.proxy, ""))
+	}
+	return b.String()
+}
+
+// isInterface reports whether a schema is a plain object that can be declared as an interface
+func isInterface(schema *base.Schema) bool {
+	return hasType(schema, "object") && schema.Properties != nil && schema.Properties.Len() > 0 &&
+		len(schema.AllOf) == 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 && !isNullable(schema)
+}
+
+// tsType returns the TypeScript type of a schema. Nested object literals are indented by indent.
+func tsType(proxy *base.SchemaProxy, indent string) string {
+	if proxy.IsReference() {
+		return referenceName(proxy.GetReference())
+	}
+
+	schema := proxy.Schema()
+	if schema == nil {
+		return "unknown"
+	}
+
+	typ := baseType(schema, indent)
+	if isNullable(schema) && typ != "unknown" {
+		return typ + " | null"
+	}
+	return typ
+}
+
+func baseType(schema *base.Schema, indent string) string {
+	switch {
+	case len(schema.OneOf) > 0:
+		return union(schema.OneOf, " | ", indent)
+	case len(schema.AnyOf) > 0:
+		return union(schema.AnyOf, " | ", indent)
+	case len(schema.AllOf) > 0:
+		typ := union(schema.AllOf, " & ", indent)
+		if schema.Properties != nil && schema.Properties.Len() > 0 {
+			typ += " & " + objectType(schema, indent)
+		}
+		return typ
+	case len(schema.Enum) > 0:
+		return enumType(schema)
+	}
+
+	var types []string
+	for _, t := range schema.Type {
+		if t == "null" {
+			continue
+		}
+		types = append(types, scalarType(t, schema, indent))
+	}
+
+	switch len(types) {
+	case 0:
+		if schema.Properties != nil && schema.Properties.Len() > 0 {
+			return objectType(schema, indent)
+		}
+		return "unknown"
+	case 1:
+		return types[0]
+	}
+	return strings.Join(types, " | ")
+}
+
+func scalarType(typ string, schema *base.Schema, indent string) string {
+	switch typ {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		if schema.Items == nil || !schema.Items.IsA() {
+			return "unknown[]"
+		}
+		item := tsType(schema.Items.A, indent)
+		if strings.ContainsAny(item, "|&") {
+			return "Array<" + item + ">"
+		}
+		return item + "[]"
+	case "object":
+		return objectType(schema, indent)
+	}
+	return "unknown"
+}
+
+// objectType returns an object literal type for the properties of schema, or a
+// Record when the object only declares additionalProperties
+func objectType(schema *base.Schema, indent string) string {
+	if schema.Properties == nil || schema.Properties.Len() == 0 {
+		if additional := schema.AdditionalProperties; additional != nil && additional.IsA() {
+			return "Record<string, " + tsType(additional.A, indent) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for name, prop := range schema.Properties.FromOldest() {
+		if s := prop.Schema(); s != nil && !prop.IsReference() {
+			writeDoc(&b, s, indent+"  ")
+		}
+
+		key := name
+		if !identifierRegex.MatchString(name) {
+			key = quote(name)
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, key, optional, tsType(prop, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func union(proxies []*base.SchemaProxy, sep, indent string) string {
+	types := make([]string, len(proxies))
+	for i, p := range proxies {
+		types[i] = tsType(p, indent)
+		if strings.ContainsAny(types[i], "|&") && sep == " & " {
+			types[i] = "(" + types[i] + ")"
+		}
+	}
+	return strings.Join(types, sep)
+}
+
+func enumType(schema *base.Schema) string {
+	values := make([]string, 0, len(schema.Enum))
+	for _, v := range schema.Enum {
+		if v == nil {
+			continue
+		}
+		if hasType(schema, "string") || len(schema.Type) == 0 {
+			values = append(values, quote(v.Value))
+			continue
+		}
+		values = append(values, v.Value)
+	}
+	return strings.Join(values, " | ")
+}
+
+// writeDoc writes the description and deprecation of a schema as a JSDoc comment
+func writeDoc(b *strings.Builder, schema *base.Schema, indent string) {
+	var lines []string
+	if d := strings.TrimSpace(schema.Description); d != "" {
+		lines = strings.Split(d, "\n")
+	}
+	if schema.Deprecated != nil && *schema.Deprecated {
+		lines = append(lines, "@deprecated")
+	}
+
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(b, "%s/** %s */\n", indent, escapeComment(lines[0]))
+		return
+	}
+
+	b.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			b.WriteString(indent + " *\n")
+			continue
+		}
+		fmt.Fprintf(b, "%s * %s\n", indent, escapeComment(line))
+	}
+	b.WriteString(indent + " */\n")
+}
+
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "*\\/")
+}
+
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func isNullable(schema *base.Schema) bool {
+	return (schema.Nullable != nil && *schema.Nullable) || hasType(schema, "null")
+}
+
+func hasType(schema *base.Schema, typ string) bool {
+	for _, t := range schema.Type {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceName extracts the schema name from a reference such as '#/components/schemas/Address'
+func referenceName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
@@ -0,0 +1,378 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	componentsPrefix = "#/components/"
+	schemaRefPrefix  = "#/components/schemas/"
+)
+
+// Config controls the rendering of the reference graph
+type Config struct {
+	Writer   io.Writer
+	SpecPath string
+	// Format is either dot or mermaid
+	Format string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// refGraph holds the references from operations to schemas and between schemas
+type refGraph struct {
+	Operations []string
+	Schemas    []string
+	// Edges maps an operation path or schema name to the schemas it references
+	Edges map[string][]string
+	// Cycles lists the groups of schemas which reference each other
+	Cycles [][]string
+	// Orphans lists the schemas no operation reaches
+	Orphans []string
+
+	inCycle map[string]int
+}
+
+// Run writes the reference graph of the spec in the requested format
+func Run(conf Config) error {
+	if conf.Format != "dot" && conf.Format != "mermaid" {
+		return fmt.Errorf("unknown format '%s': must be dot or mermaid", conf.Format)
+	}
+
+	start := time.Now()
+	if _, err := lint.Load(conf.SpecPath); err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	content, err := os.ReadFile(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	g, err := build(content)
+	if err != nil {
+		return err
+	}
+
+	if conf.Format == "mermaid" {
+		writeMermaid(conf.Writer, g)
+		return nil
+	}
+	writeDot(conf.Writer, g)
+	return nil
+}
+
+// build reads the reference graph from the spec content. References through
+// components such as responses and request bodies are followed to the schemas
+// they use.
+func build(content []byte) (*refGraph, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	g := &refGraph{Edges: make(map[string][]string), inCycle: make(map[string]int)}
+	if len(root.Content) == 0 {
+		return g, nil
+	}
+	doc := root.Content[0]
+
+	schemas := mapValue(mapValue(doc, "components"), "schemas")
+	if schemas != nil {
+		for i := 0; i+1 < len(schemas.Content); i += 2 {
+			name := schemas.Content[i].Value
+			g.Schemas = append(g.Schemas, name)
+			g.Edges[name] = schemaRefs(doc, schemas.Content[i+1])
+		}
+	}
+
+	paths := mapValue(doc, "paths")
+	if paths != nil && paths.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(paths.Content); i += 2 {
+			path := paths.Content[i].Value
+			g.Operations = append(g.Operations, path)
+			g.Edges[path] = schemaRefs(doc, paths.Content[i+1])
+		}
+	}
+
+	g.findCycles()
+	g.findOrphans()
+	return g, nil
+}
+
+// schemaRefs returns the component schemas the node references in the order they
+// are found, following references to other kinds of components
+func schemaRefs(doc, node *yaml.Node) []string {
+	var names []string
+	visited := make(map[string]bool)
+
+	var visit func(n *yaml.Node)
+	visit = func(n *yaml.Node) {
+		walkScalars(n, func(s *yaml.Node) {
+			if name, ok := strings.CutPrefix(s.Value, schemaRefPrefix); ok {
+				if name != "" && !contains(names, name) {
+					names = append(names, name)
+				}
+				return
+			}
+
+			rest, ok := strings.CutPrefix(s.Value, componentsPrefix)
+			if !ok || visited[rest] {
+				return
+			}
+			visited[rest] = true
+			kind, name, _ := strings.Cut(rest, "/")
+			if target := mapValue(mapValue(mapValue(doc, "components"), kind), name); target != nil {
+				visit(target)
+			}
+		})
+	}
+	visit(node)
+	return names
+}
+
+// findCycles groups the schemas which reference each other, using Tarjan's
+// strongly connected components
+func (g *refGraph) findCycles() {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	next := 0
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = next
+		low[name] = next
+		next++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, ref := range g.Edges[name] {
+			if _, ok := index[ref]; !ok {
+				if !g.isSchema(ref) {
+					continue
+				}
+				connect(ref)
+				low[name] = min(low[name], low[ref])
+			} else if onStack[ref] {
+				low[name] = min(low[name], index[ref])
+			}
+		}
+
+		if low[name] != index[name] {
+			return
+		}
+
+		var group []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			group = append(group, top)
+			if top == name {
+				break
+			}
+		}
+		if len(group) > 1 || contains(g.Edges[name], name) {
+			sort.Strings(group)
+			for _, member := range group {
+				g.inCycle[member] = len(g.Cycles)
+			}
+			g.Cycles = append(g.Cycles, group)
+		}
+	}
+
+	for _, name := range g.Schemas {
+		if _, ok := index[name]; !ok {
+			connect(name)
+		}
+	}
+}
+
+func (g *refGraph) findOrphans() {
+	reached := make(map[string]bool)
+	var reach func(name string)
+	reach = func(name string) {
+		if reached[name] {
+			return
+		}
+		reached[name] = true
+		for _, ref := range g.Edges[name] {
+			reach(ref)
+		}
+	}
+	for _, op := range g.Operations {
+		for _, ref := range g.Edges[op] {
+			reach(ref)
+		}
+	}
+
+	for _, name := range g.Schemas {
+		if !reached[name] {
+			g.Orphans = append(g.Orphans, name)
+		}
+	}
+}
+
+func (g *refGraph) isSchema(name string) bool {
+	_, ok := g.Edges[name]
+	return ok && !strings.HasPrefix(name, "/")
+}
+
+// cycleEdge reports whether the edge joins two schemas of the same cycle
+func (g *refGraph) cycleEdge(from, to string) bool {
+	a, ok := g.inCycle[from]
+	if !ok {
+		return false
+	}
+	b, ok := g.inCycle[to]
+	return ok && a == b
+}
+
+func (g *refGraph) writeSummary(w io.Writer, comment string) {
+	for _, cycle := range g.Cycles {
+		_, _ = fmt.Fprintf(w, "%s cycle: %s\n", comment, strings.Join(cycle, ", "))
+	}
+	if len(g.Orphans) > 0 {
+		_, _ = fmt.Fprintf(w, "%s orphaned: %s\n", comment, strings.Join(g.Orphans, ", "))
+	}
+}
+
+func writeDot(w io.Writer, g *refGraph) {
+	_, _ = fmt.Fprintln(w, "digraph api {")
+	g.writeSummary(w, "  //")
+	_, _ = fmt.Fprintln(w, "  rankdir=LR;")
+	_, _ = fmt.Fprintln(w, "  node [shape=box];")
+
+	for _, op := range g.Operations {
+		_, _ = fmt.Fprintf(w, "  %q [shape=ellipse];\n", op)
+	}
+	for _, name := range g.Schemas {
+		switch {
+		case contains(g.Orphans, name):
+			_, _ = fmt.Fprintf(w, "  %q [style=dashed, color=gray];\n", name)
+		case g.inCycleNode(name):
+			_, _ = fmt.Fprintf(w, "  %q [color=red];\n", name)
+		default:
+			_, _ = fmt.Fprintf(w, "  %q;\n", name)
+		}
+	}
+
+	for _, from := range append(append([]string(nil), g.Operations...), g.Schemas...) {
+		for _, to := range g.Edges[from] {
+			// References to schemas missing from the spec are left to 'duh lint'
+			if !g.isSchema(to) {
+				continue
+			}
+			if g.cycleEdge(from, to) {
+				_, _ = fmt.Fprintf(w, "  %q -> %q [color=red];\n", from, to)
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "  %q -> %q;\n", from, to)
+		}
+	}
+	_, _ = fmt.Fprintln(w, "}")
+}
+
+func writeMermaid(w io.Writer, g *refGraph) {
+	_, _ = fmt.Fprintln(w, "flowchart LR")
+	g.writeSummary(w, "  %%")
+
+	ids := make(map[string]string)
+	for i, op := range g.Operations {
+		ids[op] = fmt.Sprintf("op%d", i+1)
+		_, _ = fmt.Fprintf(w, "  %s([\"%s\"])\n", ids[op], op)
+	}
+	for i, name := range g.Schemas {
+		ids[name] = fmt.Sprintf("s%d", i+1)
+		_, _ = fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[name], name)
+	}
+
+	var cycleLinks []string
+	link := 0
+	for _, from := range append(append([]string(nil), g.Operations...), g.Schemas...) {
+		for _, to := range g.Edges[from] {
+			if !g.isSchema(to) {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "  %s --> %s\n", ids[from], ids[to])
+			if g.cycleEdge(from, to) {
+				cycleLinks = append(cycleLinks, fmt.Sprint(link))
+			}
+			link++
+		}
+	}
+
+	var cycleNodes, orphanNodes []string
+	for _, name := range g.Schemas {
+		if contains(g.Orphans, name) {
+			orphanNodes = append(orphanNodes, ids[name])
+		} else if g.inCycleNode(name) {
+			cycleNodes = append(cycleNodes, ids[name])
+		}
+	}
+	if len(cycleNodes) > 0 {
+		_, _ = fmt.Fprintln(w, "  classDef cycle stroke:#d33,stroke-width:2px")
+		_, _ = fmt.Fprintf(w, "  class %s cycle\n", strings.Join(cycleNodes, ","))
+	}
+	if len(cycleLinks) > 0 {
+		_, _ = fmt.Fprintf(w, "  linkStyle %s stroke:#d33\n", strings.Join(cycleLinks, ","))
+	}
+	if len(orphanNodes) > 0 {
+		_, _ = fmt.Fprintln(w, "  classDef orphan stroke-dasharray:5 5,color:#888")
+		_, _ = fmt.Fprintf(w, "  class %s orphan\n", strings.Join(orphanNodes, ","))
+	}
+}
+
+func (g *refGraph) inCycleNode(name string) bool {
+	_, ok := g.inCycle[name]
+	return ok
+}
+
+// walkScalars calls fn for every scalar value in the tree, mapping keys excluded
+func walkScalars(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkScalars(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walkScalars(node.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		fn(node)
+	}
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
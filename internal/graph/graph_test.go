@@ -0,0 +1,137 @@
+package graph_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphSpec = `openapi: 3.0.3
+info:
+  title: Org Chart
+  version: 1.0.0
+paths:
+  /teams.get:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/GetRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Team'
+        default:
+          $ref: '#/components/responses/Error'
+components:
+  responses:
+    Error:
+      description: Error
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Error'
+  schemas:
+    GetRequest:
+      type: object
+    Team:
+      type: object
+      properties:
+        lead:
+          $ref: '#/components/schemas/Employee'
+    Employee:
+      type: object
+      properties:
+        team:
+          $ref: '#/components/schemas/Team'
+        reports:
+          type: array
+          items:
+            $ref: '#/components/schemas/Employee'
+    Error:
+      type: object
+    Office:
+      type: object
+`
+
+func TestGraphDot(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(graphSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"graph", specPath})
+	out := stdout.String()
+
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, out, "digraph api {\n")
+	assert.Contains(t, out, "  // cycle: Employee, Team\n")
+	assert.Contains(t, out, "  // orphaned: Office\n")
+	assert.Contains(t, out, `"/teams.get" [shape=ellipse];`)
+	assert.Contains(t, out, `"/teams.get" -> "Team";`)
+	assert.Contains(t, out, `"/teams.get" -> "Error";`)
+	assert.Contains(t, out, `"Team" -> "Employee" [color=red];`)
+	assert.Contains(t, out, `"Employee" -> "Employee" [color=red];`)
+	assert.Contains(t, out, `"Office" [style=dashed, color=gray];`)
+	assert.Contains(t, out, `"GetRequest";`)
+}
+
+func TestGraphMermaid(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(graphSpec), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"graph", specPath, "--format", "mermaid"})
+	out := stdout.String()
+
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, out, "flowchart LR\n")
+	assert.Contains(t, out, "  %% cycle: Employee, Team\n")
+	assert.Contains(t, out, `op1(["/teams.get"])`)
+	assert.Contains(t, out, `s2["Team"]`)
+	assert.Contains(t, out, "  op1 --> s2\n")
+	assert.Contains(t, out, "  class s2,s3 cycle\n")
+	assert.Contains(t, out, "  linkStyle 3,4,5 stroke:#d33\n")
+	assert.Contains(t, out, "  class s5 orphan\n")
+}
+
+func TestGraphErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(graphSpec), 0644))
+
+	for _, test := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "unknown format",
+			args: []string{"graph", specPath, "--format", "svg"},
+			want: "unknown format 'svg': must be dot or mermaid",
+		},
+		{
+			name: "file not found",
+			args: []string{"graph", filepath.Join(t.TempDir(), "missing.yaml")},
+			want: "file not found",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, test.args)
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.want)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
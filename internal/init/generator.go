@@ -1,6 +1,106 @@
 package init
 
-import _ "embed"
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
 
-//go:embed template/openapi.yaml
-var Template []byte
+//go:embed template/*.yaml.tmpl
+var templates embed.FS
+
+// DefaultTemplate is the users CRUD example which 'duh generate duh --full'
+// recognizes and generates a complete service for
+const DefaultTemplate = "users"
+
+// Templates are the names accepted by --template
+var Templates = []string{"minimal", "users", "streaming", "multi-subject"}
+
+var (
+	subjectRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+	versionRegex = regexp.MustCompile(`^v[0-9]+[a-z0-9]*$`)
+)
+
+// templateData is rendered into the templates
+type templateData struct {
+	// Subject is the resource of the /{subject}.{method} paths, e.g. products
+	Subject string
+	// Entity is the singular of Subject, e.g. product
+	Entity string
+	// Version is the last element of the servers url, e.g. v2
+	Version string
+}
+
+var funcs = template.FuncMap{
+	// words turns line-items into 'line items'
+	"words": func(s string) string {
+		return strings.NewReplacer("-", " ", "_", " ").Replace(s)
+	},
+	"title": func(s string) string {
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"pascal": func(s string) string {
+		var b strings.Builder
+		for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+		return b.String()
+	},
+	"snake": func(s string) string {
+		return strings.ReplaceAll(s, "-", "_")
+	},
+}
+
+// Template renders the named template
+func Template(name, subject, version string) ([]byte, error) {
+	source, err := templates.ReadFile("template/" + name + ".yaml.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("unknown template '%s': must be one of %s", name, strings.Join(Templates, ", "))
+	}
+
+	if subject != "" && name != DefaultTemplate {
+		return nil, fmt.Errorf("--subject is only supported by the %s template", DefaultTemplate)
+	}
+	if subject == "" {
+		subject = "users"
+	}
+	if !subjectRegex.MatchString(subject) {
+		return nil, fmt.Errorf("invalid subject: %s (must be lowercase and start with a letter, e.g. products)", subject)
+	}
+
+	if version == "" {
+		version = "v1"
+	}
+	if !versionRegex.MatchString(version) {
+		return nil, fmt.Errorf("invalid version: %s (must start with v and a number, e.g. v2)", version)
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData{Subject: subject, Entity: singular(subject), Version: version}); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// singular returns the singular of a plural English subject, e.g. categories
+// becomes category. Subjects which are not plural are returned as is.
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"),
+		strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return strings.TrimSuffix(s, "es")
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss") && len(s) > 1:
+		return strings.TrimSuffix(s, "s")
+	}
+	return s
+}
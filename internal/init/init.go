@@ -3,12 +3,52 @@ package init
 import (
 	"fmt"
 	"io"
+
+	"github.com/duh-rpc/duh-cli/internal/output"
 )
 
-func Run(w io.Writer, outputPath string) error {
-	if err := writeFile(outputPath, Template); err != nil {
+// Config controls which template is written and where
+type Config struct {
+	Writer     io.Writer
+	OutputPath string
+	// Template is one of Templates, defaults to DefaultTemplate
+	Template string
+	// Subject replaces users in the paths, schemas and descriptions of the
+	// users template, e.g. products
+	Subject string
+	// Version is the version in the servers url, defaults to v1
+	Version string
+	// Project is a Go module path; when set go.mod, .duh.yaml and a Makefile are
+	// created next to the spec in the current directory
+	Project string
+	// Generate runs 'duh generate --full' once the Project is created
+	Generate bool
+	// Output records the files written for --output json, it may be nil
+	Output *output.Result
+}
+
+func Run(conf Config) error {
+	if conf.Template == "" {
+		conf.Template = DefaultTemplate
+	}
+
+	if conf.Generate && conf.Project == "" {
+		return fmt.Errorf("--generate requires --project")
+	}
+
+	content, err := Template(conf.Template, conf.Subject, conf.Version)
+	if err != nil {
+		return err
+	}
+
+	if conf.Project != "" {
+		return runProject(conf, content)
+	}
+
+	if err := writeFile(conf.OutputPath, content); err != nil {
 		return err
 	}
-	_, _ = fmt.Fprintf(w, "✓ Created DUH-RPC compliant OpenAPI spec at %s\n", outputPath)
+	conf.Output.AddFiles(conf.OutputPath)
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Created DUH-RPC compliant OpenAPI spec at %s\n", conf.OutputPath)
 	return nil
 }
@@ -29,7 +29,7 @@ func TestInitDefaultPath(t *testing.T) {
 
 	var stdout bytes.Buffer
 
-	exitCode := duh.RunCmd(&stdout, []string{"init"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init"})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -48,7 +48,7 @@ func TestInitCustomPath(t *testing.T) {
 
 	var stdout bytes.Buffer
 
-	exitCode := duh.RunCmd(&stdout, []string{"init", outputPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", outputPath})
 
 	require.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -66,7 +66,7 @@ func TestInitFileAlreadyExists(t *testing.T) {
 
 	var stdout bytes.Buffer
 
-	exitCode := duh.RunCmd(&stdout, []string{"init", outputPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", outputPath})
 
 	require.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "file already exists")
@@ -78,7 +78,7 @@ func TestInitCreatesParentDirectory(t *testing.T) {
 
 	var stdout bytes.Buffer
 
-	exitCode := duh.RunCmd(&stdout, []string{"init", outputPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", outputPath})
 
 	require.Equal(t, 0, exitCode)
 
@@ -90,3 +90,199 @@ func TestInitCreatesParentDirectory(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, content)
 }
+
+func TestInitTemplates(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		template string
+		path     string
+	}{
+		{name: "Minimal", template: "minimal", path: "/echo.send:"},
+		{name: "Users", template: "users", path: "/users.update:"},
+		{name: "Streaming", template: "streaming", path: "application/octet-stream:"},
+		{name: "MultiSubject", template: "multi-subject", path: "/orders.list:"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+			var stdout bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", outputPath, "--template", test.template})
+			require.Equal(t, 0, exitCode)
+
+			content, err := os.ReadFile(outputPath)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), test.path)
+
+			stdout.Reset()
+			exitCode = duh.RunCmd(&stdout, &stdout, []string{"lint", outputPath})
+			assert.Equal(t, 0, exitCode)
+		})
+	}
+}
+
+func TestInitUnknownTemplate(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"init", outputPath, "--template", "grpc"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "unknown template 'grpc': must be one of minimal, users, streaming, multi-subject")
+	assert.Empty(t, stdout.String())
+	assert.NoFileExists(t, outputPath)
+}
+
+func TestInitSubjectAndVersion(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", outputPath, "--subject", "categories", "--version", "v2"})
+	require.Equal(t, 0, exitCode)
+
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	contentStr := string(content)
+
+	assert.Contains(t, contentStr, "- url: https://api.example.com/v2")
+	assert.Contains(t, contentStr, "/categories.create:")
+	assert.Contains(t, contentStr, "/categories.update:")
+	assert.Contains(t, contentStr, "summary: Get category by ID")
+	assert.Contains(t, contentStr, "operationId: listCategories")
+	assert.Contains(t, contentStr, "category_id:")
+	assert.NotContains(t, contentStr, "users")
+	assert.NotContains(t, contentStr, "user_id")
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"lint", outputPath})
+	assert.Equal(t, 0, exitCode)
+}
+
+func TestInitSubjectAndVersionErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "InvalidSubject",
+			args:    []string{"--subject", "Products"},
+			wantErr: "invalid subject: Products",
+		},
+		{
+			name:    "InvalidVersion",
+			args:    []string{"--version", "2"},
+			wantErr: "invalid version: 2",
+		},
+		{
+			name:    "SubjectWithOtherTemplate",
+			args:    []string{"--subject", "products", "--template", "minimal"},
+			wantErr: "--subject is only supported by the users template",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			outputPath := filepath.Join(t.TempDir(), "openapi.yaml")
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"init", outputPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+			assert.NoFileExists(t, outputPath)
+		})
+	}
+}
+
+func TestInitProject(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", "--project", "github.com/acme/billing"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Created go.mod")
+	assert.Contains(t, stdout.String(), "✓ Created openapi.yaml")
+	assert.Contains(t, stdout.String(), "✓ Created .duh.yaml")
+	assert.Contains(t, stdout.String(), "✓ Created Makefile")
+	assert.Contains(t, stdout.String(), "Run 'duh generate --full openapi.yaml'")
+
+	goMod, err := os.ReadFile("go.mod")
+	require.NoError(t, err)
+	assert.Equal(t, "module github.com/acme/billing\n\ngo 1.24\n", string(goMod))
+
+	config, err := os.ReadFile(".duh.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "disable: []")
+
+	makefile, err := os.ReadFile("Makefile")
+	require.NoError(t, err)
+	assert.Contains(t, string(makefile), "proto:")
+
+	assert.FileExists(t, "openapi.yaml")
+	assert.NoFileExists(t, "server.go")
+}
+
+func TestInitProjectGenerate(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", "--project", "github.com/acme/billing", "--generate"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Generated 15 file(s)")
+
+	service, err := os.ReadFile("service.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(service), "func (s *Service) CreateUser")
+	assert.FileExists(t, "proto/v1/api.proto")
+	assert.FileExists(t, "cmd/billingctl/main.go")
+	assert.FileExists(t, "seed.go")
+}
+
+func TestInitProjectErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []string
+		existing string
+		wantErr  string
+	}{
+		{
+			name:    "InvalidModulePath",
+			args:    []string{"--project", "billing"},
+			wantErr: "invalid module path: billing",
+		},
+		{
+			name:    "GenerateWithoutProject",
+			args:    []string{"--generate"},
+			wantErr: "--generate requires --project",
+		},
+		{
+			name:     "GoModExists",
+			args:     []string{"--project", "github.com/acme/billing"},
+			existing: "go.mod",
+			wantErr:  "file already exists: go.mod",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+			require.NoError(t, os.Chdir(tempDir))
+
+			if test.existing != "" {
+				require.NoError(t, os.WriteFile(test.existing, []byte("existing content"), 0644))
+			}
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"init"}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+			assert.NoFileExists(t, "openapi.yaml")
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+)
+
+// goVersion is the go directive of the go.mod created by --project
+const goVersion = "1.24"
+
+const lintConfig = `# Configuration for duh lint, see docs/duh-linter-rules.md in duh-cli
+lint:
+  # Rules to skip, e.g. DESCRIPTION_REQUIRED
+  disable: []
+`
+
+// runProject bootstraps a service in the current directory around the spec
+func runProject(conf Config, spec []byte) error {
+	if !strings.Contains(conf.Project, "/") || strings.ContainsAny(conf.Project, " \t") {
+		return fmt.Errorf("invalid module path: %s (must contain '/', e.g. github.com/org/svc)", conf.Project)
+	}
+
+	generator, err := duh.NewGenerator()
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+	// The same Makefile 'duh generate --full' writes, so regenerating leaves it unchanged
+	makefile, err := generator.RenderMakefile(&duh.TemplateData{})
+	if err != nil {
+		return fmt.Errorf("failed to render Makefile: %w", err)
+	}
+
+	files := []struct {
+		path    string
+		content []byte
+	}{
+		{"go.mod", []byte(fmt.Sprintf("module %s\n\ngo %s\n", conf.Project, goVersion))},
+		{conf.OutputPath, spec},
+		{".duh.yaml", []byte(lintConfig)},
+		{"Makefile", makefile},
+	}
+
+	// Nothing is written unless every file can be created
+	for _, f := range files {
+		if _, err := os.Stat(f.path); err == nil {
+			return fmt.Errorf("file already exists: %s", f.path)
+		}
+	}
+
+	for _, f := range files {
+		if err := writeFile(f.path, f.content); err != nil {
+			return err
+		}
+		conf.Output.AddFiles(f.path)
+		_, _ = fmt.Fprintf(conf.Writer, "✓ Created %s\n", f.path)
+	}
+
+	if !conf.Generate {
+		_, _ = fmt.Fprintf(conf.Writer, "\nNext steps:\n")
+		_, _ = fmt.Fprintf(conf.Writer, "  1. Run 'duh generate --full %s' to generate the service\n", conf.OutputPath)
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(conf.Writer)
+	return duh.Run(duh.RunConfig{
+		Writer:    conf.Writer,
+		SpecPath:  conf.OutputPath,
+		OutputDir: ".",
+		FullFlag:  true,
+		// The Makefile written above is not an edit of the project to keep
+		Force:     true,
+		Converter: duh.NewProtoConverter(duh.ProtoOptions{}),
+		Output:    conf.Output,
+	})
+}
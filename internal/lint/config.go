@@ -11,7 +11,26 @@ type Config struct {
 }
 
 type LintConfig struct {
-	Disable []string `yaml:"disable"`
+	Disable  []string       `yaml:"disable"`
+	Security SecurityConfig `yaml:"security"`
+}
+
+// SecurityConfig enables the rules flagging the properties which look like
+// secrets or personal data, which do not run otherwise
+type SecurityConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Sensitive replaces the names the rules look for in the properties,
+	// which are rules.DefaultSensitiveNames when it is empty
+	Sensitive []string `yaml:"sensitive"`
+}
+
+// Rules returns the security rules to run besides the DUH-RPC rules, none
+// unless they are enabled
+func (c SecurityConfig) Rules() []Rule {
+	if !c.Enabled {
+		return nil
+	}
+	return SecurityRules(c.Sensitive)
 }
 
 func LoadConfig() Config {
@@ -1,6 +1,7 @@
 package lint
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,25 +9,39 @@ import (
 	"github.com/pb33f/libopenapi/datamodel/high/v3"
 )
 
+var (
+	// ErrFileNotFound is returned by Load when the spec does not exist
+	ErrFileNotFound = errors.New("file not found")
+	// ErrParse is returned when the spec is not a valid OpenAPI document
+	ErrParse = errors.New("failed to parse OpenAPI spec")
+	// ErrValidation is returned by commands which refuse a spec with lint errors
+	ErrValidation = errors.New("OpenAPI validation failed")
+)
+
 // Load reads and parses an OpenAPI 3.0 YAML file
 func Load(filePath string) (*v3.Document, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("file not found: %s", filePath)
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
 	}
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
 	}
 
+	return Parse(data)
+}
+
+// Parse parses an OpenAPI 3.0 YAML document
+func Parse(data []byte) (*v3.Document, error) {
 	doc, err := libopenapi.NewDocument(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
 	}
 
 	model, err := doc.BuildV3Model()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
 	}
 
 	return &model.Model, nil
@@ -24,3 +24,10 @@ func Print(w io.Writer, result ValidationResult) {
 		_, _ = fmt.Fprintf(w, "✓ %s is DUH-RPC compliant\n", filename)
 	}
 }
+
+// PrintViolations outputs only the violations, one per line, for -q/--quiet
+func PrintViolations(w io.Writer, result ValidationResult) {
+	for _, violation := range result.Violations {
+		_, _ = fmt.Fprintln(w, violation.String())
+	}
+}
@@ -174,7 +174,7 @@ components:
 			filePath := writeYAML(t, test.spec)
 
 			var stdout bytes.Buffer
-			exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 			assert.Equal(t, test.expectedExit, exitCode)
 			assert.Contains(t, stdout.String(), test.expectedOutput)
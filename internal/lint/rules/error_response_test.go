@@ -653,7 +653,7 @@ paths:
 			filePath := writeYAML(t, test.spec)
 
 			var stdout bytes.Buffer
-			exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 			assert.Equal(t, test.expectedExit, exitCode)
 			assert.Contains(t, stdout.String(), test.expectedOutput)
@@ -718,7 +718,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓ spec.yaml is DUH-RPC compliant")
@@ -776,7 +776,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓ spec.yaml is DUH-RPC compliant")
@@ -1,9 +1,156 @@
 package rules
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
 
 func isPaginatedEndpoint(path string) bool {
 	return strings.HasSuffix(path, ".list") ||
 		strings.HasSuffix(path, ".search") ||
 		strings.HasSuffix(path, ".query")
 }
+
+// DefaultSensitiveNames are the names the security rules look for in the
+// properties, as secrets or personal data, unless .duh.yaml sets others.
+// Only qualified tokens are listed, as the page tokens of a list are not
+// secrets.
+var DefaultSensitiveNames = []string{
+	"password", "passphrase", "secret", "access_token", "refresh_token", "auth_token", "id_token",
+	"api_key", "private_key",
+	"ssn", "social_security_number", "tax_id", "credit_card", "card_number", "cvv", "pin",
+	"date_of_birth",
+}
+
+// sensitiveName returns the name of names the property contains as whole
+// words, so pin matches pin_code but not shipping, or "" when it has none.
+// The words of snake_case, kebab-case and camelCase names are compared.
+func sensitiveName(property string, names []string) string {
+	words := splitWords(property)
+	for _, name := range names {
+		want := normalize(name)
+		for i := range words {
+			joined := ""
+			for _, word := range words[i:] {
+				joined += word
+				if joined == want {
+					return name
+				}
+				if len(joined) >= len(want) {
+					break
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// splitWords splits a property name into its lowercase words
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, c := range name {
+		switch {
+		case c == '_' || c == '-' || c == '.':
+			flush()
+		case unicode.IsUpper(c) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, c)
+		default:
+			word = append(word, c)
+		}
+	}
+	flush()
+	return words
+}
+
+// isRedacted reports whether the property is marked x-duh-redact: true
+func isRedacted(schema *base.Schema) bool {
+	if schema == nil || schema.Extensions == nil {
+		return false
+	}
+	node, ok := schema.Extensions.Get("x-duh-redact")
+	if !ok || node == nil {
+		return false
+	}
+
+	var redacted bool
+	if err := node.Decode(&redacted); err != nil {
+		return false
+	}
+	return redacted
+}
+
+// responseSchemaNames returns the component schemas a success response
+// returns, with every schema they reference
+func responseSchemaNames(doc *v3.Document) map[string]bool {
+	names := make(map[string]bool)
+	if doc == nil || doc.Paths == nil || doc.Paths.PathItems == nil {
+		return names
+	}
+
+	var add func(proxy *base.SchemaProxy)
+	add = func(proxy *base.SchemaProxy) {
+		if proxy == nil {
+			return
+		}
+		if ref := proxy.GetReference(); ref != "" {
+			name := extractSchemaName(ref)
+			if names[name] {
+				return
+			}
+			names[name] = true
+		}
+		schema := proxy.Schema()
+		if schema == nil {
+			return
+		}
+		if schema.Properties != nil {
+			for _, prop := range schema.Properties.FromOldest() {
+				add(prop)
+			}
+		}
+		if schema.Items != nil && schema.Items.IsA() {
+			add(schema.Items.A)
+		}
+		if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsA() {
+			add(schema.AdditionalProperties.A)
+		}
+		for _, list := range [][]*base.SchemaProxy{schema.AllOf, schema.OneOf, schema.AnyOf} {
+			for _, part := range list {
+				add(part)
+			}
+		}
+	}
+
+	for _, pathItem := range doc.Paths.PathItems.FromOldest() {
+		if pathItem == nil {
+			continue
+		}
+		for _, operation := range pathItem.GetOperations().FromOldest() {
+			if operation == nil || operation.Responses == nil || operation.Responses.Codes == nil {
+				continue
+			}
+			for statusCode, response := range operation.Responses.Codes.FromOldest() {
+				if len(statusCode) != 3 || statusCode[0] != '2' || response == nil || response.Content == nil {
+					continue
+				}
+				for _, media := range response.Content.FromOldest() {
+					if media != nil {
+						add(media.Schema)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
@@ -59,7 +59,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	// DESCRIPTION_REQUIRED should not appear for that operation
 	assert.Equal(t, 0, exitCode)
@@ -111,7 +111,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	assert.Equal(t, 0, exitCode)
 	assert.NotContains(t, stdout.String(), "DESCRIPTION_REQUIRED")
@@ -178,7 +178,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	// Unknown rule is silently ignored, NO_NULLABLE still fires for the schema
 	assert.Equal(t, 1, exitCode)
@@ -238,7 +238,7 @@ components:
 	filePath := writeYAML(t, spec)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 	// NO_NULLABLE should not appear since schema has x-duh-lint-ignore
 	assert.Equal(t, 0, exitCode)
@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// endOfListFields tell a client when to stop asking for the next page
+var endOfListFields = []string{"hasmore", "hasnextpage", "total", "nextpagetoken"}
+
+// pageSizeFields set how many items a page holds
+var pageSizeFields = map[string]bool{"first": true, "limit": true, "pagesize": true, "perpage": true, "maxresults": true}
+
+// PaginationCursorSafetyRule flags list endpoints a client cannot page through
+// safely: responses which do not tell when the list ends, and page sizes
+// without a maximum, which let a single call read the whole table
+type PaginationCursorSafetyRule struct{}
+
+func NewPaginationCursorSafetyRule() *PaginationCursorSafetyRule {
+	return &PaginationCursorSafetyRule{}
+}
+
+func (r *PaginationCursorSafetyRule) Name() string {
+	return "PAGINATION_CURSOR_SAFETY"
+}
+
+func (r *PaginationCursorSafetyRule) Validate(doc *v3.Document) []Violation {
+	var violations []Violation
+
+	if doc == nil || doc.Paths == nil || doc.Paths.PathItems == nil {
+		return violations
+	}
+
+	for path, pathItem := range doc.Paths.PathItems.FromOldest() {
+		if !isPaginatedEndpoint(path) {
+			continue
+		}
+
+		if pathItem == nil || pathItem.Post == nil {
+			continue
+		}
+
+		if isOperationIgnored(pathItem.Post, r.Name()) {
+			continue
+		}
+
+		location := "POST " + path
+
+		if pathItem.Post.RequestBody != nil && pathItem.Post.RequestBody.Content != nil {
+			jsonContent, ok := pathItem.Post.RequestBody.Content.Get("application/json")
+			if ok && jsonContent != nil && jsonContent.Schema != nil && jsonContent.Schema.Schema() != nil {
+				// PAGINATION_PARAMETERS already requires pagination.first to have a maximum
+				for _, field := range unboundedPageSizes(jsonContent.Schema.Schema(), false) {
+					violations = append(violations, Violation{
+						Suggestion: "Add a 'maximum' so a single call cannot read the whole collection, e.g. maximum: 100",
+						Message:    fmt.Sprintf("Page size '%s' has no maximum", field),
+						Location:   location,
+						RuleName:   r.Name(),
+						Severity:   SeverityWarning,
+					})
+				}
+			}
+		}
+
+		if pathItem.Post.Responses == nil || pathItem.Post.Responses.Codes == nil {
+			continue
+		}
+
+		for statusCode, response := range pathItem.Post.Responses.Codes.FromOldest() {
+			if len(statusCode) == 0 || statusCode[0] != '2' {
+				continue
+			}
+
+			if response == nil || response.Content == nil {
+				continue
+			}
+
+			jsonContent, ok := response.Content.Get("application/json")
+			if !ok || jsonContent == nil || jsonContent.Schema == nil {
+				continue
+			}
+
+			schema := jsonContent.Schema.Schema()
+			if schema == nil || schema.Properties == nil || hasEndOfList(schema) {
+				continue
+			}
+			if pageProxy, hasPage := schema.Properties.Get("pagination"); hasPage && pageProxy.Schema() != nil && hasEndOfList(pageProxy.Schema()) {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Suggestion: "Add 'has_more' to the 'pagination' object of the response, or 'total' or 'next_page_token'",
+				Message:    "List response does not tell the client when the last page is reached",
+				Location:   location + " response " + statusCode,
+				RuleName:   r.Name(),
+				Severity:   SeverityWarning,
+			})
+		}
+	}
+
+	return violations
+}
+
+func hasEndOfList(schema *base.Schema) bool {
+	if schema.Properties == nil {
+		return false
+	}
+	for name := range schema.Properties.KeysFromOldest() {
+		for _, field := range endOfListFields {
+			if normalize(name) == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unboundedPageSizes returns the page size properties of the request, and of
+// its pagination object, which are integers without a maximum
+func unboundedPageSizes(schema *base.Schema, nested bool) []string {
+	if schema.Properties == nil {
+		return nil
+	}
+	var fields []string
+	for name, proxy := range schema.Properties.FromOldest() {
+		property := proxy.Schema()
+		if property == nil {
+			continue
+		}
+		if !nested && normalize(name) == "pagination" {
+			for _, field := range unboundedPageSizes(property, true) {
+				fields = append(fields, name+"."+field)
+			}
+			continue
+		}
+		if !pageSizeFields[normalize(name)] || (nested && normalize(name) == "first") {
+			continue
+		}
+		if len(property.Type) > 0 && property.Type[0] == "integer" && property.Maximum == nil {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
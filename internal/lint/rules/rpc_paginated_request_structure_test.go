@@ -213,7 +213,7 @@ paths:
 			filePath := writeYAML(t, test.spec)
 
 			var stdout bytes.Buffer
-			exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 			assert.Equal(t, test.expectedExit, exitCode)
 			assert.Contains(t, stdout.String(), test.expectedOutput)
@@ -253,7 +253,7 @@ paths:
 		filePath := writeYAML(t, spec)
 
 		var stdout bytes.Buffer
-		exitCode := duh.RunCmd(&stdout, []string{"lint", filePath})
+		exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
 
 		assert.Equal(t, 1, exitCode)
 		assert.Contains(t, stdout.String(), "Pagination parameter 'first' must be nested under 'pagination' sub-object")
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// SecuritySensitiveRedactRule flags the properties named like a secret or
+// personal data which are not marked x-duh-redact, so the generated code
+// would write their values to its logs, audit records and errors
+type SecuritySensitiveRedactRule struct {
+	names []string
+}
+
+func NewSecuritySensitiveRedactRule(names []string) *SecuritySensitiveRedactRule {
+	return &SecuritySensitiveRedactRule{names: names}
+}
+
+func (r *SecuritySensitiveRedactRule) Name() string {
+	return "SECURITY_SENSITIVE_REDACT"
+}
+
+func (r *SecuritySensitiveRedactRule) Validate(doc *v3.Document) []Violation {
+	var violations []Violation
+
+	if doc == nil || doc.Components == nil || doc.Components.Schemas == nil {
+		return violations
+	}
+
+	for schemaName, schemaProxy := range doc.Components.Schemas.FromOldest() {
+		schema := schemaProxy.Schema()
+		if schema == nil || schema.Properties == nil {
+			continue
+		}
+
+		if isSchemaIgnored(schema, r.Name()) {
+			continue
+		}
+
+		for propName, propProxy := range schema.Properties.FromOldest() {
+			name := sensitiveName(propName, r.names)
+			if name == "" || isRedacted(propProxy.Schema()) {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Suggestion: "Add x-duh-redact: true to the property so the generated code masks its value",
+				Message:    fmt.Sprintf("Property '%s' looks like sensitive data (%s) but is not marked x-duh-redact", propName, name),
+				Location:   fmt.Sprintf("components/schemas/%s/%s", schemaName, propName),
+				RuleName:   r.Name(),
+				Severity:   SeverityWarning,
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,68 @@
+package rules_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecuritySensitiveRedactRule(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		spec           string
+		expectedOutput string
+		notExpected    string
+	}{
+		{
+			name: "ValidRedacted",
+			spec: fmt.Sprintf(securitySpec, `        card_number:
+          description: Card to charge
+          type: string
+          x-duh-redact: true`, "", ""),
+			notExpected: "SECURITY_SENSITIVE_REDACT",
+		},
+		{
+			name: "InvalidNotRedacted",
+			spec: fmt.Sprintf(securitySpec, `        new_password:
+          description: Password of the user
+          type: string`, "", ""),
+			expectedOutput: "Property 'new_password' looks like sensitive data (password) but is not marked x-duh-redact",
+		},
+		{
+			name: "InvalidRedactFalse",
+			spec: fmt.Sprintf(securitySpec, `        pin_code:
+          description: PIN of the card
+          type: string
+          x-duh-redact: false`, "", ""),
+			expectedOutput: "Property 'pin_code' looks like sensitive data (pin) but is not marked x-duh-redact",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := writeYAML(t, test.spec)
+
+			var stdout bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "--security", filePath})
+
+			// Only a warning, the spec stays compliant
+			assert.Equal(t, 0, exitCode, stdout.String())
+			if test.expectedOutput != "" {
+				assert.Contains(t, stdout.String(), test.expectedOutput)
+			}
+			if test.notExpected != "" {
+				assert.NotContains(t, stdout.String(), test.notExpected)
+			}
+		})
+	}
+
+	// The rule only runs with --security
+	filePath := writeYAML(t, fmt.Sprintf(securitySpec, `        password:
+          description: Password of the user
+          type: string`, "", ""))
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", filePath})
+	assert.Equal(t, 0, exitCode, stdout.String())
+	assert.NotContains(t, stdout.String(), "SECURITY_SENSITIVE_REDACT")
+}
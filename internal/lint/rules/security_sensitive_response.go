@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// SecuritySensitiveResponseRule flags the properties of the response schemas
+// named like a secret or personal data, which the clients should not receive
+type SecuritySensitiveResponseRule struct {
+	names []string
+}
+
+func NewSecuritySensitiveResponseRule(names []string) *SecuritySensitiveResponseRule {
+	return &SecuritySensitiveResponseRule{names: names}
+}
+
+func (r *SecuritySensitiveResponseRule) Name() string {
+	return "SECURITY_SENSITIVE_RESPONSE"
+}
+
+func (r *SecuritySensitiveResponseRule) Validate(doc *v3.Document) []Violation {
+	var violations []Violation
+
+	if doc == nil || doc.Components == nil || doc.Components.Schemas == nil {
+		return violations
+	}
+
+	responseSchemas := responseSchemaNames(doc)
+	for schemaName, schemaProxy := range doc.Components.Schemas.FromOldest() {
+		if !responseSchemas[schemaName] {
+			continue
+		}
+
+		schema := schemaProxy.Schema()
+		if schema == nil || schema.Properties == nil {
+			continue
+		}
+
+		if isSchemaIgnored(schema, r.Name()) {
+			continue
+		}
+
+		for propName := range schema.Properties.FromOldest() {
+			name := sensitiveName(propName, r.names)
+			if name == "" {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				Suggestion: "Remove the property from the response, or add the rule to x-duh-lint-ignore of the schema if it holds no sensitive data",
+				Message:    fmt.Sprintf("Property '%s' looks like sensitive data (%s) and must not be returned in a response", propName, name),
+				Location:   fmt.Sprintf("components/schemas/%s/%s", schemaName, propName),
+				RuleName:   r.Name(),
+				Severity:   SeverityError,
+			})
+		}
+	}
+
+	return violations
+}
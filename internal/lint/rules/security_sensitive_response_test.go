@@ -0,0 +1,203 @@
+package rules_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+)
+
+// securitySpec is a spec with the properties of CreateRequest and of the
+// User the response of /users.create returns
+const securitySpec = `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users.create:
+    post:
+      description: Create a user
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        200:
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        400:
+          description: Bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      properties:
+        name:
+          description: User name
+          type: string
+%s
+    CreateResponse:
+      type: object
+      properties:
+        user:
+          description: The created user
+          allOf:
+            - $ref: '#/components/schemas/User'
+    User:
+      type: object
+%s      properties:
+        id:
+          description: User ID
+          type: string
+%s
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          description: Error message
+          type: string`
+
+func TestSecuritySensitiveResponseRule(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		spec           string
+		expectedExit   int
+		expectedOutput string
+	}{
+		{
+			name: "ValidSecretOnlyInRequest",
+			spec: fmt.Sprintf(securitySpec, `        password:
+          description: Password of the user
+          type: string
+          x-duh-redact: true`, "", `        shipping_address:
+          description: Where orders are shipped, pin does not match shipping
+          type: string`),
+			expectedExit:   0,
+			expectedOutput: "compliant",
+		},
+		{
+			name: "InvalidSecretInReferencedSchema",
+			spec: fmt.Sprintf(securitySpec, "", "", `        api_key:
+          description: API key of the user
+          type: string
+          x-duh-redact: true`),
+			expectedExit:   1,
+			expectedOutput: "Property 'api_key' looks like sensitive data (api_key) and must not be returned in a response",
+		},
+		{
+			name: "ValidIgnored",
+			spec: fmt.Sprintf(securitySpec, "", "      x-duh-lint-ignore: [SECURITY_SENSITIVE_RESPONSE]\n", `        ssn:
+          description: Social security number
+          type: string
+          x-duh-redact: true`),
+			expectedExit:   0,
+			expectedOutput: "compliant",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := writeYAML(t, test.spec)
+
+			var stdout bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "--security", filePath})
+
+			assert.Equal(t, test.expectedExit, exitCode, stdout.String())
+			assert.Contains(t, stdout.String(), test.expectedOutput)
+		})
+	}
+}
+
+func TestSecurityPageTokenIsNotSensitive(t *testing.T) {
+	filePath := writeYAML(t, `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users.list:
+    post:
+      description: List users
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/ListRequest'
+      responses:
+        200:
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/ListResponse'
+        400:
+          description: Bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    ListRequest:
+      type: object
+      properties:
+        pagination:
+          description: Page of users to return
+          type: object
+          properties:
+            first:
+              description: Number of users per page
+              type: integer
+              format: int32
+              minimum: 1
+              maximum: 100
+            after:
+              description: Cursor of the page to return
+              type: string
+    ListResponse:
+      type: object
+      properties:
+        items:
+          description: The users of the page
+          type: array
+          items:
+            type: string
+        pagination:
+          $ref: '#/components/schemas/PageInfo'
+    PageInfo:
+      type: object
+      properties:
+        end_cursor:
+          description: Cursor of the last user of the page
+          type: string
+        next_page_token:
+          description: Token of the next page, empty on the last page
+          type: string
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          description: Error message
+          type: string`)
+
+	var stdout bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "--security", filePath})
+
+	assert.Equal(t, 0, exitCode, stdout.String())
+	assert.Contains(t, stdout.String(), "compliant")
+}
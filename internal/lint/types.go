@@ -11,6 +11,10 @@ type Violation = rules.Violation
 type ValidationResult struct {
 	Violations []Violation
 	FilePath   string
+	// Rules are the names of the rules which ran, in order
+	Rules []string
+	// Disabled are the names of the rules which were skipped
+	Disabled []string
 }
 
 // Valid returns true if no ERROR-severity violations exist
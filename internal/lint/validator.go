@@ -11,9 +11,22 @@ type Rule interface {
 	Validate(doc *v3.Document) []Violation
 }
 
-// Validate runs all registered rules against the document.
-// The disabled parameter is a list of rule names to skip.
-func Validate(doc *v3.Document, filePath string, disabled []string) ValidationResult {
+// SecurityRules returns the rules flagging the properties named like one of
+// names, or like one of rules.DefaultSensitiveNames when names is empty
+func SecurityRules(names []string) []Rule {
+	if len(names) == 0 {
+		names = rules2.DefaultSensitiveNames
+	}
+	return []Rule{
+		rules2.NewSecuritySensitiveResponseRule(names),
+		rules2.NewSecuritySensitiveRedactRule(names),
+	}
+}
+
+// Validate runs all registered rules against the document, and then extra,
+// such as the SecurityRules. The disabled parameter is a list of rule names
+// to skip.
+func Validate(doc *v3.Document, filePath string, disabled []string, extra ...Rule) ValidationResult {
 	allRules := []Rule{
 		rules2.NewPathFormatRule(),
 		rules2.NewPathNoVersionPrefixRule(),
@@ -65,24 +78,24 @@ func Validate(doc *v3.Document, filePath string, disabled []string) ValidationRe
 		rules2.NewNoPlainTextResponseRule(),
 		rules2.NewSchemaExampleValidationRule(),
 		rules2.NewPaginationNoLimitOffsetRule(),
+		rules2.NewPaginationCursorSafetyRule(),
 	}
+	allRules = append(allRules, extra...)
 
 	disabledSet := make(map[string]bool, len(disabled))
 	for _, name := range disabled {
 		disabledSet[name] = true
 	}
 
-	var violations []Violation
+	result := ValidationResult{FilePath: filePath}
 	for _, rule := range allRules {
 		if disabledSet[rule.Name()] {
+			result.Disabled = append(result.Disabled, rule.Name())
 			continue
 		}
-		ruleViolations := rule.Validate(doc)
-		violations = append(violations, ruleViolations...)
+		result.Rules = append(result.Rules, rule.Name())
+		result.Violations = append(result.Violations, rule.Validate(doc)...)
 	}
 
-	return ValidationResult{
-		Violations: violations,
-		FilePath:   filePath,
-	}
+	return result
 }
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+)
+
+// Log prints the details requested with -v/--verbose. Like Result, its methods
+// do nothing on a nil Log, so packages can log into an optional *Log without
+// checking whether verbose output was requested.
+type Log struct {
+	w io.Writer
+}
+
+// NewLog returns a Log which writes to w, normally stderr so the results on
+// stdout are not interleaved with the details
+func NewLog(w io.Writer) *Log {
+	return &Log{w: w}
+}
+
+// Printf writes a single line of detail
+func (l *Log) Printf(format string, args ...any) {
+	if l == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(l.w, "verbose: "+format+"\n", args...)
+}
+
+// Parsed reports how long it took to parse the spec, start being the time
+// lint.Load was called
+func (l *Log) Parsed(filePath string, start time.Time) {
+	l.Printf("parsed %s in %s", filePath, time.Since(start).Round(time.Microsecond))
+}
+
+// Phase reports how long a phase of a generation took, start being the time
+// the phase began
+func (l *Log) Phase(name string, start time.Time) {
+	l.Spent(name, time.Since(start))
+}
+
+// Spent reports the time spent in a phase whose work was interleaved with
+// other phases
+func (l *Log) Spent(name string, d time.Duration) {
+	l.Printf("%s phase took %s", name, d.Round(time.Microsecond))
+}
+
+// Rules reports the lint rules which ran against the spec and the rules skipped
+// because they were disabled
+func (l *Log) Rules(result lint.ValidationResult) {
+	l.Printf("ran %d rule(s) against %s: %s", len(result.Rules), result.FilePath, strings.Join(result.Rules, ", "))
+	if len(result.Disabled) > 0 {
+		l.Printf("skipped %d disabled rule(s): %s", len(result.Disabled), strings.Join(result.Disabled, ", "))
+	}
+}
+
+// Rendering reports a template being rendered
+func (l *Log) Rendering(file string) {
+	l.Printf("rendering %s", file)
+}
+
+// Unchanged reports a file which was not rendered because its inputs did not
+// change since the last run
+func (l *Log) Unchanged(file string) {
+	l.Printf("skipped %s: unchanged since the last run", file)
+}
+
+// Skipped reports a file which was not written because it already exists
+func (l *Log) Skipped(file string) {
+	l.Printf("skipped %s: already exists", file)
+}
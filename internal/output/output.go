@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+)
+
+// Formats accepted by the global --output flag
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// Error codes reported by --output json
+const (
+	CodeFileNotFound     = "file_not_found"
+	CodeParseError       = "parse_error"
+	CodeValidationFailed = "validation_failed"
+	CodeUsage            = "usage_error"
+	CodeError            = "error"
+)
+
+// Result is written by --output json in place of the text output of a command.
+// Its methods do nothing on a nil Result, so packages can record into an
+// optional *Result without checking whether JSON output was requested.
+type Result struct {
+	Command    string      `json:"command"`
+	Success    bool        `json:"success"`
+	ExitCode   int         `json:"exit_code"`
+	Files      []string    `json:"files"`
+	Violations []Violation `json:"violations"`
+	Changes    *Changes    `json:"changes,omitempty"`
+	Error      *Error      `json:"error,omitempty"`
+}
+
+// Changes is what a generation changed since the previous run, as recorded in
+// its manifest. On the first run every file, operation and message is added.
+type Changes struct {
+	FirstRun          bool     `json:"first_run"`
+	FilesAdded        []string `json:"files_added"`
+	FilesUpdated      []string `json:"files_updated"`
+	FilesUnchanged    []string `json:"files_unchanged"`
+	OperationsAdded   []string `json:"operations_added"`
+	OperationsRemoved []string `json:"operations_removed"`
+	MessagesAdded     []string `json:"messages_added"`
+}
+
+type Violation struct {
+	File       string `json:"file"`
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Location   string `json:"location"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AddFiles records files the command created or modified
+func (r *Result) AddFiles(files ...string) {
+	if r == nil {
+		return
+	}
+	r.Files = append(r.Files, files...)
+}
+
+// AddViolations records the lint violations found in file
+func (r *Result) AddViolations(file string, violations []lint.Violation) {
+	if r == nil {
+		return
+	}
+	for _, v := range violations {
+		r.Violations = append(r.Violations, Violation{
+			File:       file,
+			Rule:       v.RuleName,
+			Severity:   v.Severity.String(),
+			Location:   v.Location,
+			Message:    v.Message,
+			Suggestion: v.Suggestion,
+		})
+	}
+}
+
+// AddChanges records what a generation changed, those of several generations,
+// such as one per version, are combined
+func (r *Result) AddChanges(c Changes) {
+	if r == nil {
+		return
+	}
+	if r.Changes == nil {
+		r.Changes = &Changes{FirstRun: true}
+	}
+	r.Changes.FirstRun = r.Changes.FirstRun && c.FirstRun
+	r.Changes.FilesAdded = append(r.Changes.FilesAdded, c.FilesAdded...)
+	r.Changes.FilesUpdated = append(r.Changes.FilesUpdated, c.FilesUpdated...)
+	r.Changes.FilesUnchanged = append(r.Changes.FilesUnchanged, c.FilesUnchanged...)
+	r.Changes.OperationsAdded = append(r.Changes.OperationsAdded, c.OperationsAdded...)
+	r.Changes.OperationsRemoved = append(r.Changes.OperationsRemoved, c.OperationsRemoved...)
+	r.Changes.MessagesAdded = append(r.Changes.MessagesAdded, c.MessagesAdded...)
+}
+
+// Fail records the error which stopped the command
+func (r *Result) Fail(err error) {
+	if r == nil {
+		return
+	}
+	r.Error = &Error{Code: Code(err), Message: err.Error()}
+}
+
+// FailUsage records an error in the arguments or flags of the command
+func (r *Result) FailUsage(err error) {
+	if r == nil {
+		return
+	}
+	r.Error = &Error{Code: CodeUsage, Message: err.Error()}
+}
+
+// Write writes the result as JSON with the exit code of the command
+func (r *Result) Write(w io.Writer, exitCode int) error {
+	r.ExitCode = exitCode
+	r.Success = exitCode == 0
+	if r.Files == nil {
+		r.Files = []string{}
+	}
+	if r.Violations == nil {
+		r.Violations = []Violation{}
+	}
+	if c := r.Changes; c != nil {
+		for _, list := range []*[]string{&c.FilesAdded, &c.FilesUpdated, &c.FilesUnchanged,
+			&c.OperationsAdded, &c.OperationsRemoved, &c.MessagesAdded} {
+			if *list == nil {
+				*list = []string{}
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	_, _ = fmt.Fprintf(w, "%s\n", out)
+	return nil
+}
+
+// Code returns the error code reported for err
+func Code(err error) string {
+	switch {
+	case errors.Is(err, lint.ErrFileNotFound):
+		return CodeFileNotFound
+	case errors.Is(err, lint.ErrParse):
+		return CodeParseError
+	case errors.Is(err, lint.ErrValidation):
+		return CodeValidationFailed
+	default:
+		return CodeError
+	}
+}
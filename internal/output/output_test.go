@@ -0,0 +1,205 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartDir string
+
+func TestMain(m *testing.M) {
+	var err error
+	testStartDir, err = os.Getwd()
+	if err != nil {
+		panic("failed to get working directory: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+func TestOutputJSONInitAndAdd(t *testing.T) {
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/example/test\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"init", "--output", "json"})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, output.Result{
+		Command:    "init",
+		Success:    true,
+		Files:      []string{"openapi.yaml"},
+		Violations: []output.Violation{},
+	}, result)
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"add", "/teams.create", "TeamsCreate", "--output", "json"})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	result = output.Result{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, "add", result.Command)
+	assert.Equal(t, []string{"openapi.yaml"}, result.Files)
+}
+
+func TestOutputJSONGenerate(t *testing.T) {
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/example/test\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init"}))
+	require.NoError(t, os.Mkdir("api", 0755))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "--output-dir", "api", "--output", "json"})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Contains(t, result.Files, filepath.Join("api", "server.go"))
+	assert.Contains(t, result.Files, filepath.Join("api", "client.go"))
+	assert.Nil(t, result.Error)
+	require.NotNil(t, result.Changes)
+	assert.True(t, result.Changes.FirstRun)
+	assert.Contains(t, result.Changes.FilesAdded, "server.go")
+	assert.Contains(t, result.Changes.OperationsAdded, "/users.create")
+
+	// The second run has the first to compare with
+	stdout.Reset()
+	require.NoError(t, os.WriteFile("api/client.go", []byte("package api\n"), 0644))
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"generate", "--output-dir", "api", "--output", "json"})
+	require.Equal(t, 0, exitCode)
+
+	result = output.Result{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, &output.Changes{
+		FilesAdded:        []string{},
+		FilesUpdated:      []string{"client.go"},
+		FilesUnchanged:    []string{"server.go", filepath.Join("proto", "v1", "api.proto")},
+		OperationsAdded:   []string{},
+		OperationsRemoved: []string{},
+		MessagesAdded:     []string{},
+	}, result.Changes)
+}
+
+func TestOutputJSONViolations(t *testing.T) {
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(t.TempDir()))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/example/test\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init"}))
+	content, err := os.ReadFile("openapi.yaml")
+	require.NoError(t, err)
+	content = []byte(strings.ReplaceAll(string(content), "format: int32", "format: int8"))
+	require.NoError(t, os.WriteFile("openapi.yaml", content, 0644))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"lint", "--output", "json"})
+	require.Equal(t, 1, exitCode)
+	assert.Empty(t, stderr.String())
+
+	var result output.Result
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.False(t, result.Success)
+	assert.Nil(t, result.Error)
+	require.NotEmpty(t, result.Violations)
+	assert.Equal(t, "openapi.yaml", result.Violations[0].File)
+	assert.NotEmpty(t, result.Violations[0].Rule)
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"generate", "--output", "json"})
+	require.Equal(t, 2, exitCode)
+	assert.Empty(t, stderr.String())
+
+	result = output.Result{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.NotNil(t, result.Error)
+	assert.Equal(t, output.CodeValidationFailed, result.Error.Code)
+	assert.NotEmpty(t, result.Violations)
+	assert.Empty(t, result.Files)
+}
+
+func TestOutputJSONErrors(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		args     []string
+		wantCode string
+		wantErr  string
+	}{
+		{
+			name:     "FileNotFound",
+			args:     []string{"lint", "missing.yaml"},
+			wantCode: output.CodeFileNotFound,
+			wantErr:  "file not found: missing.yaml",
+		},
+		{
+			name:     "ParseError",
+			args:     []string{"generate", "broken.yaml"},
+			wantCode: output.CodeParseError,
+			wantErr:  "failed to parse OpenAPI spec",
+		},
+		{
+			name:     "TooManyArgs",
+			args:     []string{"lint", "a.yaml", "b.yaml"},
+			wantCode: output.CodeUsage,
+			wantErr:  "accepts at most 1 arg",
+		},
+		{
+			name:     "Interactive",
+			args:     []string{"add", "-i"},
+			wantCode: output.CodeUsage,
+			wantErr:  "--output json cannot be combined with -i",
+		},
+		{
+			name:     "UnsupportedCommand",
+			args:     []string{"stats"},
+			wantCode: output.CodeUsage,
+			wantErr:  "--output json is not supported by 'duh stats'",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+			require.NoError(t, os.Chdir(t.TempDir()))
+			require.NoError(t, os.WriteFile("broken.yaml", []byte("openapi: [3.0"), 0644))
+
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append(test.args, "--output", "json"))
+			require.Equal(t, 2, exitCode)
+			assert.Empty(t, stderr.String())
+
+			var result output.Result
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+			assert.False(t, result.Success)
+			assert.Equal(t, 2, result.ExitCode)
+			require.NotNil(t, result.Error)
+			assert.Equal(t, test.wantCode, result.Error.Code)
+			assert.Contains(t, result.Error.Message, test.wantErr)
+		})
+	}
+}
+
+func TestOutputUnknownFormat(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"lint", "--output", "xml"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "Error: unknown output format 'xml': must be text or json")
+	assert.Empty(t, stdout.String())
+}
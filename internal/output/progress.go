@@ -0,0 +1,49 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Progress shows which phase of a generation is running and the file being
+// worked on, redrawing a single status line so large specs do not sit in
+// silence until the final summary. Like Result, its methods do nothing on a
+// nil Progress.
+type Progress struct {
+	w     io.Writer
+	shown bool
+}
+
+// NewProgress returns a Progress writing to w, or nil when w is not a
+// terminal since redrawing a line only makes sense where a person watches it
+func NewProgress(w io.Writer) *Progress {
+	f, ok := w.(*os.File)
+	if !ok {
+		return nil
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &Progress{w: w}
+}
+
+// Step replaces the status line with the phase, the position of the file in
+// the phase and the file name
+func (p *Progress) Step(phase string, n, total int, file string) {
+	if p == nil {
+		return
+	}
+	_, _ = fmt.Fprintf(p.w, "\r\033[K%s [%d/%d] %s", phase, n, total, file)
+	p.shown = true
+}
+
+// Done clears the status line so the summary which follows starts on a clean line
+func (p *Progress) Done() {
+	if p == nil || !p.shown {
+		return
+	}
+	_, _ = fmt.Fprint(p.w, "\r\033[K")
+	p.shown = false
+}
@@ -0,0 +1,340 @@
+package proto
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+type context struct {
+	tracker *nameTracker
+	// definitions holds enums and messages in the order they were built
+	definitions []any
+	// components maps each schema in components/schemas to the enum or message built for it
+	components     map[string]any
+	services       []*service
+	imports        map[string]bool
+	enums          map[string]*enum
+	enumsAsStrings bool
+}
+
+type message struct {
+	name          string
+	description   string
+	deprecated    bool
+	fields        []*field
+	nested        []*message
+	reserved      []int
+	reservedNames []string
+	// oneofs maps the name of each oneof group to its description
+	oneofs map[string]string
+}
+
+type field struct {
+	name        string
+	typ         string
+	jsonName    string
+	description string
+	number      int
+	repeated    bool
+	deprecated  bool
+	// oneof names the group the field belongs to, if any
+	oneof string
+}
+
+type enum struct {
+	name          string
+	description   string
+	values        []enumValue
+	reserved      []int
+	reservedNames []string
+	// source holds the original OpenAPI values used to detect duplicates
+	source []string
+}
+
+type enumValue struct {
+	name   string
+	number int
+}
+
+func newContext(opts Options) *context {
+	return &context{
+		enumsAsStrings: opts.EnumsAsStrings,
+		tracker:        newNameTracker(),
+		imports:        make(map[string]bool),
+		components:     make(map[string]any),
+		enums:          make(map[string]*enum),
+	}
+}
+
+func (c *context) buildSchema(name string, proxy *base.SchemaProxy) error {
+	schema, err := resolve(proxy)
+	if err != nil {
+		return fmt.Errorf("schema '%s': %w", name, err)
+	}
+
+	if err := validateComposition(schema); err != nil {
+		return fmt.Errorf("schema '%s': %w", name, err)
+	}
+
+	if isEnum(schema) {
+		if !c.enumsAsStrings {
+			c.components[name] = c.buildEnum(name, schema)
+		}
+		return nil
+	}
+
+	if !hasType(schema, "object") && !isComposed(schema) {
+		return fmt.Errorf("schema '%s': only objects and enums supported at top level", name)
+	}
+
+	msg := &message{
+		name:        c.tracker.uniqueName(toPascalCase(name)),
+		description: schema.Description,
+		deprecated:  isDeprecated(schema),
+	}
+
+	if err := c.buildFields(msg, schema, toSnakeCase(msg.name)); err != nil {
+		return fmt.Errorf("schema '%s': %w", name, err)
+	}
+
+	c.components[name] = msg
+	c.definitions = append(c.definitions, msg)
+	return nil
+}
+
+// buildFields adds a field to msg for each property, numbered in YAML order.
+// Properties of allOf sub-schemas are merged in and a oneOf on the schema
+// itself becomes a oneof group called group.
+func (c *context) buildFields(msg *message, schema *base.Schema, group string) error {
+	props, err := collectProperties(schema)
+	if err != nil {
+		return err
+	}
+
+	names := newNameTracker()
+	for _, prop := range props {
+		propName, propProxy := prop.name, prop.proxy
+		propSchema := propProxy.Schema()
+		if propSchema == nil {
+			return fmt.Errorf("property '%s' has nil schema", propName)
+		}
+
+		sanitized, err := sanitizeFieldName(propName)
+		if err != nil {
+			return fmt.Errorf("property '%s' %w", propName, err)
+		}
+
+		if !propProxy.IsReference() && isOneOf(propSchema) {
+			if err := c.buildOneOf(msg, names, sanitized, propSchema); err != nil {
+				return fmt.Errorf("property '%s' %w", propName, err)
+			}
+			continue
+		}
+
+		typ, repeated, err := c.protoType(propSchema, propName, propProxy, msg)
+		if err != nil {
+			if strings.Contains(err.Error(), fmt.Sprintf("property '%s'", propName)) {
+				return err
+			}
+			return fmt.Errorf("property '%s' %w", propName, err)
+		}
+
+		// Objects and enums carry their own description on the type they define
+		description := propSchema.Description
+		if c.isEnumType(propSchema) || (isMessage(propSchema) && singleRef(propSchema) == "") || (propProxy.IsReference() && hasType(propSchema, "object")) {
+			description = ""
+		}
+		if c.enumsAsStrings {
+			description = withAllowedValues(description, propSchema)
+		}
+
+		msg.fields = append(msg.fields, &field{
+			name:        names.uniqueName(sanitized),
+			typ:         typ,
+			jsonName:    propName,
+			description: description,
+			number:      len(msg.fields) + 1,
+			repeated:    repeated,
+			deprecated:  isDeprecated(propSchema),
+		})
+	}
+
+	if len(schema.OneOf) > 0 {
+		return c.buildOneOf(msg, names, group, schema)
+	}
+	return nil
+}
+
+// buildEnum creates an enum with an UNSPECIFIED zero value followed by the
+// schema values numbered in declaration order. An enum with the same name and
+// values as one already built is reused rather than defined twice.
+func (c *context) buildEnum(name string, schema *base.Schema) *enum {
+	key := toPascalCase(name)
+	values := enumValues(schema)
+	if existing, ok := c.enums[key]; ok && slices.Equal(existing.source, values) {
+		return existing
+	}
+
+	enumName := c.tracker.uniqueName(key)
+	e := &enum{
+		name:        enumName,
+		description: schema.Description,
+		source:      values,
+		values: []enumValue{{
+			name:   strings.ToUpper(toSnakeCase(enumName)) + "_UNSPECIFIED",
+			number: 0,
+		}},
+	}
+
+	names := newNameTracker()
+	names.uniqueName(e.values[0].name)
+	for i, v := range values {
+		e.values = append(e.values, enumValue{
+			name:   names.uniqueName(toEnumValueName(enumName, v)),
+			number: i + 1,
+		})
+	}
+
+	if _, ok := c.enums[key]; !ok {
+		c.enums[key] = e
+	}
+	c.definitions = append(c.definitions, e)
+	return e
+}
+
+// isEnumType reports whether the schema is emitted as a proto enum
+func (c *context) isEnumType(schema *base.Schema) bool {
+	return isEnum(schema) && !c.enumsAsStrings
+}
+
+func enumValues(schema *base.Schema) []string {
+	values := make([]string, 0, len(schema.Enum))
+	for _, value := range schema.Enum {
+		if value != nil {
+			values = append(values, value.Value)
+			continue
+		}
+		values = append(values, "")
+	}
+	return values
+}
+
+// withAllowedValues documents the enumeration of a property kept as a string
+func withAllowedValues(description string, schema *base.Schema) string {
+	if hasType(schema, "array") && schema.Items != nil && schema.Items.A != nil {
+		if items := schema.Items.A.Schema(); items != nil {
+			schema = items
+		}
+	}
+
+	if !isEnum(schema) {
+		return description
+	}
+
+	allowed := "Allowed values: " + strings.Join(enumValues(schema), ", ")
+	if description == "" {
+		return allowed
+	}
+	return description + "\n" + allowed
+}
+
+// buildNestedMessage creates a message named after the property inside parent for an inline object
+func (c *context) buildNestedMessage(name string, schema *base.Schema, parent *message) (*message, error) {
+	msg := &message{
+		name:        c.tracker.uniqueName(toPascalCase(name)),
+		description: schema.Description,
+		deprecated:  isDeprecated(schema),
+	}
+
+	if err := c.buildFields(msg, schema, toSnakeCase(msg.name)); err != nil {
+		return nil, err
+	}
+
+	if parent != nil {
+		parent.nested = append(parent.nested, msg)
+	}
+	return msg, nil
+}
+
+func resolve(proxy *base.SchemaProxy) (*base.Schema, error) {
+	schema := proxy.Schema()
+	if schema == nil {
+		if err := proxy.GetBuildError(); err != nil {
+			return nil, fmt.Errorf("failed to resolve schema: %w", err)
+		}
+		return nil, fmt.Errorf("schema is nil")
+	}
+	return schema, nil
+}
+
+func validateComposition(schema *base.Schema) error {
+	switch {
+	case len(schema.AnyOf) > 0:
+		return fmt.Errorf("uses 'anyOf' which is not supported; use oneOf instead")
+	case schema.Not != nil:
+		return fmt.Errorf("uses 'not' which is not supported")
+	case len(schema.AllOf) > 0 && len(schema.OneOf) > 0:
+		return fmt.Errorf("combines 'allOf' and 'oneOf' which is not supported")
+	}
+	return nil
+}
+
+func isDeprecated(schema *base.Schema) bool {
+	return schema.Deprecated != nil && *schema.Deprecated
+}
+
+func isEnum(schema *base.Schema) bool {
+	return len(schema.Enum) > 0
+}
+
+// isMessage reports whether an inline schema becomes its own message
+func isMessage(schema *base.Schema) bool {
+	return isComposed(schema) || (hasType(schema, "object") && !isFreeForm(schema) && !isMap(schema))
+}
+
+// isMap reports whether an object schema only declares typed additionalProperties,
+// e.g. 'additionalProperties: {type: string}', which maps onto a proto map.
+func isMap(schema *base.Schema) bool {
+	if !hasType(schema, "object") || isComposed(schema) || (schema.Properties != nil && schema.Properties.Len() > 0) {
+		return false
+	}
+
+	additional := schema.AdditionalProperties
+	if additional == nil || !additional.IsA() {
+		return false
+	}
+	s := additional.A.Schema()
+	return s != nil && (len(s.Type) > 0 || additional.A.IsReference())
+}
+
+// isFreeForm reports whether an object schema accepts arbitrary keys and
+// declares no properties of its own, e.g. 'type: object' on its own or with
+// 'additionalProperties: true'.
+func isFreeForm(schema *base.Schema) bool {
+	if !hasType(schema, "object") || isComposed(schema) || (schema.Properties != nil && schema.Properties.Len() > 0) {
+		return false
+	}
+
+	additional := schema.AdditionalProperties
+	switch {
+	case additional == nil:
+		return true
+	case additional.IsB():
+		return additional.B
+	default:
+		s := additional.A.Schema()
+		return s == nil || (len(s.Type) == 0 && !additional.A.IsReference())
+	}
+}
+
+func hasType(schema *base.Schema, typ string) bool {
+	for _, t := range schema.Type {
+		if strings.EqualFold(t, typ) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+type property struct {
+	name  string
+	proxy *base.SchemaProxy
+}
+
+// isComposed reports whether the schema is built from allOf or oneOf sub-schemas
+func isComposed(schema *base.Schema) bool {
+	return len(schema.AllOf) > 0 || len(schema.OneOf) > 0
+}
+
+func isOneOf(schema *base.Schema) bool {
+	return len(schema.OneOf) > 0 && len(schema.AllOf) == 0 &&
+		(schema.Properties == nil || schema.Properties.Len() == 0)
+}
+
+// singleRef returns the reference of an 'allOf: [$ref]' wrapper, which is
+// commonly used to attach a description to a referenced schema
+func singleRef(schema *base.Schema) string {
+	if len(schema.AllOf) != 1 || len(schema.OneOf) > 0 || (schema.Properties != nil && schema.Properties.Len() > 0) {
+		return ""
+	}
+	if !schema.AllOf[0].IsReference() {
+		return ""
+	}
+	return schema.AllOf[0].GetReference()
+}
+
+// collectProperties returns the properties of the schema in YAML order with
+// the properties of allOf sub-schemas merged in first. A property defined by
+// more than one sub-schema keeps its first position and its last definition.
+func collectProperties(schema *base.Schema) ([]property, error) {
+	var props []property
+	add := func(name string, proxy *base.SchemaProxy) {
+		for i := range props {
+			if props[i].name == name {
+				props[i].proxy = proxy
+				return
+			}
+		}
+		props = append(props, property{name: name, proxy: proxy})
+	}
+
+	for i, proxy := range schema.AllOf {
+		sub, err := resolve(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("allOf[%d]: %w", i, err)
+		}
+
+		if err := validateComposition(sub); err != nil {
+			return nil, fmt.Errorf("allOf[%d] %w", i, err)
+		}
+
+		switch {
+		case len(sub.OneOf) > 0:
+			return nil, fmt.Errorf("allOf[%d] uses 'oneOf' which cannot be merged", i)
+		case !hasType(sub, "object") && len(sub.AllOf) == 0 && (sub.Properties == nil || sub.Properties.Len() == 0):
+			return nil, fmt.Errorf("allOf[%d] is not an object; allOf can only merge object schemas", i)
+		}
+
+		subProps, err := collectProperties(sub)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range subProps {
+			add(p.name, p.proxy)
+		}
+	}
+
+	if schema.Properties != nil {
+		for name, proxy := range schema.Properties.FromOldest() {
+			add(name, proxy)
+		}
+	}
+	return props, nil
+}
+
+// buildOneOf adds a oneof group to msg with a field for each variant of the schema
+func (c *context) buildOneOf(msg *message, names *nameTracker, group string, schema *base.Schema) error {
+	if msg.oneofs == nil {
+		msg.oneofs = make(map[string]string)
+	}
+	group = names.uniqueName(group)
+	msg.oneofs[group] = schema.Description
+
+	for i, proxy := range schema.OneOf {
+		variant, err := resolve(proxy)
+		if err != nil {
+			return fmt.Errorf("oneOf[%d]: %w", i, err)
+		}
+
+		if err := validateComposition(variant); err != nil {
+			return fmt.Errorf("oneOf[%d] %w", i, err)
+		}
+
+		var typ, name, description string
+		switch {
+		case proxy.IsReference() && isEnum(variant) && c.enumsAsStrings:
+			if typ, err = c.enumScalarType(variant); err != nil {
+				return fmt.Errorf("oneOf[%d]: %w", i, err)
+			}
+			ref, _ := referenceName(proxy.GetReference())
+			name = toSnakeCase(ref)
+		case proxy.IsReference() || singleRef(variant) != "":
+			ref := proxy.GetReference()
+			if !proxy.IsReference() {
+				ref = singleRef(variant)
+			}
+			if typ, err = referenceName(ref); err != nil {
+				return fmt.Errorf("oneOf[%d]: %w", i, err)
+			}
+			name = toSnakeCase(typ)
+		case hasType(variant, "array"):
+			return fmt.Errorf("oneOf[%d] is an array; oneof fields cannot be repeated", i)
+		case isOneOf(variant):
+			return fmt.Errorf("oneOf[%d] uses a nested 'oneOf' which is not supported", i)
+		case isMap(variant):
+			return fmt.Errorf("oneOf[%d] is a map; oneof fields cannot be maps", i)
+		case isFreeForm(variant):
+			typ, name, description = c.use(structType), "struct_value", variant.Description
+		case hasType(variant, "object") || isComposed(variant):
+			title := variant.Title
+			if title == "" {
+				title = fmt.Sprintf("%s_option_%d", group, i+1)
+			}
+
+			nested, err := c.buildNestedMessage(title, variant, msg)
+			if err != nil {
+				return err
+			}
+			typ, name = nested.name, toSnakeCase(nested.name)
+		case isEnum(variant) && !c.enumsAsStrings:
+			typ = c.buildEnum(group+"_"+variant.Title, variant).name
+			name = toSnakeCase(typ)
+		case len(variant.Type) == 0:
+			return fmt.Errorf("oneOf[%d] must have a type or $ref", i)
+		default:
+			if typ, err = c.scalarType(variant.Type[0], variant.Format); err != nil {
+				return fmt.Errorf("oneOf[%d]: %w", i, err)
+			}
+			name = strings.ToLower(typ[strings.LastIndex(typ, ".")+1:]) + "_value"
+			description = variant.Description
+		}
+
+		name = names.uniqueName(name)
+		msg.fields = append(msg.fields, &field{
+			name:        name,
+			typ:         typ,
+			jsonName:    name,
+			description: description,
+			number:      len(msg.fields) + 1,
+			oneof:       group,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package proto
+
+import (
+	"fmt"
+
+	"github.com/pb33f/libopenapi"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// Options configures the conversion from OpenAPI to Protocol Buffers
+type Options struct {
+	// PackageName is the proto package (e.g. "duh.api.v1")
+	PackageName string
+	// PackagePath is the go_package of the generated file (e.g. "github.com/myorg/api/proto/v1")
+	PackagePath string
+	// EnumsAsStrings keeps enum properties as strings instead of generating proto enums
+	EnumsAsStrings bool
+	// Lock, when set, supplies previously assigned field numbers and is updated with the new ones
+	Lock *Lock
+	// Services adds a service per subject with an rpc for each operation
+	Services bool
+	// Dir is the directory of the generated files relative to the proto root (e.g. "proto/v1").
+	// ConvertBySubject uses it for the paths of the files and their imports.
+	Dir string
+	// Document, when set, is the openapi input already parsed, which saves
+	// parsing large specs a second time
+	Document *v3.Document
+	// SkipOperation, when set, leaves the operations it returns true for out
+	// of the services
+	SkipOperation func(path string, op *v3.Operation) bool
+}
+
+// Convert generates a proto3 file from the schemas in components/schemas.
+// Schemas are emitted in the order they appear in the document and every
+// field carries a json_name annotation matching the original property name.
+func Convert(openapi []byte, opts Options) ([]byte, error) {
+	_, ctx, err := build(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+	definitions := ctx.definitions
+	for _, svc := range ctx.services {
+		definitions = append(definitions, svc)
+	}
+	return generate(opts.PackageName, opts.PackagePath, ctx.importList(), definitions), nil
+}
+
+func build(openapi []byte, opts Options) (*v3.Document, *context, error) {
+	if len(openapi) == 0 {
+		return nil, nil, fmt.Errorf("openapi input cannot be empty")
+	}
+
+	if opts.PackageName == "" {
+		return nil, nil, fmt.Errorf("package name cannot be empty")
+	}
+
+	if opts.PackagePath == "" {
+		return nil, nil, fmt.Errorf("package path cannot be empty")
+	}
+
+	model, err := parse(openapi, opts.Document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := newContext(opts)
+	if components := model.Components; components != nil && components.Schemas != nil {
+		for name, proxy := range components.Schemas.FromOldest() {
+			if err := ctx.buildSchema(name, proxy); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if opts.Services {
+		ctx.buildServices(model, opts.SkipOperation)
+	}
+
+	if opts.Lock != nil {
+		opts.Lock.apply(ctx)
+	}
+
+	return model, ctx, nil
+}
+
+// parse returns doc when the caller already parsed the openapi input
+func parse(openapi []byte, doc *v3.Document) (*v3.Document, error) {
+	if doc != nil {
+		return doc, nil
+	}
+
+	document, err := libopenapi.NewDocument(openapi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	model, err := document.BuildV3Model()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI model: %w", err)
+	}
+
+	if model == nil {
+		return nil, fmt.Errorf("only OpenAPI 3.x is supported")
+	}
+	return &model.Model, nil
+}
@@ -0,0 +1,149 @@
+package proto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func generate(packageName, packagePath string, imports []string, definitions []any) []byte {
+	var b strings.Builder
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n", packageName)
+
+	if len(imports) > 0 {
+		b.WriteString("\n")
+		for _, file := range imports {
+			fmt.Fprintf(&b, "import \"%s\";\n", file)
+		}
+	}
+
+	fmt.Fprintf(&b, "\noption go_package = \"%s\";\n", packagePath)
+
+	for _, def := range definitions {
+		switch d := def.(type) {
+		case *enum:
+			renderEnum(&b, d)
+		case *message:
+			renderMessage(&b, d, "")
+		case *service:
+			renderService(&b, d)
+		}
+	}
+	b.WriteString("\n")
+
+	return []byte(b.String())
+}
+
+func renderEnum(b *strings.Builder, e *enum) {
+	b.WriteString("\n")
+	writeComment(b, e.description, "")
+
+	fmt.Fprintf(b, "enum %s {\n", e.name)
+	writeReserved(b, e.reserved, e.reservedNames, "  ")
+	for _, v := range e.values {
+		fmt.Fprintf(b, "  %s = %d;\n", v.name, v.number)
+	}
+	b.WriteString("}\n")
+}
+
+func renderMessage(b *strings.Builder, msg *message, indent string) {
+	if indent == "" {
+		b.WriteString("\n")
+	}
+	writeComment(b, msg.description, indent)
+
+	fmt.Fprintf(b, "%smessage %s {\n", indent, msg.name)
+	if msg.deprecated {
+		fmt.Fprintf(b, "%s  option deprecated = true;\n", indent)
+	}
+	writeReserved(b, msg.reserved, msg.reservedNames, indent+"  ")
+
+	for _, nested := range msg.nested {
+		renderMessage(b, nested, indent+"  ")
+		b.WriteString("\n")
+	}
+
+	for i, f := range msg.fields {
+		if f.oneof == "" {
+			renderField(b, f, indent+"  ")
+			continue
+		}
+
+		// Fields of a oneof group are always consecutive
+		if i == 0 || msg.fields[i-1].oneof != f.oneof {
+			writeComment(b, msg.oneofs[f.oneof], indent+"  ")
+			fmt.Fprintf(b, "%s  oneof %s {\n", indent, f.oneof)
+		}
+		renderField(b, f, indent+"    ")
+		if i == len(msg.fields)-1 || msg.fields[i+1].oneof != f.oneof {
+			fmt.Fprintf(b, "%s  }\n", indent)
+		}
+	}
+
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func renderField(b *strings.Builder, f *field, indent string) {
+	writeComment(b, f.description, indent)
+
+	b.WriteString(indent)
+	if f.repeated {
+		b.WriteString("repeated ")
+	}
+	fmt.Fprintf(b, "%s %s = %d", f.typ, f.name, f.number)
+
+	var options []string
+	if f.jsonName != "" {
+		options = append(options, fmt.Sprintf("json_name = \"%s\"", f.jsonName))
+	}
+	if f.deprecated {
+		options = append(options, "deprecated = true")
+	}
+	if len(options) > 0 {
+		fmt.Fprintf(b, " [%s]", strings.Join(options, ", "))
+	}
+	b.WriteString(";\n")
+}
+
+func renderService(b *strings.Builder, svc *service) {
+	fmt.Fprintf(b, "\nservice %s {\n", svc.name)
+	for _, r := range svc.rpcs {
+		writeComment(b, r.description, "  ")
+		fmt.Fprintf(b, "  rpc %s(%s) returns (%s)", r.name, r.request, r.response)
+		if r.deprecated {
+			b.WriteString(" {\n    option deprecated = true;\n  }\n")
+			continue
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+}
+
+func writeReserved(b *strings.Builder, numbers []int, names []string, indent string) {
+	if len(numbers) > 0 {
+		list := make([]string, len(numbers))
+		for i, n := range numbers {
+			list[i] = strconv.Itoa(n)
+		}
+		fmt.Fprintf(b, "%sreserved %s;\n", indent, strings.Join(list, ", "))
+	}
+
+	if len(names) > 0 {
+		fmt.Fprintf(b, "%sreserved \"%s\";\n", indent, strings.Join(names, "\", \""))
+	}
+}
+
+func writeComment(b *strings.Builder, description, indent string) {
+	if strings.TrimSpace(description) == "" {
+		return
+	}
+
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			b.WriteString(indent + "//\n")
+			continue
+		}
+		b.WriteString(indent + "// " + line + "\n")
+	}
+}
@@ -0,0 +1,175 @@
+package proto
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+const lockHeader = "# Generated by duh. Commit this file so regenerating keeps proto field numbers stable.\n"
+
+// Protobuf reserves this range of field numbers for its own implementation
+const (
+	firstReservedNumber = 19000
+	lastReservedNumber  = 19999
+)
+
+// Lock records the numbers assigned to message fields and enum values so
+// regenerating the proto file keeps the wire format stable. Numbers of
+// removed fields and values are reserved and never handed out again.
+type Lock struct {
+	Messages map[string]*LockEntry `yaml:"messages,omitempty"`
+	Enums    map[string]*LockEntry `yaml:"enums,omitempty"`
+}
+
+// LockEntry holds the numbers of a single message or enum
+type LockEntry struct {
+	Numbers       map[string]int `yaml:"numbers"`
+	Reserved      []int          `yaml:"reserved,omitempty"`
+	ReservedNames []string       `yaml:"reserved_names,omitempty"`
+}
+
+// LoadLock reads a lock file, returning an empty lock if it does not exist yet
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Lock{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+// Save writes the lock file, creating parent directories as needed
+func (l *Lock) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString(lockHeader)
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(l); err != nil {
+		return fmt.Errorf("failed to marshal lock file: %w", err)
+	}
+	_ = enc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// apply renumbers every message and enum in ctx from the lock and records
+// the numbers handed out. Entries for definitions that no longer exist are dropped.
+func (l *Lock) apply(ctx *context) {
+	messages := make(map[string]*LockEntry)
+	enums := make(map[string]*LockEntry)
+
+	var visit func(msg *message)
+	visit = func(msg *message) {
+		entry := l.Messages[msg.name]
+		names := make([]string, len(msg.fields))
+		for i, f := range msg.fields {
+			names[i] = f.name
+		}
+
+		entry, numbers := assign(entry, names, 1)
+		for i, f := range msg.fields {
+			f.number = numbers[i]
+		}
+		msg.reserved, msg.reservedNames = entry.Reserved, entry.ReservedNames
+		messages[msg.name] = entry
+
+		for _, nested := range msg.nested {
+			visit(nested)
+		}
+	}
+
+	for _, def := range ctx.definitions {
+		switch d := def.(type) {
+		case *message:
+			visit(d)
+		case *enum:
+			names := make([]string, len(d.values))
+			for i, v := range d.values {
+				names[i] = v.name
+			}
+
+			// The UNSPECIFIED value always keeps number 0
+			entry, numbers := assign(l.Enums[d.name], names[1:], 1)
+			for i := range d.values[1:] {
+				d.values[i+1].number = numbers[i]
+			}
+			d.reserved, d.reservedNames = entry.Reserved, entry.ReservedNames
+			enums[d.name] = entry
+		}
+	}
+
+	l.Messages, l.Enums = messages, enums
+}
+
+// assign returns the number for each name, reusing locked numbers and handing
+// out numbers above any ever used to new names. Locked names that are gone
+// are moved to the reserved lists.
+func assign(prev *LockEntry, names []string, first int) (*LockEntry, []int) {
+	if prev == nil {
+		prev = &LockEntry{}
+	}
+
+	next := first
+	for _, n := range prev.Numbers {
+		next = max(next, n+1)
+	}
+	for _, n := range prev.Reserved {
+		next = max(next, n+1)
+	}
+
+	entry := &LockEntry{
+		Numbers:       make(map[string]int, len(names)),
+		Reserved:      slices.Clone(prev.Reserved),
+		ReservedNames: slices.Clone(prev.ReservedNames),
+	}
+
+	numbers := make([]int, len(names))
+	for i, name := range names {
+		n, ok := prev.Numbers[name]
+		if !ok {
+			if next >= firstReservedNumber && next <= lastReservedNumber {
+				next = lastReservedNumber + 1
+			}
+			n = next
+			next++
+		}
+
+		// A name that comes back gets a new number, so it can no longer be reserved
+		entry.ReservedNames = slices.DeleteFunc(entry.ReservedNames, func(r string) bool { return r == name })
+		entry.Numbers[name] = n
+		numbers[i] = n
+	}
+
+	for name, n := range prev.Numbers {
+		if _, ok := entry.Numbers[name]; ok {
+			continue
+		}
+		entry.Reserved = append(entry.Reserved, n)
+		entry.ReservedNames = append(entry.ReservedNames, name)
+	}
+	slices.Sort(entry.Reserved)
+	entry.Reserved = slices.Compact(entry.Reserved)
+	slices.Sort(entry.ReservedNames)
+	entry.ReservedNames = slices.Compact(entry.ReservedNames)
+
+	return entry, numbers
+}
@@ -0,0 +1,268 @@
+package proto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+)
+
+const (
+	timestampType = "google.protobuf.Timestamp"
+	durationType  = "google.protobuf.Duration"
+	structType    = "google.protobuf.Struct"
+)
+
+// wellKnownImports maps the well-known types to the file that defines them
+var wellKnownImports = map[string]string{
+	timestampType: "google/protobuf/timestamp.proto",
+	durationType:  "google/protobuf/duration.proto",
+	structType:    "google/protobuf/struct.proto",
+}
+
+// protoType returns the proto3 type for a property and whether it is repeated.
+// Inline enums are hoisted to the top level while inline objects become
+// messages nested inside parent.
+func (c *context) protoType(schema *base.Schema, propName string, proxy *base.SchemaProxy, parent *message) (string, bool, error) {
+	if err := validateComposition(schema); err != nil {
+		return "", false, fmt.Errorf("property '%s' %w", propName, err)
+	}
+
+	if proxy.IsReference() {
+		if proxy.Schema() == nil {
+			if err := proxy.GetBuildError(); err != nil {
+				return "", false, fmt.Errorf("property '%s' references external file or unresolvable reference: %w", propName, err)
+			}
+			return "", false, fmt.Errorf("property '%s' has unresolved reference", propName)
+		}
+
+		if isEnum(proxy.Schema()) && c.enumsAsStrings {
+			typ, err := c.enumScalarType(proxy.Schema())
+			return typ, false, err
+		}
+
+		name, err := referenceName(proxy.GetReference())
+		if err != nil {
+			return "", false, fmt.Errorf("property '%s': %w", propName, err)
+		}
+		return name, false, nil
+	}
+
+	if hasType(schema, "array") {
+		typ, err := c.arrayItemType(schema, propName, parent)
+		return typ, true, err
+	}
+
+	if isFreeForm(schema) {
+		return c.use(structType), false, nil
+	}
+
+	if isMap(schema) {
+		typ, err := c.mapType(schema, propName, parent)
+		return typ, false, err
+	}
+
+	if ref := singleRef(schema); ref != "" {
+		name, err := referenceName(ref)
+		return name, false, err
+	}
+
+	if hasType(schema, "object") || isComposed(schema) {
+		msg, err := c.buildNestedMessage(propName, schema, parent)
+		if err != nil {
+			return "", false, err
+		}
+		return msg.name, false, nil
+	}
+
+	if isEnum(schema) {
+		if c.enumsAsStrings {
+			typ, err := c.enumScalarType(schema)
+			return typ, false, err
+		}
+		return c.buildEnum(propName, schema).name, false, nil
+	}
+
+	if len(schema.Type) == 0 {
+		return "", false, fmt.Errorf("property must have type or $ref")
+	}
+
+	if len(schema.Type) > 1 {
+		return "", false, fmt.Errorf("multi-type properties not supported")
+	}
+
+	typ, err := c.scalarType(schema.Type[0], schema.Format)
+	return typ, false, err
+}
+
+// scalarType maps an OpenAPI type and format to a proto3 scalar or well-known type
+func (c *context) scalarType(typ, format string) (string, error) {
+	switch typ {
+	case "integer":
+		if format == "int64" {
+			return "int64", nil
+		}
+		return "int32", nil
+	case "number":
+		if format == "float" {
+			return "float", nil
+		}
+		return "double", nil
+	case "string":
+		switch format {
+		case "date", "date-time":
+			return c.use(timestampType), nil
+		case "duration":
+			return c.use(durationType), nil
+		case "byte", "binary":
+			return "bytes", nil
+		}
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	}
+	return "", fmt.Errorf("unsupported type: %s", typ)
+}
+
+// enumScalarType returns the underlying scalar type of an enum kept as a plain value
+func (c *context) enumScalarType(schema *base.Schema) (string, error) {
+	if len(schema.Type) == 0 {
+		return "string", nil
+	}
+	return c.scalarType(schema.Type[0], schema.Format)
+}
+
+// arrayItemType returns the proto3 type of the array items. Inline item
+// types are named after the property so it must be singular.
+func (c *context) arrayItemType(schema *base.Schema, propName string, parent *message) (string, error) {
+	if schema.Items == nil || schema.Items.A == nil {
+		return "", fmt.Errorf("array must have items defined")
+	}
+
+	proxy := schema.Items.A
+	items := proxy.Schema()
+	if items == nil {
+		if err := proxy.GetBuildError(); err != nil {
+			return "", fmt.Errorf("failed to resolve array items: %w", err)
+		}
+		return "", fmt.Errorf("array items schema is nil")
+	}
+
+	if hasType(items, "array") {
+		return "", fmt.Errorf("nested arrays not supported")
+	}
+
+	if !proxy.IsReference() && isOneOf(items) {
+		return "", fmt.Errorf("array items cannot use 'oneOf'; use $ref to a schema with the oneOf as a property")
+	}
+
+	if isEnum(items) && c.enumsAsStrings {
+		return c.enumScalarType(items)
+	}
+
+	if proxy.IsReference() {
+		return referenceName(proxy.GetReference())
+	}
+
+	if isFreeForm(items) {
+		return c.use(structType), nil
+	}
+
+	if isMap(items) {
+		return "", fmt.Errorf("arrays of maps are not supported; use $ref to a schema with the map as a property")
+	}
+
+	// Inline item types are named after the singular form of the property, e.g. line_items → LineItem
+	if isEnum(items) {
+		return c.buildEnum(singular(propName), items).name, nil
+	}
+
+	if ref := singleRef(items); ref != "" {
+		return referenceName(ref)
+	}
+
+	if hasType(items, "object") || isComposed(items) {
+		msg, err := c.buildNestedMessage(singular(propName), items, parent)
+		if err != nil {
+			return "", err
+		}
+		return msg.name, nil
+	}
+
+	if len(items.Type) == 0 {
+		return "", fmt.Errorf("array items must have a type")
+	}
+	return c.scalarType(items.Type[0], items.Format)
+}
+
+// mapType returns a map<string, T> type for typed additionalProperties. Inline
+// value types are named after the property with a 'Value' suffix.
+func (c *context) mapType(schema *base.Schema, propName string, parent *message) (string, error) {
+	proxy := schema.AdditionalProperties.A
+	value := proxy.Schema()
+
+	var typ string
+	var err error
+	switch {
+	case hasType(value, "array"):
+		return "", fmt.Errorf("map values cannot be arrays")
+	case !proxy.IsReference() && isOneOf(value):
+		return "", fmt.Errorf("map values cannot use 'oneOf'; use $ref to a schema with the oneOf as a property")
+	case isEnum(value) && c.enumsAsStrings:
+		typ, err = c.enumScalarType(value)
+	case proxy.IsReference():
+		typ, err = referenceName(proxy.GetReference())
+	case isFreeForm(value):
+		typ = c.use(structType)
+	case isMap(value):
+		return "", fmt.Errorf("map values cannot be maps; use $ref to a schema with the map as a property")
+	case isEnum(value):
+		typ = c.buildEnum(propName+"_value", value).name
+	case singleRef(value) != "":
+		typ, err = referenceName(singleRef(value))
+	case hasType(value, "object") || isComposed(value):
+		var msg *message
+		if msg, err = c.buildNestedMessage(propName+"_value", value, parent); err == nil {
+			typ = msg.name
+		}
+	default:
+		typ, err = c.scalarType(value.Type[0], value.Format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("map<string, %s>", typ), nil
+}
+
+// use records the import needed by a well-known type and returns the type
+func (c *context) use(typ string) string {
+	if file, ok := wellKnownImports[typ]; ok {
+		c.imports[file] = true
+	}
+	return typ
+}
+
+// importList returns the files imported by the well-known types in use, sorted
+func (c *context) importList() []string {
+	imports := make([]string, 0, len(c.imports))
+	for file := range c.imports {
+		imports = append(imports, file)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// referenceName extracts the schema name from a reference such as '#/components/schemas/Address'
+func referenceName(ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("reference string is empty")
+	}
+
+	parts := strings.Split(ref, "/")
+	name := parts[len(parts)-1]
+	if name == "" {
+		return "", fmt.Errorf("reference has empty name segment: %s", ref)
+	}
+	return name, nil
+}
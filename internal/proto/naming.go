@@ -0,0 +1,141 @@
+package proto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// toSnakeCase prefixes each upper case letter with an underscore, e.g. userId → user_id
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toPascalCase converts snake_case, camelCase and ALLCAPS names to PascalCase,
+// e.g. user_id → UserId, shippingAddress → ShippingAddress, USER → User
+func toPascalCase(s string) string {
+	allCaps, underscore := true, false
+	for _, r := range s {
+		if r == '_' {
+			underscore = true
+			continue
+		}
+		if unicode.IsLower(r) {
+			allCaps = false
+			break
+		}
+	}
+
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		switch {
+		case r == '_':
+			upper = true
+			continue
+		case upper:
+			r = unicode.ToUpper(r)
+			upper = false
+		case allCaps && !underscore:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var invalidEnumValueRegex = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// toEnumValueName prefixes the value with the enum name, e.g. (SortBy, createdAt) → SORT_BY_CREATED_AT.
+// Characters that are not valid in a proto identifier are replaced with underscores.
+func toEnumValueName(enumName, value string) string {
+	prefix := strings.ToUpper(toSnakeCase(enumName))
+	name := invalidEnumValueRegex.ReplaceAllString(strings.ToUpper(toSnakeCase(value)), "_")
+	if name = strings.Trim(name, "_"); name == "" {
+		name = "EMPTY"
+	}
+	return prefix + "_" + name
+}
+
+// singular derives a singular name from a plural property name, e.g.
+// addresses → address, categories → category, line_items → line_item
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "ches"),
+		strings.HasSuffix(name, "shes"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "uses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// sanitizeFieldName replaces characters that are invalid in proto3 field
+// names with underscores while preserving the rest of the name
+func sanitizeFieldName(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("field name cannot be empty")
+	}
+
+	switch first := name[0]; {
+	case first == '_':
+		return "", fmt.Errorf("field name cannot start with underscore, got '%s'", name)
+	case (first < 'a' || first > 'z') && (first < 'A' || first > 'Z'):
+		return "", fmt.Errorf("field name must start with a letter, got '%s'", name)
+	}
+
+	var b strings.Builder
+	var last rune
+	for _, r := range name {
+		if isFieldRune(r) {
+			b.WriteRune(r)
+			last = r
+		} else if last != '_' {
+			b.WriteRune('_')
+			last = '_'
+		}
+	}
+
+	sanitized := b.String()
+	if !isFieldRune(rune(name[len(name)-1])) {
+		sanitized = strings.TrimSuffix(sanitized, "_")
+	}
+	return sanitized, nil
+}
+
+func isFieldRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_'
+}
+
+// nameTracker hands out unique names by appending _2, _3, ... on conflict
+type nameTracker struct {
+	used map[string]int
+}
+
+func newNameTracker() *nameTracker {
+	return &nameTracker{used: make(map[string]int)}
+}
+
+func (t *nameTracker) uniqueName(name string) string {
+	count, exists := t.used[name]
+	t.used[name] = count + 1
+	if !exists {
+		return name
+	}
+	return fmt.Sprintf("%s_%d", name, count+1)
+}
@@ -0,0 +1,113 @@
+package proto
+
+import (
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// service groups the operations of a subject, e.g. /v1/users.create becomes
+// rpc Create on UsersService, which is the mapping 'duh import proto' reverses.
+type service struct {
+	name string
+	// file is the subject file the service is written to when splitting by subject
+	file string
+	rpcs []*rpc
+}
+
+type rpc struct {
+	name        string
+	request     string
+	response    string
+	description string
+	deprecated  bool
+}
+
+// buildServices creates a service for each subject with an rpc for every
+// operation whose request and response reference a generated message, unless
+// skip returns true for it
+func (c *context) buildServices(doc *v3.Document, skip func(path string, op *v3.Operation) bool) {
+	if doc.Paths == nil || doc.Paths.PathItems == nil {
+		return
+	}
+
+	services := make(map[string]*service)
+	for p, item := range doc.Paths.PathItems.FromOldest() {
+		op := item.Post
+		if op == nil || op.RequestBody == nil || (skip != nil && skip(p, op)) {
+			continue
+		}
+
+		serviceName, method := RPCName(p)
+		if method == "" {
+			continue
+		}
+
+		request, response := c.operationMessage(op.RequestBody.Content), ""
+		if op.Responses != nil && op.Responses.Codes != nil {
+			for code, resp := range op.Responses.Codes.FromOldest() {
+				if strings.HasPrefix(code, "2") {
+					response = c.operationMessage(resp.Content)
+					break
+				}
+			}
+		}
+		if request == "" || response == "" {
+			continue
+		}
+
+		file := subjectFile(p)
+		svc, ok := services[file]
+		if !ok {
+			svc = &service{name: serviceName, file: file}
+			services[file] = svc
+			c.services = append(c.services, svc)
+		}
+
+		description := op.Summary
+		if description == "" {
+			description = op.Description
+		}
+
+		svc.rpcs = append(svc.rpcs, &rpc{
+			name:        method,
+			request:     request,
+			response:    response,
+			description: description,
+			deprecated:  op.Deprecated != nil && *op.Deprecated,
+		})
+	}
+}
+
+// RPCName returns the service and rpc names for a DUH-RPC path, e.g.
+// /v1/users.create → UsersService, Create. The method is empty if the path
+// has no method.
+func RPCName(path string) (service, method string) {
+	i := strings.LastIndex(path, ".")
+	if i == -1 || i == len(path)-1 {
+		return "", ""
+	}
+	return toPascalCase(subjectFile(path)) + "Service", toPascalCase(strings.ReplaceAll(path[i+1:], "-", "_"))
+}
+
+// operationMessage returns the message referenced by a request or response body
+func (c *context) operationMessage(content *orderedmap.Map[string, *v3.MediaType]) string {
+	if content == nil {
+		return ""
+	}
+
+	for _, media := range content.FromOldest() {
+		if media.Schema == nil || !media.Schema.IsReference() {
+			continue
+		}
+		name, err := referenceName(media.Schema.GetReference())
+		if err != nil {
+			continue
+		}
+		if _, ok := c.components[name].(*message); ok {
+			return name
+		}
+	}
+	return ""
+}
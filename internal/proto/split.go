@@ -0,0 +1,242 @@
+package proto
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+)
+
+// commonFile holds the definitions shared by more than one subject
+const commonFile = "common"
+
+// File is a generated proto file
+type File struct {
+	// Path is the location of the file relative to the proto root, e.g. "proto/v1/users.proto"
+	Path    string
+	Content []byte
+}
+
+// ConvertBySubject generates a proto3 file for each subject of the API, e.g.
+// users.proto for '/v1/users.create', plus a common.proto holding the error
+// responses and every definition used by more than one subject. Subject files
+// import common.proto as needed; common.proto never imports a subject file.
+func ConvertBySubject(openapi []byte, opts Options) ([]File, error) {
+	doc, ctx, err := build(openapi, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	files, names := ctx.split(doc)
+
+	var result []File
+	for _, name := range names {
+		definitions := files[name]
+		if len(definitions) == 0 {
+			continue
+		}
+
+		result = append(result, File{
+			Path:    path.Join(opts.Dir, name+".proto"),
+			Content: generate(opts.PackageName, opts.PackagePath, ctx.fileImports(name, definitions, files, opts.Dir), definitions),
+		})
+	}
+	return result, nil
+}
+
+// split assigns every definition to a file. A definition reachable from the
+// operations of a single subject belongs to that subject, anything else such
+// as error responses or unused schemas belongs to the common file. It returns
+// the definitions of each file and the file names in the order of the paths.
+func (c *context) split(doc *v3.Document) (map[string][]any, []string) {
+	owners := make(map[any]map[string]bool)
+	var subjects []string
+
+	var mark func(def any, subject string)
+	mark = func(def any, subject string) {
+		if owners[def] == nil {
+			owners[def] = make(map[string]bool)
+		}
+		if owners[def][subject] {
+			return
+		}
+		owners[def][subject] = true
+		for _, dep := range c.dependencies(def) {
+			mark(dep, subject)
+		}
+	}
+
+	markContent := func(content *orderedmap.Map[string, *v3.MediaType], subject string) {
+		if content == nil {
+			return
+		}
+		for _, media := range content.FromOldest() {
+			if media.Schema == nil || !media.Schema.IsReference() {
+				continue
+			}
+			name, err := referenceName(media.Schema.GetReference())
+			if err != nil {
+				continue
+			}
+			if def, ok := c.components[name]; ok {
+				mark(def, subject)
+			}
+		}
+	}
+
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		for p, item := range doc.Paths.PathItems.FromOldest() {
+			subject := subjectFile(p)
+			if subject != commonFile && !slices.Contains(subjects, subject) {
+				subjects = append(subjects, subject)
+			}
+
+			for _, op := range item.GetOperations().FromOldest() {
+				if op.RequestBody != nil {
+					markContent(op.RequestBody.Content, subject)
+				}
+
+				if op.Responses == nil {
+					continue
+				}
+				if op.Responses.Codes != nil {
+					for code, resp := range op.Responses.Codes.FromOldest() {
+						// Error responses are shared by every subject
+						owner := commonFile
+						if strings.HasPrefix(code, "2") {
+							owner = subject
+						}
+						markContent(resp.Content, owner)
+					}
+				}
+				if op.Responses.Default != nil {
+					markContent(op.Responses.Default.Content, commonFile)
+				}
+			}
+		}
+	}
+
+	for _, def := range c.definitions {
+		if len(owners[def]) == 0 {
+			mark(def, commonFile)
+		}
+	}
+
+	files := make(map[string][]any)
+	for _, def := range c.definitions {
+		file := commonFile
+		if len(owners[def]) == 1 {
+			for subject := range owners[def] {
+				file = subject
+			}
+		}
+		files[file] = append(files[file], def)
+	}
+
+	for _, svc := range c.services {
+		files[svc.file] = append(files[svc.file], svc)
+	}
+	return files, append(subjects, commonFile)
+}
+
+// fileImports returns the well-known types and other generated files used by the definitions of file
+func (c *context) fileImports(file string, definitions []any, files map[string][]any, dir string) []string {
+	located := make(map[any]string)
+	for name, defs := range files {
+		for _, def := range defs {
+			located[def] = name
+		}
+	}
+
+	imports := make(map[string]bool)
+	for _, def := range definitions {
+		for _, typ := range referencedTypes(def) {
+			if wellKnown, ok := wellKnownImports[typ]; ok {
+				imports[wellKnown] = true
+				continue
+			}
+			if dep := c.lookup(typ); dep != nil && located[dep] != file {
+				imports[path.Join(dir, located[dep]+".proto")] = true
+			}
+		}
+	}
+
+	list := make([]string, 0, len(imports))
+	for file := range imports {
+		list = append(list, file)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// dependencies returns the top level definitions referenced by def
+func (c *context) dependencies(def any) []any {
+	var deps []any
+	for _, typ := range referencedTypes(def) {
+		if dep := c.lookup(typ); dep != nil {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// lookup finds the top level definition of a field type. References use the
+// schema name while inline enums use the name of the generated enum.
+func (c *context) lookup(typ string) any {
+	if def, ok := c.components[typ]; ok {
+		return def
+	}
+	for _, def := range c.definitions {
+		if e, ok := def.(*enum); ok && e.name == typ {
+			return e
+		}
+	}
+	return nil
+}
+
+// referencedTypes returns the types used by the fields of a message or the rpcs of a service
+func referencedTypes(def any) []string {
+	switch d := def.(type) {
+	case *message:
+		return fieldTypes(d)
+	case *service:
+		var types []string
+		for _, r := range d.rpcs {
+			types = append(types, r.request, r.response)
+		}
+		return types
+	}
+	return nil
+}
+
+// fieldTypes returns the types of the fields of msg and its nested messages,
+// with map types reduced to their value type
+func fieldTypes(msg *message) []string {
+	var types []string
+	for _, f := range msg.fields {
+		typ := f.typ
+		if strings.HasPrefix(typ, "map<string, ") {
+			typ = strings.TrimSuffix(strings.TrimPrefix(typ, "map<string, "), ">")
+		}
+		types = append(types, typ)
+	}
+	for _, nested := range msg.nested {
+		types = append(types, fieldTypes(nested)...)
+	}
+	return types
+}
+
+// subjectFile returns the file name for the subject of a DUH-RPC path, e.g. /v1/user-groups.list → user_groups
+func subjectFile(p string) string {
+	subject := p[strings.LastIndex(p, "/")+1:]
+	if i := strings.Index(subject, "."); i != -1 {
+		subject = subject[:i]
+	}
+	if subject = strings.ReplaceAll(toSnakeCase(subject), "-", "_"); subject == "" {
+		return commonFile
+	}
+	return subject
+}
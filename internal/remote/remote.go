@@ -0,0 +1,92 @@
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ChecksumPrefix starts every checksum, the only algorithm is sha256
+const ChecksumPrefix = "sha256:"
+
+var ErrChecksum = errors.New("checksum mismatch")
+
+// client fetches the specs, a registry which doesn't answer in time fails the
+// generation rather than hanging it
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// IsURL reports whether spec is an http or https URL rather than a file
+func IsURL(spec string) bool {
+	return strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://")
+}
+
+// ValidChecksum reports whether checksum is sha256: followed by the hex of a
+// sha256 sum
+func ValidChecksum(checksum string) bool {
+	sum, ok := strings.CutPrefix(checksum, ChecksumPrefix)
+	if !ok {
+		return false
+	}
+	b, err := hex.DecodeString(sum)
+	return err == nil && len(b) == sha256.Size
+}
+
+// Fetch downloads the spec at specURL into a temporary directory, which the
+// caller removes, and returns the file and the checksum of its content. When
+// checksum is not empty the content must match it, so a spec which changed
+// under the same URL is never generated from.
+func Fetch(specURL, checksum string) (string, string, error) {
+	u, err := url.Parse(specURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid spec URL '%s': %w", specURL, err)
+	}
+
+	resp, err := client.Get(specURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch %s: %w", specURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to fetch %s: %s", specURL, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch %s: %w", specURL, err)
+	}
+
+	sum := sha256.Sum256(content)
+	got := ChecksumPrefix + hex.EncodeToString(sum[:])
+	if checksum != "" && !strings.EqualFold(got, checksum) {
+		return "", "", fmt.Errorf("%w for %s: got %s, want %s", ErrChecksum, specURL, got, checksum)
+	}
+
+	dir, err := os.MkdirTemp("", "duh-spec-")
+	if err != nil {
+		return "", "", err
+	}
+	file := filepath.Join(dir, specName(u))
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", "", err
+	}
+	return file, got, nil
+}
+
+// specName keeps the extension of the URL, which decides whether --serve-spec
+// embeds openapi.json or openapi.yaml
+func specName(u *url.URL) string {
+	if strings.EqualFold(path.Ext(u.Path), ".json") {
+		return "openapi.json"
+	}
+	return "openapi.yaml"
+}
@@ -0,0 +1,248 @@
+package split
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BundleConfig controls how a spec split across files is bundled into one
+type BundleConfig struct {
+	Writer     io.Writer
+	SpecPath   string
+	OutputPath string
+}
+
+// Bundle inlines the path items the root spec references in other files and
+// moves the components they use into the root spec, writing a single file
+// the other duh commands can read
+func Bundle(conf BundleConfig) error {
+	rootFile, err := filepath.Abs(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", conf.SpecPath, err)
+	}
+	outputFile, err := filepath.Abs(conf.OutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", conf.OutputPath, err)
+	}
+	if outputFile == rootFile {
+		return fmt.Errorf("output %s would overwrite the root spec", conf.OutputPath)
+	}
+
+	root, err := readYAML(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+
+	b := &bundler{
+		root:     root.Content[0],
+		rootFile: rootFile,
+		docs:     map[string]*yaml.Node{rootFile: root.Content[0]},
+		origin:   make(map[string]string),
+	}
+
+	// The components of the root spec keep their names; files which define the
+	// same component again are reported as a conflict
+	components := mapValue(b.root, "components")
+	var own []*yaml.Node
+	if components != nil {
+		for i := 0; i+1 < len(components.Content); i += 2 {
+			kind := components.Content[i].Value
+			entries := components.Content[i+1]
+			for j := 0; j+1 < len(entries.Content); j += 2 {
+				b.origin[kind+"/"+entries.Content[j].Value] = rootFile
+				own = append(own, entries.Content[j+1])
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(b.root.Content); i += 2 {
+		key, value := b.root.Content[i].Value, b.root.Content[i+1]
+		switch key {
+		case "paths":
+			if err := b.paths(value, rootFile); err != nil {
+				return err
+			}
+		case "components":
+		default:
+			if err := b.resolve(value, rootFile); err != nil {
+				return err
+			}
+		}
+	}
+	for _, node := range own {
+		if err := b.resolve(node, rootFile); err != nil {
+			return err
+		}
+	}
+
+	if err := writeYAML(conf.OutputPath, b.root); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Bundled %d file(s) into %s\n", len(b.docs), conf.OutputPath)
+	return nil
+}
+
+type bundler struct {
+	root     *yaml.Node
+	rootFile string
+	// docs caches the files read by absolute path
+	docs map[string]*yaml.Node
+	// origin maps kind/name of every component in the root spec to the file it came from
+	origin map[string]string
+}
+
+// paths inlines the path items which reference another file
+func (b *bundler) paths(paths *yaml.Node, file string) error {
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		item := paths.Content[i+1]
+		ref := mapValue(item, "$ref")
+		if ref == nil {
+			if err := b.resolve(item, file); err != nil {
+				return err
+			}
+			continue
+		}
+
+		target, pointer := b.split(ref.Value, file)
+		doc, err := b.load(target)
+		if err != nil {
+			return err
+		}
+		found := lookup(doc, pointer)
+		if found == nil {
+			return fmt.Errorf("%s: $ref %s not found", relPath(file), ref.Value)
+		}
+
+		inlined := copyNode(found)
+		if err := b.resolve(inlined, target); err != nil {
+			return err
+		}
+		paths.Content[i+1] = inlined
+	}
+	return nil
+}
+
+// resolve rewrites every component reference of a node read from file to the
+// component in the root spec, copying it there when it is defined in another file
+func (b *bundler) resolve(node *yaml.Node, file string) error {
+	var err error
+	walkRefs(node, func(ref *yaml.Node) {
+		if err != nil {
+			return
+		}
+		target, pointer := b.split(ref.Value, file)
+		rest, ok := strings.CutPrefix(pointer, "/components/")
+		if !ok {
+			if target != b.rootFile {
+				err = fmt.Errorf("%s: $ref %s is not supported; only components and path items can be referenced across files",
+					relPath(file), ref.Value)
+			}
+			return
+		}
+
+		kind, name, _ := strings.Cut(rest, "/")
+		name = unescapePointer(name)
+		if err = b.component(kind, name, target); err != nil {
+			return
+		}
+		ref.Value = componentsPrefix + kind + "/" + escapePointer(name)
+	})
+	return err
+}
+
+// component makes sure the component defined in file is in the root spec
+func (b *bundler) component(kind, name, file string) error {
+	key := kind + "/" + name
+	if origin, ok := b.origin[key]; ok {
+		if origin != file {
+			return fmt.Errorf("%s %s is defined in both %s and %s", kind, name, relPath(origin), relPath(file))
+		}
+		return nil
+	}
+
+	doc, err := b.load(file)
+	if err != nil {
+		return err
+	}
+	found := mapValue(mapValue(mapValue(doc, "components"), kind), name)
+	if found == nil {
+		return fmt.Errorf("%s: components/%s/%s not found", relPath(file), kind, name)
+	}
+
+	b.origin[key] = file
+	component := copyNode(found)
+
+	components := mapValue(b.root, "components")
+	if components == nil {
+		components = &yaml.Node{Kind: yaml.MappingNode}
+		setValue(b.root, "components", components)
+	}
+	entries := mapValue(components, kind)
+	if entries == nil {
+		entries = &yaml.Node{Kind: yaml.MappingNode}
+		setValue(components, kind, entries)
+	}
+	setValue(entries, name, component)
+
+	return b.resolve(component, file)
+}
+
+// split returns the absolute file and JSON pointer of a reference made from file
+func (b *bundler) split(ref, file string) (string, string) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	if target == "" {
+		return file, pointer
+	}
+	return filepath.Join(filepath.Dir(file), target), pointer
+}
+
+func (b *bundler) load(file string) (*yaml.Node, error) {
+	if doc, ok := b.docs[file]; ok {
+		return doc, nil
+	}
+	root, err := readYAML(file)
+	if err != nil {
+		return nil, err
+	}
+	b.docs[file] = root.Content[0]
+	return root.Content[0], nil
+}
+
+// lookup returns the node at the JSON pointer, or nil when there is none
+func lookup(doc *yaml.Node, pointer string) *yaml.Node {
+	node := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		node = mapValue(node, unescapePointer(token))
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+func copyNode(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+// relPath shortens an absolute path for error messages
+func relPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+	if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return file
+}
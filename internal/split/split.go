@@ -0,0 +1,304 @@
+package split
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const componentsPrefix = "#/components/"
+
+// Config controls how a spec is split into one file per subject
+type Config struct {
+	Writer    io.Writer
+	SpecPath  string
+	OutputDir string
+	// By is how paths are grouped into files; only subject is supported
+	By string
+}
+
+// Run writes every subject of the spec to its own file in OutputDir together with
+// the schemas only that subject uses. The root file keeps everything else and
+// references the paths of each subject file.
+func Run(conf Config) error {
+	if conf.By != "subject" {
+		return fmt.Errorf("unknown --by value '%s': must be subject", conf.By)
+	}
+
+	root, err := readYAML(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	doc := root.Content[0]
+	rootFile := filepath.Base(conf.SpecPath)
+
+	paths := mapValue(doc, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode || len(paths.Content) == 0 {
+		return fmt.Errorf("%s has no paths to split", conf.SpecPath)
+	}
+
+	// Group the paths by subject, keeping the order of the spec
+	var subjects []string
+	files := make(map[string]string)
+	pathsOf := make(map[string][]int)
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		subject := subjectOf(paths.Content[i].Value)
+		if _, ok := files[subject]; !ok {
+			file := subject + ".yaml"
+			if file == rootFile {
+				return fmt.Errorf("subject %s would overwrite the root file %s", subject, rootFile)
+			}
+			files[subject] = file
+			subjects = append(subjects, subject)
+		}
+		pathsOf[subject] = append(pathsOf[subject], i)
+	}
+
+	// A schema moves into the file of the only subject which uses it; schemas
+	// shared between subjects or used by none stay in the root file
+	owner := make(map[string]string)
+	shared := make(map[string]bool)
+	for _, subject := range subjects {
+		reached := make(map[string]bool)
+		for _, i := range pathsOf[subject] {
+			reach(doc, paths.Content[i+1], reached)
+		}
+		for ref := range reached {
+			name, ok := strings.CutPrefix(ref, "schemas/")
+			if !ok {
+				continue
+			}
+			if other, ok := owner[name]; ok && other != subject {
+				shared[name] = true
+			}
+			owner[name] = subject
+		}
+	}
+	for name := range shared {
+		delete(owner, name)
+	}
+
+	fileOf := func(ref string) string {
+		if name, ok := strings.CutPrefix(ref, "schemas/"); ok {
+			if subject, ok := owner[name]; ok {
+				return files[subject]
+			}
+		}
+		return rootFile
+	}
+
+	// Build the subject files before the root file gives up its schemas
+	subjectDocs := make(map[string]*yaml.Node)
+	schemas := mapValue(mapValue(doc, "components"), "schemas")
+	for _, subject := range subjects {
+		subjectPaths := &yaml.Node{Kind: yaml.MappingNode}
+		for _, i := range pathsOf[subject] {
+			subjectPaths.Content = append(subjectPaths.Content, paths.Content[i], paths.Content[i+1])
+		}
+		subjectDoc := &yaml.Node{Kind: yaml.MappingNode}
+		setValue(subjectDoc, "paths", subjectPaths)
+
+		owned := &yaml.Node{Kind: yaml.MappingNode}
+		if schemas != nil {
+			for i := 0; i+1 < len(schemas.Content); i += 2 {
+				if owner[schemas.Content[i].Value] == subject {
+					owned.Content = append(owned.Content, schemas.Content[i], schemas.Content[i+1])
+				}
+			}
+		}
+		if len(owned.Content) > 0 {
+			components := &yaml.Node{Kind: yaml.MappingNode}
+			setValue(components, "schemas", owned)
+			setValue(subjectDoc, "components", components)
+		}
+
+		rewriteRefs(subjectDoc, files[subject], fileOf)
+		subjectDocs[subject] = subjectDoc
+	}
+
+	if schemas != nil {
+		var kept []*yaml.Node
+		for i := 0; i+1 < len(schemas.Content); i += 2 {
+			if _, ok := owner[schemas.Content[i].Value]; !ok {
+				kept = append(kept, schemas.Content[i], schemas.Content[i+1])
+			}
+		}
+		schemas.Content = kept
+		components := mapValue(doc, "components")
+		if len(kept) == 0 {
+			deleteKey(components, "schemas")
+		}
+		if len(components.Content) == 0 {
+			deleteKey(doc, "components")
+		}
+	}
+
+	rootPaths := &yaml.Node{Kind: yaml.MappingNode}
+	for _, subject := range subjects {
+		for _, i := range pathsOf[subject] {
+			path := paths.Content[i].Value
+			ref := &yaml.Node{Kind: yaml.MappingNode}
+			setValue(ref, "$ref", &yaml.Node{
+				Kind:  yaml.ScalarNode,
+				Value: files[subject] + "#/paths/" + escapePointer(path),
+				Style: yaml.SingleQuotedStyle,
+			})
+			rootPaths.Content = append(rootPaths.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: path}, ref)
+		}
+	}
+	setValue(doc, "paths", rootPaths)
+	rewriteRefs(doc, rootFile, fileOf)
+
+	for _, subject := range subjects {
+		if err := writeYAML(filepath.Join(conf.OutputDir, files[subject]), subjectDocs[subject]); err != nil {
+			return err
+		}
+	}
+	if err := writeYAML(filepath.Join(conf.OutputDir, rootFile), doc); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(conf.Writer, "✓ Split %d path(s) into %d subject file(s) in %s\n",
+		len(paths.Content)/2, len(subjects), conf.OutputDir)
+	return nil
+}
+
+// subjectOf returns the subject of a DUH-RPC path, e.g. users for /users.create.
+// A version prefix is kept in the subject so each version gets its own file.
+func subjectOf(path string) string {
+	subject, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), ".")
+	return strings.ReplaceAll(subject, "/", "-")
+}
+
+// reach records every component the node references, directly or through
+// other components, as kind/name
+func reach(doc, node *yaml.Node, reached map[string]bool) {
+	walkRefs(node, func(ref *yaml.Node) {
+		rest, ok := strings.CutPrefix(ref.Value, componentsPrefix)
+		if !ok || reached[rest] {
+			return
+		}
+		reached[rest] = true
+		kind, name, _ := strings.Cut(rest, "/")
+		if target := mapValue(mapValue(mapValue(doc, "components"), kind), name); target != nil {
+			reach(doc, target, reached)
+		}
+	})
+}
+
+// rewriteRefs points the local component references of a node in file at the
+// file which holds the component after the split
+func rewriteRefs(node *yaml.Node, file string, fileOf func(ref string) string) {
+	walkRefs(node, func(ref *yaml.Node) {
+		rest, ok := strings.CutPrefix(ref.Value, componentsPrefix)
+		if !ok {
+			return
+		}
+		if target := fileOf(rest); target != file {
+			ref.Value = target + ref.Value
+		}
+	})
+}
+
+// walkRefs calls fn with the value of every $ref in the tree
+func walkRefs(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkRefs(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "$ref" && node.Content[i+1].Kind == yaml.ScalarNode {
+				fn(node.Content[i+1])
+				continue
+			}
+			walkRefs(node.Content[i+1], fn)
+		}
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func unescapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+}
+
+func readYAML(path string) (*yaml.Node, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+	}
+
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid OpenAPI document structure in %s", path)
+	}
+	return &root, nil
+}
+
+func writeYAML(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{doc}}); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	_ = enc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setValue replaces the value of key, appending the key when it is missing
+func setValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func deleteKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
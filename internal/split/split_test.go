@@ -0,0 +1,140 @@
+package split_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBySubject(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"add", "-f", specPath, "/teams.create", "TeamsCreate"}))
+
+	outDir := filepath.Join(filepath.Dir(specPath), "specs")
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"split", specPath, "-o", outDir})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Split 5 path(s) into 2 subject file(s)")
+
+	root, err := os.ReadFile(filepath.Join(outDir, "openapi.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), "  /users.create:\n    $ref: 'users.yaml#/paths/~1users.create'")
+	assert.Contains(t, string(root), "  /teams.create:\n    $ref: 'teams.yaml#/paths/~1teams.create'")
+	assert.Contains(t, string(root), "    Error:\n")
+	assert.NotContains(t, string(root), "TeamsCreateRequest:")
+
+	teams, err := os.ReadFile(filepath.Join(outDir, "teams.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(teams), "  /teams.create:\n    post:")
+	assert.Contains(t, string(teams), "$ref: '#/components/schemas/TeamsCreateRequest'")
+	assert.Contains(t, string(teams), "$ref: 'openapi.yaml#/components/schemas/Error'")
+	assert.Contains(t, string(teams), "    TeamsCreateRequest:\n")
+	assert.NotContains(t, string(teams), "users.")
+
+	users, err := os.ReadFile(filepath.Join(outDir, "users.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(users), "    ListRequest:\n")
+	assert.Contains(t, string(users), "$ref: '#/components/schemas/PaginationRequest'")
+}
+
+func TestBundleReversesSplit(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"add", "-f", specPath, "/teams.create", "TeamsCreate"}))
+
+	dir := filepath.Dir(specPath)
+	outDir := filepath.Join(dir, "specs")
+	bundled := filepath.Join(dir, "bundled.yaml")
+
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"split", specPath, "-o", outDir}))
+
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"bundle", filepath.Join(outDir, "openapi.yaml"), "-o", bundled})
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ Bundled 3 file(s) into "+bundled)
+
+	content, err := os.ReadFile(bundled)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), ".yaml#")
+	assert.Contains(t, string(content), "  /teams.create:\n    post:")
+	assert.Contains(t, string(content), "    TeamsCreateRequest:\n")
+	assert.Contains(t, string(content), "$ref: '#/components/schemas/Error'")
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stdout, []string{"lint", bundled})
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "DUH-RPC compliant")
+}
+
+func TestSplitErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"split", specPath, "--by", "tag"})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "unknown --by value 'tag': must be subject")
+	assert.Empty(t, stdout.String())
+
+	stdout.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"split", filepath.Join(t.TempDir(), "missing.yaml")})
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "file not found")
+}
+
+func TestBundleErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		teams   string
+		output  string
+		wantErr string
+	}{
+		{
+			name:    "OverwriteRoot",
+			output:  "openapi.yaml",
+			wantErr: "would overwrite the root spec",
+		},
+		{
+			name:    "MissingComponent",
+			teams:   "paths:\n  /teams.create:\n    post:\n      responses:\n        '200':\n          $ref: '#/components/responses/Missing'\n",
+			wantErr: "teams.yaml: components/responses/Missing not found",
+		},
+		{
+			name:    "ConflictingComponent",
+			teams:   "paths:\n  /teams.create:\n    post:\n      responses:\n        '200':\n          description: OK\n          content:\n            application/json:\n              schema:\n                $ref: '#/components/schemas/Error'\ncomponents:\n  schemas:\n    Error:\n      type: object\n",
+			wantErr: "schemas Error is defined in both",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", specPath}))
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"add", "-f", specPath, "/teams.create", "TeamsCreate"}))
+
+			outDir := filepath.Join(filepath.Dir(specPath), "specs")
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"split", specPath, "-o", outDir}))
+			if test.teams != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(outDir, "teams.yaml"), []byte(test.teams), 0644))
+			}
+			output := filepath.Join(outDir, "bundled.yaml")
+			if test.output != "" {
+				output = filepath.Join(outDir, test.output)
+			}
+
+			stdout.Reset()
+			exitCode := duh.RunCmd(&stdout, &stderr, []string{"bundle", filepath.Join(outDir, "openapi.yaml"), "-o", output})
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/duh-rpc/duh-cli/internal/proto"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"gopkg.in/yaml.v3"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// estimateModule is the module path the code is generated with to estimate its size
+const estimateModule = "example.com/stats"
+
+var (
+	pathVersionRegex   = regexp.MustCompile(`^/(v\d+)/`)
+	serverVersionRegex = regexp.MustCompile(`/(v\d+)(/|$)`)
+)
+
+// Config controls the statistics report
+type Config struct {
+	Writer   io.Writer
+	SpecPath string
+	// Format is either text or json
+	Format string
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// Report is the statistics of a spec, written as JSON by --format json
+type Report struct {
+	Spec           string          `json:"spec"`
+	Operations     int             `json:"operations"`
+	Versions       []Version       `json:"versions"`
+	Schemas        int             `json:"schemas"`
+	ListOperations []ListOperation `json:"list_operations"`
+	RefDepth       RefDepth        `json:"schema_ref_depth"`
+	Generated      []GeneratedFile `json:"generated"`
+	GeneratedLines int             `json:"generated_lines"`
+}
+
+// Version groups the subjects served under a version, taken from the path prefix
+// or the server URL
+type Version struct {
+	Name     string    `json:"name"`
+	Subjects []Subject `json:"subjects"`
+}
+
+type Subject struct {
+	Name    string   `json:"name"`
+	Methods []string `json:"methods"`
+}
+
+type ListOperation struct {
+	Path  string `json:"path"`
+	Field string `json:"field"`
+}
+
+// RefDepth is the longest chain of schemas referencing each other
+type RefDepth struct {
+	Depth int      `json:"depth"`
+	Chain []string `json:"chain"`
+}
+
+// GeneratedFile is the size of a file 'duh generate' would write for the spec
+type GeneratedFile struct {
+	Name  string `json:"name"`
+	Lines int    `json:"lines"`
+}
+
+// Run writes the statistics of the spec
+func Run(conf Config) error {
+	if conf.Format != "text" && conf.Format != "json" {
+		return fmt.Errorf("unknown format '%s': must be text or json", conf.Format)
+	}
+
+	start := time.Now()
+	doc, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	content, err := os.ReadFile(conf.SpecPath)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+
+	report, err := build(conf.SpecPath, doc, content)
+	if err != nil {
+		return err
+	}
+
+	if conf.Format == "json" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		_, _ = fmt.Fprintf(conf.Writer, "%s\n", out)
+		return nil
+	}
+
+	printText(conf.Writer, report)
+	return nil
+}
+
+func build(specPath string, doc *v3.Document, content []byte) (Report, error) {
+	report := Report{
+		Spec:           specPath,
+		Versions:       []Version{},
+		ListOperations: []ListOperation{},
+		RefDepth:       RefDepth{Chain: []string{}},
+	}
+
+	serverVersion := ""
+	if len(doc.Servers) > 0 {
+		if matches := serverVersionRegex.FindStringSubmatch(doc.Servers[0].URL); matches != nil {
+			serverVersion = matches[1]
+		}
+	}
+
+	versions := make(map[string]map[string][]string)
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		for path, item := range doc.Paths.PathItems.FromOldest() {
+			if item.Post == nil {
+				continue
+			}
+			report.Operations++
+
+			version := serverVersion
+			rest := path
+			if matches := pathVersionRegex.FindStringSubmatch(path); matches != nil {
+				version = matches[1]
+				rest = strings.TrimPrefix(path, "/"+version)
+			}
+			if version == "" {
+				version = "unversioned"
+			}
+
+			subject, method, ok := strings.Cut(strings.TrimPrefix(rest, "/"), ".")
+			if !ok {
+				subject, method = strings.TrimPrefix(rest, "/"), ""
+			}
+			if versions[version] == nil {
+				versions[version] = make(map[string][]string)
+			}
+			versions[version][subject] = append(versions[version][subject], method)
+		}
+	}
+
+	for _, name := range sortedKeys(versions) {
+		version := Version{Name: name}
+		for _, subject := range sortedKeys(versions[name]) {
+			version.Subjects = append(version.Subjects, Subject{Name: subject, Methods: versions[name][subject]})
+		}
+		report.Versions = append(report.Versions, version)
+	}
+
+	if doc.Components != nil && doc.Components.Schemas != nil {
+		report.Schemas = doc.Components.Schemas.Len()
+	}
+
+	depth, err := refDepth(content)
+	if err != nil {
+		return Report{}, err
+	}
+	report.RefDepth = depth
+
+	if err := estimate(&report, doc, content); err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+// estimate generates the server, client and proto in memory to report their size
+// and the list operations the generator detects
+func estimate(report *Report, doc *v3.Document, content []byte) error {
+	config, err := duh.NewConfig("api", ".", "proto/v1/api.proto", "", "")
+	if err != nil {
+		return err
+	}
+	config.ModulePath = estimateModule
+
+	initTemplate, isFullTemplate := duh.MatchInitTemplate(doc)
+	data, err := duh.NewParser(doc, config, initTemplate, isFullTemplate).Parse()
+	if err != nil {
+		return err
+	}
+
+	for _, op := range data.ListOps {
+		report.ListOperations = append(report.ListOperations, ListOperation{Path: op.Path, Field: op.ResponseField})
+	}
+
+	generator, err := duh.NewGenerator()
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	server, err := generator.RenderServer(data)
+	if err != nil {
+		return fmt.Errorf("failed to render server.go: %w", err)
+	}
+	client, err := generator.RenderClient(data)
+	if err != nil {
+		return fmt.Errorf("failed to render client.go: %w", err)
+	}
+	protoContent, err := proto.Convert(content, proto.Options{
+		PackageName: data.ProtoPackage,
+		PackagePath: data.ProtoImport,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert OpenAPI to proto: %w", err)
+	}
+
+	for _, file := range []struct {
+		name    string
+		content []byte
+	}{
+		{"server.go", server},
+		{"client.go", client},
+		{"api.proto", protoContent},
+	} {
+		lines := bytes.Count(file.content, []byte("\n"))
+		report.Generated = append(report.Generated, GeneratedFile{Name: file.name, Lines: lines})
+		report.GeneratedLines += lines
+	}
+	return nil
+}
+
+// refDepth returns the longest chain of component schemas referencing each other.
+// A reference back to a schema already in the chain ends it.
+func refDepth(content []byte) (RefDepth, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return RefDepth{}, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	refs := make(map[string][]string)
+	var names []string
+	if len(root.Content) > 0 {
+		schemas := mapValue(mapValue(root.Content[0], "components"), "schemas")
+		if schemas != nil {
+			for i := 0; i+1 < len(schemas.Content); i += 2 {
+				name := schemas.Content[i].Value
+				names = append(names, name)
+				refs[name] = referencedSchemas(schemas.Content[i+1])
+			}
+		}
+	}
+
+	longest := []string{}
+	var visit func(chain []string)
+	visit = func(chain []string) {
+		if len(chain) > len(longest) {
+			longest = append([]string(nil), chain...)
+		}
+		for _, next := range refs[chain[len(chain)-1]] {
+			if _, ok := refs[next]; !ok || contains(chain, next) {
+				continue
+			}
+			visit(append(chain, next))
+		}
+	}
+	for _, name := range names {
+		visit([]string{name})
+	}
+
+	return RefDepth{Depth: len(longest), Chain: longest}, nil
+}
+
+func printText(w io.Writer, report Report) {
+	_, _ = fmt.Fprintf(w, "Statistics for %s\n\n", report.Spec)
+
+	_, _ = fmt.Fprintf(w, "Operations: %d\n", report.Operations)
+	for _, version := range report.Versions {
+		_, _ = fmt.Fprintf(w, "  %s\n", version.Name)
+		for _, subject := range version.Subjects {
+			_, _ = fmt.Fprintf(w, "    %-20s %3d  %s\n", subject.Name, len(subject.Methods), strings.Join(subject.Methods, ", "))
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nSchemas: %d\n", report.Schemas)
+
+	_, _ = fmt.Fprintf(w, "\nList operations: %d\n", len(report.ListOperations))
+	for _, op := range report.ListOperations {
+		_, _ = fmt.Fprintf(w, "  %s (items in %s)\n", op.Path, op.Field)
+	}
+
+	_, _ = fmt.Fprintf(w, "\nSchema reference depth: %d\n", report.RefDepth.Depth)
+	if report.RefDepth.Depth > 1 {
+		_, _ = fmt.Fprintf(w, "  %s\n", strings.Join(report.RefDepth.Chain, " → "))
+	}
+
+	_, _ = fmt.Fprintf(w, "\nEstimated generated code: %d lines\n", report.GeneratedLines)
+	for _, file := range report.Generated {
+		_, _ = fmt.Fprintf(w, "  %-10s %5d\n", file.Name, file.Lines)
+	}
+}
+
+// referencedSchemas returns the schemas the node references, in the order they are found
+func referencedSchemas(node *yaml.Node) []string {
+	var names []string
+	walk(node, func(n *yaml.Node) {
+		rest, ok := strings.CutPrefix(n.Value, schemaRefPrefix)
+		if !ok {
+			return
+		}
+		name, _, _ := strings.Cut(rest, "/")
+		if name != "" && !contains(names, name) {
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+// walk calls fn for every scalar value in the tree, mapping keys excluded
+func walk(node *yaml.Node, fn func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walk(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			walk(node.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		fn(node)
+	}
+}
+
+func mapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
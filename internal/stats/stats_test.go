@@ -0,0 +1,111 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/duh-rpc/duh-cli/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsText(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"stats", specPath})
+
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+	out := stdout.String()
+	assert.Contains(t, out, "Operations: 4\n  v1\n")
+	assert.Contains(t, out, "create, get, list, update")
+	assert.Contains(t, out, "Schemas: 11\n")
+	assert.Contains(t, out, "List operations: 1\n  /users.list (items in Items)")
+	assert.Contains(t, out, "Schema reference depth: 2\n  ListRequest → PaginationRequest")
+	assert.Contains(t, out, "Estimated generated code:")
+	assert.Contains(t, out, "server.go")
+}
+
+func TestStatsJSON(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"stats", specPath, "--format", "json"})
+	require.Equal(t, 0, exitCode)
+	assert.Empty(t, stderr.String())
+
+	var report stats.Report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	assert.Equal(t, 4, report.Operations)
+	require.Len(t, report.Versions, 1)
+	assert.Equal(t, "v1", report.Versions[0].Name)
+	require.Len(t, report.Versions[0].Subjects, 1)
+	assert.Equal(t, "users", report.Versions[0].Subjects[0].Name)
+	assert.Equal(t, []stats.ListOperation{{Path: "/users.list", Field: "Items"}}, report.ListOperations)
+	assert.Equal(t, []string{"ListRequest", "PaginationRequest"}, report.RefDepth.Chain)
+	require.Len(t, report.Generated, 3)
+	assert.Equal(t, report.Generated[0].Lines+report.Generated[1].Lines+report.Generated[2].Lines, report.GeneratedLines)
+}
+
+func TestStatsVersionedPaths(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"init", specPath}))
+	content, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	content = []byte(strings.NewReplacer(
+		"  /users.create:", "  /v1/users.create:",
+		"  /users.get:", "  /v1/users.get:",
+		"  /users.list:", "  /v2/users.list:",
+		"  /users.update:", "  /v2/users.update:",
+	).Replace(string(content)))
+	require.NoError(t, os.WriteFile(specPath, content, 0644))
+
+	stdout.Reset()
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stderr, []string{"stats", specPath, "--format", "json"}))
+
+	var report stats.Report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.Len(t, report.Versions, 2)
+	assert.Equal(t, []string{"create", "get"}, report.Versions[0].Subjects[0].Methods)
+	assert.Equal(t, "v2", report.Versions[1].Name)
+	assert.Equal(t, []string{"list", "update"}, report.Versions[1].Subjects[0].Methods)
+}
+
+func TestStatsErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "UnknownFormat",
+			args:    []string{"--format", "xml"},
+			wantErr: "unknown format 'xml': must be text or json",
+		},
+		{
+			name:    "FileNotFound",
+			args:    []string{filepath.Join(t.TempDir(), "missing.yaml")},
+			wantErr: "Error:",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"stats"}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	"go.yaml.in/yaml/v4"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Value validates a decoded JSON value, with numbers as json.Number, against
+// the schema and returns an error per mismatch, prefixed with the JSON path of
+// the value
+func Value(proxy *base.SchemaProxy, value any) []string {
+	var v validator
+	v.validate(proxy, value, "$")
+	return v.errs
+}
+
+// ProtoJSON validates a value like Value, for JSON written by protojson from
+// the proto message of the schema, as a DUH server replies. protojson writes
+// 64 bit integers as strings and omits fields holding their zero value, so a
+// required number, boolean or list may be missing.
+func ProtoJSON(proxy *base.SchemaProxy, value any) []string {
+	v := validator{protoJSON: true}
+	v.validate(proxy, value, "$")
+	return v.errs
+}
+
+type validator struct {
+	errs      []string
+	protoJSON bool
+}
+
+func (v *validator) errorf(path, format string, args ...any) {
+	v.errs = append(v.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether the value matches the schema without recording errors
+func (v *validator) matches(proxy *base.SchemaProxy, value any) bool {
+	m := validator{protoJSON: v.protoJSON}
+	m.validate(proxy, value, "$")
+	return len(m.errs) == 0
+}
+
+func (v *validator) validate(proxy *base.SchemaProxy, value any, path string) {
+	if proxy == nil {
+		return
+	}
+	schema := proxy.Schema()
+	if schema == nil {
+		return
+	}
+
+	for _, part := range schema.AllOf {
+		v.validate(part, value, path)
+	}
+	if len(schema.OneOf) > 0 {
+		var count int
+		for _, part := range schema.OneOf {
+			if v.matches(part, value) {
+				count++
+			}
+		}
+		switch {
+		case count == 0:
+			v.errorf(path, "matches none of the oneOf schemas")
+		case count > 1:
+			v.errorf(path, "matches %d of the oneOf schemas, must match exactly one", count)
+		}
+	}
+	if len(schema.AnyOf) > 0 && !slices.ContainsFunc(schema.AnyOf, func(part *base.SchemaProxy) bool {
+		return v.matches(part, value)
+	}) {
+		v.errorf(path, "matches none of the anyOf schemas")
+	}
+
+	if value == nil {
+		if !nullable(schema) {
+			v.errorf(path, "must not be null")
+		}
+		return
+	}
+
+	if v.protoJSON {
+		value = int64String(schema, value)
+	}
+
+	got := jsonType(value)
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(want string) bool {
+		return want == got || (want == "number" && got == "integer")
+	}) {
+		v.errorf(path, "expected %s, got %s", strings.Join(schema.Type, " or "), got)
+		return
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(node *yaml.Node) bool {
+		return node.Value == scalar(value)
+	}) {
+		v.errorf(path, "'%s' is not one of %s", scalar(value), enumValues(schema.Enum))
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		v.object(schema, value, path)
+	case []any:
+		v.array(schema, value, path)
+	case string:
+		v.string(schema, value, path)
+	case json.Number:
+		v.number(schema, value, path)
+	}
+}
+
+func (v *validator) object(schema *base.Schema, value map[string]any, path string) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; ok {
+			continue
+		}
+		if v.protoJSON && schema.Properties != nil && !presence(schema.Properties.GetOrZero(name)) {
+			continue
+		}
+		v.errorf(path, "missing required property '%s'", name)
+	}
+
+	var extra []string
+	for name := range value {
+		if schema.Properties == nil || schema.Properties.GetOrZero(name) == nil {
+			extra = append(extra, name)
+		}
+	}
+	slices.Sort(extra)
+
+	if schema.Properties != nil {
+		for name, prop := range schema.Properties.FromOldest() {
+			if field, ok := value[name]; ok {
+				v.validate(prop, field, path+"."+name)
+			}
+		}
+	}
+
+	// Unknown properties are allowed unless additionalProperties says otherwise
+	additional := schema.AdditionalProperties
+	for _, name := range extra {
+		switch {
+		case additional == nil:
+		case additional.IsA():
+			v.validate(additional.A, value[name], path+"."+name)
+		case !additional.B:
+			v.errorf(path, "unknown property '%s'", name)
+		}
+	}
+}
+
+func (v *validator) array(schema *base.Schema, value []any, path string) {
+	if schema.MinItems != nil && int64(len(value)) < *schema.MinItems {
+		v.errorf(path, "has %d items, fewer than minItems %d", len(value), *schema.MinItems)
+	}
+	if schema.MaxItems != nil && int64(len(value)) > *schema.MaxItems {
+		v.errorf(path, "has %d items, more than maxItems %d", len(value), *schema.MaxItems)
+	}
+	if schema.Items == nil || !schema.Items.IsA() {
+		return
+	}
+	for i, item := range value {
+		v.validate(schema.Items.A, item, fmt.Sprintf("%s[%d]", path, i))
+	}
+}
+
+func (v *validator) string(schema *base.Schema, value string, path string) {
+	length := int64(utf8.RuneCountInString(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.errorf(path, "is %d characters, shorter than minLength %d", length, *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.errorf(path, "is %d characters, longer than maxLength %d", length, *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(value) {
+			v.errorf(path, "'%s' does not match pattern '%s'", value, schema.Pattern)
+		}
+	}
+
+	var valid bool
+	switch schema.Format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		valid = err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(value)
+	case "email":
+		local, domain, ok := strings.Cut(value, "@")
+		valid = ok && local != "" && domain != ""
+	default:
+		valid = true
+	}
+	if !valid {
+		v.errorf(path, "'%s' is not a valid %s", value, schema.Format)
+	}
+}
+
+func (v *validator) number(schema *base.Schema, value json.Number, path string) {
+	n, err := value.Float64()
+	if err != nil {
+		v.errorf(path, "'%s' is not a valid number", value)
+		return
+	}
+
+	switch schema.Format {
+	case "int32":
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			v.errorf(path, "%s overflows int32", value)
+		}
+	case "int64":
+		if _, err := strconv.ParseInt(value.String(), 10, 64); err != nil && jsonType(value) == "integer" {
+			v.errorf(path, "%s overflows int64", value)
+		}
+	}
+
+	// exclusiveMinimum and exclusiveMaximum are booleans in OpenAPI 3.0 and
+	// bounds of their own in 3.1
+	if schema.Minimum != nil {
+		exclusive := schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A
+		if n < *schema.Minimum || (exclusive && n == *schema.Minimum) {
+			v.errorf(path, "%s is less than the minimum %v", value, *schema.Minimum)
+		}
+	}
+	if schema.ExclusiveMinimum != nil && schema.ExclusiveMinimum.IsB() && n <= schema.ExclusiveMinimum.B {
+		v.errorf(path, "%s is not greater than the exclusiveMinimum %v", value, schema.ExclusiveMinimum.B)
+	}
+	if schema.Maximum != nil {
+		exclusive := schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A
+		if n > *schema.Maximum || (exclusive && n == *schema.Maximum) {
+			v.errorf(path, "%s is greater than the maximum %v", value, *schema.Maximum)
+		}
+	}
+	if schema.ExclusiveMaximum != nil && schema.ExclusiveMaximum.IsB() && n >= schema.ExclusiveMaximum.B {
+		v.errorf(path, "%s is not less than the exclusiveMaximum %v", value, schema.ExclusiveMaximum.B)
+	}
+}
+
+// int64String returns a 64 bit integer protojson wrote as a string as the
+// json.Number it holds, and any other value unchanged
+func int64String(schema *base.Schema, value any) any {
+	s, ok := value.(string)
+	if !ok || !slices.Contains(schema.Type, "integer") || (schema.Format != "int64" && schema.Format != "uint64") {
+		return value
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return json.Number(s)
+	}
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil && schema.Format == "uint64" {
+		return json.Number(s)
+	}
+	return value
+}
+
+// presence reports whether protojson writes a field of the schema holding its
+// zero value, which only holds for messages. An empty string is omitted too,
+// but a required string is expected to be set.
+func presence(proxy *base.SchemaProxy) bool {
+	if proxy == nil || proxy.Schema() == nil {
+		return false
+	}
+	schema := proxy.Schema()
+	return slices.Contains(schema.Type, "object") || slices.Contains(schema.Type, "string") ||
+		(len(schema.Type) == 0 && schema.Properties != nil) || len(schema.AllOf) > 0
+}
+
+// nullable reports whether null is allowed, by nullable in OpenAPI 3.0 or a
+// null type in 3.1
+func nullable(schema *base.Schema) bool {
+	return (schema.Nullable != nil && *schema.Nullable) || slices.Contains(schema.Type, "null")
+}
+
+func jsonType(value any) string {
+	switch value := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(value.String(), ".eE") {
+			return "number"
+		}
+		return "integer"
+	}
+	return "null"
+}
+
+// scalar formats a value the way the yaml.Node of an enum holds it
+func scalar(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func enumValues(enum []*yaml.Node) string {
+	values := make([]string, len(enum))
+	for i, node := range enum {
+		values[i] = node.Value
+	}
+	return strings.Join(values, ", ")
+}
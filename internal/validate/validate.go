@@ -0,0 +1,160 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v3 "github.com/pb33f/libopenapi/datamodel/high/v3"
+)
+
+// ErrInvalid is returned when a document does not match the schema
+var ErrInvalid = errors.New("document does not match the schema")
+
+// Config controls which schema the JSON documents are validated against
+type Config struct {
+	Writer   io.Writer
+	SpecPath string
+	// Schema is the name of a component schema, Request and Response the path
+	// of an operation whose request body or 200 response is used instead
+	Schema   string
+	Request  string
+	Response string
+	// Files are the JSON documents, - reads Stdin
+	Files []string
+	Stdin io.Reader
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+}
+
+// Run validates every file against the schema, printing the errors of each
+// document which doesn't match it
+func Run(conf Config) error {
+	if countSet(conf.Schema, conf.Request, conf.Response) != 1 {
+		return errors.New("exactly one of --schema, --request or --response is required")
+	}
+	if len(conf.Files) == 0 {
+		return errors.New("--file is required")
+	}
+
+	start := time.Now()
+	doc, err := lint.Load(conf.SpecPath)
+	if err != nil {
+		return err
+	}
+	conf.Log.Parsed(conf.SpecPath, start)
+
+	name, proxy, err := lookup(doc, conf)
+	if err != nil {
+		return err
+	}
+
+	var invalid bool
+	for _, file := range conf.Files {
+		value, err := readJSON(file, conf.Stdin)
+		if err != nil {
+			return err
+		}
+
+		errs := Value(proxy, value)
+		if len(errs) == 0 {
+			_, _ = fmt.Fprintf(conf.Writer, "✓ %s is a valid %s\n", file, name)
+			continue
+		}
+		invalid = true
+		_, _ = fmt.Fprintf(conf.Writer, "✗ %s is not a valid %s\n", file, name)
+		for _, e := range errs {
+			_, _ = fmt.Fprintf(conf.Writer, "  - %s\n", e)
+		}
+	}
+
+	if invalid {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// lookup returns the schema named by the config and how it is described in
+// the output
+func lookup(doc *v3.Document, conf Config) (string, *base.SchemaProxy, error) {
+	if conf.Schema != "" {
+		if doc.Components != nil && doc.Components.Schemas != nil {
+			if proxy := doc.Components.Schemas.GetOrZero(conf.Schema); proxy != nil {
+				return conf.Schema, proxy, nil
+			}
+		}
+		return "", nil, fmt.Errorf("schema '%s' not found in components/schemas", conf.Schema)
+	}
+
+	path := conf.Request + conf.Response
+	var op *v3.Operation
+	if doc.Paths != nil && doc.Paths.PathItems != nil {
+		if item := doc.Paths.PathItems.GetOrZero(path); item != nil {
+			op = item.Post
+		}
+	}
+	if op == nil {
+		return "", nil, fmt.Errorf("operation '%s' not found in paths", path)
+	}
+
+	if conf.Request != "" {
+		if op.RequestBody != nil && op.RequestBody.Content != nil {
+			if media := op.RequestBody.Content.GetOrZero("application/json"); media != nil && media.Schema != nil {
+				return "request of " + path, media.Schema, nil
+			}
+		}
+		return "", nil, fmt.Errorf("operation '%s' has no application/json request body", path)
+	}
+
+	if op.Responses != nil && op.Responses.Codes != nil {
+		if resp := op.Responses.Codes.GetOrZero("200"); resp != nil && resp.Content != nil {
+			if media := resp.Content.GetOrZero("application/json"); media != nil && media.Schema != nil {
+				return "response of " + path, media.Schema, nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("operation '%s' has no application/json 200 response", path)
+}
+
+// readJSON decodes the document keeping numbers as json.Number, which tells
+// integers from numbers
+func readJSON(file string, stdin io.Reader) (any, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("failed to parse %s: more than one JSON document", file)
+	}
+	return value, nil
+}
+
+func countSet(values ...string) int {
+	var count int
+	for _, v := range values {
+		if v != "" {
+			count++
+		}
+	}
+	return count
+}
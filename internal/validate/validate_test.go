@@ -0,0 +1,230 @@
+package validate_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validateSpec = `openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /v1/users.create:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateUserRequest'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    CreateUserRequest:
+      type: object
+      additionalProperties: false
+      required: [name, email]
+      properties:
+        name:
+          type: string
+          minLength: 1
+          maxLength: 10
+        email:
+          type: string
+          format: email
+        age:
+          type: integer
+          format: int32
+          minimum: 0
+        role:
+          type: string
+          enum: [admin, member]
+        tags:
+          type: array
+          maxItems: 2
+          items:
+            type: string
+    User:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: string
+          format: uuid
+        nickname:
+          type: string
+          nullable: true
+`
+
+func TestValidateDataValid(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	payloadPath := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadPath,
+		[]byte(`{"name": "Ann", "email": "ann@example.com", "age": 30, "role": "admin", "tags": ["a"]}`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", specPath,
+		"--schema", "CreateUserRequest", "--file", payloadPath})
+
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), "payload.json is a valid CreateUserRequest")
+	assert.Empty(t, stderr.String())
+}
+
+func TestValidateDataInvalid(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	payloadPath := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadPath,
+		[]byte(`{"name": "", "email": "ann", "age": -1, "role": "owner", "tags": ["a", 2, "c"], "extra": true}`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", specPath,
+		"--schema", "CreateUserRequest", "--file", payloadPath})
+
+	require.Equal(t, 1, exitCode, stderr.String())
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "payload.json is not a valid CreateUserRequest")
+	for _, want := range []string{
+		"$.name: is 0 characters, shorter than minLength 1",
+		"$.email: 'ann' is not a valid email",
+		"$.age: -1 is less than the minimum 0",
+		"$.role: 'owner' is not one of admin, member",
+		"$.tags: has 3 items, more than maxItems 2",
+		"$.tags[1]: expected string, got integer",
+		"$: unknown property 'extra'",
+	} {
+		assert.Contains(t, stdout.String(), want)
+	}
+}
+
+func TestValidateDataRequired(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	payloadPath := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadPath, []byte(`{"name": "Ann", "age": 1.5}`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", specPath,
+		"--request", "/v1/users.create", "--file", payloadPath})
+
+	require.Equal(t, 1, exitCode, stderr.String())
+	assert.Empty(t, stderr.String())
+	assert.Contains(t, stdout.String(), "payload.json is not a valid request of /v1/users.create")
+	assert.Contains(t, stdout.String(), "$: missing required property 'email'")
+	assert.Contains(t, stdout.String(), "$.age: expected integer, got number")
+}
+
+func TestValidateDataResponseStdin(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	stdinPath := filepath.Join(dir, "stdin")
+	require.NoError(t, os.WriteFile(stdinPath,
+		[]byte(`{"id": "7c9e6679-7425-40de-944b-e07fc1f90ae7", "nickname": null, "other": 1}`), 0644))
+
+	f, err := os.Open(stdinPath)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	original := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = original }()
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", specPath,
+		"--response", "/v1/users.create", "--file", "-"})
+
+	require.Equal(t, 0, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), "- is a valid response of /v1/users.create")
+	assert.Empty(t, stderr.String())
+}
+
+func TestValidateDataErrors(t *testing.T) {
+	specPath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+
+	for _, test := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "unknown schema",
+			args: []string{"--schema", "Missing", "--file", specPath},
+			want: "schema 'Missing' not found in components/schemas",
+		},
+		{
+			name: "unknown operation",
+			args: []string{"--request", "/v1/users.missing", "--file", specPath},
+			want: "operation '/v1/users.missing' not found in paths",
+		},
+		{
+			name: "several schemas",
+			args: []string{"--schema", "User", "--request", "/v1/users.create", "--file", specPath},
+			want: "exactly one of --schema, --request or --response is required",
+		},
+		{
+			name: "no file",
+			args: []string{"--schema", "User"},
+			want: "--file is required",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, append([]string{"validate-data", specPath}, test.args...))
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.want)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestValidateDataParseError(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	payloadPath := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadPath, []byte(`{"name": `), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", specPath,
+		"--schema", "CreateUserRequest", "--file", payloadPath})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "failed to parse")
+	assert.Empty(t, stdout.String())
+}
+
+func TestValidateDataQuiet(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "openapi.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(validateSpec), 0644))
+	payloadPath := filepath.Join(dir, "payload.json")
+	require.NoError(t, os.WriteFile(payloadPath, []byte(`{"name": "Ann"}`), 0644))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"validate-data", "-q", specPath,
+		"--schema", "CreateUserRequest", "--file", payloadPath})
+
+	require.Equal(t, 1, exitCode, stderr.String())
+	assert.Contains(t, stdout.String(), "payload.json is not a valid CreateUserRequest")
+	assert.Contains(t, stdout.String(), "$: missing required property 'email'")
+	assert.Empty(t, stderr.String())
+}
@@ -0,0 +1,225 @@
+package work
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the workspace file read from the current directory
+const FileName = "duh.work"
+
+// ErrViolations is returned by Lint when every spec was linted but some of
+// them are not DUH-RPC compliant
+var ErrViolations = errors.New("violations found")
+
+// Workspace lists the services of a repository which are linted and generated together
+type Workspace struct {
+	Services []Service `yaml:"services"`
+}
+
+// Service is a spec and where the code generated from it is written. Paths are
+// relative to the directory of the duh.work file.
+type Service struct {
+	Spec         string `yaml:"spec"`
+	OutputDir    string `yaml:"output-dir"`
+	Package      string `yaml:"package"`
+	ProtoPath    string `yaml:"proto-path"`
+	ProtoImport  string `yaml:"proto-import"`
+	ProtoPackage string `yaml:"proto-package"`
+}
+
+// Load reads and checks the duh.work file
+func Load(filePath string) (Workspace, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Workspace{}, fmt.Errorf("%s not found in the current directory", filePath)
+		}
+		return Workspace{}, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	var ws Workspace
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&ws); err != nil && !errors.Is(err, io.EOF) {
+		return Workspace{}, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	if len(ws.Services) == 0 {
+		return Workspace{}, fmt.Errorf("%s lists no services", filePath)
+	}
+
+	seen := make(map[string]string)
+	for i, s := range ws.Services {
+		if s.Spec == "" {
+			return Workspace{}, fmt.Errorf("%s: service %d has no spec", filePath, i+1)
+		}
+		outputDir := s.outputDir()
+		if other, ok := seen[outputDir]; ok {
+			return Workspace{}, fmt.Errorf("%s: %s and %s both generate into %s", filePath, other, s.Spec, outputDir)
+		}
+		seen[outputDir] = s.Spec
+	}
+
+	return ws, nil
+}
+
+func (s Service) outputDir() string {
+	if s.OutputDir != "" {
+		return filepath.Clean(s.OutputDir)
+	}
+	return filepath.Dir(s.Spec)
+}
+
+// LintConfig controls how every spec of the workspace is linted
+type LintConfig struct {
+	Writer io.Writer
+	// ErrWriter receives the errors of the specs which could not be linted
+	ErrWriter io.Writer
+	Disabled  []string
+	// Rules are run besides the DUH-RPC rules, such as the lint.SecurityRules
+	Rules []lint.Rule
+	// Output records the violations for --output json, it may be nil
+	Output *output.Result
+	// Log prints the details of --verbose, it may be nil
+	Log *output.Log
+	// Quiet prints only the violations of each spec
+	Quiet bool
+}
+
+// Lint validates the spec of every service, printing the report of each and a
+// summary of the specs which are not compliant
+func Lint(conf LintConfig) error {
+	ws, err := Load(FileName)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	var errored int
+	// With Quiet only the violations are printed, errors still go to ErrWriter
+	w := conf.Writer
+	if conf.Quiet {
+		w = io.Discard
+	}
+
+	for _, s := range ws.Services {
+		_, _ = fmt.Fprintf(w, "==> %s\n", s.Spec)
+
+		start := time.Now()
+		doc, err := lint.Load(s.Spec)
+		if err != nil {
+			_, _ = fmt.Fprintf(conf.ErrWriter, "Error: %v\n", err)
+			_, _ = fmt.Fprintln(w)
+			problems = append(problems, fmt.Sprintf("%s: %v", s.Spec, err))
+			errored++
+			continue
+		}
+		conf.Log.Parsed(s.Spec, start)
+
+		result := lint.Validate(doc, s.Spec, conf.Disabled, conf.Rules...)
+		conf.Log.Rules(result)
+		if conf.Quiet {
+			lint.PrintViolations(conf.Writer, result)
+		} else {
+			lint.Print(w, result)
+		}
+		conf.Output.AddViolations(s.Spec, result.Violations)
+		_, _ = fmt.Fprintln(w)
+		if !result.Valid() {
+			problems = append(problems, fmt.Sprintf("%s: %d errors", s.Spec, result.ErrorCount()))
+		}
+	}
+
+	printSummary(w, len(ws.Services), "compliant", problems)
+
+	if errored > 0 {
+		return fmt.Errorf("%d of %d spec(s) could not be linted", errored, len(ws.Services))
+	}
+	if len(problems) > 0 {
+		return ErrViolations
+	}
+	return nil
+}
+
+// GenerateConfig controls how the code of every service of the workspace is
+// generated. Options is applied to every service, with the spec, output
+// directory, package and proto options taken from the duh.work file.
+type GenerateConfig struct {
+	Writer io.Writer
+	// ErrWriter receives the errors of the services which failed to generate
+	ErrWriter io.Writer
+	Options   duh.RunConfig
+}
+
+// Generate runs 'duh generate' for every service, continuing past failures,
+// and prints a summary of the services which failed
+func Generate(conf GenerateConfig) error {
+	ws, err := Load(FileName)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, s := range ws.Services {
+		_, _ = fmt.Fprintf(conf.Writer, "==> %s\n", s.Spec)
+
+		config := conf.Options
+		config.Writer = conf.Writer
+		config.SpecPath = s.Spec
+		config.OutputDir = s.outputDir()
+		if s.Package != "" {
+			config.PackageName = s.Package
+		}
+		if s.ProtoPath != "" {
+			config.ProtoPath = s.ProtoPath
+		}
+		config.ProtoImport = s.ProtoImport
+		config.ProtoPackage = s.ProtoPackage
+
+		if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+			err = fmt.Errorf("failed to create %s: %w", config.OutputDir, err)
+			_, _ = fmt.Fprintf(conf.ErrWriter, "Error: %v\n", err)
+			_, _ = fmt.Fprintln(conf.Writer)
+			problems = append(problems, fmt.Sprintf("%s: %v", s.Spec, err))
+			continue
+		}
+
+		if err := duh.Run(config); err != nil {
+			_, _ = fmt.Fprintf(conf.ErrWriter, "Error: %v\n", err)
+			_, _ = fmt.Fprintln(conf.Writer)
+			problems = append(problems, fmt.Sprintf("%s: %v", s.Spec, err))
+			continue
+		}
+		_, _ = fmt.Fprintln(conf.Writer)
+	}
+
+	printSummary(conf.Writer, len(ws.Services), "generated", problems)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d of %d service(s) failed to generate", len(problems), len(ws.Services))
+	}
+	return nil
+}
+
+func printSummary(w io.Writer, total int, outcome string, problems []string) {
+	if len(problems) == 0 {
+		_, _ = fmt.Fprintf(w, "✓ All %d spec(s) %s\n", total, outcome)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "Summary: %d of %d spec(s) %s\n", total-len(problems), total, outcome)
+	for _, problem := range problems {
+		_, _ = fmt.Fprintf(w, "  ✗ %s\n", problem)
+	}
+}
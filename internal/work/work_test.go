@@ -0,0 +1,214 @@
+package work_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/duh-rpc/duh-cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testStartDir string
+
+func TestMain(m *testing.M) {
+	var err error
+	testStartDir, err = os.Getwd()
+	if err != nil {
+		panic("failed to get working directory: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+const workFile = `services:
+  - spec: services/billing/openapi.yaml
+    output-dir: services/billing/api
+  - spec: services/users/openapi.yaml
+    package: users
+`
+
+func TestLintAll(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/acme/mono\n"), 0644))
+	require.NoError(t, os.WriteFile("duh.work", []byte(workFile), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/billing/openapi.yaml", "--template", "minimal"}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/users/openapi.yaml"}))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "--all"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "==> services/billing/openapi.yaml")
+	assert.Contains(t, stdout.String(), "==> services/users/openapi.yaml")
+	assert.Contains(t, stdout.String(), "✓ All 2 spec(s) compliant")
+}
+
+func TestLintAllViolations(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/acme/mono\n"), 0644))
+	require.NoError(t, os.WriteFile("duh.work", []byte(workFile), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/billing/openapi.yaml", "--template", "minimal"}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/users/openapi.yaml"}))
+
+	content, err := os.ReadFile("services/users/openapi.yaml")
+	require.NoError(t, err)
+	content = []byte(strings.ReplaceAll(string(content), "format: int32", "format: int8"))
+	require.NoError(t, os.WriteFile("services/users/openapi.yaml", content, 0644))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "--all"})
+
+	require.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout.String(), "Summary: 1 of 2 spec(s) compliant")
+	assert.Contains(t, stdout.String(), "✗ services/users/openapi.yaml:")
+	assert.NotContains(t, stdout.String(), "✗ services/billing/openapi.yaml")
+}
+
+func TestGenerateAll(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/acme/mono\n"), 0644))
+	require.NoError(t, os.WriteFile("duh.work", []byte(workFile), 0644))
+
+	var stdout bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/billing/openapi.yaml", "--template", "minimal"}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/users/openapi.yaml"}))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "--all"})
+
+	require.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout.String(), "✓ All 2 spec(s) generated")
+
+	billing, err := os.ReadFile(filepath.Join("services", "billing", "api", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(billing), "package api\n")
+
+	users, err := os.ReadFile(filepath.Join("services", "users", "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(users), "package users\n")
+	assert.Contains(t, string(users), `pb "github.com/acme/mono/services/users/proto/v1"`)
+}
+
+func TestGenerateAllContinuesPastFailures(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/acme/mono\n"), 0644))
+	require.NoError(t, os.WriteFile("duh.work", []byte(workFile), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/billing/openapi.yaml", "--template", "minimal"}))
+	require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/users/openapi.yaml"}))
+
+	content, err := os.ReadFile("services/users/openapi.yaml")
+	require.NoError(t, err)
+	content = []byte(strings.ReplaceAll(string(content), "format: int32", "format: int8"))
+	require.NoError(t, os.WriteFile("services/users/openapi.yaml", content, 0644))
+	require.NoError(t, os.WriteFile("services/billing/openapi.yaml", []byte("not: [valid"), 0644))
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "--all"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stdout.String(), "==> services/users/openapi.yaml")
+	assert.Contains(t, stdout.String(), "Summary: 0 of 2 spec(s) generated")
+	assert.Contains(t, stdout.String(), "✗ services/users/openapi.yaml: OpenAPI validation failed")
+	assert.Contains(t, stderr.String(), "Error: failed to parse OpenAPI spec")
+	assert.Contains(t, stderr.String(), "Error: 2 of 2 service(s) failed to generate")
+}
+
+func TestWorkspaceErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		work    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "NoServices",
+			work:    "services: []\n",
+			args:    []string{"lint", "--all"},
+			wantErr: "duh.work lists no services",
+		},
+		{
+			name:    "MissingSpec",
+			work:    "services:\n  - output-dir: api\n",
+			args:    []string{"generate", "--all"},
+			wantErr: "duh.work: service 1 has no spec",
+		},
+		{
+			name:    "UnknownField",
+			work:    "services:\n  - spec: openapi.yaml\n    output: api\n",
+			args:    []string{"generate", "--all"},
+			wantErr: "failed to parse duh.work",
+		},
+		{
+			name:    "SameOutputDir",
+			work:    "services:\n  - spec: a/openapi.yaml\n    output-dir: api\n  - spec: b/openapi.yaml\n    output-dir: api/\n",
+			args:    []string{"generate", "--all"},
+			wantErr: "duh.work: a/openapi.yaml and b/openapi.yaml both generate into api",
+		},
+		{
+			name:    "SpecFile",
+			work:    workFile,
+			args:    []string{"lint", "--all", "openapi.yaml"},
+			wantErr: "--all cannot be combined with a spec file",
+		},
+		{
+			name:    "OutputDirFlag",
+			work:    workFile,
+			args:    []string{"generate", "--all", "--output-dir", "api"},
+			wantErr: "--output-dir cannot be combined with --all",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+			require.NoError(t, os.Chdir(tempDir))
+
+			require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/acme/mono\n"), 0644))
+			require.NoError(t, os.WriteFile("duh.work", []byte(test.work), 0644))
+
+			var stdout, stderr bytes.Buffer
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/billing/openapi.yaml", "--template", "minimal"}))
+			require.Equal(t, 0, duh.RunCmd(&stdout, &stdout, []string{"init", "services/users/openapi.yaml"}))
+
+			stdout.Reset()
+			exitCode := duh.RunCmd(&stdout, &stderr, test.args)
+
+			require.Equal(t, 2, exitCode)
+			assert.Contains(t, stderr.String(), test.wantErr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestWorkspaceNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "--all"})
+
+	require.Equal(t, 2, exitCode)
+	assert.Contains(t, stderr.String(), "duh.work not found in the current directory")
+	assert.Empty(t, stdout.String())
+}
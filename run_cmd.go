@@ -1,31 +1,136 @@
 package duh
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/duh-rpc/duh-cli/duhdyn"
 	"github.com/duh-rpc/duh-cli/internal/add"
+	"github.com/duh-rpc/duh-cli/internal/call"
+	"github.com/duh-rpc/duh-cli/internal/conformance"
+	"github.com/duh-rpc/duh-cli/internal/convert"
+	"github.com/duh-rpc/duh-cli/internal/docs"
+	"github.com/duh-rpc/duh-cli/internal/example"
+	"github.com/duh-rpc/duh-cli/internal/export"
 	"github.com/duh-rpc/duh-cli/internal/generate/duh"
+	"github.com/duh-rpc/duh-cli/internal/generate/plugin"
+	"github.com/duh-rpc/duh-cli/internal/generate/python"
+	"github.com/duh-rpc/duh-cli/internal/generate/ts"
+	"github.com/duh-rpc/duh-cli/internal/graph"
 	init_ "github.com/duh-rpc/duh-cli/internal/init"
 	"github.com/duh-rpc/duh-cli/internal/lint"
+	"github.com/duh-rpc/duh-cli/internal/output"
+	"github.com/duh-rpc/duh-cli/internal/split"
+	"github.com/duh-rpc/duh-cli/internal/stats"
+	"github.com/duh-rpc/duh-cli/internal/validate"
+	"github.com/duh-rpc/duh-cli/internal/work"
 	"github.com/spf13/cobra"
 )
 
 const Version = "1.0.0"
 
-// RunCmd executes the CLI logic and returns exit code
-func RunCmd(stdout io.Writer, args []string) int {
-	exitCode := 0
+// Exit codes returned by RunCmd for every command
+const (
+	// ExitOK is returned when the command succeeded
+	ExitOK = 0
+	// ExitViolations is returned when a check finds violations: lint when the
+	// spec is not DUH-RPC compliant, validate-data when a document does not
+	// match the spec, test conformance when a server does not and call when
+	// the server replies with an error
+	ExitViolations = 1
+	// ExitError is returned for every error, including invalid arguments and flags
+	ExitError = 2
+)
+
+// RunCmd executes the CLI logic and returns the exit code. Results are written
+// to stdout, error messages and usage errors to stderr.
+func RunCmd(stdout, stderr io.Writer, args []string) int {
+	exitCode := ExitOK
+	// report collects the result of the command with --output json and is nil otherwise
+	var report *output.Result
+	// log prints the details of -v/--verbose to stderr and is nil otherwise
+	var log *output.Log
+	// quiet is set by -q/--quiet to print only violations and errors
+	var quiet bool
+	// progress shows the phases of a generation when stderr is a terminal, it is
+	// nil with --verbose, which prints the same steps line by line, and --quiet
+	var progress *output.Progress
 
 	rootCmd := &cobra.Command{
 		Use:   "duh",
 		Short: "DUH-RPC tooling",
-		Long:  `duh is a command-line tool for working with DUH-RPC specifications and code.`,
+		Long: `duh is a command-line tool for working with DUH-RPC specifications and code.
+
+Use --output json with init, add, generate and lint to print a JSON result
+with the files written, the violations found and any error with its code
+instead of the text output.
+
+Results are written to stdout and error messages to stderr. Every command
+uses the same exit codes:
+  0    Success
+  1    Violations found (lint, validate-data and test conformance) or an error
+       reply to call
+  2    Error, including invalid arguments, unknown flags and unknown commands
+
+Use -v/--verbose to print the lint rules run, how long the spec took to parse,
+the templates rendered and the files skipped because they exist to stderr.
+Use -q/--quiet to print only violations and errors.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
 		},
+		// Errors are printed below so --output json can report them instead
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			verbose, _ := cmd.Root().PersistentFlags().GetBool("verbose")
+			quiet, _ = cmd.Root().PersistentFlags().GetBool("quiet")
+			if verbose && quiet {
+				return errors.New("--verbose cannot be combined with --quiet")
+			}
+
+			format, _ := cmd.Root().PersistentFlags().GetString("output")
+			switch format {
+			case output.Text:
+				if verbose {
+					log = output.NewLog(cmd.ErrOrStderr())
+				}
+				if !verbose && !quiet {
+					progress = output.NewProgress(cmd.ErrOrStderr())
+				}
+				if quiet {
+					switch cmd.CommandPath() {
+					// The output of these commands is the result, lint prints its violations below
+					case "duh lint", "duh stats", "duh graph", "duh validate-data", "duh test conformance", "duh example", "duh call":
+					default:
+						if interactive, _ := cmd.Flags().GetBool("interactive"); !interactive {
+							cmd.SetOut(io.Discard)
+						}
+					}
+				}
+				return nil
+			case output.JSON:
+			default:
+				return fmt.Errorf("unknown output format '%s': must be text or json", format)
+			}
+
+			switch cmd.CommandPath() {
+			case "duh init", "duh add", "duh generate", "duh lint":
+			default:
+				return fmt.Errorf("--output json is not supported by '%s'", cmd.CommandPath())
+			}
+
+			report = &output.Result{Command: cmd.Name()}
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			return nil
+		},
 	}
+	rootCmd.PersistentFlags().String("output", output.Text, "Output format of init, add, generate and lint: text or json")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Print the rules run, parse timing, templates rendered and files skipped to stderr")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Print only violations and errors")
 
 	rootCmd.Version = Version
 	rootCmd.SetVersionTemplate("duh version {{.Version}}\n")
@@ -40,6 +145,13 @@ and reports any violations found.
 
 If no file path is provided, defaults to 'openapi.yaml' in the current directory.
 
+With --all, every spec listed in the duh.work file of the current directory is
+linted and a summary of the specs with violations is printed.
+
+With --security, or lint.security.enabled in .duh.yaml, the rules flagging
+properties which look like secrets or personal data run as well: those returned
+in a response and those not marked x-duh-redact.
+
 Exit Codes:
   0    Validation passed (spec is DUH-RPC compliant)
   1    Validation failed (violations found)
@@ -52,13 +164,6 @@ Exit Codes:
 				filePath = args[0]
 			}
 
-			doc, err := lint.Load(filePath)
-			if err != nil {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Error: %v\n", err)
-				exitCode = 2
-				return
-			}
-
 			cfg := lint.LoadConfig()
 			disabled := cfg.Lint.Disable
 
@@ -69,17 +174,71 @@ Exit Codes:
 				}
 			}
 
-			result := lint.Validate(doc, filePath, disabled)
-			lint.Print(cmd.OutOrStdout(), result)
+			security := cfg.Lint.Security
+			if enabled, _ := cmd.Flags().GetBool("security"); enabled {
+				security.Enabled = true
+			}
+
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				if len(args) > 0 {
+					err := errors.New("--all cannot be combined with a spec file")
+					report.FailUsage(err)
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					exitCode = ExitError
+					return
+				}
+
+				err := work.Lint(work.LintConfig{
+					Writer:    cmd.OutOrStdout(),
+					ErrWriter: cmd.ErrOrStderr(),
+					Disabled:  disabled,
+					Rules:     security.Rules(),
+					Output:    report,
+					Log:       log,
+					Quiet:     quiet,
+				})
+				switch {
+				case err == nil:
+					exitCode = ExitOK
+				case errors.Is(err, work.ErrViolations):
+					exitCode = ExitViolations
+				default:
+					report.Fail(err)
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					exitCode = ExitError
+				}
+				return
+			}
+
+			start := time.Now()
+			doc, err := lint.Load(filePath)
+			if err != nil {
+				report.Fail(err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+			log.Parsed(filePath, start)
+
+			result := lint.Validate(doc, filePath, disabled, security.Rules()...)
+			log.Rules(result)
+			if quiet {
+				lint.PrintViolations(cmd.OutOrStdout(), result)
+			} else {
+				lint.Print(cmd.OutOrStdout(), result)
+			}
+			report.AddViolations(filePath, result.Violations)
 
 			if result.Valid() {
-				exitCode = 0
+				exitCode = ExitOK
 			} else {
-				exitCode = 1
+				exitCode = ExitViolations
 			}
 		},
 	}
 	lintCmd.Flags().String("disable", "", "Comma-separated list of rules to disable")
+	lintCmd.Flags().Bool("all", false, "Lint every spec listed in duh.work")
+	lintCmd.Flags().Bool("security", false, "Also run the rules flagging properties which look like secrets or personal data")
 
 	initCmd := &cobra.Command{
 		Use:   "init [openapi-file]",
@@ -91,9 +250,31 @@ that demonstrates all DUH-RPC requirements and best practices.
 
 If no file path is provided, defaults to 'openapi.yaml' in the current directory.
 
+Use --template to choose the starting point:
+  minimal        A single /echo.send endpoint
+  users          Create, get, list and update users (default); 'duh generate duh
+                 --full' generates a complete service from it
+  streaming      Uploading a file and streaming it back with application/octet-stream
+  multi-subject  Users and orders subjects sharing schemas
+
+Use --subject to name the resource of the users template after your domain and
+--version to set the version in the servers url:
+
+  duh init --subject products --version v2
+
+creates /products.create, /products.get, /products.list and /products.update
+served from https://api.example.com/v2. 'duh generate duh --full' still
+recognizes the endpoints and generates a complete service for them.
+
+Use --project to bootstrap a service in the current directory. It creates
+go.mod for the module path, the spec, a .duh.yaml lint configuration and a
+Makefile. Add --generate to run 'duh generate --full' straight away:
+
+  duh init --project github.com/acme/billing --generate
+
 Exit Codes:
   0    Template created successfully
-  2    Error (file already exists, permission denied, etc.)`,
+  2    Error (unknown template, file already exists, permission denied, etc.)`,
 		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			const defaultOutput = "openapi.yaml"
@@ -102,14 +283,36 @@ Exit Codes:
 				outputPath = args[0]
 			}
 
-			if err := init_.Run(cmd.OutOrStdout(), outputPath); err != nil {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Error: %v\n", err)
-				exitCode = 2
+			template, _ := cmd.Flags().GetString("template")
+			subject, _ := cmd.Flags().GetString("subject")
+			version, _ := cmd.Flags().GetString("version")
+			project, _ := cmd.Flags().GetString("project")
+			generate, _ := cmd.Flags().GetBool("generate")
+
+			if err := init_.Run(init_.Config{
+				Writer:     cmd.OutOrStdout(),
+				OutputPath: outputPath,
+				Template:   template,
+				Subject:    subject,
+				Version:    version,
+				Project:    project,
+				Generate:   generate,
+				Output:     report,
+			}); err != nil {
+				report.Fail(err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
 				return
 			}
 		},
 	}
 
+	initCmd.Flags().String("template", init_.DefaultTemplate, "Starting spec: "+strings.Join(init_.Templates, ", "))
+	initCmd.Flags().String("subject", "", "Resource name used in place of users, e.g. products (users template only)")
+	initCmd.Flags().String("version", "v1", "Version in the servers url, e.g. v2")
+	initCmd.Flags().String("project", "", "Go module path; also create go.mod, .duh.yaml and a Makefile")
+	initCmd.Flags().Bool("generate", false, "Run 'duh generate --full' after creating the project (requires --project)")
+
 	addCmd := &cobra.Command{
 		Use:   "add <path> <name>",
 		Short: "Add a new DUH-RPC endpoint to an OpenAPI specification",
@@ -124,28 +327,241 @@ For example: /users.create
 The name is used to generate schema names: {Name}Request and {Name}Response
 For example: CreateUser generates CreateUserRequest and CreateUserResponse
 
+With --crud the path names a resource and the create, get, list, update and
+delete endpoints are added at once. The name is the entity schema returned by
+the endpoints, for example:
+
+  duh add --crud /products Product
+
+adds /products.create through /products.delete with ProductsCreateRequest,
+ProductsCreateResponse and so on, a Product schema and the PaginationRequest
+and PaginationResponse schemas used by /products.list.
+
+Use --req-field and --resp-field to define the schema properties instead of
+the placeholders. Each takes name:type and may be repeated:
+
+  duh add /orders.create CreateOrder --req-field customer_id:string \
+    --req-field lines:[]LineItem --resp-field created_at:date-time
+
+Types are string, integer, int32, int64, number, float, double, boolean, date,
+date-time, email, uuid and uri. Prefix a type with [] for an array, or use the
+name of a schema in components/schemas to reference it. End a field with ! to
+make it required, e.g. customer_id:string!. With --crud the request fields
+are used by create and update and the response fields define the entity schema.
+
+Use --request-schema and --response-schema to point the endpoint at schemas
+already defined in components/schemas; no {Name}Request or {Name}Response
+schema is generated for them.
+
+Use --errors to choose the error responses, all referencing the Error schema.
+The default is 400,404,500; the allowed codes are 400, 401, 403, 404, 409, 429
+and 500.
+
+Use --tag to set the tags of the added operations, it may be repeated.
+'duh generate duh' groups operations which share a tag into their own service
+interface, and 'duh docs' lists them under the tag instead of the path subject:
+
+  duh add --crud /invoices Invoice --tag billing
+
+Use --from to start from a copy of an existing endpoint. Its request and
+response schemas are copied to {Name}Request and {Name}Response:
+
+  duh add --from /users.create /admins.create AdminsCreate
+
+Use 'duh add schema' to add a component schema without an endpoint.
+
+Use -i to be prompted for the path, name, fields and error codes. The YAML to
+be inserted is shown before anything is written. Values given as arguments or
+flags are not asked for again.
+
 Use the -f flag to specify a custom OpenAPI file (defaults to 'openapi.yaml').
 
 Exit Codes:
   0    Endpoint added successfully
   2    Error (invalid path, file not found, path already exists, etc.)`,
-		Args: cobra.ExactArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if interactive, _ := cmd.Flags().GetBool("interactive"); interactive {
+				return cobra.MaximumNArgs(2)(cmd, args)
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			path := args[0]
-			name := args[1]
 			filePath, _ := cmd.Flags().GetString("file")
+			crud, _ := cmd.Flags().GetBool("crud")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			from, _ := cmd.Flags().GetString("from")
+			errorCodes, _ := cmd.Flags().GetStringSlice("errors")
+			tags, _ := cmd.Flags().GetStringArray("tag")
+			reqFields, _ := cmd.Flags().GetStringArray("req-field")
+			respFields, _ := cmd.Flags().GetStringArray("resp-field")
+			requestSchema, _ := cmd.Flags().GetString("request-schema")
+			responseSchema, _ := cmd.Flags().GetString("response-schema")
+
+			// Interactive mode prompts for whatever is not given
+			var path, name string
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if len(args) > 1 {
+				name = args[1]
+			}
+
+			if interactive && report != nil {
+				err := errors.New("--output json cannot be combined with -i")
+				report.FailUsage(err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
 
-			if err := add.Run(cmd.OutOrStdout(), filePath, path, name); err != nil {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Error: %v\n", err)
-				exitCode = 2
+			if err := add.Run(add.Config{
+				Writer:         cmd.OutOrStdout(),
+				FilePath:       filePath,
+				Path:           path,
+				Name:           name,
+				CRUD:           crud,
+				From:           from,
+				Interactive:    interactive,
+				Reader:         cmd.InOrStdin(),
+				RequestFields:  reqFields,
+				ResponseFields: respFields,
+				RequestSchema:  requestSchema,
+				ResponseSchema: responseSchema,
+				ErrorCodes:     errorCodes,
+				Tags:           tags,
+			}); err != nil {
+				report.Fail(err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
 				return
 			}
+			report.AddFiles(filePath)
 		},
 	}
 	addCmd.Flags().StringP("file", "f", "openapi.yaml", "OpenAPI specification file to modify")
+	addCmd.Flags().Bool("crud", false, "Add create, get, list, update and delete endpoints for a resource path")
+	addCmd.Flags().StringArray("req-field", nil, "Request field as name:type, may be repeated (replaces the placeholder fields)")
+	addCmd.Flags().StringArray("resp-field", nil, "Response field as name:type, may be repeated (replaces the placeholder fields)")
+	addCmd.Flags().String("request-schema", "", "Use an existing schema from components/schemas as the request")
+	addCmd.Flags().String("response-schema", "", "Use an existing schema from components/schemas as the response")
+	addCmd.Flags().StringSlice("errors", nil, "Error responses to add, e.g. 400,401,404,429,500 (default 400,404,500)")
+	addCmd.Flags().StringArray("tag", nil, "Tag to set on the added operations, may be repeated")
+	addCmd.Flags().String("from", "", "Copy an existing endpoint and its request and response schemas")
+	addCmd.Flags().BoolP("interactive", "i", false, "Prompt for the endpoint and preview the YAML before writing")
+
+	addSchemaCmd := &cobra.Command{
+		Use:   "schema <name>",
+		Short: "Add a component schema without adding an endpoint",
+		Long: `Add a component schema without adding an endpoint.
+
+The schema command inserts an object schema into components/schemas so shared
+sub-objects can be referenced from other schemas, for example:
+
+  duh add schema Address --field street:string --field zip:string!
+  duh add /users.create UsersCreate --req-field address:Address
+
+Fields use the same name:type syntax as --req-field.
+
+Use the -f flag to specify a custom OpenAPI file (defaults to 'openapi.yaml').
+
+Exit Codes:
+  0    Schema added successfully
+  2    Error (invalid field, schema already exists, file not found, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filePath, _ := cmd.Flags().GetString("file")
+			fields, _ := cmd.Flags().GetStringArray("field")
+
+			if err := add.Schema(add.SchemaConfig{
+				Writer:   cmd.OutOrStdout(),
+				FilePath: filePath,
+				Name:     args[0],
+				Fields:   fields,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	addSchemaCmd.Flags().StringP("file", "f", "openapi.yaml", "OpenAPI specification file to modify")
+	addSchemaCmd.Flags().StringArray("field", nil, "Field as name:type, may be repeated")
+	addCmd.AddCommand(addSchemaCmd)
+
+	removeCmd := &cobra.Command{
+		Use:   "remove <path>",
+		Short: "Remove a DUH-RPC endpoint from an OpenAPI specification",
+		Long: `Remove a DUH-RPC endpoint from an OpenAPI specification.
+
+The remove command deletes the path and every schema it referenced that is no
+longer referenced by anything else in the specification. Schemas that were
+already unreferenced are kept. Comments elsewhere in the file are preserved.
+
+Use the -f flag to specify a custom OpenAPI file (defaults to 'openapi.yaml').
+
+Exit Codes:
+  0    Endpoint removed successfully
+  2    Error (path not found, file not found, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			filePath, _ := cmd.Flags().GetString("file")
+
+			if err := add.Remove(add.RemoveConfig{
+				Writer:   cmd.OutOrStdout(),
+				FilePath: filePath,
+				Path:     args[0],
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	removeCmd.Flags().StringP("file", "f", "openapi.yaml", "OpenAPI specification file to modify")
+
+	renameCmd := &cobra.Command{
+		Use:   "rename <path> <new-path>",
+		Short: "Rename a DUH-RPC endpoint in an OpenAPI specification",
+		Long: `Rename a DUH-RPC endpoint in an OpenAPI specification.
+
+The rename command moves the endpoint to the new path, keeping its position
+and comments in the file. With --name the request and response schemas become
+{Name}Request and {Name}Response and the operationId is updated, for example:
+
+  duh rename /users.get /users.fetch --name FetchUser
+
+Without --name, schemas following the {Resource}{Method}Request convention are
+renamed to match the new path, e.g. UsersGetRequest becomes UsersFetchRequest.
+Every reference to a renamed schema is updated.
+
+Use the -f flag to specify a custom OpenAPI file (defaults to 'openapi.yaml').
+
+Exit Codes:
+  0    Endpoint renamed successfully
+  2    Error (path not found, path or schema already exists, etc.)`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			filePath, _ := cmd.Flags().GetString("file")
+			name, _ := cmd.Flags().GetString("name")
+
+			if err := add.Rename(add.RenameConfig{
+				Writer:   cmd.OutOrStdout(),
+				FilePath: filePath,
+				Path:     args[0],
+				NewPath:  args[1],
+				Name:     name,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	renameCmd.Flags().StringP("file", "f", "openapi.yaml", "OpenAPI specification file to modify")
+	renameCmd.Flags().String("name", "", "Rename the request and response schemas to {Name}Request and {Name}Response")
 
 	generateCmd := &cobra.Command{
-		Use:   "generate [openapi-file]",
+		Use:   "generate [openapi-file]...",
 		Short: "Generate DUH-RPC client, server, and proto from OpenAPI specification",
 		Long: `Generate DUH-RPC client, server, and proto from OpenAPI specification.
 
@@ -153,32 +569,133 @@ The generate command generates DUH-RPC specific code including HTTP client with
 pagination iterators, server with routing, and protobuf definitions.
 
 By default, generates client.go, server.go, iterator.go (if list operations),
-proto file, buf.yaml, and buf.gen.yaml. Use flags to customize output.
+proto file, proto.lock, buf.yaml, and buf.gen.yaml. Use flags to customize output.
 
 After generation, run 'buf generate' to generate Go code from proto files,
 then run 'go mod tidy' to update dependencies.
 
-With --full flag, additionally generates editable scaffolding files:
+Methods are named after the operationId of their operation when it has one
+(getUserById gives GetUserById), otherwise after the path (/users.get gives
+UsersGet). Use --path-names to name every method after its path.
+An x-duh-name on an operation overrides its method and const name, and on a
+schema overrides its proto message and Go type name.
+
+With --connect, additionally generates connect_client.go with a ConnectClient
+implementing the same ClientInterface over the Connect protocol, for servers
+built with connect-go from the proto services (--connect implies --proto-service).
+
+With --cli, additionally generates cmd/<name>ctl/main.go, a cobra CLI with a
+subcommand per operation, flags for the request fields and JSON output. The
+name is taken from the last element of the Go module path.
+
+With --serve-spec, additionally copies the spec next to server.go, where it is
+embedded with go:embed, and the Handler serves it at /v1/openapi.get so clients
+and gateways can discover the contract from a running service.
+
+With --introspect, the Handler also serves /v1/rpc.list, which lists the path,
+request and response messages and deprecation of every rpc, much like gRPC
+reflection.
+
+With --audience internal, partner or public, only the operations the
+x-duh-audience of the spec shows to that audience are generated, so a single
+spec yields a client for each. internal sees every operation, partner the
+partner and public ones, and public only the public ones, which operations
+without x-duh-audience are.
+
+With --full flag, additionally generates editable scaffolding files and the CLI:
   - daemon.go: Service orchestration with TLS/HTTP support
+  - config.go: Daemon settings read from a YAML file, environment and flags
   - service.go: Service implementation (full or stub based on spec)
   - api_test.go: Integration tests (full suite or minimal example)
-  - Makefile: Build automation with test, lint, and proto targets
+  - Makefile: Build automation with test, lint, proto and regen targets, shaped
+    by --proto-tool, --proto-out and --lint-targets
+  - README.md and .gitignore: Project files, written only when missing
+
+The regenerated code is merged into scaffolding files edited since the last
+run, against the code that run generated, so handlers written in service.go
+survive adding an endpoint. Edits which conflict with the regenerated code are
+left between <<<<<<< and >>>>>>> markers. A file without that code, such as one
+generated by an older duh, is kept and listed instead. --force overwrites the
+edited files, --skip-existing keeps every scaffolding file which exists and
+-i/--interactive asks for each edited file which cannot be merged.
+
+With --update-service, the code is generated without --full and a stub is
+added to the existing service.go for each operation it has no method for,
+leaving the methods already there as they are. Methods whose parameters no
+longer match the spec are listed. Run it after 'duh add':
+
+  duh add /users.delete DeleteUser
+  duh generate --update-service
+
+Methods of service.go shaped like a handler, which no operation of the spec
+calls any more, are listed after generating so removed endpoints don't leave
+dead code behind. With --strict they fail the run.
+
+With --docker, additionally generates everything of --full and, to deploy it:
+  - cmd/<name>d/main.go: The daemon main, configured by config.go
+  - Dockerfile: Multi-stage build of the daemon into a distroless image
+  - .dockerignore: Files left out of the build context
+  - docker-compose.yaml: Runs the daemon on port 8080
+
+With --ci github or --ci gitlab, additionally generates everything of --full
+and a CI workflow (.github/workflows/duh.yaml or .gitlab-ci.yml) which lints
+the spec, fails when regenerating the code changes it and runs the tests.
+
+With --k8s, additionally generates everything of --docker and Kubernetes
+manifests under k8s/, applied with 'kubectl apply -k k8s':
+  - deployment.yaml: The daemon with /readyz readiness and TCP liveness probes
+  - service.yaml: A ClusterIP Service on port 8080
+  - hpa.yaml: A HorizontalPodAutoscaler scaling on CPU
+  - kustomization.yaml: The image, replicas and resources to set per environment
 
 If the OpenAPI spec matches 'duh init' template (users.create, users.get,
-users.list, users.update), full implementations are generated. Otherwise,
-stub implementations with TODO comments are generated for you to fill in.
+users.list, users.update, or the same methods of the --subject given to init),
+full implementations are generated. Otherwise, stub implementations with TODO
+comments are generated for you to fill in.
+
+If every path starts with a version, such as /v1/users.create and
+/v2/users.create, each version is generated into its own package (v1/, v2/)
+with its own proto package (duh.api.v1, duh.api.v2), and handler.go combines
+them into a Handler that routes requests by version prefix.
 
 If no file path is provided, defaults to 'openapi.yaml' in the current directory.
 
+Several specs, such as one per domain, are merged into one client, server and
+proto. The info, servers and extensions come from the first spec, and a path or
+a differently defined component in two specs is an error:
+
+  duh generate users.yaml billing.yaml --package api
+
+The spec may also be an http or https URL, such as a release of a spec
+registry, which is fetched before generating so consumers don't vendor the
+spec. The sha256 of the fetched spec is printed; pass it back with --checksum
+to fail when the spec under that URL changes. A fetched spec cannot be combined
+with --full, whose Makefile regenerates from a local spec. With --client-only,
+server.go is not generated:
+
+  duh generate https://specs.example.com/org/users@v1.2.0 --client-only \
+    --checksum sha256:<hex>
+
+With --all, code is generated for every service listed in the duh.work file of
+the current directory, continuing past failures, and a summary is printed. The
+spec, output-dir, package, proto-path, proto-import and proto-package of each
+service are read from duh.work:
+
+  services:
+    - spec: services/billing/openapi.yaml
+      output-dir: services/billing/api
+    - spec: services/users/openapi.yaml
+
 Exit Codes:
   0    All components generated successfully
   2    Error (file not found, validation failed, generation failed, etc.)`,
-		Args: cobra.MaximumNArgs(1),
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			const defaultFile = "openapi.yaml"
 			filePath := defaultFile
+			var mergeSpecs []string
 			if len(args) > 0 {
-				filePath = args[0]
+				filePath, mergeSpecs = args[0], args[1:]
 			}
 
 			packageName, _ := cmd.Flags().GetString("package")
@@ -186,21 +703,116 @@ Exit Codes:
 			protoPath, _ := cmd.Flags().GetString("proto-path")
 			protoImport, _ := cmd.Flags().GetString("proto-import")
 			protoPackage, _ := cmd.Flags().GetString("proto-package")
+			modulePath, _ := cmd.Flags().GetString("module")
+			pathNames, _ := cmd.Flags().GetBool("path-names")
 			fullFlag, _ := cmd.Flags().GetBool("full")
+			enumsAsStrings, _ := cmd.Flags().GetBool("enums-as-strings")
+			splitBySubject, _ := cmd.Flags().GetBool("split-by-subject")
+			protoService, _ := cmd.Flags().GetBool("proto-service")
+			connectFlag, _ := cmd.Flags().GetBool("connect")
+			cliFlag, _ := cmd.Flags().GetBool("cli")
+			serveSpec, _ := cmd.Flags().GetBool("serve-spec")
+			introspect, _ := cmd.Flags().GetBool("introspect")
+			docker, _ := cmd.Flags().GetBool("docker")
+			k8s, _ := cmd.Flags().GetBool("k8s")
+			ci, _ := cmd.Flags().GetString("ci")
+			protoTool, _ := cmd.Flags().GetString("proto-tool")
+			protoOut, _ := cmd.Flags().GetString("proto-out")
+			lintTargets, _ := cmd.Flags().GetStringSlice("lint-targets")
+			bufPlugins, _ := cmd.Flags().GetStringArray("buf-plugin")
+			bufModule, _ := cmd.Flags().GetString("buf-module")
+			bufDeps, _ := cmd.Flags().GetStringArray("buf-dep")
+			basePath, _ := cmd.Flags().GetString("base-path")
+			clientOnly, _ := cmd.Flags().GetBool("client-only")
+			checksum, _ := cmd.Flags().GetString("checksum")
+			force, _ := cmd.Flags().GetBool("force")
+			skipExisting, _ := cmd.Flags().GetBool("skip-existing")
+			interactive, _ := cmd.Flags().GetBool("interactive")
+			updateService, _ := cmd.Flags().GetBool("update-service")
+			strict, _ := cmd.Flags().GetBool("strict")
+			audience, _ := cmd.Flags().GetString("audience")
 
-			if err := duh.Run(duh.RunConfig{
-				Writer:       cmd.OutOrStdout(),
-				SpecPath:     filePath,
-				PackageName:  packageName,
-				OutputDir:    outputDir,
-				ProtoPath:    protoPath,
-				ProtoImport:  protoImport,
-				ProtoPackage: protoPackage,
-				FullFlag:     fullFlag,
-				Converter:    duh.NewProtoConverter(),
-			}); err != nil {
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Error: %v\n", err)
-				exitCode = 2
+			config := duh.RunConfig{
+				Writer:         cmd.OutOrStdout(),
+				SpecPath:       filePath,
+				MergeSpecs:     mergeSpecs,
+				PackageName:    packageName,
+				OutputDir:      outputDir,
+				ProtoPath:      protoPath,
+				ProtoImport:    protoImport,
+				ProtoPackage:   protoPackage,
+				ModulePath:     modulePath,
+				PathNames:      pathNames,
+				FullFlag:       fullFlag,
+				ConnectFlag:    connectFlag,
+				CLIFlag:        cliFlag,
+				ServeSpecFlag:  serveSpec,
+				IntrospectFlag: introspect,
+				DockerFlag:     docker,
+				K8sFlag:        k8s,
+				CI:             ci,
+				ProtoTool:      protoTool,
+				ProtoOut:       protoOut,
+				LintTargets:    lintTargets,
+				BufPlugins:     bufPlugins,
+				BufModule:      bufModule,
+				BufDeps:        bufDeps,
+				BasePath:       basePath,
+				ClientOnlyFlag: clientOnly,
+				Checksum:       checksum,
+				Force:          force,
+				SkipExisting:   skipExisting,
+				Interactive:    interactive,
+				Reader:         cmd.InOrStdin(),
+				UpdateService:  updateService,
+				Strict:         strict,
+				Audience:       audience,
+				Converter: duh.NewProtoConverter(duh.ProtoOptions{
+					EnumsAsStrings: enumsAsStrings,
+					SplitBySubject: splitBySubject,
+					// The Connect client calls the rpcs of the proto services
+					Services: protoService || connectFlag,
+				}),
+				Output:   report,
+				Log:      log,
+				Progress: progress,
+			}
+
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				if len(args) > 0 {
+					err := errors.New("--all cannot be combined with a spec file")
+					report.FailUsage(err)
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					exitCode = ExitError
+					return
+				}
+				// Where each service is generated comes from duh.work
+				for _, name := range []string{"package", "output-dir", "proto-path", "proto-import", "proto-package"} {
+					if cmd.Flags().Changed(name) {
+						err := fmt.Errorf("--%s cannot be combined with --all; set it per service in duh.work", name)
+						report.FailUsage(err)
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+						exitCode = ExitError
+						return
+					}
+				}
+
+				if err := work.Generate(work.GenerateConfig{
+					Writer:    cmd.OutOrStdout(),
+					ErrWriter: cmd.ErrOrStderr(),
+					Options:   config,
+				}); err != nil {
+					report.Fail(err)
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+					exitCode = ExitError
+				}
+				return
+			}
+
+			if err := duh.Run(config); err != nil {
+				report.Fail(err)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
 				return
 			}
 		},
@@ -210,16 +822,1012 @@ Exit Codes:
 	generateCmd.Flags().String("proto-path", "proto/v1/api.proto", "Proto file path")
 	generateCmd.Flags().String("proto-import", "", "Proto import override (optional)")
 	generateCmd.Flags().String("proto-package", "", "Proto package override (optional)")
+	generateCmd.Flags().String("module", "", "Go module path to use instead of reading go.mod (optional)")
+	generateCmd.Flags().Bool("path-names", false, "Name methods after their paths (users.create gives UsersCreate) even when operations have an operationId")
 	generateCmd.Flags().Bool("full", false, "Generate additional editable scaffolding files")
+	generateCmd.Flags().Bool("enums-as-strings", false, "Keep enum properties as strings instead of proto enums")
+	generateCmd.Flags().Bool("split-by-subject", false, "Write a proto file per subject plus a shared common.proto")
+	generateCmd.Flags().Bool("proto-service", false, "Add a gRPC service definition for the operations to the proto")
+	generateCmd.Flags().Bool("connect", false, "Also generate a Connect protocol client (implies --proto-service)")
+	generateCmd.Flags().Bool("cli", false, "Also generate a command line client under cmd/ (included in --full)")
+	generateCmd.Flags().Bool("client-only", false, "Generate the client without server.go")
+	generateCmd.Flags().String("checksum", "", "sha256:<hex> the spec fetched from a URL must match")
+	generateCmd.Flags().Bool("serve-spec", false, "Embed the spec in the server and serve it at /v1/openapi.get")
+	generateCmd.Flags().Bool("introspect", false, "Serve the list of rpcs at /v1/rpc.list")
+	generateCmd.Flags().Bool("docker", false, "Also generate a daemon main, Dockerfile and docker-compose.yaml (implies --full)")
+	generateCmd.Flags().Bool("k8s", false, "Also generate Kubernetes manifests under k8s/ (implies --docker)")
+	generateCmd.Flags().String("proto-tool", "buf", "Tool the proto target of the Makefile runs: buf or protoc (protoc skips buf.yaml and buf.gen.yaml)")
+	generateCmd.Flags().String("proto-out", ".", "Directory the Go code of the proto files is generated into, relative to --output-dir")
+	generateCmd.Flags().StringArray("buf-plugin", nil, "Plugin of buf.gen.yaml as name[=version], pinning a default plugin or adding one (repeatable)")
+	generateCmd.Flags().String("base-path", "", "Route prefix, like /api, the handler serves and the client calls the rpcs under; overrides x-duh-base-path")
+	generateCmd.Flags().String("buf-module", "", "Buf Schema Registry module named in buf.yaml, like buf.build/acme/api; adds a push target to the Makefile of --full")
+	generateCmd.Flags().StringArray("buf-dep", nil, "Buf Schema Registry module buf.yaml depends on as remote/owner/module[:label] (repeatable)")
+	generateCmd.Flags().StringSlice("lint-targets", nil, "Lint targets added to the Makefile of --full: spec, proto or vet")
+	generateCmd.Flags().String("ci", "", "Also generate a CI workflow checking the spec and generated code: github or gitlab (implies --full)")
+	generateCmd.Flags().Bool("all", false, "Generate every service listed in duh.work")
+	generateCmd.Flags().Bool("force", false, "Render every file, even those unchanged since the last run, overwriting edited scaffold files")
+	generateCmd.Flags().Bool("skip-existing", false, "Keep every scaffold file of --full which already exists")
+	generateCmd.Flags().BoolP("interactive", "i", false, "Ask before overwriting each edited scaffold file which cannot be merged")
+	generateCmd.Flags().Bool("strict", false, "Fail when service.go has handlers of operations no longer in the spec")
+	generateCmd.Flags().String("audience", "", "Generate only the operations x-duh-audience shows to internal, partner or public")
+	generateCmd.Flags().Bool("update-service", false, "Add a stub to service.go for each operation without a method and report methods whose signature changed")
 
-	rootCmd.AddCommand(lintCmd, initCmd, addCmd, generateCmd)
-	rootCmd.SetOut(stdout)
-	rootCmd.SetErr(stdout)
-	rootCmd.SetArgs(args)
+	generateTsCmd := &cobra.Command{
+		Use:   "ts [openapi-file]",
+		Short: "Generate a TypeScript client from an OpenAPI specification",
+		Long: `Generate a TypeScript client from an OpenAPI specification.
+
+The ts command writes a fetch based client for web frontends to the output
+directory:
+  - types.ts: an interface or type for every schema in components/schemas
+  - client.ts: a Client class with a method per operation, async iterators for
+    list operations and a DuhError thrown for DUH-RPC error replies
+  - index.ts: re-exports both
+
+Requests and responses are sent as JSON, so the client works with any DUH-RPC
+service without a protobuf runtime.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Client generated successfully
+  2    Error (file not found, validation failed, generation failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			outputDir, _ := cmd.Flags().GetString("out")
+
+			if err := ts.Run(ts.Config{
+				Writer:    cmd.OutOrStdout(),
+				SpecPath:  filePath,
+				OutputDir: outputDir,
+				Log:       log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	generateTsCmd.Flags().String("out", "web/src/api", "Output directory for the TypeScript files")
+	generateCmd.AddCommand(generateTsCmd)
+
+	generatePythonCmd := &cobra.Command{
+		Use:   "python [openapi-file]",
+		Short: "Generate a Python client package from an OpenAPI specification",
+		Long: `Generate a Python client package from an OpenAPI specification.
+
+The python command writes an installable package to the output directory:
+  - pyproject.toml: package metadata depending on httpx and pydantic
+  - <package>/models.py: a pydantic model or type alias for every schema
+  - <package>/errors.py: a DuhError exception with a subclass per DUH-RPC
+    error code, carrying the parsed error reply
+  - <package>/client.py: Client and AsyncClient with a method per operation
+    and iterators for list operations
+  - <package>/__init__.py: re-exports the clients, models and errors
+
+The package name defaults to the spec title in snake_case with a _client
+suffix, e.g. 'Pet Store' becomes pet_store_client.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Client generated successfully
+  2    Error (file not found, validation failed, generation failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
 
-	if err := rootCmd.Execute(); err != nil {
-		return 2
+			outputDir, _ := cmd.Flags().GetString("out")
+			packageName, _ := cmd.Flags().GetString("package")
+
+			if err := python.Run(python.Config{
+				Writer:    cmd.OutOrStdout(),
+				SpecPath:  filePath,
+				OutputDir: outputDir,
+				Package:   packageName,
+				Log:       log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	generatePythonCmd.Flags().String("out", "python", "Output directory for the Python package")
+	generatePythonCmd.Flags().StringP("package", "p", "", "Python package name (defaults to the spec title)")
+	generateCmd.AddCommand(generatePythonCmd)
+
+	generateGatewayCmd := &cobra.Command{
+		Use:   "gateway <openapi-file>...",
+		Short: "Generate a reverse proxy in front of the services of several specs",
+		Long: `Generate a reverse proxy in front of the services of several specs.
+
+The gateway command writes a Go main to the output directory which routes each
+DUH-RPC path of the specs to the backend of the service defining it. JSON
+requests are checked against the request schema of their path before they are
+proxied, and GET /healthz replies with the health of every backend.
+
+Each spec is a service named after its file, or after its directory when the
+file is openapi.yaml, so specs/users.yaml and users/openapi.yaml are both the
+users service. The backends are read at startup from gateway.yaml:
+
+  services:
+    users:
+      url: http://localhost:8081
+      health: http://localhost:8081/healthz
+
+An example gateway.yaml is written to the output directory unless it exists.
+
+Exit Codes:
+  0    Gateway generated successfully
+  2    Error (file not found, validation failed, a path in two specs, etc.)`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			outputDir, _ := cmd.Flags().GetString("out")
+
+			if err := duh.RunGateway(duh.GatewayConfig{
+				Writer:    cmd.OutOrStdout(),
+				SpecPaths: args,
+				OutputDir: outputDir,
+				Log:       log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	generateGatewayCmd.Flags().String("out", "gateway", "Output directory for the gateway main")
+	generateCmd.AddCommand(generateGatewayCmd)
+
+	generatePluginCmd := &cobra.Command{
+		Use:   "plugin <name> [openapi-file]",
+		Short: "Generate code with a duh-gen-<name> plugin",
+		Long: `Generate code with a duh-gen-<name> plugin.
+
+The plugin command lets generators live outside duh, such as a Kotlin client
+or a Terraform provider. 'duh generate plugin kotlin' validates the spec and
+runs the duh-gen-kotlin executable found on PATH, writing a JSON request to
+its stdin:
+
+  {
+    "version": 1,
+    "spec_path": "openapi.yaml",
+    "spec": { ...the OpenAPI document as JSON... },
+    "data": { ...the operations and names the Go code is generated from... },
+    "options": { ...the --opt key=value pairs... }
+  }
+
+The plugin replies on stdout with the files to write, relative to the output
+directory, or an error to report:
+
+  {"files": {"Client.kt": "..."}, "error": ""}
+
+Whatever the plugin writes to stderr is shown to the user. The Go fields of
+"data" are derived from go.mod, use --module where there is none.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Code generated successfully
+  2    Error (plugin not found, validation failed, plugin failed, etc.)`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 1 {
+				filePath = args[1]
+			}
+
+			outputDir, _ := cmd.Flags().GetString("out")
+			packageName, _ := cmd.Flags().GetString("package")
+			modulePath, _ := cmd.Flags().GetString("module")
+			pairs, _ := cmd.Flags().GetStringArray("opt")
+
+			options, err := plugin.ParseOptions(pairs)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+
+			if err := plugin.Run(plugin.Config{
+				Writer:      cmd.OutOrStdout(),
+				ErrWriter:   cmd.ErrOrStderr(),
+				Name:        args[0],
+				SpecPath:    filePath,
+				OutputDir:   outputDir,
+				PackageName: packageName,
+				ModulePath:  modulePath,
+				Options:     options,
+				Log:         log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
 	}
+	generatePluginCmd.Flags().String("out", ".", "Output directory for the files returned by the plugin")
+	generatePluginCmd.Flags().StringP("package", "p", "api", "Go package name in the data sent to the plugin")
+	generatePluginCmd.Flags().String("module", "", "Go module path to use instead of reading go.mod (optional)")
+	generatePluginCmd.Flags().StringArray("opt", nil, "Option passed to the plugin as key=value (repeatable)")
+	generateCmd.AddCommand(generatePluginCmd)
+
+	protoCmd := &cobra.Command{
+		Use:   "proto [openapi-file]",
+		Short: "Generate only the proto file from an OpenAPI specification",
+		Long: `Generate only the proto file from an OpenAPI specification.
+
+The proto command runs the same OpenAPI to protobuf conversion as 'duh generate'
+but writes nothing else, which makes it suitable for Makefile targets that only
+need to refresh the proto definitions.
+
+The proto package is derived from the version directory of the output path
+(proto/v1/api.proto becomes duh.api.v1) unless --package is given. The
+go_package option is derived from go.mod and the output directory unless
+--proto-import is given.
+
+String enums become proto enums with an UNSPECIFIED zero value and the values
+numbered in declaration order, so new values should be appended. Use
+--enums-as-strings to keep them as strings; the allowed values are then listed
+in the field comment.
+
+Field and enum value numbers are recorded in a proto.lock file next to the
+proto file. Commit it so regenerating keeps existing numbers, even when
+properties are reordered, and reserves the numbers of removed fields.
+
+Large APIs can use --split-by-subject to write one file per subject next to the
+output path instead, e.g. proto/v1/users.proto for /v1/users.create. Error
+responses and schemas used by more than one subject go into common.proto,
+which the subject files import.
+
+With --proto-service a service is added for each subject with an rpc per
+operation, e.g. rpc Create on UsersService for /v1/users.create, so the same
+proto can be served over gRPC or used with service-aware protoc plugins.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Proto file generated successfully
+  2    Error (file not found, validation failed, conversion failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
 
+			outputPath, _ := cmd.Flags().GetString("output")
+			protoImport, _ := cmd.Flags().GetString("proto-import")
+			protoPackage, _ := cmd.Flags().GetString("package")
+			enumsAsStrings, _ := cmd.Flags().GetBool("enums-as-strings")
+			splitBySubject, _ := cmd.Flags().GetBool("split-by-subject")
+			protoService, _ := cmd.Flags().GetBool("proto-service")
+
+			if err := duh.RunProto(duh.ProtoConfig{
+				Writer:       cmd.OutOrStdout(),
+				SpecPath:     filePath,
+				OutputPath:   outputPath,
+				ProtoImport:  protoImport,
+				ProtoPackage: protoPackage,
+				Converter: duh.NewProtoConverter(duh.ProtoOptions{
+					EnumsAsStrings: enumsAsStrings,
+					SplitBySubject: splitBySubject,
+					Services:       protoService,
+				}),
+				Log: log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	protoCmd.Flags().StringP("output", "o", "proto/v1/api.proto", "Output path for the proto file")
+	protoCmd.Flags().String("package", "", "Proto package name (optional)")
+	protoCmd.Flags().String("proto-import", "", "Proto go_package override (optional)")
+	protoCmd.Flags().Bool("enums-as-strings", false, "Keep enum properties as strings instead of proto enums")
+	protoCmd.Flags().Bool("split-by-subject", false, "Write a proto file per subject plus a shared common.proto")
+	protoCmd.Flags().Bool("proto-service", false, "Add a gRPC service definition for the operations to the proto")
+
+	docsCmd := &cobra.Command{
+		Use:   "docs [openapi-file]",
+		Short: "Generate an HTML API reference from an OpenAPI specification",
+		Long: `Generate an HTML API reference from an OpenAPI specification.
+
+The docs command renders an interactive HTML reference for a DUH-RPC spec.
+Operations are grouped by subject and every operation includes a try-it-out
+panel pre-filled with an example request derived from the request schema.
+
+By default the reference is written to 'docs.html'. Use --serve to run a local
+HTTP server instead; the spec is re-read on every page load and try-it-out
+requests are proxied to the configured base URL to avoid CORS issues. Only
+--base-url and the servers of the spec are reachable through the proxy.
+
+With --audience internal, partner or public, only the operations the
+x-duh-audience of the spec shows to that audience are documented.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Reference generated successfully
+  2    Error (file not found, parse error, server failed to start, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			outputPath, _ := cmd.Flags().GetString("output")
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			addr, _ := cmd.Flags().GetString("addr")
+			serve, _ := cmd.Flags().GetBool("serve")
+			audience, _ := cmd.Flags().GetString("audience")
+
+			if err := docs.Run(docs.Config{
+				Writer:     cmd.OutOrStdout(),
+				SpecPath:   filePath,
+				OutputPath: outputPath,
+				BaseURL:    baseURL,
+				Serve:      serve,
+				Addr:       addr,
+				Audience:   audience,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	docsCmd.Flags().StringP("output", "o", "docs.html", "Output path for the generated HTML reference")
+	docsCmd.Flags().String("base-url", "", "Base URL used by try-it-out (defaults to the first server URL)")
+	docsCmd.Flags().String("addr", "localhost:8088", "Listen address used with --serve")
+	docsCmd.Flags().Bool("serve", false, "Serve the reference over HTTP instead of writing a file")
+	docsCmd.Flags().String("audience", "", "Document only the operations x-duh-audience shows to internal, partner or public")
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export an OpenAPI specification to other formats",
+		Long: `Export an OpenAPI specification to other formats.
+
+Use one of the subcommands to choose the export format.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	exportPostmanCmd := &cobra.Command{
+		Use:   "postman [openapi-file]",
+		Short: "Export a Postman collection from an OpenAPI specification",
+		Long: `Export a Postman collection from an OpenAPI specification.
+
+The postman command writes a Postman v2.1 collection with one POST request per
+operation, grouped into folders by subject. Request bodies are pre-filled with
+examples derived from the request schemas. The collection defines 'baseUrl'
+and 'authorization' variables; use --env to also write a Postman environment
+containing the same variables. Insomnia can import the collection directly.
+With --audience, only the operations x-duh-audience shows to it are exported.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Collection exported successfully
+  2    Error (file not found, parse error, write failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			outputPath, _ := cmd.Flags().GetString("output")
+			envPath, _ := cmd.Flags().GetString("env")
+			audience, _ := cmd.Flags().GetString("audience")
+
+			if err := export.Postman(export.PostmanConfig{
+				Writer:          cmd.OutOrStdout(),
+				EnvironmentPath: envPath,
+				OutputPath:      outputPath,
+				SpecPath:        filePath,
+				Audience:        audience,
+				Log:             log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	exportPostmanCmd.Flags().StringP("output", "o", "collection.json", "Output path for the Postman collection")
+	exportPostmanCmd.Flags().String("env", "", "Output path for a Postman environment file (optional)")
+	exportPostmanCmd.Flags().String("audience", "", "Export only the operations x-duh-audience shows to internal, partner or public")
+	exportCmd.AddCommand(exportPostmanCmd)
+
+	exportJSONSchemaCmd := &cobra.Command{
+		Use:   "jsonschema [openapi-file]",
+		Short: "Export the component schemas as JSON Schema files",
+		Long: `Export the component schemas as JSON Schema files.
+
+The jsonschema command writes one draft 2020-12 JSON Schema file per schema in
+components/schemas, named after the schema (e.g. schemas/User.json). Every file
+is standalone: the schemas it references are bundled under $defs. OpenAPI
+specific keywords are translated, e.g. 'nullable: true' adds "null" to the type
+and 'example' becomes 'examples'.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Schemas exported successfully
+  2    Error (file not found, unsupported reference, write failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			outputDir, _ := cmd.Flags().GetString("out")
+			audience, _ := cmd.Flags().GetString("audience")
+
+			if err := export.JSONSchema(export.JSONSchemaConfig{
+				Writer:    cmd.OutOrStdout(),
+				SpecPath:  filePath,
+				OutputDir: outputDir,
+				Audience:  audience,
+				Log:       log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	exportJSONSchemaCmd.Flags().String("out", "schemas", "Output directory for the JSON Schema files")
+	exportJSONSchemaCmd.Flags().String("audience", "", "Leave out the schemas only the operations x-duh-audience hides from internal, partner or public use")
+	exportCmd.AddCommand(exportJSONSchemaCmd)
+
+	convertCmd := &cobra.Command{
+		Use:   "convert <openapi-file>",
+		Short: "Convert a RESTful OpenAPI specification to DUH-RPC",
+		Long: `Convert a RESTful OpenAPI specification to DUH-RPC.
+
+The convert command rewrites every REST operation as a DUH-RPC POST operation:
+  GET    /users        -> POST /users.list
+  GET    /users/{id}   -> POST /users.get
+  POST   /users        -> POST /users.create
+  PUT    /users/{id}   -> POST /users.update
+  DELETE /users/{id}   -> POST /users.delete
+  POST   /users/{id}/activate -> POST /users.activate
+
+Path, query and cookie parameters are folded into a {Resource}{Method}Request
+schema together with the original request body. Status codes are mapped onto
+the DUH-RPC allowed set, every error response references a synthesized Error
+schema, and list operations use cursor-based pagination. Version prefixes such
+as /v1 move from the paths to servers[].url. Schemas are normalized to
+snake_case property names with explicit integer and number formats.
+
+Exit Codes:
+  0    Specification converted successfully
+  2    Error (file not found, parse error, write failed, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			if err := convert.Run(convert.Config{
+				Writer:     cmd.OutOrStdout(),
+				OutputPath: outputPath,
+				SpecPath:   args[0],
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	convertCmd.Flags().StringP("output", "o", "duh-openapi.yaml", "Output path for the converted specification")
+
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a DUH-RPC OpenAPI specification from other formats",
+		Long: `Import a DUH-RPC OpenAPI specification from other formats.
+
+Use one of the subcommands to choose the source format.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	importProtoCmd := &cobra.Command{
+		Use:   "proto <proto-file>",
+		Short: "Generate a DUH-RPC OpenAPI specification from a .proto file",
+		Long: `Generate a DUH-RPC OpenAPI specification from a .proto file.
+
+The proto command maps every rpc of every service onto a DUH-RPC operation at
+/{service}.{method}, where the service name drops any 'Service' suffix. For
+example UserService.GetUser becomes POST /user.get-user. Messages and enums
+become component schemas, nested definitions are named after their parent
+(User.Status becomes UserStatus), and every operation references the standard
+DUH-RPC error responses. The API version is taken from the proto package
+(e.g. acme.users.v2) and placed in servers[].url, defaulting to v1.
+
+Server streaming rpcs respond with application/duh-stream+json. Client
+streaming rpcs have no DUH-RPC equivalent and are skipped.
+
+Exit Codes:
+  0    Specification generated successfully
+  2    Error (file not found, parse error, unknown type, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			if err := convert.FromProto(convert.ProtoConfig{
+				Writer:     cmd.OutOrStdout(),
+				OutputPath: outputPath,
+				ProtoPath:  args[0],
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	importProtoCmd.Flags().StringP("output", "o", "openapi.yaml", "Output path for the generated specification")
+	importCmd.AddCommand(importProtoCmd)
+
+	statsCmd := &cobra.Command{
+		Use:   "stats [openapi-file]",
+		Short: "Report statistics about an OpenAPI specification",
+		Long: `Report statistics about an OpenAPI specification.
+
+The stats command counts the operations of each subject, grouped by the
+version taken from the /vN/ path prefix or the server URL, and the schemas of
+the spec. It lists the operations 'duh generate' detects as list operations,
+reports the longest chain of schemas referencing each other and estimates the
+number of lines 'duh generate' writes for the server, client and proto.
+
+Use --format json for output suited to scripts and reviews.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Statistics reported successfully
+  2    Error (file not found, parse error, unknown format, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			format, _ := cmd.Flags().GetString("format")
+
+			if err := stats.Run(stats.Config{
+				Writer:   cmd.OutOrStdout(),
+				SpecPath: filePath,
+				Format:   format,
+				Log:      log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	statsCmd.Flags().String("format", "text", "Output format: text or json")
+
+	graphCmd := &cobra.Command{
+		Use:   "graph [openapi-file]",
+		Short: "Output the reference graph between operations and schemas",
+		Long: `Output the reference graph between operations and schemas.
+
+The graph command draws an edge from every operation to the schemas its
+request and responses use, and from every schema to the schemas it
+references. Schemas which reference each other are drawn in red and schemas
+no operation reaches are drawn dashed; both are also listed in comments at
+the top of the output.
+
+Use --format dot (default) for Graphviz or --format mermaid for Markdown:
+
+  duh graph | dot -Tsvg -o graph.svg
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Graph written successfully
+  2    Error (file not found, parse error, unknown format, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			format, _ := cmd.Flags().GetString("format")
+
+			if err := graph.Run(graph.Config{
+				Writer:   cmd.OutOrStdout(),
+				SpecPath: filePath,
+				Format:   format,
+				Log:      log,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	graphCmd.Flags().String("format", "dot", "Output format: dot or mermaid")
+
+	validateDataCmd := &cobra.Command{
+		Use:   "validate-data [openapi-file]",
+		Short: "Validate JSON documents against a schema of the spec",
+		Long: `Validate JSON documents against a schema of the spec.
+
+The validate-data command checks JSON payloads, such as test fixtures or the
+requests a producer sends, against a component schema (--schema), the request
+body of an operation (--request) or its 200 response (--response), and lists
+every mismatch with the JSON path of the value:
+
+  duh validate-data --schema CreateUserRequest --file payload.json
+  duh validate-data --request /v1/users.create --file payload.json
+  curl -s ... | duh validate-data --response /v1/users.get --file -
+
+--file may be repeated and - reads stdin. Types, required and unknown
+properties (when additionalProperties is false), enums, formats, lengths,
+patterns, bounds, item counts and allOf, oneOf and anyOf are checked.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Every document matches the schema
+  1    A document does not match the schema
+  2    Error (file not found, parse error, unknown schema, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			schema, _ := cmd.Flags().GetString("schema")
+			request, _ := cmd.Flags().GetString("request")
+			response, _ := cmd.Flags().GetString("response")
+			files, _ := cmd.Flags().GetStringArray("file")
+
+			err := validate.Run(validate.Config{
+				Writer:   cmd.OutOrStdout(),
+				SpecPath: filePath,
+				Schema:   schema,
+				Request:  request,
+				Response: response,
+				Files:    files,
+				Stdin:    cmd.InOrStdin(),
+				Log:      log,
+			})
+			switch {
+			case err == nil:
+				exitCode = ExitOK
+			case errors.Is(err, validate.ErrInvalid):
+				exitCode = ExitViolations
+			default:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+			}
+		},
+	}
+	validateDataCmd.Flags().String("schema", "", "Component schema to validate against")
+	validateDataCmd.Flags().String("request", "", "Path of the operation whose request body to validate against")
+	validateDataCmd.Flags().String("response", "", "Path of the operation whose 200 response to validate against")
+	validateDataCmd.Flags().StringArrayP("file", "f", nil, "JSON document to validate, - reads stdin (repeatable)")
+
+	exampleCmd := &cobra.Command{
+		Use:   "example [openapi-file] <path>",
+		Short: "Print an example request and response of an operation",
+		Long: `Print an example request and response of an operation.
+
+The example command synthesizes a fully populated request of the operation
+and a reply of its first 2XX response from their schemas. Examples, defaults
+and the first enum value of a property are used first, then a placeholder for
+its type and format; --faker fills in realistic data picked by the property
+name instead, such as a person's name for 'name':
+
+  duh example /v1/users.create
+  duh example api/openapi.yaml /v1/users.create --faker
+
+--request and --response print only one of them, as bare JSON for other
+commands to read:
+
+  duh example /v1/users.get --request --faker | duh call /v1/users.get \
+    --descriptors api.binpb --base-url http://localhost:8080 --data -
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    The examples were printed
+  2    Error (file not found, parse error, unknown operation, etc.)`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath, path := defaultFile, args[0]
+			if len(args) > 1 {
+				filePath, path = args[0], args[1]
+			}
+
+			request, _ := cmd.Flags().GetBool("request")
+			response, _ := cmd.Flags().GetBool("response")
+			faker, _ := cmd.Flags().GetBool("faker")
+
+			err := example.Run(example.Config{
+				Writer:   cmd.OutOrStdout(),
+				SpecPath: filePath,
+				Path:     path,
+				Request:  request,
+				Response: response,
+				Options:  example.Options{Faker: faker},
+				Log:      log,
+			})
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+			exitCode = ExitOK
+		},
+	}
+	exampleCmd.Flags().Bool("request", false, "Print only the request, as bare JSON")
+	exampleCmd.Flags().Bool("response", false, "Print only the response, as bare JSON")
+	exampleCmd.Flags().Bool("faker", false, "Fill in realistic data instead of placeholders")
+
+	testCmd := &cobra.Command{
+		Use:   "test",
+		Short: "Test a running server against an OpenAPI specification",
+		Long: `Test a running server against an OpenAPI specification.
+
+Use one of the subcommands to choose the test to run.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	testConformanceCmd := &cobra.Command{
+		Use:   "conformance [openapi-file]",
+		Short: "Check that a live server conforms to the spec",
+		Long: `Check that a live server conforms to the spec.
+
+The conformance command posts a request to every operation of the spec, with
+a body derived from the examples of its request schema, and checks that the
+server answers with a declared status code and content type and a body which
+matches the response schema. The result is a pass/fail matrix with the
+reasons of every failure, which works against any implementation of the spec:
+
+  duh test conformance --base-url http://staging:8080
+  duh test conformance api/openapi.yaml --base-url http://localhost:8080 \
+    --header "Authorization: Bearer $TOKEN"
+
+Responses are matched to the status code, then its range (e.g. 4XX) and then
+the default response. Only application/json bodies are validated.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Every operation conforms to the spec
+  1    An operation does not conform to the spec
+  2    Error (file not found, parse error, missing --base-url, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			headers, _ := cmd.Flags().GetStringArray("header")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			err := conformance.Run(conformance.Config{
+				Writer:   cmd.OutOrStdout(),
+				SpecPath: filePath,
+				BaseURL:  baseURL,
+				Headers:  headers,
+				Timeout:  timeout,
+				Log:      log,
+			})
+			switch {
+			case err == nil:
+				exitCode = ExitOK
+			case errors.Is(err, conformance.ErrFailed):
+				exitCode = ExitViolations
+			default:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+			}
+		},
+	}
+	testConformanceCmd.Flags().String("base-url", "", "URL of the server the operation paths are appended to")
+	testConformanceCmd.Flags().StringArrayP("header", "H", nil, "Header added to every request as 'Name: value' (repeatable)")
+	testConformanceCmd.Flags().Duration("timeout", 10*time.Second, "Timeout of each request")
+	testCmd.AddCommand(testConformanceCmd)
+
+	callCmd := &cobra.Command{
+		Use:   "call <path>",
+		Short: "Call an rpc of a running server without generated code",
+		Long: `Call an rpc of a running server without generated code.
+
+The call command builds the request of the rpc at the path from a protobuf
+descriptor set, sends it as protobuf and prints the reply as JSON. The rpc
+of a path is found the way 'duh proto --proto-service' names it,
+/v1/users.create is rpc Create of UsersService. The proto only has services
+with --proto-service, so write it with the flag and build its descriptor set
+with buf:
+
+  duh proto --proto-service
+  buf build -o api.binpb
+  duh call /v1/users.create --descriptors api.binpb \
+    --base-url http://localhost:8080 --data '{"name": "Ann"}'
+  echo '{"userId": "1"}' | duh call /v1/users.get --descriptors api.binpb \
+    --base-url http://localhost:8080 --data -
+
+The request and reply are in the protojson encoding of the messages.
+
+Exit Codes:
+  0    The server replied with the reply of the rpc
+  1    The server replied with an error
+  2    Error (descriptor set not found, unknown rpc, invalid request, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			descriptors, _ := cmd.Flags().GetString("descriptors")
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			data, _ := cmd.Flags().GetString("data")
+			headers, _ := cmd.Flags().GetStringArray("header")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			err := call.Run(call.Config{
+				Writer:      cmd.OutOrStdout(),
+				Descriptors: descriptors,
+				Path:        args[0],
+				BaseURL:     baseURL,
+				Data:        data,
+				Stdin:       cmd.InOrStdin(),
+				Headers:     headers,
+				Timeout:     timeout,
+				Log:         log,
+			})
+			var reply *duhdyn.Error
+			switch {
+			case err == nil:
+				exitCode = ExitOK
+			case errors.As(err, &reply):
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitViolations
+			default:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+			}
+		},
+	}
+	callCmd.Flags().String("descriptors", "", "Binary protobuf descriptor set, such as 'buf build -o api.binpb' writes")
+	callCmd.Flags().String("base-url", "", "URL of the server the path is appended to")
+	callCmd.Flags().StringP("data", "d", "{}", "Request in JSON, - reads stdin")
+	callCmd.Flags().StringArrayP("header", "H", nil, "Header added to the request as 'Name: value' (repeatable)")
+	callCmd.Flags().Duration("timeout", 10*time.Second, "Timeout of the request")
+
+	splitCmd := &cobra.Command{
+		Use:   "split [openapi-file]",
+		Short: "Split an OpenAPI specification into one file per subject",
+		Long: `Split an OpenAPI specification into one file per subject.
+
+The split command writes the paths of every subject, e.g. /users.create and
+/users.get, to <subject>.yaml in the output directory together with the
+schemas only that subject uses. The root file keeps the info, servers, shared
+schemas and other components, and references the paths of each subject file.
+Component references are rewritten to point at the file which holds them.
+
+Use 'duh bundle' to combine the files into a single spec again.
+
+If no file path is provided, defaults to 'openapi.yaml' in the current directory.
+
+Exit Codes:
+  0    Specification split successfully
+  2    Error (file not found, parse error, write failed, etc.)`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			const defaultFile = "openapi.yaml"
+			filePath := defaultFile
+			if len(args) > 0 {
+				filePath = args[0]
+			}
+
+			outputDir, _ := cmd.Flags().GetString("output-dir")
+			by, _ := cmd.Flags().GetString("by")
+
+			if err := split.Run(split.Config{
+				Writer:    cmd.OutOrStdout(),
+				SpecPath:  filePath,
+				OutputDir: outputDir,
+				By:        by,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	splitCmd.Flags().StringP("output-dir", "o", "specs", "Directory to write the split files to")
+	splitCmd.Flags().String("by", "subject", "How paths are grouped into files (subject)")
+
+	bundleCmd := &cobra.Command{
+		Use:   "bundle <openapi-file>",
+		Short: "Bundle an OpenAPI specification split across files into one",
+		Long: `Bundle an OpenAPI specification split across files into one.
+
+The bundle command inlines the path items the root spec references in other
+files and copies the components they use into the root spec's components,
+rewriting every reference to point inside the bundled file. A component
+defined in more than one file is reported as an error. The result can be
+used by every other duh command.
+
+Exit Codes:
+  0    Specification bundled successfully
+  2    Error (file not found, missing reference, conflicting components, etc.)`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			outputPath, _ := cmd.Flags().GetString("output")
+
+			if err := split.Bundle(split.BundleConfig{
+				Writer:     cmd.OutOrStdout(),
+				SpecPath:   args[0],
+				OutputPath: outputPath,
+			}); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+				exitCode = ExitError
+				return
+			}
+		},
+	}
+	bundleCmd.Flags().StringP("output", "o", "openapi.yaml", "Output path for the bundled specification")
+
+	rootCmd.AddCommand(lintCmd, initCmd, addCmd, removeCmd, renameCmd, generateCmd, protoCmd, docsCmd, exportCmd, convertCmd, importCmd, statsCmd, graphCmd, validateDataCmd, exampleCmd, testCmd, callCmd, splitCmd, bundleCmd)
+	rootCmd.SetOut(stdout)
+	rootCmd.SetErr(stderr)
+	rootCmd.SetArgs(args)
+
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		exitCode = ExitError
+		if format, _ := rootCmd.PersistentFlags().GetString("output"); format == output.JSON {
+			report = &output.Result{Command: cmd.Name()}
+			report.FailUsage(err)
+		} else {
+			_, _ = fmt.Fprintf(stderr, "Error: %v\n", err)
+			_, _ = fmt.Fprintln(stderr, cmd.UsageString())
+		}
+	}
+
+	if report != nil {
+		_ = report.Write(stdout, exitCode)
+	}
 	return exitCode
 }
@@ -25,7 +25,7 @@ func TestMain(m *testing.M) {
 
 func TestRunCmdHelp(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"--help"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"--help"})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "duh is a command-line tool")
@@ -35,7 +35,7 @@ func TestRunCmdHelp(t *testing.T) {
 
 func TestRunCmdVersion(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"--version"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"--version"})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "duh version")
@@ -44,13 +44,121 @@ func TestRunCmdVersion(t *testing.T) {
 
 func TestRunCmdFileNotFound(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", "nonexistent.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "nonexistent.yaml"})
 
 	assert.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "Error:")
 	assert.Contains(t, stdout.String(), "file not found")
 }
 
+func TestRunCmdErrorsGoToStderr(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "CommandError",
+			args:    []string{"lint", "nonexistent.yaml"},
+			wantErr: "Error: file not found: nonexistent.yaml\n",
+		},
+		{
+			name:    "TooManyArgs",
+			args:    []string{"lint", "a.yaml", "b.yaml"},
+			wantErr: "Error: accepts at most 1 arg(s), received 2\nUsage:",
+		},
+		{
+			name:    "UnknownFlag",
+			args:    []string{"generate", "--bogus"},
+			wantErr: "Error: unknown flag: --bogus\nUsage:",
+		},
+		{
+			name:    "UnknownCommand",
+			args:    []string{"bogus"},
+			wantErr: `Error: unknown command "bogus" for "duh"`,
+		},
+		{
+			name:    "UnknownSubcommand",
+			args:    []string{"export", "bogus"},
+			wantErr: `Error: unknown command "bogus" for "duh export"`,
+		},
+		{
+			name:    "VerboseAndQuiet",
+			args:    []string{"lint", "-v", "-q"},
+			wantErr: "Error: --verbose cannot be combined with --quiet\nUsage:",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			exitCode := duh.RunCmd(&stdout, &stderr, test.args)
+
+			assert.Equal(t, duh.ExitError, exitCode)
+			assert.Empty(t, stdout.String())
+			assert.Contains(t, stderr.String(), test.wantErr)
+		})
+	}
+}
+
+func TestRunCmdViolationsGoToStdout(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"lint", filepath.Join(testStartDir, "internal/lint/testdata/multiple-violations.yaml")})
+
+	assert.Equal(t, duh.ExitViolations, exitCode)
+	assert.Contains(t, stdout.String(), "[ERROR]")
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunCmdQuiet(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/example/test\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, duh.ExitOK, duh.RunCmd(&stdout, &stderr, []string{"init", "-q"}))
+	require.Equal(t, duh.ExitOK, duh.RunCmd(&stdout, &stderr, []string{"generate", "-q"}))
+	assert.Empty(t, stdout.String())
+	assert.Empty(t, stderr.String())
+	assert.FileExists(t, "server.go")
+
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"lint", "-q", filepath.Join(testStartDir, "internal/lint/testdata/multiple-violations.yaml")})
+	assert.Equal(t, duh.ExitViolations, exitCode)
+	assert.True(t, strings.HasPrefix(stdout.String(), "[ERROR]"))
+	assert.NotContains(t, stdout.String(), "errors,")
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunCmdVerbose(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+	require.NoError(t, os.WriteFile("go.mod", []byte("module github.com/example/test\n"), 0644))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, duh.ExitOK, duh.RunCmd(&stdout, &stderr, []string{"init"}))
+	require.Equal(t, duh.ExitOK, duh.RunCmd(&stdout, &stderr, []string{"generate"}))
+	require.Empty(t, stderr.String())
+
+	stdout.Reset()
+	exitCode := duh.RunCmd(&stdout, &stderr, []string{"generate", "--verbose", "--force"})
+	require.Equal(t, duh.ExitOK, exitCode)
+	assert.Contains(t, stderr.String(), "verbose: parsed openapi.yaml in ")
+	assert.Contains(t, stderr.String(), "verbose: ran 51 rule(s) against openapi.yaml: PATH_FORMAT, ")
+	assert.Contains(t, stderr.String(), "verbose: rendering server.go\n")
+	assert.Contains(t, stderr.String(), "verbose: skipped buf.yaml: already exists\n")
+	assert.Contains(t, stderr.String(), "verbose: render phase took ")
+	assert.Contains(t, stderr.String(), "verbose: proto convert phase took ")
+	assert.Contains(t, stderr.String(), "verbose: write phase took ")
+	assert.NotContains(t, stdout.String(), "verbose:")
+	assert.Contains(t, stdout.String(), "✓ Generated")
+
+	stderr.Reset()
+	exitCode = duh.RunCmd(&stdout, &stderr, []string{"lint", "-v", "--disable", "DESCRIPTION_REQUIRED"})
+	require.Equal(t, duh.ExitOK, exitCode)
+	assert.Contains(t, stderr.String(), "verbose: ran 50 rule(s) against openapi.yaml")
+	assert.Contains(t, stderr.String(), "verbose: skipped 1 disabled rule(s): DESCRIPTION_REQUIRED\n")
+}
+
 func TestRunCmdNoArguments(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -67,7 +175,7 @@ func TestRunCmdNoArguments(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint"})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -76,7 +184,7 @@ func TestRunCmdNoArguments(t *testing.T) {
 
 func TestRunCmdMultipleArguments(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", "file1.yaml", "file2.yaml"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", "file1.yaml", "file2.yaml"})
 
 	assert.Equal(t, 2, exitCode)
 	output := strings.ToLower(stdout.String())
@@ -98,7 +206,7 @@ func TestLintWithDefaultFile(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint"})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -113,7 +221,7 @@ func TestLintWithDefaultFileNotFound(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint"})
 
 	assert.Equal(t, 2, exitCode)
 	assert.Contains(t, stdout.String(), "Error:")
@@ -137,7 +245,7 @@ func TestLintWithExplicitFile(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"lint", customFile})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"lint", customFile})
 
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout.String(), "✓")
@@ -153,7 +261,7 @@ func TestInitCommandWithDefaultPath(t *testing.T) {
 
 	var stdout bytes.Buffer
 	const defaultOutput = "openapi.yaml"
-	exitCode := duh.RunCmd(&stdout, []string{"init"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init"})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "✓ Created DUH-RPC compliant OpenAPI spec")
@@ -170,7 +278,7 @@ func TestInitCommandWithCustomPath(t *testing.T) {
 	customPath := filepath.Join(tempDir, "custom-api.yaml")
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"init", customPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", customPath})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "✓ Created DUH-RPC compliant OpenAPI spec")
@@ -190,7 +298,7 @@ func TestInitCommandFileAlreadyExists(t *testing.T) {
 	require.NoError(t, err)
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"init", existingFile})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", existingFile})
 
 	require.Equal(t, 2, exitCode)
 	require.Contains(t, stdout.String(), "Error:")
@@ -202,7 +310,7 @@ func TestInitCommandCreatesNestedDirectories(t *testing.T) {
 	nestedPath := filepath.Join(tempDir, "api", "v1", "openapi.yaml")
 
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"init", nestedPath})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", nestedPath})
 
 	require.Equal(t, 0, exitCode)
 
@@ -216,11 +324,11 @@ func TestInitGeneratedFilePassesLint(t *testing.T) {
 	outputPath := filepath.Join(tempDir, "openapi.yaml")
 
 	var initStdout bytes.Buffer
-	initExitCode := duh.RunCmd(&initStdout, []string{"init", outputPath})
+	initExitCode := duh.RunCmd(&initStdout, &initStdout, []string{"init", outputPath})
 	require.Equal(t, 0, initExitCode)
 
 	var lintStdout bytes.Buffer
-	lintExitCode := duh.RunCmd(&lintStdout, []string{"lint", outputPath})
+	lintExitCode := duh.RunCmd(&lintStdout, &lintStdout, []string{"lint", outputPath})
 	require.Equal(t, 0, lintExitCode)
 	require.Contains(t, lintStdout.String(), "✓")
 	require.Contains(t, lintStdout.String(), "DUH-RPC compliant")
@@ -228,7 +336,7 @@ func TestInitGeneratedFilePassesLint(t *testing.T) {
 
 func TestInitCommandHelp(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"init", "--help"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"init", "--help"})
 
 	require.Equal(t, 0, exitCode)
 	require.Contains(t, stdout.String(), "init")
@@ -238,7 +346,7 @@ func TestInitCommandHelp(t *testing.T) {
 
 func TestGenerateOapiCommandRemoved(t *testing.T) {
 	var stdout bytes.Buffer
-	exitCode := duh.RunCmd(&stdout, []string{"generate", "oapi"})
+	exitCode := duh.RunCmd(&stdout, &stdout, []string{"generate", "oapi"})
 
 	require.Equal(t, 2, exitCode)
 	output := strings.ToLower(stdout.String())
@@ -301,7 +409,7 @@ components:
 	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
 	require.NoError(t, os.Chdir(tempDir))
 
-	exitCode := duh.RunCmd(&stdout1, []string{"lint", specPath})
+	exitCode := duh.RunCmd(&stdout1, &stdout1, []string{"lint", specPath})
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout1.String(), "DESCRIPTION_REQUIRED")
 
@@ -314,11 +422,100 @@ components:
 
 	// With config, DESCRIPTION_REQUIRED should not appear
 	var stdout2 bytes.Buffer
-	exitCode = duh.RunCmd(&stdout2, []string{"lint", specPath})
+	exitCode = duh.RunCmd(&stdout2, &stdout2, []string{"lint", specPath})
 	assert.Equal(t, 0, exitCode)
 	assert.NotContains(t, stdout2.String(), "DESCRIPTION_REQUIRED")
 }
 
+func TestConfigSecurityRules(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// The response returns a card_number and the request takes a nickname
+	specContent := `openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /cards.create:
+    post:
+      description: Add a card
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateRequest'
+      responses:
+        200:
+          description: Success
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/CreateResponse'
+        400:
+          description: Bad request
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  schemas:
+    CreateRequest:
+      type: object
+      properties:
+        nickname:
+          description: Name of the card holder
+          type: string
+    CreateResponse:
+      type: object
+      properties:
+        card_number:
+          description: Number of the card
+          type: string
+    Error:
+      type: object
+      required: [message]
+      properties:
+        message:
+          description: Error message
+          type: string`
+
+	specPath := filepath.Join(tempDir, "spec.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(specContent), 0644))
+	t.Cleanup(func() { _ = os.Chdir(testStartDir) })
+	require.NoError(t, os.Chdir(tempDir))
+
+	// Without config, the security rules do not run
+	var stdout1 bytes.Buffer
+	exitCode := duh.RunCmd(&stdout1, &stdout1, []string{"lint", specPath})
+	assert.Equal(t, 0, exitCode)
+	assert.NotContains(t, stdout1.String(), "SECURITY_")
+
+	// Enabled with the default names, card_number is returned in a response
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".duh.yaml"), []byte("lint:\n  security:\n    enabled: true\n"), 0644))
+	var stdout2 bytes.Buffer
+	exitCode = duh.RunCmd(&stdout2, &stdout2, []string{"lint", specPath})
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout2.String(), "[ERROR] [SECURITY_SENSITIVE_RESPONSE] components/schemas/CreateResponse/card_number")
+	assert.Contains(t, stdout2.String(), "[WARNING] [SECURITY_SENSITIVE_REDACT] components/schemas/CreateResponse/card_number")
+	assert.NotContains(t, stdout2.String(), "nickname")
+
+	// The names of the config replace the default names
+	configContent := `lint:
+  security:
+    enabled: true
+    sensitive: [nickname]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".duh.yaml"), []byte(configContent), 0644))
+	var stdout3 bytes.Buffer
+	exitCode = duh.RunCmd(&stdout3, &stdout3, []string{"lint", specPath})
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, stdout3.String(), "Property 'nickname' looks like sensitive data (nickname) but is not marked x-duh-redact")
+	assert.NotContains(t, stdout3.String(), "card_number")
+}
+
 func TestCliDisableFlag(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -372,13 +569,13 @@ components:
 
 	// Without --disable, DESCRIPTION_REQUIRED appears
 	var stdout1 bytes.Buffer
-	exitCode := duh.RunCmd(&stdout1, []string{"lint", specPath})
+	exitCode := duh.RunCmd(&stdout1, &stdout1, []string{"lint", specPath})
 	assert.Equal(t, 0, exitCode)
 	assert.Contains(t, stdout1.String(), "DESCRIPTION_REQUIRED")
 
 	// With --disable DESCRIPTION_REQUIRED, it should not appear
 	var stdout2 bytes.Buffer
-	exitCode = duh.RunCmd(&stdout2, []string{"lint", "--disable", "DESCRIPTION_REQUIRED", specPath})
+	exitCode = duh.RunCmd(&stdout2, &stdout2, []string{"lint", "--disable", "DESCRIPTION_REQUIRED", specPath})
 	assert.Equal(t, 0, exitCode)
 	assert.NotContains(t, stdout2.String(), "DESCRIPTION_REQUIRED")
 }